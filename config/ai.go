@@ -0,0 +1,147 @@
+package config
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AIRetryConfig controls retries of failed LLM calls
+type AIRetryConfig struct {
+	MaxAttempts    int
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	RequestTimeout time.Duration
+}
+
+// GetAIRetryConfig retrieves AI retry configuration from environment variables
+func GetAIRetryConfig() AIRetryConfig {
+	return AIRetryConfig{
+		MaxAttempts:    parseInt("AI_MAX_ATTEMPTS", 3),
+		BaseDelay:      parseDuration("AI_RETRY_BASE_DELAY", 2*time.Second),
+		MaxDelay:       parseDuration("AI_RETRY_MAX_DELAY", 30*time.Second),
+		RequestTimeout: parseDuration("AI_REQUEST_TIMEOUT", 120*time.Second),
+	}
+}
+
+// AIProviderConfig holds LLM provider selection and credentials
+type AIProviderConfig struct {
+	DefaultProvider string
+	GeminiAPIKey    string
+	GeminiModel     string
+	OpenAIAPIKey    string
+	OpenAIModel     string
+	OpenAIBaseURL   string
+	FakeModel       string
+}
+
+// GetAIProviderConfig retrieves LLM provider configuration from environment variables
+func GetAIProviderConfig() AIProviderConfig {
+	return AIProviderConfig{
+		DefaultProvider: getEnv("AI_PROVIDER", "gemini"),
+		GeminiAPIKey:    getEnv("GEMINI_API_KEY", ""),
+		GeminiModel:     getEnv("GEMINI_MODEL", "gemini-2.0-flash"),
+		OpenAIAPIKey:    getEnv("OPENAI_API_KEY", ""),
+		OpenAIModel:     getEnv("OPENAI_MODEL", "gpt-4o-2024-08-06"),
+		OpenAIBaseURL:   getEnv("OPENAI_BASE_URL", ""),
+		FakeModel:       getEnv("FAKE_MODEL", "fake-rps-v1"),
+	}
+}
+
+// AIParamConfig holds the admin-allowed ranges for per-request model parameters.
+// Requests are also held to the limits of the selected provider.
+type AIParamConfig struct {
+	AllowedModels  map[string][]string // per provider selection name, besides the provider's configured model
+	MinTemperature float64
+	MaxTemperature float64
+	MinTopP        float64
+	MaxTopP        float64
+	MaxTopK        int
+	MaxTokens      int
+}
+
+// GetAIParamConfig retrieves allowed model parameter ranges from environment variables
+func GetAIParamConfig() AIParamConfig {
+	return AIParamConfig{
+		AllowedModels: map[string][]string{
+			"gemini": parseList("AI_ALLOWED_MODELS_GEMINI"),
+			"openai": parseList("AI_ALLOWED_MODELS_OPENAI"),
+			"fake":   parseList("AI_ALLOWED_MODELS_FAKE"),
+		},
+		MinTemperature: parseFloat("AI_MIN_TEMPERATURE", 0),
+		MaxTemperature: parseFloat("AI_MAX_TEMPERATURE", 2),
+		MinTopP:        parseFloat("AI_MIN_TOP_P", 0),
+		MaxTopP:        parseFloat("AI_MAX_TOP_P", 1),
+		MaxTopK:        parseInt("AI_MAX_TOP_K", 100),
+		MaxTokens:      parseInt("AI_MAX_OUTPUT_TOKENS", 65536),
+	}
+}
+
+// parseFloat reads a non-negative number environment variable with fallback default value
+func parseFloat(key string, defaultValue float64) float64 {
+	value := getEnv(key, "")
+	if value == "" {
+		return defaultValue
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		log.Printf("Warning: invalid %s %q, using default %g", key, value, defaultValue)
+		return defaultValue
+	}
+	return f
+}
+
+// parseList reads a comma-separated environment variable, skipping empty entries
+func parseList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ModelPrice is the USD price per 1M prompt and completion tokens of a model
+type ModelPrice struct {
+	Prompt     float64
+	Completion float64
+}
+
+// defaultModelPrices are list prices, used for models AI_MODEL_PRICES leaves out
+var defaultModelPrices = map[string]ModelPrice{
+	"gemini-2.0-flash":      {0.10, 0.40},
+	"gemini-2.0-flash-lite": {0.075, 0.30},
+	"gemini-1.5-flash":      {0.075, 0.30},
+	"gemini-1.5-pro":        {1.25, 5.00},
+	"gemini-2.5-flash":      {0.30, 2.50},
+	"gemini-2.5-pro":        {1.25, 10.00},
+	"gpt-4o":                {2.50, 10.00},
+	"gpt-4o-2024-08-06":     {2.50, 10.00},
+	"gpt-4o-mini":           {0.15, 0.60},
+	"fake-rps-v1":           {0, 0},
+}
+
+// GetAIModelPrices retrieves the model price table. AI_MODEL_PRICES adds or
+// overrides entries as "model=prompt:completion", comma-separated.
+func GetAIModelPrices() map[string]ModelPrice {
+	prices := make(map[string]ModelPrice, len(defaultModelPrices))
+	for model, price := range defaultModelPrices {
+		prices[model] = price
+	}
+
+	for _, entry := range parseList("AI_MODEL_PRICES") {
+		model, rates, ok := strings.Cut(entry, "=")
+		promptRate, completionRate, ok2 := strings.Cut(rates, ":")
+		prompt, err := strconv.ParseFloat(strings.TrimSpace(promptRate), 64)
+		completion, err2 := strconv.ParseFloat(strings.TrimSpace(completionRate), 64)
+		if !ok || !ok2 || err != nil || err2 != nil || prompt < 0 || completion < 0 {
+			log.Printf("Warning: invalid AI_MODEL_PRICES entry %q, ignoring it", entry)
+			continue
+		}
+		prices[strings.TrimSpace(model)] = ModelPrice{Prompt: prompt, Completion: completion}
+	}
+	return prices
+}
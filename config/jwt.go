@@ -0,0 +1,42 @@
+package config
+
+import (
+	"log"
+	"time"
+)
+
+// JWTConfig holds token signing configuration
+type JWTConfig struct {
+	Secret        string
+	AccessExpiry  time.Duration
+	RefreshExpiry time.Duration
+}
+
+// GetJWTConfig retrieves JWT configuration from environment variables
+func GetJWTConfig() JWTConfig {
+	secret := getEnv("JWT_SECRET", "")
+	if secret == "" {
+		log.Println("Warning: JWT_SECRET is not set, tokens cannot be issued")
+	}
+
+	return JWTConfig{
+		Secret:        secret,
+		AccessExpiry:  parseDuration("JWT_EXPIRY", 15*time.Minute),
+		RefreshExpiry: parseDuration("JWT_REFRESH_EXPIRY", 7*24*time.Hour),
+	}
+}
+
+// parseDuration reads a duration environment variable with fallback default value
+func parseDuration(key string, defaultValue time.Duration) time.Duration {
+	value := getEnv(key, "")
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s %q, using default %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"log"
+	"strconv"
+	"time"
+)
+
+// WorkerConfig holds generation job queue configuration
+type WorkerConfig struct {
+	Concurrency       int
+	PollInterval      time.Duration
+	LeaseDuration     time.Duration
+	HeartbeatInterval time.Duration
+	MaxAttempts       int
+	ShutdownTimeout   time.Duration
+}
+
+// GetWorkerConfig retrieves generation worker configuration from environment variables
+func GetWorkerConfig() WorkerConfig {
+	return WorkerConfig{
+		Concurrency:       parseInt("GENERATION_WORKERS", 2),
+		PollInterval:      parseDuration("GENERATION_POLL_INTERVAL", 2*time.Second),
+		LeaseDuration:     parseDuration("GENERATION_LEASE_DURATION", 2*time.Minute),
+		HeartbeatInterval: parseDuration("GENERATION_HEARTBEAT_INTERVAL", 30*time.Second),
+		MaxAttempts:       parseInt("GENERATION_MAX_ATTEMPTS", 3),
+		ShutdownTimeout:   parseDuration("GENERATION_SHUTDOWN_TIMEOUT", 3*time.Minute),
+	}
+}
+
+// parseInt reads a positive integer environment variable with fallback default value
+func parseInt(key string, defaultValue int) int {
+	value := getEnv(key, "")
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Warning: invalid %s %q, using default %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
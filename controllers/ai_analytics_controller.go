@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+type AIAnalyticsController struct {
+	service services.AIAnalyticsService
+}
+
+func NewAIAnalyticsController(service services.AIAnalyticsService) *AIAnalyticsController {
+	return &AIAnalyticsController{service: service}
+}
+
+// Analytics godoc
+// @Summary AI traffic analytics
+// @Description Hourly or daily series (UTC) of requests, failures by error class, p50/p95/p99 latency of the successful calls, tokens and cost, with totals over the range. Hourly series cover at most 31 days, daily series at most 366.
+// @Tags AI Admin
+// @Produce json
+// @Param interval query string false "hour or day (default day)"
+// @Param from query string false "Start (RFC3339, inclusive; default 24 hours or 30 days before to)"
+// @Param to query string false "End (RFC3339, exclusive; default now)"
+// @Param model query string false "Model name"
+// @Param course_id query string false "Course ID"
+// @Param program_id query string false "Program ID"
+// @Success 200 {object} dto.APIResponse{data=dto.AIAnalyticsResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /api/v1/admin/ai/analytics [get]
+func (c *AIAnalyticsController) Analytics(ctx *gin.Context) {
+	query := dto.AIAnalyticsQuery{
+		Interval: ctx.Query("interval"),
+		Model:    ctx.Query("model"),
+	}
+
+	var ok bool
+	if query.From, ok = queryTime(ctx, "from"); !ok {
+		return
+	}
+	if query.To, ok = queryTime(ctx, "to"); !ok {
+		return
+	}
+	if query.CourseID, ok = queryUUID(ctx, "course_id"); !ok {
+		return
+	}
+	if query.ProgramID, ok = queryUUID(ctx, "program_id"); !ok {
+		return
+	}
+
+	analytics, err := c.service.Analytics(ctx.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, helper.ErrInvalidInput) {
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_QUERY", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to build AI analytics", "ANALYTICS_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("AI analytics built successfully", analytics))
+}
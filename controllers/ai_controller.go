@@ -1,318 +1,249 @@
 package controllers
 
 import (
-	"context"
-	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/middleware"
 	"github.com/syrlramadhan/dokumentasi-rps-api/services"
-	"gorm.io/datatypes"
 )
 
 type AIController struct {
-	aiService              services.AIService
-	generatedRPSService    services.GeneratedRPSService
-	templateVersionService services.TemplateVersionService
-	courseService          services.CourseService
+	aiService         services.AIService
+	generationService services.GenerationService
 }
 
-func NewAIController(
-	aiService services.AIService,
-	generatedRPSService services.GeneratedRPSService,
-	templateVersionService services.TemplateVersionService,
-	courseService services.CourseService,
-) *AIController {
+func NewAIController(aiService services.AIService, generationService services.GenerationService) *AIController {
 	return &AIController{
-		aiService:              aiService,
-		generatedRPSService:    generatedRPSService,
-		templateVersionService: templateVersionService,
-		courseService:          courseService,
+		aiService:         aiService,
+		generationService: generationService,
 	}
 }
 
-// GenerateRPSWithAI - Generate RPS menggunakan OpenAI (Synchronous)
+// GenerateRPSWithAI - Generate RPS menggunakan LLM provider (Synchronous)
 // @Summary Generate RPS with AI (Sync)
-// @Description Generate RPS using OpenAI structured output - waits for completion
+// @Description Generate RPS using the configured LLM provider's structured output - waits for completion
 // @Tags AI
 // @Accept json
 // @Produce json
 // @Param request body dto.GenerateRPSRequest true "Generate RPS Request"
 // @Success 200 {object} dto.APIResponse{data=dto.GeneratedRPSResponse}
 // @Failure 400 {object} dto.APIResponse
+// @Failure 429 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
 // @Router /api/v1/generate/sync [post]
 func (ctrl *AIController) GenerateRPSWithAI(c *gin.Context) {
-	var req dto.GenerateRPSRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
-		return
-	}
-
-	// Validate
-	if req.TemplateVersionID == uuid.Nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse("template_version_id is required", "VALIDATION_ERROR", nil))
-		return
-	}
-	if req.CourseID == uuid.Nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse("course_id is required", "VALIDATION_ERROR", nil))
-		return
-	}
-
-	// Get template version
-	templateVersion, err := ctrl.templateVersionService.FindByID(req.TemplateVersionID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse("Template version not found", "NOT_FOUND", nil))
-		return
-	}
-
-	// Get course
-	course, err := ctrl.courseService.FindByID(req.CourseID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse("Course not found", "NOT_FOUND", nil))
+	req, ok := ctrl.bindGenerateRequest(c)
+	if !ok {
 		return
 	}
 
-	// Create initial generated_rps record
-	createReq := &dto.CreateGeneratedRPSRequest{
-		TemplateVersionID: &req.TemplateVersionID,
-		CourseID:          &req.CourseID,
-		GeneratedBy:       req.GeneratedBy,
-	}
-
-	generatedRPS, err := ctrl.generatedRPSService.Create(createReq)
+	generatedRPS, err := ctrl.generationService.GenerateSync(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to create job", "CREATE_ERROR", nil))
-		return
-	}
-
-	// Update status to processing
-	ctrl.generatedRPSService.UpdateStatus(generatedRPS.ID, "processing")
-
-	// Parse template definition
-	var templateDef map[string]interface{}
-	if templateVersion.Definition != nil {
-		defBytes, _ := json.Marshal(templateVersion.Definition)
-		json.Unmarshal(defBytes, &templateDef)
-	}
-	templateDef["id"] = templateVersion.ID.String()
-
-	// Build course data
-	courseData := map[string]interface{}{
-		"id":      course.ID.String(),
-		"title":   course.Title,
-		"code":    course.Code,
-		"credits": course.Credits,
-	}
-
-	// Build options - copy all fields from request
-	options := dto.GenerateRPSOptions{
-		Language: "Indonesia",
-		Tone:     "formal",
-	}
-	if req.Options != nil {
-		if req.Options.Language != "" {
-			options.Language = req.Options.Language
+		if ctrl.respondJobNotFound(c, err) {
+			return
 		}
-		if req.Options.Tone != "" {
-			options.Tone = req.Options.Tone
+		if respondBudgetExceeded(c, err) {
+			return
 		}
-		if req.Options.DosenPengampu != "" {
-			options.DosenPengampu = req.Options.DosenPengampu
+		if errors.Is(err, helper.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_MODEL_PARAMS", nil))
+			return
 		}
-		if req.Options.Semester != "" {
-			options.Semester = req.Options.Semester
+		if errors.Is(err, services.ErrJobCancelled) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse("Generation was cancelled", "CANCELLED", nil))
+			return
 		}
-		if req.Options.Prasyarat != "" {
-			options.Prasyarat = req.Options.Prasyarat
-		}
-		if req.Options.ProgramStudi != "" {
-			options.ProgramStudi = req.Options.ProgramStudi
-		}
-		if req.Options.Fakultas != "" {
-			options.Fakultas = req.Options.Fakultas
-		}
-		if req.Options.TahunAkademik != "" {
-			options.TahunAkademik = req.Options.TahunAkademik
-		}
-		options.Overrides = req.Options.Overrides
-	}
-
-	// Call AI service
-	aiResult, err := ctrl.aiService.GenerateRPS(c.Request.Context(), generatedRPS.ID.String(), courseData, templateDef, options)
-	if err != nil {
-		ctrl.generatedRPSService.UpdateStatus(generatedRPS.ID, "failed")
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("AI generation failed", "AI_ERROR", map[string]string{"error": err.Error()}))
 		return
 	}
 
-	// Update generated_rps with result
-	resultJSON, _ := json.Marshal(aiResult.Result)
-	metadataJSON, _ := json.Marshal(aiResult.AIMetadata)
-
-	status := "done"
-	updateReq := &dto.UpdateGeneratedRPSRequest{
-		Status:     &status,
-		Result:     datatypes.JSON(resultJSON),
-		AIMetadata: datatypes.JSON(metadataJSON),
-	}
-
-	updatedRPS, err := ctrl.generatedRPSService.Update(generatedRPS.ID, updateReq)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to update result", "UPDATE_ERROR", nil))
-		return
-	}
-
-	c.JSON(http.StatusOK, dto.SuccessResponse("RPS generated successfully", updatedRPS))
+	c.JSON(http.StatusOK, dto.SuccessResponse("RPS generated successfully", generatedRPS))
 }
 
 // GenerateRPSAsync - Generate RPS secara async (return job_id langsung)
 // @Summary Generate RPS Async
-// @Description Start async RPS generation, returns job_id immediately
+// @Description Queue RPS generation for the worker pool, returns job_id immediately
 // @Tags AI
 // @Accept json
 // @Produce json
 // @Param request body dto.GenerateRPSRequest true "Generate RPS Request"
 // @Success 202 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 429 {object} dto.APIResponse
 // @Router /api/v1/generate [post]
 func (ctrl *AIController) GenerateRPSAsync(c *gin.Context) {
-	var req dto.GenerateRPSRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
-		return
-	}
-
-	// Validate
-	if req.TemplateVersionID == uuid.Nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse("template_version_id is required", "VALIDATION_ERROR", nil))
-		return
-	}
-	if req.CourseID == uuid.Nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse("course_id is required", "VALIDATION_ERROR", nil))
+	req, ok := ctrl.bindGenerateRequest(c)
+	if !ok {
 		return
 	}
 
-	// Create job with status "queued"
-	createReq := &dto.CreateGeneratedRPSRequest{
-		TemplateVersionID: &req.TemplateVersionID,
-		CourseID:          &req.CourseID,
-		GeneratedBy:       req.GeneratedBy,
-	}
-
-	generatedRPS, err := ctrl.generatedRPSService.Create(createReq)
+	generatedRPS, err := ctrl.generationService.Enqueue(req)
 	if err != nil {
+		if ctrl.respondJobNotFound(c, err) {
+			return
+		}
+		if respondBudgetExceeded(c, err) {
+			return
+		}
+		if errors.Is(err, helper.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_MODEL_PARAMS", nil))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to create job", "CREATE_ERROR", nil))
 		return
 	}
 
-	// Start async generation in goroutine
-	go ctrl.processGenerationAsync(generatedRPS.ID, req)
-
-	c.JSON(http.StatusAccepted, dto.SuccessResponse("RPS generation started", gin.H{
+	c.JSON(http.StatusAccepted, dto.SuccessResponse("RPS generation queued", gin.H{
 		"job_id": generatedRPS.ID,
-		"status": "queued",
+		"status": generatedRPS.Status,
 	}))
 }
 
-func (ctrl *AIController) processGenerationAsync(jobID uuid.UUID, req dto.GenerateRPSRequest) {
-	// Update to processing
-	ctrl.generatedRPSService.UpdateStatus(jobID, "processing")
-
-	// Get template version
-	templateVersion, err := ctrl.templateVersionService.FindByID(req.TemplateVersionID)
+// CancelJob - Hentikan job generate yang masih antre atau berjalan
+// @Summary Cancel an RPS generation job
+// @Description Cancel a queued or processing job; the in-flight LLM request is aborted and the job is marked cancelled
+// @Tags AI
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} dto.APIResponse{data=dto.GeneratedRPSResponse}
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /api/v1/generate/{job_id}/cancel [post]
+func (ctrl *AIController) CancelJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("job_id"))
 	if err != nil {
-		ctrl.markAsFailed(jobID, "Template version not found")
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid job ID", "INVALID_ID", nil))
 		return
 	}
 
-	// Get course
-	course, err := ctrl.courseService.FindByID(req.CourseID)
+	generatedRPS, err := ctrl.generationService.Cancel(id)
 	if err != nil {
-		ctrl.markAsFailed(jobID, "Course not found")
+		switch {
+		case helper.IsNotFoundError(err):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse("Job not found", "NOT_FOUND", nil))
+		case errors.Is(err, services.ErrJobNotCancellable):
+			c.JSON(http.StatusConflict, dto.ErrorResponse(err.Error(), "INVALID_STATE", nil))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to cancel job", "CANCEL_ERROR", nil))
+		}
 		return
 	}
 
-	// Parse template definition
-	var templateDef map[string]interface{}
-	if templateVersion.Definition != nil {
-		defBytes, _ := json.Marshal(templateVersion.Definition)
-		json.Unmarshal(defBytes, &templateDef)
-	}
-	templateDef["id"] = templateVersion.ID.String()
-
-	// Build course data
-	courseData := map[string]interface{}{
-		"id":      course.ID.String(),
-		"title":   course.Title,
-		"code":    course.Code,
-		"credits": course.Credits,
+	c.JSON(http.StatusOK, dto.SuccessResponse("RPS generation cancelled", generatedRPS))
+}
+
+// RegenerateSection - Generate ulang satu bagian RPS yang sudah selesai
+// @Summary Regenerate an RPS section
+// @Description Regenerate one section of a finished RPS (optionally a range of weeks of rencana_mingguan) and merge it into the stored result
+// @Tags AI
+// @Accept json
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param section path string true "Section (identitas|capaian_pembelajaran|deskripsi_mata_kuliah|rencana_mingguan|rencana_penilaian|daftar_referensi)"
+// @Param request body dto.RegenerateSectionRequest false "Regenerate Section Request"
+// @Success 200 {object} dto.APIResponse{data=dto.GeneratedRPSResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Failure 429 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /api/v1/generated/{id}/sections/{section}/regenerate [post]
+func (ctrl *AIController) RegenerateSection(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid generated RPS ID", "INVALID_ID", nil))
+		return
 	}
 
-	// Build options - copy all fields from request
-	options := dto.GenerateRPSOptions{
-		Language: "Indonesia",
-		Tone:     "formal",
+	// The body is optional
+	var req dto.RegenerateSectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
 	}
-	if req.Options != nil {
-		if req.Options.Language != "" {
-			options.Language = req.Options.Language
-		}
-		if req.Options.Tone != "" {
-			options.Tone = req.Options.Tone
-		}
-		if req.Options.DosenPengampu != "" {
-			options.DosenPengampu = req.Options.DosenPengampu
-		}
-		if req.Options.Semester != "" {
-			options.Semester = req.Options.Semester
-		}
-		if req.Options.Prasyarat != "" {
-			options.Prasyarat = req.Options.Prasyarat
-		}
-		if req.Options.ProgramStudi != "" {
-			options.ProgramStudi = req.Options.ProgramStudi
-		}
-		if req.Options.Fakultas != "" {
-			options.Fakultas = req.Options.Fakultas
-		}
-		if req.Options.TahunAkademik != "" {
-			options.TahunAkademik = req.Options.TahunAkademik
-		}
-		options.Overrides = req.Options.Overrides
+	if err := helper.ValidateStruct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+	if user, ok := middleware.CurrentUser(c); ok {
+		req.RequestedBy = &user.ID
 	}
 
-	// Call AI (use background context since HTTP request already returned)
-	aiResult, err := ctrl.aiService.GenerateRPS(context.Background(), jobID.String(), courseData, templateDef, options)
+	generatedRPS, err := ctrl.generationService.RegenerateSection(c.Request.Context(), id, c.Param("section"), &req)
 	if err != nil {
-		ctrl.markAsFailed(jobID, err.Error())
+		switch {
+		case errors.Is(err, services.ErrUnknownSection):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse("Unknown RPS section", "INVALID_SECTION", nil))
+		case errors.Is(err, services.ErrInvalidWeekRange):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_WEEK_RANGE", nil))
+		case errors.Is(err, services.ErrRPSNotReady):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse("RPS generation is not completed yet", "NOT_READY", nil))
+		case errors.Is(err, services.ErrRPSLocked):
+			c.JSON(http.StatusConflict, dto.ErrorResponse(err.Error(), "RPS_LOCKED", nil))
+		case errors.Is(err, services.ErrGeneratedRPSNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse("Generated RPS not found", "NOT_FOUND", nil))
+		case errors.Is(err, helper.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_MODEL_PARAMS", nil))
+		default:
+			if ctrl.respondJobNotFound(c, err) || respondBudgetExceeded(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Section regeneration failed", "AI_ERROR", map[string]string{"error": err.Error()}))
+		}
 		return
 	}
 
-	// Update with result
-	resultJSON, _ := json.Marshal(aiResult.Result)
-	metadataJSON, _ := json.Marshal(aiResult.AIMetadata)
+	c.JSON(http.StatusOK, dto.SuccessResponse("RPS section regenerated successfully", generatedRPS))
+}
+
+func (ctrl *AIController) bindGenerateRequest(c *gin.Context) (*dto.GenerateRPSRequest, bool) {
+	var req dto.GenerateRPSRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return nil, false
+	}
 
-	status := "done"
-	updateReq := &dto.UpdateGeneratedRPSRequest{
-		Status:     &status,
-		Result:     datatypes.JSON(resultJSON),
-		AIMetadata: datatypes.JSON(metadataJSON),
+	// Validate
+	if req.TemplateVersionID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("template_version_id is required", "VALIDATION_ERROR", nil))
+		return nil, false
+	}
+	if req.CourseID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("course_id is required", "VALIDATION_ERROR", nil))
+		return nil, false
+	}
+	if req.Options != nil {
+		if err := helper.ValidateStruct(req.Options); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+			return nil, false
+		}
+	}
+	if user, ok := middleware.CurrentUser(c); ok {
+		req.GeneratedBy = &user.ID
 	}
 
-	ctrl.generatedRPSService.Update(jobID, updateReq)
+	return &req, true
 }
 
-func (ctrl *AIController) markAsFailed(jobID uuid.UUID, errorMsg string) {
-	metadataJSON, _ := json.Marshal(map[string]string{"error": errorMsg})
-	status := "failed"
-	updateReq := &dto.UpdateGeneratedRPSRequest{
-		Status:     &status,
-		AIMetadata: datatypes.JSON(metadataJSON),
+func (ctrl *AIController) respondJobNotFound(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrTemplateVersionNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Template version not found", "NOT_FOUND", nil))
+	case errors.Is(err, services.ErrCourseNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Course not found", "NOT_FOUND", nil))
+	default:
+		return false
 	}
-	ctrl.generatedRPSService.Update(jobID, updateReq)
+	return true
 }
 
 // GetPromptByID - Get AI prompt by MongoDB ID
@@ -414,6 +345,71 @@ func (ctrl *AIController) GetAllPrompts(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.SuccessResponse("Prompts retrieved successfully", prompts))
 }
 
+// GetPromptsByModel - Get AI prompts of a model
+// @Summary Get AI prompts by model
+// @Tags AI Admin
+// @Produce json
+// @Param model path string true "Model name"
+// @Success 200 {object} dto.APIResponse
+// @Router /api/v1/admin/ai/prompts/model/{model} [get]
+func (ctrl *AIController) GetPromptsByModel(c *gin.Context) {
+	prompts, err := ctrl.aiService.GetPromptsByModel(c.Request.Context(), c.Param("model"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get prompts", "INTERNAL_ERROR", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Prompts retrieved successfully", prompts))
+}
+
+// GetPromptsByStatus - Get AI prompts by status
+// @Summary Get AI prompts by status
+// @Tags AI Admin
+// @Produce json
+// @Param status path string true "Status (success, failed, timeout, cancelled)"
+// @Success 200 {object} dto.APIResponse
+// @Router /api/v1/admin/ai/prompts/status/{status} [get]
+func (ctrl *AIController) GetPromptsByStatus(c *gin.Context) {
+	prompts, err := ctrl.aiService.GetPromptsByStatus(c.Request.Context(), c.Param("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get prompts", "INTERNAL_ERROR", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Prompts retrieved successfully", prompts))
+}
+
+// GetPromptsByDateRange - Get AI prompts created within a date range
+// @Summary Get AI prompts by date range
+// @Tags AI Admin
+// @Produce json
+// @Param start query string true "Start date (RFC3339)"
+// @Param end query string true "End date (RFC3339)"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Router /api/v1/admin/ai/prompts/date-range [get]
+func (ctrl *AIController) GetPromptsByDateRange(c *gin.Context) {
+	start, err := time.Parse(time.RFC3339, c.Query("start"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid start date format", "INVALID_DATE", nil))
+		return
+	}
+
+	end, err := time.Parse(time.RFC3339, c.Query("end"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid end date format", "INVALID_DATE", nil))
+		return
+	}
+
+	prompts, err := ctrl.aiService.GetPromptsByDateRange(c.Request.Context(), start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get prompts", "INTERNAL_ERROR", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse("Prompts retrieved successfully", prompts))
+}
+
 // GetAllGenerations - Get all AI generations with pagination
 // @Summary Get all AI generations
 // @Description Get all AI generations with pagination
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+type AICostController struct {
+	service services.AICostService
+}
+
+func NewAICostController(service services.AICostService) *AICostController {
+	return &AICostController{service: service}
+}
+
+// FindBudgets godoc
+// @Summary List AI budgets with the spend of the current month
+// @Tags AI Admin
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=[]dto.AIBudgetResponse}
+// @Router /api/v1/admin/budgets [get]
+func (c *AICostController) FindBudgets(ctx *gin.Context) {
+	budgets, err := c.service.FindBudgets(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch budgets", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Budgets fetched successfully", budgets))
+}
+
+// SetProgramBudget godoc
+// @Summary Set the monthly AI budget of a program
+// @Description Generation for the program's courses is refused with BUDGET_EXCEEDED once the month's spend reaches the limit
+// @Tags AI Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param request body dto.SetAIBudgetRequest true "Set AI Budget Request"
+// @Success 200 {object} dto.APIResponse{data=dto.AIBudgetResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /api/v1/admin/budgets/programs/{id} [put]
+func (c *AICostController) SetProgramBudget(ctx *gin.Context) {
+	c.setBudget(ctx, c.service.SetProgramBudget)
+}
+
+// SetUserBudget godoc
+// @Summary Set the monthly AI budget of a user
+// @Description Generation requested by the user is refused with BUDGET_EXCEEDED once the month's spend reaches the limit
+// @Tags AI Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.SetAIBudgetRequest true "Set AI Budget Request"
+// @Success 200 {object} dto.APIResponse{data=dto.AIBudgetResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /api/v1/admin/budgets/users/{id} [put]
+func (c *AICostController) SetUserBudget(ctx *gin.Context) {
+	c.setBudget(ctx, c.service.SetUserBudget)
+}
+
+// DeleteBudget godoc
+// @Summary Remove an AI budget
+// @Tags AI Admin
+// @Produce json
+// @Param id path string true "Budget ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /api/v1/admin/budgets/{id} [delete]
+func (c *AICostController) DeleteBudget(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid budget ID", "INVALID_ID", nil))
+		return
+	}
+
+	if err := c.service.DeleteBudget(id); err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Budget not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to delete budget", "DELETE_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Budget deleted successfully", nil))
+}
+
+// Report godoc
+// @Summary AI cost report
+// @Description Usage and cost of every recorded AI call, grouped by any of program, user, model and month (UTC)
+// @Tags AI Admin
+// @Produce json
+// @Param group_by query string false "Comma-separated: program, user, model, month (default month)"
+// @Param from query string false "Start (RFC3339, inclusive)"
+// @Param to query string false "End (RFC3339, exclusive)"
+// @Param program_id query string false "Program ID"
+// @Param user_id query string false "User ID"
+// @Success 200 {object} dto.APIResponse{data=dto.AICostReport}
+// @Failure 400 {object} dto.APIResponse
+// @Router /api/v1/admin/ai/costs [get]
+func (c *AICostController) Report(ctx *gin.Context) {
+	var query dto.AICostReportQuery
+	if groupBy := ctx.Query("group_by"); groupBy != "" {
+		for _, key := range strings.Split(groupBy, ",") {
+			query.GroupBy = append(query.GroupBy, strings.TrimSpace(key))
+		}
+	}
+
+	var ok bool
+	if query.From, ok = queryTime(ctx, "from"); !ok {
+		return
+	}
+	if query.To, ok = queryTime(ctx, "to"); !ok {
+		return
+	}
+	if query.ProgramID, ok = queryUUID(ctx, "program_id"); !ok {
+		return
+	}
+	if query.UserID, ok = queryUUID(ctx, "user_id"); !ok {
+		return
+	}
+
+	report, err := c.service.Report(ctx.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, helper.ErrInvalidInput) {
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_GROUP_BY", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to build cost report", "REPORT_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Cost report built successfully", report))
+}
+
+func (c *AICostController) setBudget(ctx *gin.Context, set func(context.Context, uuid.UUID, *dto.SetAIBudgetRequest, *uuid.UUID) (*dto.AIBudgetResponse, error)) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID", "INVALID_ID", nil))
+		return
+	}
+
+	var req dto.SetAIBudgetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	budget, err := set(ctx.Request.Context(), id, &req, currentUserID(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBudgetProgramNotFound):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Program not found", "NOT_FOUND", nil))
+		case errors.Is(err, services.ErrBudgetUserNotFound):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("User not found", "NOT_FOUND", nil))
+		default:
+			ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to set budget", "UPDATE_ERROR", nil))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Budget set successfully", budget))
+}
+
+// queryTime reads an optional RFC3339 query parameter, answering 400 when it is malformed
+func queryTime(ctx *gin.Context, param string) (*time.Time, bool) {
+	value := ctx.Query(param)
+	if value == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid "+param+" date format", "INVALID_DATE", nil))
+		return nil, false
+	}
+	return &t, true
+}
+
+// queryUUID reads an optional UUID query parameter, answering 400 when it is malformed
+func queryUUID(ctx *gin.Context, param string) (*uuid.UUID, bool) {
+	value := ctx.Query(param)
+	if value == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid "+param, "INVALID_ID", nil))
+		return nil, false
+	}
+	return &id, true
+}
+
+// respondBudgetExceeded answers generation requests refused by a budget
+func respondBudgetExceeded(ctx *gin.Context, err error) bool {
+	if !errors.Is(err, services.ErrBudgetExceeded) {
+		return false
+	}
+	ctx.JSON(http.StatusTooManyRequests, dto.ErrorResponse(err.Error(), "BUDGET_EXCEEDED", nil))
+	return true
+}
@@ -137,6 +137,29 @@ func (c *AuditLogController) FindByAction(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, dto.SuccessResponse("Audit logs fetched successfully", logs))
 }
 
+// FindByTargetID godoc
+// @Summary Get audit logs of a target resource
+// @Tags Audit Logs
+// @Produce json
+// @Param id path string true "Target ID"
+// @Success 200 {object} dto.APIResponse
+// @Router /audit-logs/target/{id} [get]
+func (c *AuditLogController) FindByTargetID(ctx *gin.Context) {
+	targetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid target ID", "INVALID_ID", nil))
+		return
+	}
+
+	logs, err := c.service.FindByTargetID(targetID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch audit logs", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Audit logs fetched successfully", logs))
+}
+
 // FindByDateRange godoc
 // @Summary Get audit logs by date range
 // @Tags Audit Logs
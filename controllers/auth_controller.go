@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/middleware"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+type AuthController struct {
+	service services.AuthService
+}
+
+func NewAuthController(service services.AuthService) *AuthController {
+	return &AuthController{service: service}
+}
+
+// Login godoc
+// @Summary Login with username and password
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body dto.LoginRequest true "Login Request"
+// @Success 200 {object} dto.APIResponse{data=dto.AuthResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 401 {object} dto.APIResponse
+// @Router /auth/login [post]
+func (c *AuthController) Login(ctx *gin.Context) {
+	var req dto.LoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		errors := helper.FormatValidationErrors(err)
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", errors))
+		return
+	}
+
+	auth, err := c.service.Login(&req)
+	if err != nil {
+		if errors.Is(err, helper.ErrUnauthorized) {
+			ctx.JSON(http.StatusUnauthorized, dto.ErrorResponse("Invalid username or password", "UNAUTHORIZED", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to login", "LOGIN_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Login successful", auth))
+}
+
+// Refresh godoc
+// @Summary Exchange a refresh token for a new token pair
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest true "Refresh Token Request"
+// @Success 200 {object} dto.APIResponse{data=dto.AuthResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 401 {object} dto.APIResponse
+// @Router /auth/refresh [post]
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		errors := helper.FormatValidationErrors(err)
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", errors))
+		return
+	}
+
+	auth, err := c.service.Refresh(&req)
+	if err != nil {
+		if errors.Is(err, helper.ErrUnauthorized) {
+			ctx.JSON(http.StatusUnauthorized, dto.ErrorResponse("Invalid or expired refresh token", "UNAUTHORIZED", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to refresh token", "REFRESH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Token refreshed successfully", auth))
+}
+
+// Logout godoc
+// @Summary Revoke the current access token and refresh token(s)
+// @Description Without refresh_token in the body, every session of the user is revoked
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body dto.LogoutRequest false "Logout Request"
+// @Success 200 {object} dto.APIResponse
+// @Failure 401 {object} dto.APIResponse
+// @Router /auth/logout [post]
+func (c *AuthController) Logout(ctx *gin.Context) {
+	claims, ok := middleware.TokenClaims(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, dto.ErrorResponse("Not authenticated", "UNAUTHORIZED", nil))
+		return
+	}
+
+	var req dto.LogoutRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+			return
+		}
+	}
+
+	if err := c.service.Logout(claims, &req); err != nil {
+		if errors.Is(err, helper.ErrForbidden) {
+			ctx.JSON(http.StatusForbidden, dto.ErrorResponse("Refresh token belongs to another user", "FORBIDDEN", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to logout", "LOGOUT_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Logout successful", nil))
+}
+
+// Me godoc
+// @Summary Get the authenticated user
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.UserResponse}
+// @Failure 401 {object} dto.APIResponse
+// @Router /auth/me [get]
+func (c *AuthController) Me(ctx *gin.Context) {
+	user, ok := middleware.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, dto.ErrorResponse("Not authenticated", "UNAUTHORIZED", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("User fetched successfully", helper.ToUserResponse(user)))
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -42,6 +43,10 @@ func (c *CourseController) Create(ctx *gin.Context) {
 
 	course, err := c.service.Create(&req)
 	if err != nil {
+		if errors.Is(err, services.ErrCreditSplit) {
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_CREDITS", nil))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to create course", "CREATE_ERROR", nil))
 		return
 	}
@@ -152,6 +157,10 @@ func (c *CourseController) Update(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Course not found", "NOT_FOUND", nil))
 			return
 		}
+		if errors.Is(err, services.ErrCreditSplit) {
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_CREDITS", nil))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to update course", "UPDATE_ERROR", nil))
 		return
 	}
@@ -185,3 +194,301 @@ func (c *CourseController) Delete(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, dto.SuccessResponse("Course deleted successfully", nil))
 }
+
+// AssignLecturer godoc
+// @Summary Assign a lecturer to a course
+// @Tags Courses
+// @Accept json
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param request body dto.AssignLecturerRequest true "Assign Lecturer Request"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /courses/{id}/lecturers [post]
+func (c *CourseController) AssignLecturer(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid course ID", "INVALID_ID", nil))
+		return
+	}
+
+	var req dto.AssignLecturerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		errors := helper.FormatValidationErrors(err)
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", errors))
+		return
+	}
+
+	course, err := c.service.AssignLecturer(id, &req)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Course or user not found", "NOT_FOUND", nil))
+			return
+		}
+		if errors.Is(err, helper.ErrInvalidInput) {
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Only dosen or kaprodi users can be assigned as lecturers", "INVALID_ROLE", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to assign lecturer", "UPDATE_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Lecturer assigned successfully", course))
+}
+
+// UnassignLecturer godoc
+// @Summary Remove a lecturer from a course
+// @Tags Courses
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /courses/{id}/lecturers/{user_id} [delete]
+func (c *CourseController) UnassignLecturer(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid course ID", "INVALID_ID", nil))
+		return
+	}
+
+	userID, err := uuid.Parse(ctx.Param("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid user ID", "INVALID_ID", nil))
+		return
+	}
+
+	course, err := c.service.UnassignLecturer(id, userID)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Course or user not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to remove lecturer", "UPDATE_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Lecturer removed successfully", course))
+}
+
+// FindLearningOutcomes godoc
+// @Summary Get the program learning outcomes (CPL) a course supports
+// @Tags Courses
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} dto.APIResponse{data=[]dto.LearningOutcomeResponse}
+// @Failure 404 {object} dto.APIResponse
+// @Router /courses/{id}/learning-outcomes [get]
+func (c *CourseController) FindLearningOutcomes(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid course ID", "INVALID_ID", nil))
+		return
+	}
+
+	course, err := c.service.FindByID(id)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Course not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch learning outcomes", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Learning outcomes fetched successfully", course.LearningOutcomes))
+}
+
+// SetLearningOutcomes godoc
+// @Summary Replace the program learning outcomes (CPL) a course supports
+// @Tags Courses
+// @Accept json
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param request body dto.SetCourseLearningOutcomesRequest true "Learning outcomes of the course program"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /courses/{id}/learning-outcomes [put]
+func (c *CourseController) SetLearningOutcomes(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid course ID", "INVALID_ID", nil))
+		return
+	}
+
+	var req dto.SetCourseLearningOutcomesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	course, err := c.service.SetLearningOutcomes(id, &req)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Course not found", "NOT_FOUND", nil))
+			return
+		}
+		if errors.Is(err, services.ErrLearningOutcomeProgramMismatch) {
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_LEARNING_OUTCOME", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to update learning outcomes", "UPDATE_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Learning outcomes updated successfully", course))
+}
+
+// FindPrerequisiteTree godoc
+// @Summary Get the prerequisite tree of a course
+// @Description Every course the course requires, transitively, with prerequisite or corequisite type
+// @Tags Courses
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} dto.APIResponse{data=dto.CoursePrerequisiteNode}
+// @Failure 404 {object} dto.APIResponse
+// @Router /courses/{id}/prerequisites [get]
+func (c *CourseController) FindPrerequisiteTree(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid course ID", "INVALID_ID", nil))
+		return
+	}
+
+	tree, err := c.service.FindPrerequisiteTree(id)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Course not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch prerequisites", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Prerequisites fetched successfully", tree))
+}
+
+// FindDependents godoc
+// @Summary Get the courses that require a course
+// @Tags Courses
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} dto.APIResponse{data=[]dto.CourseDependentResponse}
+// @Failure 404 {object} dto.APIResponse
+// @Router /courses/{id}/dependents [get]
+func (c *CourseController) FindDependents(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid course ID", "INVALID_ID", nil))
+		return
+	}
+
+	dependents, err := c.service.FindDependents(id)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Course not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch dependents", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Dependents fetched successfully", dependents))
+}
+
+// AddPrerequisite godoc
+// @Summary Add a prerequisite or corequisite to a course
+// @Description Adding an edge that already exists changes its type. Edges that would create a cycle are rejected.
+// @Tags Courses
+// @Accept json
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param request body dto.AddPrerequisiteRequest true "Add Prerequisite Request"
+// @Success 200 {object} dto.APIResponse{data=dto.CoursePrerequisiteNode}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /courses/{id}/prerequisites [post]
+func (c *CourseController) AddPrerequisite(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid course ID", "INVALID_ID", nil))
+		return
+	}
+
+	var req dto.AddPrerequisiteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	tree, err := c.service.AddPrerequisite(id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPrerequisiteNotFound):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Prerequisite course not found", "NOT_FOUND", nil))
+		case helper.IsNotFoundError(err):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Course not found", "NOT_FOUND", nil))
+		case errors.Is(err, services.ErrPrerequisiteCycle), errors.Is(err, services.ErrSelfPrerequisite):
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "PREREQUISITE_CYCLE", nil))
+		default:
+			ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to add prerequisite", "UPDATE_ERROR", nil))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Prerequisite added successfully", tree))
+}
+
+// RemovePrerequisite godoc
+// @Summary Remove a prerequisite or corequisite from a course
+// @Tags Courses
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param prerequisite_id path string true "Prerequisite Course ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /courses/{id}/prerequisites/{prerequisite_id} [delete]
+func (c *CourseController) RemovePrerequisite(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid course ID", "INVALID_ID", nil))
+		return
+	}
+
+	prerequisiteID, err := uuid.Parse(ctx.Param("prerequisite_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid prerequisite ID", "INVALID_ID", nil))
+		return
+	}
+
+	if err := c.service.RemovePrerequisite(id, prerequisiteID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrPrerequisiteNotFound):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Prerequisite not found", "NOT_FOUND", nil))
+		case helper.IsNotFoundError(err):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Course not found", "NOT_FOUND", nil))
+		default:
+			ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to remove prerequisite", "UPDATE_ERROR", nil))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Prerequisite removed successfully", nil))
+}
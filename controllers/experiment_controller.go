@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	models "github.com/syrlramadhan/dokumentasi-rps-api/models/mongo"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+type ExperimentController struct {
+	service services.ExperimentService
+}
+
+func NewExperimentController(service services.ExperimentService) *ExperimentController {
+	return &ExperimentController{service: service}
+}
+
+// Create godoc
+// @Summary Create an A/B experiment
+// @Description An arm pins a prompt template version and may override the model, temperature and max tokens. The experiment starts as a draft.
+// @Tags AI Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateExperimentRequest true "Create Experiment Request"
+// @Success 201 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /api/v1/admin/experiments [post]
+func (c *ExperimentController) Create(ctx *gin.Context) {
+	var req dto.CreateExperimentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	experiment, err := c.service.Create(ctx.Request.Context(), &req, mongoUserID(ctx))
+	if err != nil {
+		c.respondError(ctx, err, "Failed to create experiment", "CREATE_ERROR")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.SuccessResponse("Experiment created successfully", experiment))
+}
+
+// FindAll godoc
+// @Summary List experiments
+// @Tags AI Admin
+// @Produce json
+// @Success 200 {object} dto.APIResponse
+// @Router /api/v1/admin/experiments [get]
+func (c *ExperimentController) FindAll(ctx *gin.Context) {
+	experiments, err := c.service.FindAll(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch experiments", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Experiments fetched successfully", experiments))
+}
+
+// FindByID godoc
+// @Summary Get an experiment
+// @Tags AI Admin
+// @Produce json
+// @Param id path string true "Experiment ID (MongoDB ObjectID)"
+// @Success 200 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /api/v1/admin/experiments/{id} [get]
+func (c *ExperimentController) FindByID(ctx *gin.Context) {
+	experiment, err := c.service.FindByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.respondError(ctx, err, "Failed to fetch experiment", "FETCH_ERROR")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Experiment fetched successfully", experiment))
+}
+
+// Update godoc
+// @Summary Update an experiment
+// @Description Arms can only be changed while the experiment is a draft
+// @Tags AI Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Experiment ID (MongoDB ObjectID)"
+// @Param request body dto.UpdateExperimentRequest true "Update Experiment Request"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /api/v1/admin/experiments/{id} [put]
+func (c *ExperimentController) Update(ctx *gin.Context) {
+	var req dto.UpdateExperimentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	experiment, err := c.service.Update(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.respondError(ctx, err, "Failed to update experiment", "UPDATE_ERROR")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Experiment updated successfully", experiment))
+}
+
+// Start godoc
+// @Summary Start an experiment
+// @Description New generation jobs are split between the arms until the experiment is stopped. Only one experiment runs at a time.
+// @Tags AI Admin
+// @Produce json
+// @Param id path string true "Experiment ID (MongoDB ObjectID)"
+// @Success 200 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /api/v1/admin/experiments/{id}/start [post]
+func (c *ExperimentController) Start(ctx *gin.Context) {
+	c.act(ctx, c.service.Start, "Experiment started")
+}
+
+// Stop godoc
+// @Summary Stop a running experiment
+// @Tags AI Admin
+// @Produce json
+// @Param id path string true "Experiment ID (MongoDB ObjectID)"
+// @Success 200 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /api/v1/admin/experiments/{id}/stop [post]
+func (c *ExperimentController) Stop(ctx *gin.Context) {
+	c.act(ctx, c.service.Stop, "Experiment stopped")
+}
+
+// Delete godoc
+// @Summary Delete an experiment that is not running
+// @Tags AI Admin
+// @Produce json
+// @Param id path string true "Experiment ID (MongoDB ObjectID)"
+// @Success 200 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /api/v1/admin/experiments/{id} [delete]
+func (c *ExperimentController) Delete(ctx *gin.Context) {
+	if err := c.service.Delete(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.respondError(ctx, err, "Failed to delete experiment", "DELETE_ERROR")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Experiment deleted successfully", nil))
+}
+
+// Report godoc
+// @Summary Compare the arms of an experiment
+// @Description Per arm: success rate, tokens, cost and latency from the AI logs, validation findings and reviewer acceptance from the generated RPS
+// @Tags AI Admin
+// @Produce json
+// @Param id path string true "Experiment ID (MongoDB ObjectID)"
+// @Success 200 {object} dto.APIResponse{data=dto.ExperimentReport}
+// @Failure 404 {object} dto.APIResponse
+// @Router /api/v1/admin/experiments/{id}/report [get]
+func (c *ExperimentController) Report(ctx *gin.Context) {
+	report, err := c.service.Report(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.respondError(ctx, err, "Failed to build experiment report", "REPORT_ERROR")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Experiment report built successfully", report))
+}
+
+func (c *ExperimentController) act(ctx *gin.Context, action func(context.Context, string) (*models.Experiment, error), message string) {
+	experiment, err := action(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.respondError(ctx, err, "Failed to update experiment", "UPDATE_ERROR")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse(message, experiment))
+}
+
+func (c *ExperimentController) respondError(ctx *gin.Context, err error, message, code string) {
+	switch {
+	case helper.IsNotFoundError(err):
+		ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Experiment not found", "NOT_FOUND", nil))
+	case errors.Is(err, services.ErrExperimentNameTaken):
+		ctx.JSON(http.StatusConflict, dto.ErrorResponse(err.Error(), "DUPLICATE_NAME", nil))
+	case errors.Is(err, services.ErrExperimentAlreadyRunning), errors.Is(err, services.ErrExperimentNotDraft),
+		errors.Is(err, services.ErrExperimentNotRunning), errors.Is(err, services.ErrExperimentRunning):
+		ctx.JSON(http.StatusConflict, dto.ErrorResponse(err.Error(), "INVALID_STATE", nil))
+	case errors.Is(err, helper.ErrInvalidInput):
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_EXPERIMENT", nil))
+	default:
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse(message, code, nil))
+	}
+}
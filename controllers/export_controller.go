@@ -74,8 +74,15 @@ func (ctrl *ExportController) ExportToPDF(c *gin.Context) {
 		return
 	}
 
+	// Resolve layout directives from the template version
+	layout, err := services.LayoutForGeneratedRPS(generatedRPS)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Template layout is invalid", "INVALID_LAYOUT", nil))
+		return
+	}
+
 	// Generate PDF
-	pdfBytes, err := ctrl.exportService.ExportToPDF(&rpsData)
+	pdfBytes, err := ctrl.exportService.ExportToPDF(&rpsData, layout)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to generate PDF", "EXPORT_ERROR", nil))
 		return
@@ -89,9 +96,73 @@ func (ctrl *ExportController) ExportToPDF(c *gin.Context) {
 	c.Data(http.StatusOK, "application/pdf", pdfBytes)
 }
 
-// ExportToHTML exports RPS to HTML format (can be converted to DOCX via browser)
+// ExportToDOCX exports RPS to an editable Word document
+// @Summary Export RPS to DOCX
+// @Description Export a generated RPS to an editable Word (.docx) document
+// @Tags Export
+// @Produce application/vnd.openxmlformats-officedocument.wordprocessingml.document
+// @Param id path string true "Generated RPS ID (UUID)"
+// @Success 200 {file} binary "DOCX file"
+// @Failure 404 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /api/v1/export/{id}/docx [get]
+func (ctrl *ExportController) ExportToDOCX(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid ID format", "INVALID_ID", nil))
+		return
+	}
+
+	// Get generated RPS
+	generatedRPS, err := ctrl.generatedRPSService.FindByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Generated RPS not found", "NOT_FOUND", nil))
+		return
+	}
+
+	// Check if RPS is completed
+	if generatedRPS.Status != "done" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("RPS generation is not completed yet", "NOT_READY", nil))
+		return
+	}
+
+	// Parse result to RPSStructuredOutput
+	var rpsData dto.RPSStructuredOutput
+	if generatedRPS.Result != nil {
+		if err := json.Unmarshal(generatedRPS.Result, &rpsData); err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to parse RPS data", "PARSE_ERROR", nil))
+			return
+		}
+	} else {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("RPS result is empty", "EMPTY_RESULT", nil))
+		return
+	}
+
+	// Resolve layout directives from the template version
+	layout, err := services.LayoutForGeneratedRPS(generatedRPS)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Template layout is invalid", "INVALID_LAYOUT", nil))
+		return
+	}
+
+	// Generate DOCX
+	docxBytes, err := ctrl.exportService.ExportToDOCX(&rpsData, layout)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to generate DOCX", "EXPORT_ERROR", nil))
+		return
+	}
+
+	// Set headers and send file
+	filename := fmt.Sprintf("RPS_%s_%s.docx", rpsData.Identitas.KodeMataKuliah, rpsData.Identitas.NamaMataKuliah)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Header("Content-Length", fmt.Sprintf("%d", len(docxBytes)))
+	c.Data(http.StatusOK, services.DOCXMimeType, docxBytes)
+}
+
+// ExportToHTML exports RPS to HTML format
 // @Summary Export RPS to HTML
-// @Description Export a generated RPS to HTML document (can be saved as DOCX from browser)
+// @Description Export a generated RPS to HTML document
 // @Tags Export
 // @Produce text/html
 // @Param id path string true "Generated RPS ID (UUID)"
@@ -137,8 +208,15 @@ func (ctrl *ExportController) ExportToHTML(c *gin.Context) {
 		return
 	}
 
+	// Resolve layout directives from the template version
+	layout, err := services.LayoutForGeneratedRPS(generatedRPS)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Template layout is invalid", "INVALID_LAYOUT", nil))
+		return
+	}
+
 	// Generate HTML
-	htmlContent, err := ctrl.exportService.ExportToHTML(&rpsData)
+	htmlContent, err := ctrl.exportService.ExportToHTML(&rpsData, layout)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to generate HTML", "EXPORT_ERROR", nil))
 		return
@@ -199,8 +277,15 @@ func (ctrl *ExportController) ExportToHTMLPreview(c *gin.Context) {
 		return
 	}
 
+	// Resolve layout directives from the template version
+	layout, err := services.LayoutForGeneratedRPS(generatedRPS)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Template layout is invalid", "INVALID_LAYOUT", nil))
+		return
+	}
+
 	// Generate HTML
-	htmlContent, err := ctrl.exportService.ExportToHTML(&rpsData)
+	htmlContent, err := ctrl.exportService.ExportToHTML(&rpsData, layout)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to generate HTML", "EXPORT_ERROR", nil))
 		return
@@ -227,10 +312,17 @@ func (ctrl *ExportController) GetExportFormats(c *gin.Context) {
 			"endpoint":    "/api/v1/export/{id}/pdf",
 			"mime_type":   "application/pdf",
 		},
+		{
+			"format":      "docx",
+			"name":        "Word Document",
+			"description": "Export RPS ke format DOCX yang dapat diedit di Microsoft Word",
+			"endpoint":    "/api/v1/export/{id}/docx",
+			"mime_type":   services.DOCXMimeType,
+		},
 		{
 			"format":      "html",
 			"name":        "HTML Document",
-			"description": "Export RPS ke format HTML (dapat dibuka di browser dan disimpan sebagai DOCX)",
+			"description": "Export RPS ke format HTML untuk dibuka di browser",
 			"endpoint":    "/api/v1/export/{id}/html",
 			"mime_type":   "text/html",
 		},
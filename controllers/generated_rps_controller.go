@@ -1,21 +1,26 @@
 package controllers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
 	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/middleware"
 	"github.com/syrlramadhan/dokumentasi-rps-api/services"
 )
 
 type GeneratedRPSController struct {
-	service services.GeneratedRPSService
+	service       services.GeneratedRPSService
+	exportService services.ExportService
 }
 
-func NewGeneratedRPSController(service services.GeneratedRPSService) *GeneratedRPSController {
-	return &GeneratedRPSController{service: service}
+func NewGeneratedRPSController(service services.GeneratedRPSService, exportService services.ExportService) *GeneratedRPSController {
+	return &GeneratedRPSController{service: service, exportService: exportService}
 }
 
 // Create godoc
@@ -93,6 +98,39 @@ func (c *GeneratedRPSController) FindByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, dto.SuccessResponse("Generated RPS fetched successfully", rps))
 }
 
+// Validation godoc
+// @Summary Get validation findings of a generated RPS
+// @Description Returns the rule-based validator findings (error/warning, JSON path, message) stored with the RPS
+// @Tags Generated RPS
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Success 200 {object} dto.APIResponse{data=dto.RPSValidationReport}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /generated-rps/{id}/validation [get]
+func (c *GeneratedRPSController) Validation(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid generated RPS ID", "INVALID_ID", nil))
+		return
+	}
+
+	report, err := c.service.GetValidation(id)
+	if err != nil {
+		switch {
+		case helper.IsNotFoundError(err):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Generated RPS not found", "NOT_FOUND", nil))
+		case errors.Is(err, services.ErrRPSNotReady):
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Generated RPS has no result yet", "NOT_READY", nil))
+		default:
+			ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to validate generated RPS", "VALIDATION_FETCH_ERROR", nil))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Validation fetched successfully", report))
+}
+
 // FindByCourseID godoc
 // @Summary Get generated RPS by course ID
 // @Tags Generated RPS
@@ -120,7 +158,7 @@ func (c *GeneratedRPSController) FindByCourseID(ctx *gin.Context) {
 // @Summary Get generated RPS by status
 // @Tags Generated RPS
 // @Produce json
-// @Param status path string true "Status (queued|processing|done|failed)"
+// @Param status path string true "Status (queued|processing|done|failed|cancelled)"
 // @Success 200 {object} dto.APIResponse
 // @Router /generated-rps/status/{status} [get]
 func (c *GeneratedRPSController) FindByStatus(ctx *gin.Context) {
@@ -149,6 +187,7 @@ func (c *GeneratedRPSController) FindByStatus(ctx *gin.Context) {
 // @Success 200 {object} dto.APIResponse
 // @Failure 400 {object} dto.APIResponse
 // @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
 // @Router /generated-rps/{id} [put]
 func (c *GeneratedRPSController) Update(ctx *gin.Context) {
 	id, err := uuid.Parse(ctx.Param("id"))
@@ -169,12 +208,20 @@ func (c *GeneratedRPSController) Update(ctx *gin.Context) {
 		return
 	}
 
+	if user, ok := middleware.CurrentUser(ctx); ok {
+		req.EditedBy = &user.ID
+	}
+
 	rps, err := c.service.Update(id, &req)
 	if err != nil {
 		if helper.IsNotFoundError(err) {
 			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Generated RPS not found", "NOT_FOUND", nil))
 			return
 		}
+		if errors.Is(err, services.ErrRPSLocked) {
+			ctx.JSON(http.StatusConflict, dto.ErrorResponse(err.Error(), "RPS_LOCKED", nil))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to update generated RPS", "UPDATE_ERROR", nil))
 		return
 	}
@@ -289,12 +336,47 @@ func (c *GeneratedRPSController) Export(ctx *gin.Context) {
 		return
 	}
 
-	// TODO: Generate PDF/DOCX file on-the-fly or queue for generation
-	ctx.JSON(http.StatusOK, dto.SuccessResponse("Export functionality - TODO: implement file generation", gin.H{
-		"id":     rps.ID,
-		"format": format,
-		"status": rps.Status,
-	}))
+	if rps.Status != "done" {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("RPS generation is not completed yet", "NOT_READY", nil))
+		return
+	}
+	if rps.Result == nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("RPS result is empty", "EMPTY_RESULT", nil))
+		return
+	}
+
+	var rpsData dto.RPSStructuredOutput
+	if err := json.Unmarshal(rps.Result, &rpsData); err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to parse RPS data", "PARSE_ERROR", nil))
+		return
+	}
+
+	layout, err := services.LayoutForGeneratedRPS(rps)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Template layout is invalid", "INVALID_LAYOUT", nil))
+		return
+	}
+
+	var (
+		fileBytes []byte
+		mimeType  string
+	)
+	switch format {
+	case "docx":
+		fileBytes, err = c.exportService.ExportToDOCX(&rpsData, layout)
+		mimeType = services.DOCXMimeType
+	default:
+		fileBytes, err = c.exportService.ExportToPDF(&rpsData, layout)
+		mimeType = "application/pdf"
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to export RPS", "EXPORT_ERROR", nil))
+		return
+	}
+
+	filename := fmt.Sprintf("RPS_%s_%s.%s", rpsData.Identitas.KodeMataKuliah, rpsData.Identitas.NamaMataKuliah, format)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	ctx.Data(http.StatusOK, mimeType, fileBytes)
 }
 
 // CompleteGeneration godoc
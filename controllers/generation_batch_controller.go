@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/middleware"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+type GenerationBatchController struct {
+	service services.GenerationBatchService
+}
+
+func NewGenerationBatchController(service services.GenerationBatchService) *GenerationBatchController {
+	return &GenerationBatchController{service: service}
+}
+
+// Create godoc
+// @Summary Queue RPS generation for a program, a semester or a list of courses
+// @Description Creates a batch with one queued job per selected course, sharing the template version and options
+// @Tags Generation Batches
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateGenerationBatchRequest true "Create Generation Batch Request"
+// @Success 202 {object} dto.APIResponse{data=dto.GenerationBatchResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 403 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 429 {object} dto.APIResponse
+// @Router /api/v1/generate/batches [post]
+func (c *GenerationBatchController) Create(ctx *gin.Context) {
+	user, ok := middleware.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, dto.ErrorResponse("Not authenticated", "UNAUTHORIZED", nil))
+		return
+	}
+
+	var req dto.CreateGenerationBatchRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+	req.CreatedBy = &user.ID
+
+	batch, err := c.service.Create(&req, user)
+	if err != nil {
+		switch {
+		case errors.Is(err, helper.ErrForbidden):
+			ctx.JSON(http.StatusForbidden, dto.ErrorResponse("You may not generate RPS for every selected course", "FORBIDDEN", nil))
+		case errors.Is(err, services.ErrTemplateVersionNotFound):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Template version not found", "NOT_FOUND", nil))
+		case errors.Is(err, services.ErrCourseNotFound):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Course not found", "NOT_FOUND", nil))
+		case errors.Is(err, services.ErrEmptyBatch):
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "EMPTY_BATCH", nil))
+		case errors.Is(err, helper.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_MODEL_PARAMS", nil))
+		case errors.Is(err, services.ErrBudgetExceeded):
+			respondBudgetExceeded(ctx, err)
+		default:
+			ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to create batch", "CREATE_ERROR", nil))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, dto.SuccessResponse("RPS generation batch queued", batch))
+}
+
+// FindAll godoc
+// @Summary List generation batches with their progress
+// @Tags Generation Batches
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=[]dto.GenerationBatchResponse}
+// @Router /api/v1/generate/batches [get]
+func (c *GenerationBatchController) FindAll(ctx *gin.Context) {
+	batches, err := c.service.FindAll()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch batches", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Batches fetched successfully", batches))
+}
+
+// FindByID godoc
+// @Summary Get a generation batch with progress, token usage, jobs and failures
+// @Tags Generation Batches
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Success 200 {object} dto.APIResponse{data=dto.GenerationBatchResponse}
+// @Failure 404 {object} dto.APIResponse
+// @Router /api/v1/generate/batches/{id} [get]
+func (c *GenerationBatchController) FindByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid batch ID", "INVALID_ID", nil))
+		return
+	}
+
+	batch, err := c.service.FindByID(id)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Batch not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch batch", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Batch fetched successfully", batch))
+}
+
+// Cancel godoc
+// @Summary Cancel a generation batch
+// @Description Cancels the queued jobs; running jobs stop at their next heartbeat
+// @Tags Generation Batches
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Success 200 {object} dto.APIResponse{data=dto.GenerationBatchResponse}
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /api/v1/generate/batches/{id}/cancel [post]
+func (c *GenerationBatchController) Cancel(ctx *gin.Context) {
+	c.act(ctx, c.service.Cancel, "Batch cancelled", services.ErrBatchNotActive)
+}
+
+// RetryFailed godoc
+// @Summary Retry the failed and cancelled jobs of a generation batch
+// @Tags Generation Batches
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Success 200 {object} dto.APIResponse{data=dto.GenerationBatchResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Failure 429 {object} dto.APIResponse
+// @Router /api/v1/generate/batches/{id}/retry-failed [post]
+func (c *GenerationBatchController) RetryFailed(ctx *gin.Context) {
+	c.act(ctx, c.service.RetryFailed, "Failed jobs requeued", services.ErrBatchNothingToRetry)
+}
+
+func (c *GenerationBatchController) act(ctx *gin.Context, action func(uuid.UUID) (*dto.GenerationBatchResponse, error), message string, conflict error) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid batch ID", "INVALID_ID", nil))
+		return
+	}
+
+	batch, err := action(id)
+	if err != nil {
+		switch {
+		case helper.IsNotFoundError(err):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Batch not found", "NOT_FOUND", nil))
+		case errors.Is(err, conflict):
+			ctx.JSON(http.StatusConflict, dto.ErrorResponse(err.Error(), "INVALID_STATE", nil))
+		case errors.Is(err, helper.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_MODEL_PARAMS", nil))
+		case errors.Is(err, services.ErrBudgetExceeded):
+			respondBudgetExceeded(ctx, err)
+		default:
+			ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to update batch", "UPDATE_ERROR", nil))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse(message, batch))
+}
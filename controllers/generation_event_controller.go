@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+// eventPollInterval is how often an open stream sends a keep-alive and rechecks
+// the stored status, which catches jobs processed by another instance
+const eventPollInterval = 15 * time.Second
+
+type GenerationEventController struct {
+	generatedRPSService services.GeneratedRPSService
+	events              services.GenerationEvents
+}
+
+func NewGenerationEventController(generatedRPSService services.GeneratedRPSService, events services.GenerationEvents) *GenerationEventController {
+	return &GenerationEventController{
+		generatedRPSService: generatedRPSService,
+		events:              events,
+	}
+}
+
+// Stream godoc
+// @Summary Stream generation job progress
+// @Description Server-Sent Events of a job: status transitions, LLM attempts, validation findings and the final result. The stream starts with the current status and ends once the job is done, failed or cancelled.
+// @Tags AI
+// @Produce text/event-stream
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} dto.GenerationEvent
+// @Failure 404 {object} dto.APIResponse
+// @Router /api/v1/generate/{job_id}/events [get]
+func (c *GenerationEventController) Stream(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("job_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid job ID", "INVALID_ID", nil))
+		return
+	}
+
+	// Subscribe before reading the snapshot so no transition falls in between
+	events, unsubscribe := c.events.Subscribe(id)
+	defer unsubscribe()
+
+	job, err := c.generatedRPSService.FindByID(id)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Job not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch job", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no") // keep reverse proxies from buffering the stream
+
+	status := job.Status
+	if !c.sendSnapshot(ctx, job) {
+		return
+	}
+
+	ticker := time.NewTicker(eventPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case event := <-events:
+			ctx.SSEvent(event.Type, event)
+			ctx.Writer.Flush()
+			if event.Type == services.GenerationEventStatus {
+				status = event.Status
+				if services.IsTerminalStatus(status) {
+					return
+				}
+			}
+		case <-ticker.C:
+			job, err := c.generatedRPSService.FindByID(id)
+			if err != nil || job.Status == status {
+				ctx.SSEvent("ping", gin.H{"timestamp": time.Now()})
+				ctx.Writer.Flush()
+				continue
+			}
+			status = job.Status
+			if !c.sendSnapshot(ctx, job) {
+				return
+			}
+		}
+	}
+}
+
+// sendSnapshot sends the stored status of job, and its result once done. It
+// reports whether the stream should stay open.
+func (c *GenerationEventController) sendSnapshot(ctx *gin.Context, job *dto.GeneratedRPSResponse) bool {
+	now := time.Now()
+	if job.Status == "done" {
+		ctx.SSEvent(services.GenerationEventResult, dto.GenerationEvent{JobID: job.ID, Type: services.GenerationEventResult, Status: job.Status, Data: job, Timestamp: now})
+	}
+	ctx.SSEvent(services.GenerationEventStatus, dto.GenerationEvent{JobID: job.ID, Type: services.GenerationEventStatus, Status: job.Status, Attempt: job.Attempts, Timestamp: now})
+	ctx.Writer.Flush()
+	return !services.IsTerminalStatus(job.Status)
+}
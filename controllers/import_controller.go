@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+type ImportController struct {
+	service services.ImportService
+}
+
+func NewImportController(service services.ImportService) *ImportController {
+	return &ImportController{service: service}
+}
+
+// ImportPrograms godoc
+// @Summary Import programs from a CSV or XLSX file
+// @Description Columns: code, name. Runs as a dry run unless dry_run=false; nothing is written while any row has errors.
+// @Tags Import
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Only validate (default true)"
+// @Param upsert query bool false "Update programs whose code already exists"
+// @Success 200 {object} dto.APIResponse{data=dto.ImportReport}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 422 {object} dto.APIResponse{data=dto.ImportReport}
+// @Router /admin/import/programs [post]
+func (c *ImportController) ImportPrograms(ctx *gin.Context) {
+	c.handleImport(ctx, c.service.ImportPrograms)
+}
+
+// ImportCourses godoc
+// @Summary Import courses from a CSV or XLSX file
+// @Description Columns: program_code, code, title, credits, semester, course_type, theory_credits, practice_credits, description, bahan_kajian (separated by semicolons). Runs as a dry run unless dry_run=false; nothing is written while any row has errors.
+// @Tags Import
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Only validate (default true)"
+// @Param upsert query bool false "Update courses whose code already exists"
+// @Success 200 {object} dto.APIResponse{data=dto.ImportReport}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 422 {object} dto.APIResponse{data=dto.ImportReport}
+// @Router /admin/import/courses [post]
+func (c *ImportController) ImportCourses(ctx *gin.Context) {
+	c.handleImport(ctx, c.service.ImportCourses)
+}
+
+func (c *ImportController) handleImport(ctx *gin.Context, importFile func(filename string, data []byte, dryRun, upsert bool) (*dto.ImportReport, error)) {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("A CSV or XLSX file is required in the file field", "INVALID_REQUEST", nil))
+		return
+	}
+	if header.Size > services.MaxImportBytes {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Import file is too large", "FILE_TOO_LARGE", nil))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to read import file", "INVALID_FILE", nil))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxImportBytes))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Failed to read import file", "INVALID_FILE", nil))
+		return
+	}
+
+	dryRun := ctx.DefaultQuery("dry_run", "true") != "false"
+	upsert := ctx.Query("upsert") == "true"
+
+	report, err := importFile(header.Filename, data, dryRun, upsert)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrImportHasErrors):
+			response := dto.ErrorResponse("Import has invalid rows, nothing was imported", "IMPORT_INVALID", nil)
+			response.Data = report
+			ctx.JSON(http.StatusUnprocessableEntity, response)
+		case errors.Is(err, helper.ErrInvalidInput):
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_FILE", nil))
+		default:
+			ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to import file", "IMPORT_ERROR", nil))
+		}
+		return
+	}
+
+	message := "Import validated successfully"
+	if report.Committed {
+		message = "Import completed successfully"
+	}
+	ctx.JSON(http.StatusOK, dto.SuccessResponse(message, report))
+}
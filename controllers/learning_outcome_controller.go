@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+type LearningOutcomeController struct {
+	service services.LearningOutcomeService
+}
+
+func NewLearningOutcomeController(service services.LearningOutcomeService) *LearningOutcomeController {
+	return &LearningOutcomeController{service: service}
+}
+
+// Create godoc
+// @Summary Create a program learning outcome (CPL)
+// @Tags Learning Outcomes
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateLearningOutcomeRequest true "Create Learning Outcome Request"
+// @Success 201 {object} dto.APIResponse{data=dto.LearningOutcomeResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /learning-outcomes [post]
+func (c *LearningOutcomeController) Create(ctx *gin.Context) {
+	var req dto.CreateLearningOutcomeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	outcome, err := c.service.Create(&req)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Program not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to create learning outcome", "CREATE_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.SuccessResponse("Learning outcome created successfully", outcome))
+}
+
+// FindByID godoc
+// @Summary Get learning outcome by ID
+// @Tags Learning Outcomes
+// @Produce json
+// @Param id path string true "Learning Outcome ID"
+// @Success 200 {object} dto.APIResponse{data=dto.LearningOutcomeResponse}
+// @Failure 404 {object} dto.APIResponse
+// @Router /learning-outcomes/{id} [get]
+func (c *LearningOutcomeController) FindByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid learning outcome ID", "INVALID_ID", nil))
+		return
+	}
+
+	outcome, err := c.service.FindByID(id)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Learning outcome not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch learning outcome", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Learning outcome fetched successfully", outcome))
+}
+
+// FindByProgramID godoc
+// @Summary Get learning outcomes of a program
+// @Tags Learning Outcomes
+// @Produce json
+// @Param program_id path string true "Program ID"
+// @Success 200 {object} dto.APIResponse{data=[]dto.LearningOutcomeResponse}
+// @Router /learning-outcomes/program/{program_id} [get]
+func (c *LearningOutcomeController) FindByProgramID(ctx *gin.Context) {
+	programID, err := uuid.Parse(ctx.Param("program_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid program ID", "INVALID_ID", nil))
+		return
+	}
+
+	outcomes, err := c.service.FindByProgramID(programID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch learning outcomes", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Learning outcomes fetched successfully", outcomes))
+}
+
+// Update godoc
+// @Summary Update learning outcome
+// @Tags Learning Outcomes
+// @Accept json
+// @Produce json
+// @Param id path string true "Learning Outcome ID"
+// @Param request body dto.UpdateLearningOutcomeRequest true "Update Learning Outcome Request"
+// @Success 200 {object} dto.APIResponse{data=dto.LearningOutcomeResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /learning-outcomes/{id} [put]
+func (c *LearningOutcomeController) Update(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid learning outcome ID", "INVALID_ID", nil))
+		return
+	}
+
+	var req dto.UpdateLearningOutcomeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	outcome, err := c.service.Update(id, &req)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Learning outcome not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to update learning outcome", "UPDATE_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Learning outcome updated successfully", outcome))
+}
+
+// Delete godoc
+// @Summary Delete learning outcome
+// @Description Also removes it from every course it is mapped to
+// @Tags Learning Outcomes
+// @Produce json
+// @Param id path string true "Learning Outcome ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /learning-outcomes/{id} [delete]
+func (c *LearningOutcomeController) Delete(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid learning outcome ID", "INVALID_ID", nil))
+		return
+	}
+
+	if err := c.service.Delete(id); err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Learning outcome not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to delete learning outcome", "DELETE_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Learning outcome deleted successfully", nil))
+}
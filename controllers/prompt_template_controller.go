@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+type PromptTemplateController struct {
+	service services.PromptTemplateService
+}
+
+func NewPromptTemplateController(service services.PromptTemplateService) *PromptTemplateController {
+	return &PromptTemplateController{service: service}
+}
+
+// Create godoc
+// @Summary Create a prompt template
+// @Description Placeholders are written as {{name}} and must be declared in variables. An active template replaces the active one of its category.
+// @Tags AI Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreatePromptTemplateRequest true "Create Prompt Template Request"
+// @Success 201 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /api/v1/admin/prompt-templates [post]
+func (c *PromptTemplateController) Create(ctx *gin.Context) {
+	var req dto.CreatePromptTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	template, err := c.service.Create(ctx.Request.Context(), &req, mongoUserID(ctx))
+	if err != nil {
+		c.respondError(ctx, err, "Failed to create prompt template", "CREATE_ERROR")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.SuccessResponse("Prompt template created successfully", template))
+}
+
+// FindAll godoc
+// @Summary List prompt templates with their usage statistics
+// @Tags AI Admin
+// @Produce json
+// @Success 200 {object} dto.APIResponse
+// @Router /api/v1/admin/prompt-templates [get]
+func (c *PromptTemplateController) FindAll(ctx *gin.Context) {
+	templates, err := c.service.FindAll(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch prompt templates", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Prompt templates fetched successfully", templates))
+}
+
+// FindVariables godoc
+// @Summary List the variables RPS generation provides to prompt templates
+// @Tags AI Admin
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=[]dto.PromptVariableInfo}
+// @Router /api/v1/admin/prompt-templates/variables [get]
+func (c *PromptTemplateController) FindVariables(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Prompt variables fetched successfully", c.service.Variables()))
+}
+
+// FindByID godoc
+// @Summary Get a prompt template
+// @Tags AI Admin
+// @Produce json
+// @Param id path string true "Prompt Template ID (MongoDB ObjectID)"
+// @Success 200 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /api/v1/admin/prompt-templates/{id} [get]
+func (c *PromptTemplateController) FindByID(ctx *gin.Context) {
+	template, err := c.service.FindByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.respondError(ctx, err, "Failed to fetch prompt template", "FETCH_ERROR")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Prompt template fetched successfully", template))
+}
+
+// Update godoc
+// @Summary Update a prompt template
+// @Description Changing the prompts or the variables bumps the version recorded on later AI prompts
+// @Tags AI Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Prompt Template ID (MongoDB ObjectID)"
+// @Param request body dto.UpdatePromptTemplateRequest true "Update Prompt Template Request"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /api/v1/admin/prompt-templates/{id} [put]
+func (c *PromptTemplateController) Update(ctx *gin.Context) {
+	var req dto.UpdatePromptTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	template, err := c.service.Update(ctx.Request.Context(), ctx.Param("id"), &req, mongoUserID(ctx))
+	if err != nil {
+		c.respondError(ctx, err, "Failed to update prompt template", "UPDATE_ERROR")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Prompt template updated successfully", template))
+}
+
+// Delete godoc
+// @Summary Delete a prompt template
+// @Description Generation falls back to the built-in prompts when no template of the category is active
+// @Tags AI Admin
+// @Produce json
+// @Param id path string true "Prompt Template ID (MongoDB ObjectID)"
+// @Success 200 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /api/v1/admin/prompt-templates/{id} [delete]
+func (c *PromptTemplateController) Delete(ctx *gin.Context) {
+	if err := c.service.Delete(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.respondError(ctx, err, "Failed to delete prompt template", "DELETE_ERROR")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Prompt template deleted successfully", nil))
+}
+
+func (c *PromptTemplateController) respondError(ctx *gin.Context, err error, message, code string) {
+	switch {
+	case helper.IsNotFoundError(err):
+		ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Prompt template not found", "NOT_FOUND", nil))
+	case errors.Is(err, services.ErrPromptTemplateNameTaken):
+		ctx.JSON(http.StatusConflict, dto.ErrorResponse(err.Error(), "DUPLICATE_NAME", nil))
+	case errors.Is(err, helper.ErrInvalidInput):
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_TEMPLATE", nil))
+	default:
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse(message, code, nil))
+	}
+}
+
+// mongoUserID is the authenticated user's ID as stored on Mongo documents
+func mongoUserID(ctx *gin.Context) string {
+	if id := currentUserID(ctx); id != nil {
+		return id.String()
+	}
+	return ""
+}
@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/middleware"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+type RPSCommentController struct {
+	service services.RPSCommentService
+}
+
+func NewRPSCommentController(service services.RPSCommentService) *RPSCommentController {
+	return &RPSCommentController{service: service}
+}
+
+// FindThreads godoc
+// @Summary List review comment threads of a generated RPS
+// @Tags RPS Comments
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param unresolved query bool false "Only unresolved threads"
+// @Success 200 {object} dto.APIResponse{data=[]dto.RPSCommentResponse}
+// @Failure 404 {object} dto.APIResponse
+// @Router /generated-rps/{id}/comments [get]
+func (c *RPSCommentController) FindThreads(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid generated RPS ID", "INVALID_ID", nil))
+		return
+	}
+
+	threads, err := c.service.FindThreads(id, ctx.Query("unresolved") == "true")
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Generated RPS not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch comments", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Comments fetched successfully", threads))
+}
+
+// Create godoc
+// @Summary Comment on a part of a generated RPS
+// @Description Opens a thread anchored to a JSON path of the result, e.g. rencana_mingguan[4].metode_pembelajaran
+// @Tags RPS Comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param request body dto.CreateRPSCommentRequest true "Create Comment Request"
+// @Success 201 {object} dto.APIResponse{data=dto.RPSCommentResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /generated-rps/{id}/comments [post]
+func (c *RPSCommentController) Create(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid generated RPS ID", "INVALID_ID", nil))
+		return
+	}
+
+	var req dto.CreateRPSCommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	comment, err := c.service.Create(id, &req, currentUserID(ctx))
+	if err != nil {
+		respondCommentError(ctx, err, "Failed to create comment")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.SuccessResponse("Comment created successfully", comment))
+}
+
+// Reply godoc
+// @Summary Reply to a review comment thread
+// @Tags RPS Comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param comment_id path string true "Root comment ID"
+// @Param request body dto.ReplyRPSCommentRequest true "Reply Request"
+// @Success 201 {object} dto.APIResponse{data=dto.RPSCommentResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /generated-rps/{id}/comments/{comment_id}/replies [post]
+func (c *RPSCommentController) Reply(ctx *gin.Context) {
+	id, commentID, ok := parseCommentParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.ReplyRPSCommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	thread, err := c.service.Reply(id, commentID, &req, currentUserID(ctx))
+	if err != nil {
+		respondCommentError(ctx, err, "Failed to add reply")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.SuccessResponse("Reply added successfully", thread))
+}
+
+// Resolve godoc
+// @Summary Resolve a review comment thread
+// @Tags RPS Comments
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param comment_id path string true "Root comment ID"
+// @Success 200 {object} dto.APIResponse{data=dto.RPSCommentResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /generated-rps/{id}/comments/{comment_id}/resolve [post]
+func (c *RPSCommentController) Resolve(ctx *gin.Context) {
+	id, commentID, ok := parseCommentParams(ctx)
+	if !ok {
+		return
+	}
+
+	thread, err := c.service.Resolve(id, commentID, currentUserID(ctx))
+	if err != nil {
+		respondCommentError(ctx, err, "Failed to resolve comment")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Comment resolved successfully", thread))
+}
+
+// Unresolve godoc
+// @Summary Reopen a resolved review comment thread
+// @Tags RPS Comments
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param comment_id path string true "Root comment ID"
+// @Success 200 {object} dto.APIResponse{data=dto.RPSCommentResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /generated-rps/{id}/comments/{comment_id}/unresolve [post]
+func (c *RPSCommentController) Unresolve(ctx *gin.Context) {
+	id, commentID, ok := parseCommentParams(ctx)
+	if !ok {
+		return
+	}
+
+	thread, err := c.service.Unresolve(id, commentID)
+	if err != nil {
+		respondCommentError(ctx, err, "Failed to unresolve comment")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Comment reopened successfully", thread))
+}
+
+func parseCommentParams(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid generated RPS ID", "INVALID_ID", nil))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	commentID, err := uuid.Parse(ctx.Param("comment_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid comment ID", "INVALID_ID", nil))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, commentID, true
+}
+
+func respondCommentError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrCommentNotFound):
+		ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Comment not found", "NOT_FOUND", nil))
+	case helper.IsNotFoundError(err):
+		ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Generated RPS not found", "NOT_FOUND", nil))
+	case errors.Is(err, services.ErrInvalidAnchor):
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_ANCHOR", nil))
+	case errors.Is(err, services.ErrUnknownMention):
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "INVALID_MENTION", nil))
+	case errors.Is(err, services.ErrNotThreadRoot):
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "NOT_THREAD_ROOT", nil))
+	case errors.Is(err, services.ErrRPSNotReady):
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("RPS generation is not completed yet", "NOT_READY", nil))
+	default:
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse(message, "COMMENT_ERROR", nil))
+	}
+}
+
+// currentUserID returns the ID of the authenticated user, if any
+func currentUserID(ctx *gin.Context) *uuid.UUID {
+	if user, ok := middleware.CurrentUser(ctx); ok {
+		return &user.ID
+	}
+	return nil
+}
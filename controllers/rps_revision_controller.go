@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/middleware"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+type RPSRevisionController struct {
+	service services.RPSRevisionService
+}
+
+func NewRPSRevisionController(service services.RPSRevisionService) *RPSRevisionController {
+	return &RPSRevisionController{service: service}
+}
+
+// FindAll godoc
+// @Summary List revisions of a generated RPS
+// @Description Newest first, without the result content
+// @Tags RPS Revisions
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Success 200 {object} dto.APIResponse{data=[]dto.RPSRevisionResponse}
+// @Failure 404 {object} dto.APIResponse
+// @Router /generated-rps/{id}/revisions [get]
+func (c *RPSRevisionController) FindAll(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid generated RPS ID", "INVALID_ID", nil))
+		return
+	}
+
+	revisions, err := c.service.FindAll(id)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Generated RPS not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch revisions", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Revisions fetched successfully", revisions))
+}
+
+// FindByNumber godoc
+// @Summary Get one revision of a generated RPS
+// @Tags RPS Revisions
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param number path int true "Revision number"
+// @Success 200 {object} dto.APIResponse{data=dto.RPSRevisionResponse}
+// @Failure 404 {object} dto.APIResponse
+// @Router /generated-rps/{id}/revisions/{number} [get]
+func (c *RPSRevisionController) FindByNumber(ctx *gin.Context) {
+	id, number, ok := parseRevisionParams(ctx)
+	if !ok {
+		return
+	}
+
+	revision, err := c.service.FindByNumber(id, number)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Revision not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch revision", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Revision fetched successfully", revision))
+}
+
+// Restore godoc
+// @Summary Restore a revision of a generated RPS
+// @Description Makes the content of the revision current again, recorded as a new revision
+// @Tags RPS Revisions
+// @Accept json
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param number path int true "Revision number"
+// @Param request body dto.RestoreRPSRevisionRequest false "Optional change note"
+// @Success 200 {object} dto.APIResponse{data=dto.GeneratedRPSResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /generated-rps/{id}/revisions/{number}/restore [post]
+func (c *RPSRevisionController) Restore(ctx *gin.Context) {
+	id, number, ok := parseRevisionParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.RestoreRPSRevisionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	var restoredBy *uuid.UUID
+	if user, ok := middleware.CurrentUser(ctx); ok {
+		restoredBy = &user.ID
+	}
+
+	rps, err := c.service.Restore(id, number, &req, restoredBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRevisionNotFound):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Revision not found", "NOT_FOUND", nil))
+		case helper.IsNotFoundError(err):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Generated RPS not found", "NOT_FOUND", nil))
+		case errors.Is(err, services.ErrRPSLocked):
+			ctx.JSON(http.StatusConflict, dto.ErrorResponse(err.Error(), "RPS_LOCKED", nil))
+		default:
+			ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to restore revision", "RESTORE_ERROR", nil))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Revision restored successfully", rps))
+}
+
+// Diff godoc
+// @Summary Compare two revisions of a generated RPS
+// @Description Structural diff at the level of RPS fields, e.g. rencana_mingguan[3].topik or rencana_penilaian.komponen[1].bobot
+// @Tags RPS Revisions
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param from query int true "Base revision number"
+// @Param to query int true "Compared revision number"
+// @Success 200 {object} dto.APIResponse{data=dto.RPSRevisionDiffResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /generated-rps/{id}/revisions/diff [get]
+func (c *RPSRevisionController) Diff(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid generated RPS ID", "INVALID_ID", nil))
+		return
+	}
+
+	from, fromErr := strconv.Atoi(ctx.Query("from"))
+	to, toErr := strconv.Atoi(ctx.Query("to"))
+	if fromErr != nil || toErr != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("from and to must be revision numbers", "INVALID_REVISION", nil))
+		return
+	}
+
+	diff, err := c.service.Diff(id, from, to)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Revision not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to compare revisions", "DIFF_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Revisions compared successfully", diff))
+}
+
+func parseRevisionParams(ctx *gin.Context) (uuid.UUID, int, bool) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid generated RPS ID", "INVALID_ID", nil))
+		return uuid.Nil, 0, false
+	}
+
+	number, err := strconv.Atoi(ctx.Param("number"))
+	if err != nil || number < 1 {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid revision number", "INVALID_REVISION", nil))
+		return uuid.Nil, 0, false
+	}
+
+	return id, number, true
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/middleware"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+type RPSWorkflowController struct {
+	service services.RPSWorkflowService
+}
+
+func NewRPSWorkflowController(service services.RPSWorkflowService) *RPSWorkflowController {
+	return &RPSWorkflowController{service: service}
+}
+
+// Submit godoc
+// @Summary Submit a generated RPS for review
+// @Description Moves the RPS from draft or changes_requested to in_review (dosen, kaprodi, admin)
+// @Tags RPS Workflow
+// @Accept json
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param request body dto.RPSWorkflowTransitionRequest false "Optional comment"
+// @Success 200 {object} dto.APIResponse{data=dto.GeneratedRPSResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 403 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /generated-rps/{id}/workflow/submit [post]
+func (c *RPSWorkflowController) Submit(ctx *gin.Context) {
+	c.transition(ctx, services.WorkflowActionSubmit, "RPS submitted for review")
+}
+
+// RequestChanges godoc
+// @Summary Request changes on a generated RPS under review
+// @Description Moves the RPS from in_review to changes_requested; a comment is required (kaprodi, admin)
+// @Tags RPS Workflow
+// @Accept json
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param request body dto.RPSWorkflowTransitionRequest true "Reviewer comment"
+// @Success 200 {object} dto.APIResponse{data=dto.GeneratedRPSResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 403 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /generated-rps/{id}/workflow/request-changes [post]
+func (c *RPSWorkflowController) RequestChanges(ctx *gin.Context) {
+	c.transition(ctx, services.WorkflowActionRequestChanges, "Changes requested")
+}
+
+// Approve godoc
+// @Summary Approve a generated RPS under review
+// @Description Moves the RPS from in_review to approved (kaprodi, admin)
+// @Tags RPS Workflow
+// @Accept json
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param request body dto.RPSWorkflowTransitionRequest false "Optional reviewer comment"
+// @Success 200 {object} dto.APIResponse{data=dto.GeneratedRPSResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 403 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /generated-rps/{id}/workflow/approve [post]
+func (c *RPSWorkflowController) Approve(ctx *gin.Context) {
+	c.transition(ctx, services.WorkflowActionApprove, "RPS approved")
+}
+
+// Publish godoc
+// @Summary Publish an approved RPS
+// @Description Moves the RPS from approved to published (kaprodi, admin)
+// @Tags RPS Workflow
+// @Accept json
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param request body dto.RPSWorkflowTransitionRequest false "Optional comment"
+// @Success 200 {object} dto.APIResponse{data=dto.GeneratedRPSResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 403 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /generated-rps/{id}/workflow/publish [post]
+func (c *RPSWorkflowController) Publish(ctx *gin.Context) {
+	c.transition(ctx, services.WorkflowActionPublish, "RPS published")
+}
+
+// Reopen godoc
+// @Summary Reopen an approved or published RPS
+// @Description Moves the RPS back to draft so its content can be edited again; a comment is required (kaprodi, admin)
+// @Tags RPS Workflow
+// @Accept json
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Param request body dto.RPSWorkflowTransitionRequest true "Reason for reopening"
+// @Success 200 {object} dto.APIResponse{data=dto.GeneratedRPSResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 403 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Router /generated-rps/{id}/workflow/reopen [post]
+func (c *RPSWorkflowController) Reopen(ctx *gin.Context) {
+	c.transition(ctx, services.WorkflowActionReopen, "RPS reopened")
+}
+
+// FindTransitions godoc
+// @Summary Get the workflow history of a generated RPS
+// @Tags RPS Workflow
+// @Produce json
+// @Param id path string true "Generated RPS ID"
+// @Success 200 {object} dto.APIResponse{data=[]dto.RPSWorkflowTransitionResponse}
+// @Failure 404 {object} dto.APIResponse
+// @Router /generated-rps/{id}/workflow [get]
+func (c *RPSWorkflowController) FindTransitions(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid generated RPS ID", "INVALID_ID", nil))
+		return
+	}
+
+	transitions, err := c.service.FindTransitions(id)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Generated RPS not found", "NOT_FOUND", nil))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to fetch workflow history", "FETCH_ERROR", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse("Workflow history fetched successfully", transitions))
+}
+
+func (c *RPSWorkflowController) transition(ctx *gin.Context, action, message string) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid generated RPS ID", "INVALID_ID", nil))
+		return
+	}
+
+	user, ok := middleware.CurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, dto.ErrorResponse("Not authenticated", "UNAUTHORIZED", nil))
+		return
+	}
+
+	// The comment is optional for most transitions, so an empty body is fine
+	var req dto.RPSWorkflowTransitionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid request body", "INVALID_REQUEST", nil))
+		return
+	}
+
+	if err := helper.ValidateStruct(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Validation failed", "VALIDATION_ERROR", helper.FormatValidationErrors(err)))
+		return
+	}
+
+	rps, err := c.service.Transition(id, action, user, &req)
+	if err != nil {
+		switch {
+		case helper.IsNotFoundError(err):
+			ctx.JSON(http.StatusNotFound, dto.ErrorResponse("Generated RPS not found", "NOT_FOUND", nil))
+		case errors.Is(err, helper.ErrForbidden):
+			ctx.JSON(http.StatusForbidden, dto.ErrorResponse("Your role may not perform this transition", "FORBIDDEN", nil))
+		case errors.Is(err, services.ErrWorkflowCommentRequired):
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error(), "COMMENT_REQUIRED", nil))
+		case errors.Is(err, services.ErrRPSNotReady):
+			ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("RPS generation is not completed yet", "NOT_READY", nil))
+		case errors.Is(err, services.ErrWorkflowTransition), errors.Is(err, services.ErrWorkflowConflict):
+			ctx.JSON(http.StatusConflict, dto.ErrorResponse(err.Error(), "INVALID_TRANSITION", nil))
+		default:
+			ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to update workflow", "WORKFLOW_ERROR", nil))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.SuccessResponse(message, rps))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
 	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
 	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+	"gorm.io/datatypes"
 )
 
 type TemplateVersionController struct {
@@ -40,6 +41,10 @@ func (c *TemplateVersionController) Create(ctx *gin.Context) {
 		return
 	}
 
+	if !c.validateLayout(ctx, req.Definition) {
+		return
+	}
+
 	version, err := c.service.Create(&req)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to create template version", "CREATE_ERROR", nil))
@@ -174,6 +179,10 @@ func (c *TemplateVersionController) Update(ctx *gin.Context) {
 		return
 	}
 
+	if req.Definition != nil && !c.validateLayout(ctx, req.Definition) {
+		return
+	}
+
 	version, err := c.service.Update(id, &req)
 	if err != nil {
 		if helper.IsNotFoundError(err) {
@@ -213,3 +222,16 @@ func (c *TemplateVersionController) Delete(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, dto.SuccessResponse("Template version deleted successfully", nil))
 }
+
+// validateLayout rejects definitions whose layout directives the exporters cannot render
+func (c *TemplateVersionController) validateLayout(ctx *gin.Context, definition datatypes.JSON) bool {
+	if _, err := services.ParseRPSLayout(definition); err != nil {
+		errors := helper.FormatValidationErrors(err)
+		if len(errors) == 0 {
+			errors["layout"] = err.Error()
+		}
+		ctx.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid template layout", "VALIDATION_ERROR", errors))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AIAnalyticsQuery - query of GET /admin/ai/analytics
+type AIAnalyticsQuery struct {
+	Interval  string // hour or day; default day
+	From      *time.Time
+	To        *time.Time
+	Model     string
+	CourseID  *uuid.UUID
+	ProgramID *uuid.UUID
+}
+
+// AILatency is the latency of the successful calls in milliseconds
+type AILatency struct {
+	P50 int64   `json:"p50"`
+	P95 int64   `json:"p95"`
+	P99 int64   `json:"p99"`
+	Avg float64 `json:"avg"`
+}
+
+// AIAnalyticsPoint is the AI traffic of one bucket, or of the whole range in the totals
+type AIAnalyticsPoint struct {
+	Bucket           *time.Time       `json:"bucket,omitempty"` // start of the hour or day, UTC
+	Requests         int64            `json:"requests"`
+	Succeeded        int64            `json:"succeeded"`
+	Failed           int64            `json:"failed"` // failed and timed out
+	Cancelled        int64            `json:"cancelled"`
+	FailureRate      float64          `json:"failure_rate"` // failed / (succeeded + failed)
+	FailuresByClass  map[string]int64 `json:"failures_by_class"`
+	LatencyMs        AILatency        `json:"latency_ms"`
+	PromptTokens     int64            `json:"prompt_tokens"`
+	CompletionTokens int64            `json:"completion_tokens"`
+	TotalTokens      int64            `json:"total_tokens"`
+	Cost             float64          `json:"cost"` // USD
+}
+
+type AIAnalyticsResponse struct {
+	Interval  string             `json:"interval"`
+	From      time.Time          `json:"from"`
+	To        time.Time          `json:"to"`
+	Model     string             `json:"model,omitempty"`
+	CourseID  *uuid.UUID         `json:"course_id,omitempty"`
+	ProgramID *uuid.UUID         `json:"program_id,omitempty"`
+	Totals    AIAnalyticsPoint   `json:"totals"`
+	Series    []AIAnalyticsPoint `json:"series"` // every bucket of the range, empty ones included
+}
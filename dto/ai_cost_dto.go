@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SetAIBudgetRequest - request body for PUT /admin/budgets/programs/:id and /admin/budgets/users/:id
+type SetAIBudgetRequest struct {
+	MonthlyLimit *float64 `json:"monthly_limit" validate:"required,gte=0"` // USD per calendar month (UTC); 0 blocks generation
+}
+
+// AIBudgetResponse is a budget with the spend of the current month
+type AIBudgetResponse struct {
+	ID           uuid.UUID        `json:"id"`
+	Scope        string           `json:"scope"` // program or user
+	ProgramID    *uuid.UUID       `json:"program_id,omitempty"`
+	Program      *ProgramResponse `json:"program,omitempty"`
+	UserID       *uuid.UUID       `json:"user_id,omitempty"`
+	Username     string           `json:"username,omitempty"`
+	MonthlyLimit float64          `json:"monthly_limit"`
+	Month        string           `json:"month"` // YYYY-MM
+	Spent        float64          `json:"spent"`
+	Remaining    float64          `json:"remaining"` // never below 0
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// AICostReportQuery - query of GET /admin/ai/costs
+type AICostReportQuery struct {
+	GroupBy   []string // program, user, model, month; default month
+	From      *time.Time
+	To        *time.Time
+	ProgramID *uuid.UUID
+	UserID    *uuid.UUID
+}
+
+// AICostReportRow is the usage and cost of one group; keys that were not grouped by are omitted
+type AICostReportRow struct {
+	ProgramID        string  `json:"program_id,omitempty"`
+	ProgramCode      string  `json:"program_code,omitempty"`
+	UserID           string  `json:"user_id,omitempty"`
+	Username         string  `json:"username,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	Month            string  `json:"month,omitempty"` // YYYY-MM, UTC
+	Calls            int64   `json:"calls"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"` // USD
+}
+
+type AICostReport struct {
+	GroupBy   []string          `json:"group_by"`
+	From      *time.Time        `json:"from,omitempty"`
+	To        *time.Time        `json:"to,omitempty"`
+	TotalCost float64           `json:"total_cost"`
+	Rows      []AICostReportRow `json:"rows"`
+}
@@ -23,7 +23,7 @@ type GeminiPart struct {
 
 // GeminiGenConfig - Generation configuration
 type GeminiGenConfig struct {
-	Temperature      float64                `json:"temperature,omitempty"`
+	Temperature      *float64               `json:"temperature,omitempty"` // pointer so 0 is still sent
 	TopP             float64                `json:"topP,omitempty"`
 	TopK             int                    `json:"topK,omitempty"`
 	MaxOutputTokens  int                    `json:"maxOutputTokens,omitempty"`
@@ -76,6 +76,62 @@ type GeminiError struct {
 	} `json:"error"`
 }
 
+// ==================== OpenAI-compatible Chat Completions DTOs ====================
+
+// OpenAIChatRequest - request for /chat/completions (OpenAI, Ollama, vLLM)
+type OpenAIChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []OpenAIChatMessage   `json:"messages"`
+	Temperature    float64               `json:"temperature"`
+	TopP           float64               `json:"top_p,omitempty"`
+	MaxTokens      int                   `json:"max_tokens,omitempty"`
+	ResponseFormat *OpenAIResponseFormat `json:"response_format,omitempty"`
+}
+
+// OpenAIChatMessage - single chat message
+type OpenAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// OpenAIResponseFormat - structured output format
+type OpenAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *OpenAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+// OpenAIJSONSchema - JSON schema for structured output
+type OpenAIJSONSchema struct {
+	Name   string                 `json:"name"`
+	Schema map[string]interface{} `json:"schema"`
+	Strict bool                   `json:"strict"`
+}
+
+// OpenAIChatResponse - response from /chat/completions
+type OpenAIChatResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int               `json:"index"`
+		Message      OpenAIChatMessage `json:"message"`
+		FinishReason string            `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// OpenAIError - error response from OpenAI-compatible servers
+type OpenAIError struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    any    `json:"code"`
+	} `json:"error"`
+}
+
 // RPS Structured Output Schema
 type RPSStructuredOutput struct {
 	Identitas           RPSIdentitas           `json:"identitas"`
@@ -93,6 +149,11 @@ type RPSIdentitas struct {
 	Semester       string `json:"semester"`
 	Prasyarat      string `json:"prasyarat"`
 	DosenPengampu  string `json:"dosen_pengampu"`
+
+	// Copied from the course catalog after generation
+	SifatMataKuliah string `json:"sifat_mata_kuliah,omitempty"`
+	SKSTeori        int    `json:"sks_teori,omitempty"`
+	SKSPraktikum    int    `json:"sks_praktikum,omitempty"`
 }
 
 type RPSCapaianPembelajaran struct {
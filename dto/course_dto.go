@@ -8,26 +8,68 @@ import (
 
 // Request DTOs
 type CreateCourseRequest struct {
-	ProgramID *uuid.UUID `json:"program_id" validate:"omitempty,uuid"`
-	Code      string     `json:"code" validate:"required,min=2,max=20"`
-	Title     string     `json:"title" validate:"required,min=3,max=200"`
-	Credits   *int       `json:"credits" validate:"omitempty,min=1,max=10"`
+	ProgramID       *uuid.UUID `json:"program_id" validate:"omitempty,uuid"`
+	Code            string     `json:"code" validate:"required,min=2,max=20"`
+	Title           string     `json:"title" validate:"required,min=3,max=200"`
+	Credits         *int       `json:"credits" validate:"omitempty,min=1,max=10"`
+	Semester        *int       `json:"semester" validate:"omitempty,min=1,max=14"`
+	CourseType      *string    `json:"course_type" validate:"omitempty,oneof=wajib pilihan"`
+	TheoryCredits   *int       `json:"theory_credits" validate:"omitempty,min=0,max=10"`
+	PracticeCredits *int       `json:"practice_credits" validate:"omitempty,min=0,max=10"`
+	Description     *string    `json:"description" validate:"omitempty,max=2000"`
+	BahanKajian     []string   `json:"bahan_kajian" validate:"omitempty,max=30,dive,min=2,max=200"`
 }
 
 type UpdateCourseRequest struct {
-	ProgramID *uuid.UUID `json:"program_id" validate:"omitempty,uuid"`
-	Code      *string    `json:"code" validate:"omitempty,min=2,max=20"`
-	Title     *string    `json:"title" validate:"omitempty,min=3,max=200"`
-	Credits   *int       `json:"credits" validate:"omitempty,min=1,max=10"`
+	ProgramID       *uuid.UUID `json:"program_id" validate:"omitempty,uuid"`
+	Code            *string    `json:"code" validate:"omitempty,min=2,max=20"`
+	Title           *string    `json:"title" validate:"omitempty,min=3,max=200"`
+	Credits         *int       `json:"credits" validate:"omitempty,min=1,max=10"`
+	Semester        *int       `json:"semester" validate:"omitempty,min=1,max=14"`
+	CourseType      *string    `json:"course_type" validate:"omitempty,oneof=wajib pilihan"`
+	TheoryCredits   *int       `json:"theory_credits" validate:"omitempty,min=0,max=10"`
+	PracticeCredits *int       `json:"practice_credits" validate:"omitempty,min=0,max=10"`
+	Description     *string    `json:"description" validate:"omitempty,max=2000"`
+	BahanKajian     []string   `json:"bahan_kajian" validate:"omitempty,max=30,dive,min=2,max=200"`
+}
+
+type AssignLecturerRequest struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+type AddPrerequisiteRequest struct {
+	PrerequisiteID uuid.UUID `json:"prerequisite_id" validate:"required"`
+	Type           string    `json:"type" validate:"omitempty,oneof=prerequisite corequisite"` // default prerequisite
 }
 
 // Response DTOs
 type CourseResponse struct {
-	ID        uuid.UUID        `json:"id"`
-	ProgramID *uuid.UUID       `json:"program_id,omitempty"`
-	Code      string           `json:"code"`
-	Title     string           `json:"title"`
-	Credits   *int             `json:"credits,omitempty"`
-	CreatedAt time.Time        `json:"created_at"`
-	Program   *ProgramResponse `json:"program,omitempty"`
+	ID               uuid.UUID                 `json:"id"`
+	ProgramID        *uuid.UUID                `json:"program_id,omitempty"`
+	Code             string                    `json:"code"`
+	Title            string                    `json:"title"`
+	Credits          *int                      `json:"credits,omitempty"`
+	Semester         *int                      `json:"semester,omitempty"`
+	CourseType       *string                   `json:"course_type,omitempty"`
+	TheoryCredits    *int                      `json:"theory_credits,omitempty"`
+	PracticeCredits  *int                      `json:"practice_credits,omitempty"`
+	Description      *string                   `json:"description,omitempty"`
+	BahanKajian      []string                  `json:"bahan_kajian,omitempty"`
+	CreatedAt        time.Time                 `json:"created_at"`
+	Program          *ProgramResponse          `json:"program,omitempty"`
+	Lecturers        []UserResponse            `json:"lecturers,omitempty"`
+	LearningOutcomes []LearningOutcomeResponse `json:"learning_outcomes,omitempty"`
+}
+
+// CoursePrerequisiteNode is a course in a prerequisite tree with the courses it requires
+type CoursePrerequisiteNode struct {
+	Course        CourseResponse           `json:"course"`
+	Type          string                   `json:"type,omitempty"`
+	Prerequisites []CoursePrerequisiteNode `json:"prerequisites"`
+}
+
+// CourseDependentResponse is a course that requires another course
+type CourseDependentResponse struct {
+	Course CourseResponse `json:"course"`
+	Type   string         `json:"type"`
 }
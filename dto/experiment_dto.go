@@ -0,0 +1,71 @@
+package dto
+
+import "time"
+
+// ExperimentArmRequest defines one arm of an experiment. PromptTemplateID pins
+// the template at its current version, which must equal PromptTemplateVersion when given.
+type ExperimentArmRequest struct {
+	Name                  string   `json:"name" validate:"required,max=50"`
+	Weight                int      `json:"weight" validate:"required,min=1,max=100"` // share of the traffic relative to the other arms
+	PromptTemplateID      string   `json:"prompt_template_id" validate:"omitempty,len=24,hexadecimal"`
+	PromptTemplateVersion int      `json:"prompt_template_version" validate:"omitempty,min=1"`
+	Model                 string   `json:"model" validate:"omitempty,max=100"`
+	Temperature           *float64 `json:"temperature" validate:"omitempty,min=0,max=2"`
+	MaxTokens             int      `json:"max_tokens" validate:"omitempty,min=1,max=65536"`
+}
+
+// CreateExperimentRequest - request body for POST /admin/experiments
+type CreateExperimentRequest struct {
+	Name        string                 `json:"name" validate:"required,min=3,max=100"`
+	Description string                 `json:"description" validate:"omitempty,max=500"`
+	Arms        []ExperimentArmRequest `json:"arms" validate:"required,min=2,max=10,dive"`
+}
+
+// UpdateExperimentRequest - request body for PUT /admin/experiments/:id.
+// Arms can only change before the experiment starts.
+type UpdateExperimentRequest struct {
+	Name        *string                `json:"name" validate:"omitempty,min=3,max=100"`
+	Description *string                `json:"description" validate:"omitempty,max=500"`
+	Arms        []ExperimentArmRequest `json:"arms" validate:"omitempty,min=2,max=10,dive"`
+}
+
+// ExperimentArmReport compares one arm: generation outcome and usage from
+// ai_generations, call latency from ai_prompts, validation and review from the generated RPS
+type ExperimentArmReport struct {
+	Arm                   string   `json:"arm"`
+	Weight                int      `json:"weight"`
+	PromptTemplateID      string   `json:"prompt_template_id,omitempty"`
+	PromptTemplateVersion int      `json:"prompt_template_version,omitempty"`
+	Model                 string   `json:"model,omitempty"`
+	Temperature           *float64 `json:"temperature,omitempty"`
+	MaxTokens             int      `json:"max_tokens,omitempty"`
+
+	Generations   int64   `json:"generations"`
+	Succeeded     int64   `json:"succeeded"`
+	Failed        int64   `json:"failed"`
+	Cancelled     int64   `json:"cancelled"`
+	SuccessRate   float64 `json:"success_rate"` // succeeded out of succeeded and failed, 0..1
+	TotalTokens   int64   `json:"total_tokens"`
+	AvgTokens     float64 `json:"avg_tokens"` // per generation, retries included
+	TotalCost     float64 `json:"total_cost"` // USD
+	AvgCost       float64 `json:"avg_cost"`   // per generation, retries included
+	AvgDurationMs float64 `json:"avg_duration_ms"`
+	AvgLatencyMs  float64 `json:"avg_latency_ms"` // per successful provider call
+
+	Jobs             int64   `json:"jobs"`
+	Done             int64   `json:"done"`
+	AvgFindings      float64 `json:"avg_findings"` // validation findings per done job
+	AvgErrorFindings float64 `json:"avg_error_findings"`
+	Reviewed         int64   `json:"reviewed"`
+	Approved         int64   `json:"approved"`
+	AcceptanceRate   float64 `json:"acceptance_rate"` // approved out of reviewed, 0..1
+}
+
+type ExperimentReport struct {
+	ExperimentID string                `json:"experiment_id"`
+	Name         string                `json:"name"`
+	Status       string                `json:"status"`
+	StartedAt    *time.Time            `json:"started_at,omitempty"`
+	StoppedAt    *time.Time            `json:"stopped_at,omitempty"`
+	Arms         []ExperimentArmReport `json:"arms"`
+}
@@ -15,14 +15,16 @@ type CreateGeneratedRPSRequest struct {
 }
 
 type UpdateGeneratedRPSRequest struct {
-	Status          *string        `json:"status" validate:"omitempty,oneof=queued processing done failed"`
+	Status          *string        `json:"status" validate:"omitempty,oneof=queued processing done failed cancelled"`
 	Result          datatypes.JSON `json:"result" validate:"omitempty"`
+	ChangeNote      *string        `json:"change_note" validate:"omitempty,max=500"` // stored on the revision created for a new result
 	ExportedFileURL *string        `json:"exported_file_url" validate:"omitempty,url"`
 	AIMetadata      datatypes.JSON `json:"ai_metadata" validate:"omitempty"`
+	EditedBy        *uuid.UUID     `json:"-"` // set from the authenticated user
 }
 
 type UpdateGeneratedRPSStatusRequest struct {
-	Status string `json:"status" validate:"required,oneof=queued processing done failed"`
+	Status string `json:"status" validate:"required,oneof=queued processing done failed cancelled"`
 }
 
 // CompleteGenerationRequest - used by internal worker to submit generation result
@@ -45,15 +47,35 @@ type GenerateRPSRequest struct {
 
 // GenerateRPSOptions - options for RPS generation
 type GenerateRPSOptions struct {
-	Language      string                 `json:"language" validate:"omitempty"`
-	Tone          string                 `json:"tone" validate:"omitempty"`
-	DosenPengampu string                 `json:"dosen_pengampu" validate:"omitempty"`
-	Semester      string                 `json:"semester" validate:"omitempty"`
-	Prasyarat     string                 `json:"prasyarat" validate:"omitempty"`
-	ProgramStudi  string                 `json:"program_studi" validate:"omitempty"`
-	Fakultas      string                 `json:"fakultas" validate:"omitempty"`
-	TahunAkademik string                 `json:"tahun_akademik" validate:"omitempty"`
-	Overrides     map[string]interface{} `json:"overrides" validate:"omitempty"`
+	Language          string                 `json:"language" validate:"omitempty"`
+	Tone              string                 `json:"tone" validate:"omitempty"`
+	DosenPengampu     string                 `json:"dosen_pengampu" validate:"omitempty"`
+	Semester          string                 `json:"semester" validate:"omitempty"`
+	Prasyarat         string                 `json:"prasyarat" validate:"omitempty"`
+	ProgramStudi      string                 `json:"program_studi" validate:"omitempty"`
+	Fakultas          string                 `json:"fakultas" validate:"omitempty"`
+	TahunAkademik     string                 `json:"tahun_akademik" validate:"omitempty"`
+	Provider          string                 `json:"provider" validate:"omitempty,oneof=gemini openai fake"` // default: AI_PROVIDER
+	ValidationRetries int                    `json:"validation_retries" validate:"omitempty,min=0,max=3"`    // regenerate while the validator reports errors
+	Overrides         map[string]interface{} `json:"overrides" validate:"omitempty"`
+
+	// Model parameters, checked against the provider limits and the AI_* allowed
+	// ranges; unset values keep the prompt template or built-in defaults
+	Model       string   `json:"model,omitempty" validate:"omitempty,max=100"`
+	Temperature *float64 `json:"temperature,omitempty" validate:"omitempty,min=0,max=2"`
+	TopP        *float64 `json:"top_p,omitempty" validate:"omitempty,gt=0,max=1"`
+	TopK        *int     `json:"top_k,omitempty" validate:"omitempty,min=1"`
+	MaxTokens   *int     `json:"max_tokens,omitempty" validate:"omitempty,min=1"`
+}
+
+// RegenerateSectionRequest - request body for POST /generated/:id/sections/:section/regenerate.
+// WeekFrom/WeekTo only apply to rencana_mingguan.
+type RegenerateSectionRequest struct {
+	WeekFrom     int        `json:"week_from" validate:"omitempty,min=1"`
+	WeekTo       int        `json:"week_to" validate:"omitempty,min=1,gtefield=WeekFrom"`
+	Instructions string     `json:"instructions" validate:"omitempty,max=2000"`
+	Provider     string     `json:"provider" validate:"omitempty,oneof=gemini openai fake"` // default: provider of the original generation
+	RequestedBy  *uuid.UUID `json:"-"`                                                      // set from the authenticated user
 }
 
 // GenerateRPSResponse - response for POST /generate
@@ -64,17 +86,32 @@ type GenerateRPSResponse struct {
 
 // Response DTOs
 type GeneratedRPSResponse struct {
-	ID                uuid.UUID                `json:"id"`
-	TemplateVersionID *uuid.UUID               `json:"template_version_id,omitempty"`
-	CourseID          *uuid.UUID               `json:"course_id,omitempty"`
-	GeneratedBy       *uuid.UUID               `json:"generated_by,omitempty"`
-	Status            string                   `json:"status"`
-	Result            datatypes.JSON           `json:"result,omitempty"`
-	ExportedFileURL   *string                  `json:"exported_file_url,omitempty"`
-	AIMetadata        datatypes.JSON           `json:"ai_metadata,omitempty"`
-	CreatedAt         time.Time                `json:"created_at"`
-	UpdatedAt         time.Time                `json:"updated_at"`
-	TemplateVersion   *TemplateVersionResponse `json:"template_version,omitempty"`
-	Course            *CourseResponse          `json:"course,omitempty"`
-	Generator         *UserResponse            `json:"generator,omitempty"`
+	ID                 uuid.UUID                `json:"id"`
+	TemplateVersionID  *uuid.UUID               `json:"template_version_id,omitempty"`
+	CourseID           *uuid.UUID               `json:"course_id,omitempty"`
+	GeneratedBy        *uuid.UUID               `json:"generated_by,omitempty"`
+	BatchID            *uuid.UUID               `json:"batch_id,omitempty"`
+	ExperimentID       *string                  `json:"experiment_id,omitempty"`
+	ExperimentArm      *string                  `json:"experiment_arm,omitempty"`
+	Status             string                   `json:"status"`
+	Result             datatypes.JSON           `json:"result,omitempty"`
+	ExportedFileURL    *string                  `json:"exported_file_url,omitempty"`
+	AIMetadata         datatypes.JSON           `json:"ai_metadata,omitempty"`
+	ValidationFindings datatypes.JSON           `json:"validation_findings,omitempty"`
+	ValidatedAt        *time.Time               `json:"validated_at,omitempty"`
+	WorkflowStatus     string                   `json:"workflow_status"`
+	SubmittedAt        *time.Time               `json:"submitted_at,omitempty"`
+	ReviewedAt         *time.Time               `json:"reviewed_at,omitempty"`
+	ReviewedBy         *uuid.UUID               `json:"reviewed_by,omitempty"`
+	ApprovedAt         *time.Time               `json:"approved_at,omitempty"`
+	PublishedAt        *time.Time               `json:"published_at,omitempty"`
+	UnresolvedComments int                      `json:"unresolved_comments"`
+	Attempts           int                      `json:"attempts"`
+	StartedAt          *time.Time               `json:"started_at,omitempty"`
+	FinishedAt         *time.Time               `json:"finished_at,omitempty"`
+	CreatedAt          time.Time                `json:"created_at"`
+	UpdatedAt          time.Time                `json:"updated_at"`
+	TemplateVersion    *TemplateVersionResponse `json:"template_version,omitempty"`
+	Course             *CourseResponse          `json:"course,omitempty"`
+	Generator          *UserResponse            `json:"generator,omitempty"`
 }
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// CreateGenerationBatchRequest - request body for POST /generate/batches.
+// Either ProgramID (optionally narrowed to a semester) or CourseIDs selects the courses.
+type CreateGenerationBatchRequest struct {
+	ProgramID         *uuid.UUID          `json:"program_id" validate:"required_without=CourseIDs,excluded_with=CourseIDs"`
+	Semester          *int                `json:"semester" validate:"omitempty,min=1,max=14,excluded_without=ProgramID"`
+	CourseIDs         []uuid.UUID         `json:"course_ids" validate:"omitempty,max=200"`
+	TemplateVersionID uuid.UUID           `json:"template_version_id" validate:"required"`
+	Options           *GenerateRPSOptions `json:"options" validate:"omitempty"` // shared by every job of the batch
+	CreatedBy         *uuid.UUID          `json:"-"`                            // set from the authenticated user
+}
+
+// GenerationBatchProgress counts the jobs of a batch per status
+type GenerationBatchProgress struct {
+	Total      int64 `json:"total"`
+	Queued     int64 `json:"queued"`
+	Processing int64 `json:"processing"`
+	Done       int64 `json:"done"`
+	Failed     int64 `json:"failed"`
+	Cancelled  int64 `json:"cancelled"`
+	Percent    int   `json:"percent"` // finished (done, failed or cancelled) jobs out of total
+}
+
+// GenerationBatchUsage sums the AI usage recorded by the finished jobs
+type GenerationBatchUsage struct {
+	TotalTokens   int64   `json:"total_tokens"`
+	EstimatedCost float64 `json:"estimated_cost"`
+}
+
+// GenerationBatchJob is one child job as listed in the batch detail
+type GenerationBatchJob struct {
+	JobID       uuid.UUID  `json:"job_id"`
+	CourseID    *uuid.UUID `json:"course_id,omitempty"`
+	CourseCode  string     `json:"course_code,omitempty"`
+	CourseTitle string     `json:"course_title,omitempty"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	Error       string     `json:"error,omitempty"` // set for failed jobs
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+type GenerationBatchResponse struct {
+	ID                uuid.UUID               `json:"id"`
+	ProgramID         *uuid.UUID              `json:"program_id,omitempty"`
+	Semester          *int                    `json:"semester,omitempty"`
+	TemplateVersionID uuid.UUID               `json:"template_version_id"`
+	Options           datatypes.JSON          `json:"options,omitempty"`
+	CreatedBy         *uuid.UUID              `json:"created_by,omitempty"`
+	Status            string                  `json:"status"` // running|completed|completed_with_failures|cancelled
+	CancelledAt       *time.Time              `json:"cancelled_at,omitempty"`
+	CreatedAt         time.Time               `json:"created_at"`
+	UpdatedAt         time.Time               `json:"updated_at"`
+	Progress          GenerationBatchProgress `json:"progress"`
+	Usage             GenerationBatchUsage    `json:"usage"`
+	Failures          []GenerationBatchJob    `json:"failures,omitempty"`
+	Jobs              []GenerationBatchJob    `json:"jobs,omitempty"`
+	Program           *ProgramResponse        `json:"program,omitempty"`
+	Creator           *UserResponse           `json:"creator,omitempty"`
+}
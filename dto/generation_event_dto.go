@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GenerationEvent is one progress update of a generation job, streamed by
+// GET /generate/:job_id/events
+type GenerationEvent struct {
+	JobID     uuid.UUID   `json:"job_id"`
+	Type      string      `json:"type"`              // status|attempt|validation|result
+	Status    string      `json:"status,omitempty"`  // job status, or the outcome of an attempt
+	Attempt   int         `json:"attempt,omitempty"` // LLM attempt or validation round
+	Message   string      `json:"message,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
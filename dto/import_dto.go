@@ -0,0 +1,22 @@
+package dto
+
+// ImportRowResult is the outcome of one data row of an import file
+type ImportRowResult struct {
+	Row    int               `json:"row"` // row number in the file, the header being row 1
+	Code   string            `json:"code"`
+	Action string            `json:"action"` // create|update|error
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// ImportReport summarizes a dry run or a committed import
+type ImportReport struct {
+	Entity    string            `json:"entity"` // program|course
+	DryRun    bool              `json:"dry_run"`
+	Upsert    bool              `json:"upsert"`
+	Committed bool              `json:"committed"`
+	Total     int               `json:"total"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+// RPSLayout - layout directives read from TemplateVersion.Definition["layout"].
+// Every field is optional; missing values fall back to the default RPS layout.
+type RPSLayout struct {
+	Title            string             `json:"title"`
+	Orientation      string             `json:"orientation" validate:"omitempty,oneof=portrait landscape"`
+	HeadingNumbering string             `json:"heading_numbering" validate:"omitempty,oneof=roman numeric alpha none"`
+	Sections         []RPSLayoutSection `json:"sections" validate:"omitempty,dive"`
+	WeeklyColumns    []RPSLayoutColumn  `json:"weekly_columns" validate:"omitempty,dive"`
+	Header           string             `json:"header"` // supports {page} and {pages}
+	Footer           string             `json:"footer"` // supports {page} and {pages}
+	Logo             *RPSLayoutLogo     `json:"logo" validate:"omitempty"`
+}
+
+// RPSLayoutSection - a section in output order; sections not listed are omitted
+type RPSLayoutSection struct {
+	Key             string `json:"key" validate:"required,oneof=identitas capaian_pembelajaran deskripsi_mata_kuliah rencana_mingguan rencana_penilaian daftar_referensi"`
+	Heading         string `json:"heading"`
+	PageBreakBefore bool   `json:"page_break_before"`
+}
+
+// RPSLayoutColumn - a column of the rencana_mingguan table
+type RPSLayoutColumn struct {
+	Key   string  `json:"key" validate:"required,oneof=minggu topik sub_topik indikator_capaian metode_pembelajaran waktu_menit referensi bentuk_penilaian"`
+	Label string  `json:"label"`
+	Width float64 `json:"width" validate:"omitempty,gt=0"` // relative weight, scaled to the page width
+}
+
+// RPSLayoutLogo - logo printed above the title
+type RPSLayoutLogo struct {
+	Data     string  `json:"data" validate:"required"`                // data URI, e.g. data:image/png;base64,...
+	Width    float64 `json:"width" validate:"omitempty,gt=0,lte=100"` // mm
+	Position string  `json:"position" validate:"omitempty,oneof=left center right"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Request DTOs
+type CreateLearningOutcomeRequest struct {
+	ProgramID   uuid.UUID `json:"program_id" validate:"required"`
+	Code        string    `json:"code" validate:"required,min=2,max=20"`
+	Description string    `json:"description" validate:"required,min=10,max=1000"`
+	Category    *string   `json:"category" validate:"omitempty,oneof=sikap pengetahuan keterampilan_umum keterampilan_khusus"`
+}
+
+type UpdateLearningOutcomeRequest struct {
+	Code        *string `json:"code" validate:"omitempty,min=2,max=20"`
+	Description *string `json:"description" validate:"omitempty,min=10,max=1000"`
+	Category    *string `json:"category" validate:"omitempty,oneof=sikap pengetahuan keterampilan_umum keterampilan_khusus"`
+}
+
+// SetCourseLearningOutcomesRequest replaces the CPL mapped to a course
+type SetCourseLearningOutcomesRequest struct {
+	LearningOutcomeIDs []uuid.UUID `json:"learning_outcome_ids" validate:"max=50"`
+}
+
+// Response DTOs
+type LearningOutcomeResponse struct {
+	ID          uuid.UUID `json:"id"`
+	ProgramID   uuid.UUID `json:"program_id"`
+	Code        string    `json:"code"`
+	Description string    `json:"description"`
+	Category    *string   `json:"category,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,48 @@
+package dto
+
+// PromptVariableRequest declares a {{name}} placeholder of a prompt template
+type PromptVariableRequest struct {
+	Name         string `json:"name" validate:"required,max=64"`
+	Description  string `json:"description" validate:"omitempty,max=500"`
+	Required     bool   `json:"required"`
+	DefaultValue string `json:"default_value"`
+	Type         string `json:"type" validate:"omitempty,oneof=string number array object"` // defaults to string
+}
+
+// CreatePromptTemplateRequest - request body for POST /admin/prompt-templates.
+// SystemPrompt and UserPromptTemplate may reference the declared variables as {{name}}.
+type CreatePromptTemplateRequest struct {
+	Name               string                  `json:"name" validate:"required,min=3,max=100"`
+	Description        string                  `json:"description" validate:"omitempty,max=500"`
+	SystemPrompt       string                  `json:"system_prompt" validate:"required"`
+	UserPromptTemplate string                  `json:"user_prompt_template" validate:"required"`
+	Variables          []PromptVariableRequest `json:"variables" validate:"omitempty,dive"`
+	DefaultModel       string                  `json:"default_model" validate:"omitempty,max=100"`
+	DefaultTemperature float64                 `json:"default_temperature" validate:"omitempty,min=0,max=2"`
+	DefaultMaxTokens   int                     `json:"default_max_tokens" validate:"omitempty,min=1,max=65536"`
+	Category           string                  `json:"category" validate:"omitempty,oneof=rps_generation review summary"` // defaults to rps_generation
+	Tags               []string                `json:"tags" validate:"omitempty,dive,max=50"`
+	IsActive           bool                    `json:"is_active"` // activating deactivates the other templates of the category
+}
+
+// UpdatePromptTemplateRequest - request body for PUT /admin/prompt-templates/:id.
+// Changing the prompts or the variables bumps the template version.
+type UpdatePromptTemplateRequest struct {
+	Name               *string                 `json:"name" validate:"omitempty,min=3,max=100"`
+	Description        *string                 `json:"description" validate:"omitempty,max=500"`
+	SystemPrompt       *string                 `json:"system_prompt" validate:"omitempty,min=1"`
+	UserPromptTemplate *string                 `json:"user_prompt_template" validate:"omitempty,min=1"`
+	Variables          []PromptVariableRequest `json:"variables" validate:"omitempty,dive"` // replaces the list when present
+	DefaultModel       *string                 `json:"default_model" validate:"omitempty,max=100"`
+	DefaultTemperature *float64                `json:"default_temperature" validate:"omitempty,min=0,max=2"`
+	DefaultMaxTokens   *int                    `json:"default_max_tokens" validate:"omitempty,min=1,max=65536"`
+	Category           *string                 `json:"category" validate:"omitempty,oneof=rps_generation review summary"`
+	Tags               []string                `json:"tags" validate:"omitempty,dive,max=50"`
+	IsActive           *bool                   `json:"is_active"`
+}
+
+// PromptVariableInfo describes a value the generator provides to prompt templates
+type PromptVariableInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
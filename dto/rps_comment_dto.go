@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Request DTOs
+type CreateRPSCommentRequest struct {
+	AnchorPath string      `json:"anchor_path" validate:"required,max=500"` // e.g. rencana_mingguan[4].metode_pembelajaran
+	Body       string      `json:"body" validate:"required,max=5000"`
+	Mentions   []uuid.UUID `json:"mentions" validate:"omitempty,max=20"` // user IDs
+}
+
+type ReplyRPSCommentRequest struct {
+	Body     string      `json:"body" validate:"required,max=5000"`
+	Mentions []uuid.UUID `json:"mentions" validate:"omitempty,max=20"`
+}
+
+// Response DTOs
+type RPSCommentResponse struct {
+	ID             uuid.UUID            `json:"id"`
+	GeneratedRPSID uuid.UUID            `json:"generated_rps_id"`
+	ParentID       *uuid.UUID           `json:"parent_id,omitempty"`
+	AnchorPath     string               `json:"anchor_path"`
+	AnchorValue    datatypes.JSON       `json:"anchor_value,omitempty"`
+	Body           string               `json:"body"`
+	AuthorID       *uuid.UUID           `json:"author_id,omitempty"`
+	Resolved       bool                 `json:"resolved"`
+	ResolvedBy     *uuid.UUID           `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time           `json:"resolved_at,omitempty"`
+	Outdated       bool                 `json:"outdated"` // the anchored value changed since the comment was made
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	Author         *UserResponse        `json:"author,omitempty"`
+	Mentions       []UserResponse       `json:"mentions,omitempty"`
+	Replies        []RPSCommentResponse `json:"replies,omitempty"`
+}
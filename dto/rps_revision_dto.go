@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Request DTOs
+type RestoreRPSRevisionRequest struct {
+	Note *string `json:"note" validate:"omitempty,max=500"`
+}
+
+// Response DTOs
+type RPSRevisionResponse struct {
+	ID             uuid.UUID      `json:"id"`
+	GeneratedRPSID uuid.UUID      `json:"generated_rps_id"`
+	Number         int            `json:"number"`
+	Source         string         `json:"source"`
+	Result         datatypes.JSON `json:"result,omitempty"` // omitted when listing revisions
+	Note           *string        `json:"note,omitempty"`
+	RestoredFrom   *int           `json:"restored_from,omitempty"`
+	AuthorID       *uuid.UUID     `json:"author_id,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	Author         *UserResponse  `json:"author,omitempty"`
+}
+
+// RPSRevisionChange is one changed field between two revisions, e.g. a week topic
+// at "rencana_mingguan[3].topik" in section "rencana_mingguan"
+type RPSRevisionChange struct {
+	Section string      `json:"section"`
+	Path    string      `json:"path"`
+	Type    string      `json:"type"` // added|removed|changed
+	From    interface{} `json:"from,omitempty"`
+	To      interface{} `json:"to,omitempty"`
+}
+
+type RPSRevisionDiffResponse struct {
+	GeneratedRPSID uuid.UUID           `json:"generated_rps_id"`
+	From           int                 `json:"from"`
+	To             int                 `json:"to"`
+	Changes        []RPSRevisionChange `json:"changes"`
+}
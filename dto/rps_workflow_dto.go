@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Request DTOs
+type RPSWorkflowTransitionRequest struct {
+	Comment *string `json:"comment" validate:"omitempty,max=2000"`
+}
+
+// Response DTOs
+type RPSWorkflowTransitionResponse struct {
+	ID             uuid.UUID     `json:"id"`
+	GeneratedRPSID uuid.UUID     `json:"generated_rps_id"`
+	Action         string        `json:"action"`
+	FromStatus     string        `json:"from_status"`
+	ToStatus       string        `json:"to_status"`
+	ActorID        *uuid.UUID    `json:"actor_id,omitempty"`
+	Comment        *string       `json:"comment,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	Actor          *UserResponse `json:"actor,omitempty"`
+}
@@ -8,25 +8,30 @@ import (
 
 // Request DTOs
 type CreateUserRequest struct {
-	Username    string  `json:"username" validate:"required,min=3,max=50"`
-	Email       *string `json:"email" validate:"omitempty,email"`
-	DisplayName *string `json:"display_name" validate:"omitempty,max=100"`
-	Role        string  `json:"role" validate:"required,oneof=admin kaprodi dosen viewer"`
+	Username    string     `json:"username" validate:"required,min=3,max=50"`
+	Email       *string    `json:"email" validate:"omitempty,email"`
+	DisplayName *string    `json:"display_name" validate:"omitempty,max=100"`
+	Role        string     `json:"role" validate:"required,oneof=admin kaprodi dosen viewer"`
+	ProgramID   *uuid.UUID `json:"program_id" validate:"omitempty,uuid"`
+	Password    string     `json:"password" validate:"required,min=8,max=72"`
 }
 
 type UpdateUserRequest struct {
-	Username    *string `json:"username" validate:"omitempty,min=3,max=50"`
-	Email       *string `json:"email" validate:"omitempty,email"`
-	DisplayName *string `json:"display_name" validate:"omitempty,max=100"`
-	Role        *string `json:"role" validate:"omitempty,oneof=admin kaprodi dosen viewer"`
+	Username    *string    `json:"username" validate:"omitempty,min=3,max=50"`
+	Email       *string    `json:"email" validate:"omitempty,email"`
+	DisplayName *string    `json:"display_name" validate:"omitempty,max=100"`
+	Role        *string    `json:"role" validate:"omitempty,oneof=admin kaprodi dosen viewer"`
+	ProgramID   *uuid.UUID `json:"program_id" validate:"omitempty,uuid"`
+	Password    *string    `json:"password" validate:"omitempty,min=8,max=72"`
 }
 
 // Response DTOs
 type UserResponse struct {
-	ID          uuid.UUID `json:"id"`
-	Username    string    `json:"username"`
-	Email       *string   `json:"email,omitempty"`
-	DisplayName *string   `json:"display_name,omitempty"`
-	Role        string    `json:"role"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          uuid.UUID  `json:"id"`
+	Username    string     `json:"username"`
+	Email       *string    `json:"email,omitempty"`
+	DisplayName *string    `json:"display_name,omitempty"`
+	Role        string     `json:"role"`
+	ProgramID   *uuid.UUID `json:"program_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
 }
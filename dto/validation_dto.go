@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ValidationFinding - a rule violation found in an RPS result; Path addresses the
+// offending value the same way as revision diffs, e.g. "rencana_mingguan[7].topik"
+type ValidationFinding struct {
+	Severity string `json:"severity"` // error|warning
+	Rule     string `json:"rule"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+// RPSValidationReport - response for GET /generated/:id/validation
+type RPSValidationReport struct {
+	GeneratedRPSID uuid.UUID           `json:"generated_rps_id"`
+	Valid          bool                `json:"valid"` // no error findings
+	ErrorCount     int                 `json:"error_count"`
+	WarningCount   int                 `json:"warning_count"`
+	Findings       []ValidationFinding `json:"findings"`
+	ValidatedAt    *time.Time          `json:"validated_at,omitempty"`
+}
@@ -0,0 +1,133 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// JSONChange describes a single difference between two JSON documents
+type JSONChange struct {
+	Path string      `json:"path"`
+	Type string      `json:"type"` // added|removed|changed
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// DiffJSON compares two values by their JSON representation and returns the
+// changed leaves, addressed by paths such as "rencana_mingguan[3].topik"
+func DiffJSON(before, after interface{}) ([]JSONChange, error) {
+	a, err := normalizeJSON(before)
+	if err != nil {
+		return nil, err
+	}
+	b, err := normalizeJSON(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []JSONChange{}
+	diffValue("", a, b, &changes)
+	return changes, nil
+}
+
+func normalizeJSON(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	var data []byte
+	switch val := v.(type) {
+	case []byte:
+		data = val
+	case json.RawMessage:
+		data = val
+	default:
+		marshaled, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = marshaled
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func diffValue(path string, a, b interface{}, changes *[]JSONChange) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			diffObject(path, av, bv, changes)
+			return
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			diffArray(path, av, bv, changes)
+			return
+		}
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+
+	switch {
+	case a == nil:
+		*changes = append(*changes, JSONChange{Path: path, Type: "added", To: b})
+	case b == nil:
+		*changes = append(*changes, JSONChange{Path: path, Type: "removed", From: a})
+	default:
+		*changes = append(*changes, JSONChange{Path: path, Type: "changed", From: a, To: b})
+	}
+}
+
+func diffObject(path string, a, b map[string]interface{}, changes *[]JSONChange) {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		childPath := k
+		if path != "" {
+			childPath = path + "." + k
+		}
+		diffValue(childPath, a[k], b[k], changes)
+	}
+}
+
+func diffArray(path string, a, b []interface{}, changes *[]JSONChange) {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+
+	for i := 0; i < n; i++ {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(a):
+			*changes = append(*changes, JSONChange{Path: childPath, Type: "added", To: b[i]})
+		case i >= len(b):
+			*changes = append(*changes, JSONChange{Path: childPath, Type: "removed", From: a[i]})
+		default:
+			diffValue(childPath, a[i], b[i], changes)
+		}
+	}
+}
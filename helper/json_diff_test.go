@@ -0,0 +1,91 @@
+package helper
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after interface{}
+		want          []JSONChange
+	}{
+		{
+			name:   "equal documents",
+			before: []byte(`{"a": 1, "b": [1, 2]}`),
+			after:  []byte(`{"b": [1, 2], "a": 1}`),
+			want:   []JSONChange{},
+		},
+		{
+			name:   "changed leaf",
+			before: []byte(`{"identitas": {"sks": 3}}`),
+			after:  []byte(`{"identitas": {"sks": 2}}`),
+			want:   []JSONChange{{Path: "identitas.sks", Type: "changed", From: 3.0, To: 2.0}},
+		},
+		{
+			name:   "added and removed keys in sorted order",
+			before: []byte(`{"b": "x", "c": true}`),
+			after:  []byte(`{"a": "y", "c": true}`),
+			want: []JSONChange{
+				{Path: "a", Type: "added", To: "y"},
+				{Path: "b", Type: "removed", From: "x"},
+			},
+		},
+		{
+			name:   "array elements",
+			before: []byte(`{"rencana_mingguan": [{"topik": "A"}, {"topik": "B"}]}`),
+			after:  []byte(`{"rencana_mingguan": [{"topik": "A"}, {"topik": "C"}, {"topik": "D"}]}`),
+			want: []JSONChange{
+				{Path: "rencana_mingguan[1].topik", Type: "changed", From: "B", To: "C"},
+				{Path: "rencana_mingguan[2]", Type: "added", To: map[string]interface{}{"topik": "D"}},
+			},
+		},
+		{
+			name:   "shorter array",
+			before: []byte(`[1, 2, 3]`),
+			after:  []byte(`[1]`),
+			want: []JSONChange{
+				{Path: "[1]", Type: "removed", From: 2.0},
+				{Path: "[2]", Type: "removed", From: 3.0},
+			},
+		},
+		{
+			name:   "type change replaces the whole value",
+			before: []byte(`{"a": {"b": 1}}`),
+			after:  []byte(`{"a": [1]}`),
+			want:   []JSONChange{{Path: "a", Type: "changed", From: map[string]interface{}{"b": 1.0}, To: []interface{}{1.0}}},
+		},
+		{
+			name:   "empty before",
+			before: nil,
+			after:  json.RawMessage(`{"a": 1}`),
+			want:   []JSONChange{{Path: "", Type: "added", To: map[string]interface{}{"a": 1.0}}},
+		},
+		{
+			name:   "structs compare by their json",
+			before: struct{ Name string }{"old"},
+			after:  map[string]string{"Name": "new"},
+			want:   []JSONChange{{Path: "Name", Type: "changed", From: "old", To: "new"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffJSON(tt.before, tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("DiffJSON() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffJSONInvalid(t *testing.T) {
+	if _, err := DiffJSON([]byte(`{"a":`), []byte(`{}`)); err == nil {
+		t.Fatal("DiffJSON() = nil error for malformed JSON")
+	}
+}
@@ -0,0 +1,86 @@
+package helper
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidJSONPath = errors.New("invalid JSON path")
+
+// jsonPathStep is either an object key or an array index
+type jsonPathStep struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// LookupJSONPath returns the value at a path in the style produced by DiffJSON,
+// e.g. "rencana_mingguan[4].metode_pembelajaran" or "capaian_pembelajaran.cpmk[1]".
+// found is false when the path does not exist in doc.
+func LookupJSONPath(doc interface{}, path string) (value interface{}, found bool, err error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	current, err := normalizeJSON(doc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, step := range steps {
+		if step.isKey {
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false, nil
+			}
+			if current, ok = object[step.key]; !ok {
+				return nil, false, nil
+			}
+			continue
+		}
+
+		array, ok := current.([]interface{})
+		if !ok || step.index >= len(array) {
+			return nil, false, nil
+		}
+		current = array[step.index]
+	}
+
+	return current, true, nil
+}
+
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, ErrInvalidJSONPath
+	}
+
+	var steps []jsonPathStep
+	for _, segment := range strings.Split(path, ".") {
+		key := segment
+		if open := strings.IndexByte(segment, '['); open >= 0 {
+			key = segment[:open]
+		}
+		if key == "" {
+			return nil, ErrInvalidJSONPath
+		}
+		steps = append(steps, jsonPathStep{key: key, isKey: true})
+
+		rest := segment[len(key):]
+		for rest != "" {
+			end := strings.IndexByte(rest, ']')
+			if rest[0] != '[' || end < 0 {
+				return nil, ErrInvalidJSONPath
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, ErrInvalidJSONPath
+			}
+			steps = append(steps, jsonPathStep{index: index})
+			rest = rest[end+1:]
+		}
+	}
+
+	return steps, nil
+}
@@ -1,9 +1,13 @@
 package helper
 
 import (
+	"encoding/json"
+	"strings"
+
 	"github.com/google/uuid"
 	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
 	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"gorm.io/datatypes"
 )
 
 // User Mapper
@@ -17,6 +21,7 @@ func ToUserResponse(user *models.User) *dto.UserResponse {
 		Email:       user.Email,
 		DisplayName: user.DisplayName,
 		Role:        user.Role,
+		ProgramID:   user.ProgramID,
 		CreatedAt:   user.CreatedAt,
 	}
 }
@@ -36,6 +41,7 @@ func ToUserModel(req *dto.CreateUserRequest) *models.User {
 		Email:       req.Email,
 		DisplayName: req.DisplayName,
 		Role:        req.Role,
+		ProgramID:   req.ProgramID,
 	}
 }
 
@@ -73,13 +79,21 @@ func ToCourseResponse(course *models.Course) *dto.CourseResponse {
 		return nil
 	}
 	return &dto.CourseResponse{
-		ID:        course.ID,
-		ProgramID: course.ProgramID,
-		Code:      course.Code,
-		Title:     course.Title,
-		Credits:   course.Credits,
-		CreatedAt: course.CreatedAt,
-		Program:   ToProgramResponse(course.Program),
+		ID:               course.ID,
+		ProgramID:        course.ProgramID,
+		Code:             course.Code,
+		Title:            course.Title,
+		Credits:          course.Credits,
+		Semester:         course.Semester,
+		CourseType:       course.CourseType,
+		TheoryCredits:    course.TheoryCredits,
+		PracticeCredits:  course.PracticeCredits,
+		Description:      course.Description,
+		BahanKajian:      ToBahanKajianList(course.BahanKajian),
+		CreatedAt:        course.CreatedAt,
+		Program:          ToProgramResponse(course.Program),
+		Lecturers:        ToUserResponseList(course.Lecturers),
+		LearningOutcomes: ToLearningOutcomeResponseList(course.LearningOutcomes),
 	}
 }
 
@@ -93,11 +107,67 @@ func ToCourseResponseList(courses []models.Course) []dto.CourseResponse {
 
 func ToCourseModel(req *dto.CreateCourseRequest) *models.Course {
 	return &models.Course{
-		ID:        uuid.New(),
-		ProgramID: req.ProgramID,
-		Code:      req.Code,
-		Title:     req.Title,
-		Credits:   req.Credits,
+		ID:              uuid.New(),
+		ProgramID:       req.ProgramID,
+		Code:            req.Code,
+		Title:           req.Title,
+		Credits:         req.Credits,
+		Semester:        req.Semester,
+		CourseType:      req.CourseType,
+		TheoryCredits:   req.TheoryCredits,
+		PracticeCredits: req.PracticeCredits,
+		Description:     req.Description,
+		BahanKajian:     ToBahanKajianJSON(req.BahanKajian),
+	}
+}
+
+// ToBahanKajianJSON stores the study materials of a course; nil stays nil
+func ToBahanKajianJSON(items []string) datatypes.JSON {
+	if items == nil {
+		return nil
+	}
+	data, _ := json.Marshal(items)
+	return datatypes.JSON(data)
+}
+
+func ToBahanKajianList(data datatypes.JSON) []string {
+	var items []string
+	if len(data) > 0 {
+		json.Unmarshal(data, &items)
+	}
+	return items
+}
+
+// LearningOutcome Mapper
+func ToLearningOutcomeResponse(outcome *models.LearningOutcome) *dto.LearningOutcomeResponse {
+	if outcome == nil {
+		return nil
+	}
+	return &dto.LearningOutcomeResponse{
+		ID:          outcome.ID,
+		ProgramID:   outcome.ProgramID,
+		Code:        outcome.Code,
+		Description: outcome.Description,
+		Category:    outcome.Category,
+		CreatedAt:   outcome.CreatedAt,
+	}
+}
+
+func ToLearningOutcomeResponseList(outcomes []models.LearningOutcome) []dto.LearningOutcomeResponse {
+	result := make([]dto.LearningOutcomeResponse, len(outcomes))
+	for i, outcome := range outcomes {
+		result[i] = *ToLearningOutcomeResponse(&outcome)
+	}
+	return result
+}
+
+func ToLearningOutcomeModel(req *dto.CreateLearningOutcomeRequest) *models.LearningOutcome {
+	return &models.LearningOutcome{
+		ID:          uuid.New(),
+		ProgramID:   req.ProgramID,
+		Code:        req.Code,
+		Description: req.Description,
+		Category:    req.Category,
 	}
 }
 
@@ -183,19 +253,34 @@ func ToGeneratedRPSResponse(rps *models.GeneratedRPS) *dto.GeneratedRPSResponse
 		return nil
 	}
 	return &dto.GeneratedRPSResponse{
-		ID:                rps.ID,
-		TemplateVersionID: rps.TemplateVersionID,
-		CourseID:          rps.CourseID,
-		GeneratedBy:       rps.GeneratedBy,
-		Status:            rps.Status,
-		Result:            rps.Result,
-		ExportedFileURL:   rps.ExportedFileURL,
-		AIMetadata:        rps.AIMetadata,
-		CreatedAt:         rps.CreatedAt,
-		UpdatedAt:         rps.UpdatedAt,
-		TemplateVersion:   ToTemplateVersionResponse(rps.TemplateVersion),
-		Course:            ToCourseResponse(rps.Course),
-		Generator:         ToUserResponse(rps.Generator),
+		ID:                 rps.ID,
+		TemplateVersionID:  rps.TemplateVersionID,
+		CourseID:           rps.CourseID,
+		GeneratedBy:        rps.GeneratedBy,
+		BatchID:            rps.BatchID,
+		ExperimentID:       rps.ExperimentID,
+		ExperimentArm:      rps.ExperimentArm,
+		Status:             rps.Status,
+		Result:             rps.Result,
+		ExportedFileURL:    rps.ExportedFileURL,
+		AIMetadata:         rps.AIMetadata,
+		ValidationFindings: rps.ValidationFindings,
+		ValidatedAt:        rps.ValidatedAt,
+		WorkflowStatus:     rps.WorkflowStatus,
+		SubmittedAt:        rps.SubmittedAt,
+		ReviewedAt:         rps.ReviewedAt,
+		ReviewedBy:         rps.ReviewedBy,
+		ApprovedAt:         rps.ApprovedAt,
+		PublishedAt:        rps.PublishedAt,
+		UnresolvedComments: rps.UnresolvedComments,
+		Attempts:           rps.Attempts,
+		StartedAt:          rps.StartedAt,
+		FinishedAt:         rps.FinishedAt,
+		CreatedAt:          rps.CreatedAt,
+		UpdatedAt:          rps.UpdatedAt,
+		TemplateVersion:    ToTemplateVersionResponse(rps.TemplateVersion),
+		Course:             ToCourseResponse(rps.Course),
+		Generator:          ToUserResponse(rps.Generator),
 	}
 }
 
@@ -214,9 +299,140 @@ func ToGeneratedRPSModel(req *dto.CreateGeneratedRPSRequest) *models.GeneratedRP
 		CourseID:          req.CourseID,
 		GeneratedBy:       req.GeneratedBy,
 		Status:            "queued",
+		WorkflowStatus:    models.WorkflowDraft,
 	}
 }
 
+// GenerationBatch Mapper - progress, usage and jobs are filled in by the service
+func ToGenerationBatchResponse(batch *models.GenerationBatch) *dto.GenerationBatchResponse {
+	if batch == nil {
+		return nil
+	}
+	return &dto.GenerationBatchResponse{
+		ID:                batch.ID,
+		ProgramID:         batch.ProgramID,
+		Semester:          batch.Semester,
+		TemplateVersionID: batch.TemplateVersionID,
+		Options:           batch.Options,
+		CreatedBy:         batch.CreatedBy,
+		CancelledAt:       batch.CancelledAt,
+		CreatedAt:         batch.CreatedAt,
+		UpdatedAt:         batch.UpdatedAt,
+		Program:           ToProgramResponse(batch.Program),
+		Creator:           ToUserResponse(batch.Creator),
+	}
+}
+
+// RPSWorkflowTransition Mapper
+func ToRPSWorkflowTransitionResponse(transition *models.RPSWorkflowTransition) *dto.RPSWorkflowTransitionResponse {
+	if transition == nil {
+		return nil
+	}
+	return &dto.RPSWorkflowTransitionResponse{
+		ID:             transition.ID,
+		GeneratedRPSID: transition.GeneratedRPSID,
+		Action:         transition.Action,
+		FromStatus:     transition.FromStatus,
+		ToStatus:       transition.ToStatus,
+		ActorID:        transition.ActorID,
+		Comment:        transition.Comment,
+		CreatedAt:      transition.CreatedAt,
+		Actor:          ToUserResponse(transition.Actor),
+	}
+}
+
+func ToRPSWorkflowTransitionResponseList(transitions []models.RPSWorkflowTransition) []dto.RPSWorkflowTransitionResponse {
+	result := make([]dto.RPSWorkflowTransitionResponse, len(transitions))
+	for i, transition := range transitions {
+		result[i] = *ToRPSWorkflowTransitionResponse(&transition)
+	}
+	return result
+}
+
+// RPSRevision Mapper
+func ToRPSRevisionResponse(revision *models.RPSRevision) *dto.RPSRevisionResponse {
+	if revision == nil {
+		return nil
+	}
+	return &dto.RPSRevisionResponse{
+		ID:             revision.ID,
+		GeneratedRPSID: revision.GeneratedRPSID,
+		Number:         revision.Number,
+		Source:         revision.Source,
+		Result:         revision.Result,
+		Note:           revision.Note,
+		RestoredFrom:   revision.RestoredFrom,
+		AuthorID:       revision.AuthorID,
+		CreatedAt:      revision.CreatedAt,
+		Author:         ToUserResponse(revision.Author),
+	}
+}
+
+func ToRPSRevisionResponseList(revisions []models.RPSRevision) []dto.RPSRevisionResponse {
+	result := make([]dto.RPSRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		result[i] = *ToRPSRevisionResponse(&revision)
+	}
+	return result
+}
+
+// ToRPSRevisionChanges tags each JSON change with the top-level RPS section it belongs to
+func ToRPSRevisionChanges(changes []JSONChange) []dto.RPSRevisionChange {
+	result := make([]dto.RPSRevisionChange, len(changes))
+	for i, change := range changes {
+		section := change.Path
+		if end := strings.IndexAny(section, ".["); end >= 0 {
+			section = section[:end]
+		}
+		result[i] = dto.RPSRevisionChange{
+			Section: section,
+			Path:    change.Path,
+			Type:    change.Type,
+			From:    change.From,
+			To:      change.To,
+		}
+	}
+	return result
+}
+
+// RPSComment Mapper
+func ToRPSCommentResponse(comment *models.RPSComment) *dto.RPSCommentResponse {
+	if comment == nil {
+		return nil
+	}
+
+	response := &dto.RPSCommentResponse{
+		ID:             comment.ID,
+		GeneratedRPSID: comment.GeneratedRPSID,
+		ParentID:       comment.ParentID,
+		AnchorPath:     comment.AnchorPath,
+		AnchorValue:    comment.AnchorValue,
+		Body:           comment.Body,
+		AuthorID:       comment.AuthorID,
+		Resolved:       comment.Resolved,
+		ResolvedBy:     comment.ResolvedBy,
+		ResolvedAt:     comment.ResolvedAt,
+		CreatedAt:      comment.CreatedAt,
+		UpdatedAt:      comment.UpdatedAt,
+		Author:         ToUserResponse(comment.Author),
+	}
+	if len(comment.Mentions) > 0 {
+		response.Mentions = ToUserResponseList(comment.Mentions)
+	}
+	if len(comment.Replies) > 0 {
+		response.Replies = ToRPSCommentResponseList(comment.Replies)
+	}
+	return response
+}
+
+func ToRPSCommentResponseList(comments []models.RPSComment) []dto.RPSCommentResponse {
+	result := make([]dto.RPSCommentResponse, len(comments))
+	for i, comment := range comments {
+		result[i] = *ToRPSCommentResponse(&comment)
+	}
+	return result
+}
+
 // AuditLog Mapper
 func ToAuditLogResponse(log *models.AuditLog) *dto.AuditLogResponse {
 	if log == nil {
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/syrlramadhan/dokumentasi-rps-api/config"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
 	"github.com/syrlramadhan/dokumentasi-rps-api/models"
 	"github.com/syrlramadhan/dokumentasi-rps-api/routes"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -32,10 +39,19 @@ func main() {
 		&models.User{},
 		&models.Program{},
 		&models.Course{},
+		&models.LearningOutcome{},
+		&models.CoursePrerequisite{},
 		&models.Template{},
 		&models.TemplateVersion{},
+		&models.GenerationBatch{},
+		&models.AIBudget{},
 		&models.GeneratedRPS{},
+		&models.RPSWorkflowTransition{},
+		&models.RPSRevision{},
+		&models.RPSComment{},
 		&models.AuditLog{},
+		&models.RefreshToken{},
+		&models.RevokedAccessToken{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
@@ -43,11 +59,19 @@ func main() {
 
 	log.Println("Database migration completed successfully")
 
+	// Seed initial admin so the protected API is reachable on a fresh database
+	if err := seedAdminUser(db); err != nil {
+		log.Fatalf("Failed to seed admin user: %v", err)
+	}
+
 	// Setup Gin router
 	r := gin.Default()
 
 	// Setup routes with both PostgreSQL and MongoDB
-	routes.SetupRoutes(r, db, mongoDB)
+	generationWorker := routes.SetupRoutes(r, db, mongoDB)
+
+	// Start generation workers (recovers jobs orphaned by a previous crash)
+	generationWorker.Start()
 
 	// Get port from environment variable
 	port := os.Getenv("APP_PORT")
@@ -55,9 +79,68 @@ func main() {
 		port = "8080"
 	}
 
+	srv := &http.Server{
+		Addr:    ":" + port,
+		Handler: r,
+	}
+
 	// Start server
-	log.Printf("Server starting on port %s...", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	go func() {
+		log.Printf("Server starting on port %s...", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for interrupt signal, then stop accepting requests and drain running generations
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.GetWorkerConfig().ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+	if err := generationWorker.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Generation workers did not drain in time: %v", err)
 	}
+
+	log.Println("Server exited")
+}
+
+// seedAdminUser creates the ADMIN_USERNAME/ADMIN_PASSWORD account when no admin exists yet
+func seedAdminUser(db *gorm.DB) error {
+	username := os.Getenv("ADMIN_USERNAME")
+	password := os.Getenv("ADMIN_PASSWORD")
+	if username == "" || password == "" {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(&models.User{}).Where("role = ?", "admin").Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	passwordHash, err := helper.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	admin := &models.User{
+		Username:     username,
+		Role:         "admin",
+		PasswordHash: passwordHash,
+	}
+	if err := db.Create(admin).Error; err != nil {
+		return err
+	}
+
+	log.Printf("Seeded initial admin user %q", username)
+	return nil
 }
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+// SnapshotFunc loads the current state of an audited resource
+type SnapshotFunc func(id uuid.UUID) (interface{}, error)
+
+// bodyCaptureWriter keeps a copy of the response body for the audit entry
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Audit records every successful POST/PUT/PATCH/DELETE on a route group as an
+// AuditLog with the actor, action and a before/after diff of the target.
+// It must run after AuthRequired.
+func Audit(auditLogService services.AuditLogService, targetType string, snapshot SnapshotFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if readMethods[c.Request.Method] {
+			c.Next()
+			return
+		}
+
+		targetID, hasID := parseParamID(c, "id")
+
+		var before interface{}
+		if hasID && snapshot != nil {
+			if state, err := snapshot(targetID); err == nil {
+				before = state
+			}
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		if writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		responseData := extractResponseData(writer.body.Bytes())
+
+		var after interface{}
+		switch {
+		case c.Request.Method == http.MethodDelete && !isSubresource(c):
+			after = nil
+		case hasID && snapshot != nil:
+			if state, err := snapshot(targetID); err == nil {
+				after = state
+			}
+		default:
+			after = responseData
+		}
+
+		// Creates have no :id, take it from the created resource
+		if !hasID {
+			if data, ok := responseData.(map[string]interface{}); ok {
+				if idStr, ok := data["id"].(string); ok {
+					if id, err := uuid.Parse(idStr); err == nil {
+						targetID, hasID = id, true
+					}
+				}
+			}
+		}
+
+		payload := map[string]interface{}{
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+			"before": before,
+			"after":  after,
+		}
+		if changes, err := helper.DiffJSON(before, after); err == nil {
+			payload["changes"] = changes
+		}
+		if isSubresource(c) && responseData != nil {
+			payload["result"] = responseData
+		}
+
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			log.Printf("Warning: failed to marshal audit payload: %v", err)
+			return
+		}
+
+		req := &dto.CreateAuditLogRequest{
+			Action:     auditAction(c, targetType),
+			TargetType: &targetType,
+			Payload:    payloadJSON,
+		}
+		if hasID {
+			req.TargetID = &targetID
+		}
+		if user, ok := CurrentUser(c); ok {
+			req.UserID = &user.ID
+		}
+
+		if _, err := auditLogService.Create(req); err != nil {
+			log.Printf("Warning: failed to write audit log for %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+	}
+}
+
+// auditAction builds names like "course.update" or "generated_rps.status.update"
+func auditAction(c *gin.Context, targetType string) string {
+	verb := "update"
+	switch c.Request.Method {
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodDelete:
+		verb = "delete"
+	}
+
+	if sub := subresourceName(c); sub != "" {
+		return targetType + "." + sub + "." + verb
+	}
+	return targetType + "." + verb
+}
+
+// subresourceName returns the first static path segment after ":id", if any
+func subresourceName(c *gin.Context) string {
+	_, rest, found := strings.Cut(c.FullPath(), "/:id/")
+	if !found {
+		return ""
+	}
+	segment, _, _ := strings.Cut(rest, "/")
+	if strings.HasPrefix(segment, ":") {
+		return ""
+	}
+	return segment
+}
+
+func isSubresource(c *gin.Context) bool {
+	return subresourceName(c) != ""
+}
+
+func parseParamID(c *gin.Context, param string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func extractResponseData(body []byte) interface{} {
+	var response struct {
+		Data interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil
+	}
+	return response.Data
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"github.com/syrlramadhan/dokumentasi-rps-api/services"
+)
+
+const (
+	currentUserKey = "currentUser"
+	tokenClaimsKey = "tokenClaims"
+)
+
+// AuthRequired validates the Bearer access token and stores the authenticated
+// models.User in the request context
+func AuthRequired(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		tokenString, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || strings.TrimSpace(tokenString) == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse("Missing or invalid Authorization header", "UNAUTHORIZED", nil))
+			return
+		}
+
+		user, claims, err := authService.ValidateAccessToken(strings.TrimSpace(tokenString))
+		if err != nil {
+			if errors.Is(err, helper.ErrUnauthorized) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse("Invalid or expired token", "UNAUTHORIZED", nil))
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to validate token", "INTERNAL_ERROR", nil))
+			return
+		}
+
+		c.Set(currentUserKey, user)
+		c.Set(tokenClaimsKey, claims)
+		c.Next()
+	}
+}
+
+// CurrentUser returns the authenticated user set by AuthRequired
+func CurrentUser(c *gin.Context) (*models.User, bool) {
+	value, exists := c.Get(currentUserKey)
+	if !exists {
+		return nil, false
+	}
+	user, ok := value.(*models.User)
+	return user, ok
+}
+
+// TokenClaims returns the access token claims set by AuthRequired
+func TokenClaims(c *gin.Context) (*services.AccessTokenClaims, bool) {
+	value, exists := c.Get(tokenClaimsKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*services.AccessTokenClaims)
+	return claims, ok
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+)
+
+// Role sets used by route policies
+var (
+	AllRoles   = []string{models.RoleAdmin, models.RoleKaprodi, models.RoleDosen, models.RoleViewer}
+	AdminOnly  = []string{models.RoleAdmin}
+	Managers   = []string{models.RoleAdmin, models.RoleKaprodi}
+	RPSAuthors = []string{models.RoleAdmin, models.RoleKaprodi, models.RoleDosen}
+)
+
+var readMethods = map[string]bool{http.MethodGet: true, http.MethodHead: true, http.MethodOptions: true}
+
+// Policy declares which roles may read (GET/HEAD) and write (any other method)
+// on a route group
+type Policy struct {
+	Read  []string
+	Write []string
+}
+
+// ScopeCheck verifies that user may act on the resource identified by id.
+// It returns nil, helper.ErrForbidden or helper.ErrNotFound.
+type ScopeCheck func(user *models.User, id uuid.UUID) error
+
+// Authorize enforces a Policy; it must run after AuthRequired
+func Authorize(policy Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse("Not authenticated", "UNAUTHORIZED", nil))
+			return
+		}
+
+		allowed := policy.Write
+		if readMethods[c.Request.Method] {
+			allowed = policy.Read
+		}
+
+		if !hasRole(user.Role, allowed) {
+			abortForbidden(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRoles restricts a single route to the given roles
+func RequireRoles(roles ...string) gin.HandlerFunc {
+	return Authorize(Policy{Read: roles, Write: roles})
+}
+
+// ScopeParam runs check against the UUID in the named path parameter.
+// Malformed IDs and missing resources are left to the handler to report.
+func ScopeParam(param string, check ScopeCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			c.Next()
+			return
+		}
+		enforceScope(c, check, id)
+	}
+}
+
+// ScopeBody runs check against the UUID in the named top-level JSON body field.
+// A missing field is checked as uuid.Nil, which only admins pass.
+func ScopeBody(field string, check ScopeCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		enforceScope(c, check, bodyUUID(c, field))
+	}
+}
+
+// ScopeBodyIfSet runs check against the UUID in the named JSON body field only
+// when the request sets it, e.g. the target program of an update. A value that
+// is not a UUID is checked as uuid.Nil.
+func ScopeBodyIfSet(field string, check ScopeCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, set := bodyField(c, field)
+		if !set {
+			c.Next()
+			return
+		}
+		enforceScope(c, check, id)
+	}
+}
+
+func enforceScope(c *gin.Context, check ScopeCheck, id uuid.UUID) {
+	user, ok := CurrentUser(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse("Not authenticated", "UNAUTHORIZED", nil))
+		return
+	}
+
+	if err := check(user, id); err != nil {
+		if errors.Is(err, helper.ErrForbidden) {
+			abortForbidden(c)
+			return
+		}
+		if !helper.IsNotFoundError(err) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to check permissions", "INTERNAL_ERROR", nil))
+			return
+		}
+	}
+	c.Next()
+}
+
+// bodyUUID reads a field from the JSON body and restores the body for the handler
+func bodyUUID(c *gin.Context, field string) uuid.UUID {
+	id, _ := bodyField(c, field)
+	return id
+}
+
+// bodyField is bodyUUID that also reports whether the field is set to a non-null value
+func bodyField(c *gin.Context, field string) (uuid.UUID, bool) {
+	if c.Request.Body == nil {
+		return uuid.Nil, false
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return uuid.Nil, false
+	}
+
+	raw, set := body[field]
+	if !set || raw == nil {
+		return uuid.Nil, false
+	}
+	value, _ := raw.(string)
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, true
+	}
+	return id, true
+}
+
+func hasRole(role string, allowed []string) bool {
+	for _, r := range allowed {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func abortForbidden(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse("You do not have permission to perform this action", "FORBIDDEN", map[string]string{"error": helper.ErrForbidden.Error()}))
+}
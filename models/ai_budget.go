@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AIBudget caps the AI spend of a program or a user per calendar month (UTC).
+// Exactly one of ProgramID and UserID is set.
+type AIBudget struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ProgramID    *uuid.UUID `json:"program_id" gorm:"type:uuid;uniqueIndex"`
+	UserID       *uuid.UUID `json:"user_id" gorm:"type:uuid;uniqueIndex"`
+	MonthlyLimit float64    `json:"monthly_limit" gorm:"not null"` // USD; 0 blocks generation
+	UpdatedBy    *uuid.UUID `json:"updated_by" gorm:"type:uuid"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"default:now()"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"default:now()"`
+
+	// Relations
+	Program *Program `json:"program,omitempty" gorm:"foreignKey:ProgramID"`
+	User    *User    `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken stores a hashed, single-use refresh token. Each refresh rotates
+// the token: the old row is revoked and points to its replacement.
+type RefreshToken struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	TokenHash  string     `json:"-" gorm:"type:text;uniqueIndex;not null"` // sha256 of the raw token
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	ReplacedBy *uuid.UUID `json:"replaced_by" gorm:"type:uuid"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"default:now()"`
+
+	// Relations
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// RevokedAccessToken blacklists an access token (by its jti) until it expires
+type RevokedAccessToken struct {
+	JTI       string    `json:"jti" gorm:"type:text;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"default:now()"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PrerequisiteTypePrerequisite = "prerequisite" // must be passed before the course
+	PrerequisiteTypeCorequisite  = "corequisite"  // taken before or together with the course
+)
+
+// CoursePrerequisite is an edge of the course prerequisite graph: Course requires Prerequisite
+type CoursePrerequisite struct {
+	CourseID       uuid.UUID `json:"course_id" gorm:"type:uuid;primaryKey"`
+	PrerequisiteID uuid.UUID `json:"prerequisite_id" gorm:"type:uuid;primaryKey;index"`
+	Type           string    `json:"type" gorm:"type:text;not null;default:prerequisite"`
+	CreatedAt      time.Time `json:"created_at" gorm:"default:now()"`
+
+	// Relations
+	Course       *Course `json:"course,omitempty" gorm:"foreignKey:CourseID"`
+	Prerequisite *Course `json:"prerequisite,omitempty" gorm:"foreignKey:PrerequisiteID"`
+}
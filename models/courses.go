@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 )
 
 type Course struct {
@@ -14,6 +15,16 @@ type Course struct {
 	Credits   *int       `json:"credits" gorm:"type:int"`
 	CreatedAt time.Time  `json:"created_at" gorm:"default:now()"`
 
+	// Catalog
+	Semester        *int           `json:"semester" gorm:"type:int"`         // recommended semester
+	CourseType      *string        `json:"course_type" gorm:"type:text"`     // 'wajib'|'pilihan'
+	TheoryCredits   *int           `json:"theory_credits" gorm:"type:int"`   // SKS teori
+	PracticeCredits *int           `json:"practice_credits" gorm:"type:int"` // SKS praktikum
+	Description     *string        `json:"description" gorm:"type:text"`     // official course description
+	BahanKajian     datatypes.JSON `json:"bahan_kajian" gorm:"type:jsonb"`   // []string
+
 	// Relations
-	Program *Program `json:"program,omitempty" gorm:"foreignKey:ProgramID"`
+	Program          *Program          `json:"program,omitempty" gorm:"foreignKey:ProgramID"`
+	Lecturers        []User            `json:"lecturers,omitempty" gorm:"many2many:course_lecturers"`                 // dosen assigned to the course
+	LearningOutcomes []LearningOutcome `json:"learning_outcomes,omitempty" gorm:"many2many:course_learning_outcomes"` // CPL of the program the course supports
 }
@@ -8,16 +8,43 @@ import (
 )
 
 type GeneratedRPS struct {
-	ID                uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	TemplateVersionID *uuid.UUID     `json:"template_version_id" gorm:"type:uuid"`
-	CourseID          *uuid.UUID     `json:"course_id" gorm:"type:uuid"`
-	GeneratedBy       *uuid.UUID     `json:"generated_by" gorm:"type:uuid"`      // user yang memicu
-	Status            string         `json:"status" gorm:"type:text;not null"`   // queued|processing|done|failed
-	Result            datatypes.JSON `json:"result" gorm:"type:jsonb"`           // final RPS structured
-	ExportedFileURL   *string        `json:"exported_file_url" gorm:"type:text"` // S3 link jika ada
-	AIMetadata        datatypes.JSON `json:"ai_metadata" gorm:"type:jsonb"`      // ringkasan: model name, temperature, tokens, prompt_id (Mongo)
-	CreatedAt         time.Time      `json:"created_at" gorm:"default:now()"`
-	UpdatedAt         time.Time      `json:"updated_at" gorm:"default:now()"`
+	ID                 uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TemplateVersionID  *uuid.UUID     `json:"template_version_id" gorm:"type:uuid"`
+	CourseID           *uuid.UUID     `json:"course_id" gorm:"type:uuid"`
+	GeneratedBy        *uuid.UUID     `json:"generated_by" gorm:"type:uuid"`          // user yang memicu
+	Status             string         `json:"status" gorm:"type:text;not null;index"` // queued|processing|done|failed|cancelled
+	Result             datatypes.JSON `json:"result" gorm:"type:jsonb"`               // final RPS structured
+	ExportedFileURL    *string        `json:"exported_file_url" gorm:"type:text"`     // S3 link jika ada
+	AIMetadata         datatypes.JSON `json:"ai_metadata" gorm:"type:jsonb"`          // ringkasan: model name, temperature, tokens, prompt_id (Mongo)
+	JobOptions         datatypes.JSON `json:"job_options" gorm:"type:jsonb"`          // GenerateRPSOptions yang diminta, dipakai worker
+	ValidationFindings datatypes.JSON `json:"validation_findings" gorm:"type:jsonb"`  // temuan validator: severity, rule, path, message
+	ValidatedAt        *time.Time     `json:"validated_at"`                           // terakhir divalidasi
+	Attempts           int            `json:"attempts" gorm:"not null;default:0"`     // berapa kali job di-lease worker
+	LockedBy           *string        `json:"locked_by" gorm:"type:text"`             // worker pemegang lease
+	LockedUntil        *time.Time     `json:"locked_until"`                           // lease habis -> job dianggap yatim
+	HeartbeatAt        *time.Time     `json:"heartbeat_at"`                           // heartbeat terakhir dari worker
+	StartedAt          *time.Time     `json:"started_at"`                             // pertama kali diproses
+	FinishedAt         *time.Time     `json:"finished_at"`                            // selesai (done/failed)
+	CreatedAt          time.Time      `json:"created_at" gorm:"default:now();index"`
+	UpdatedAt          time.Time      `json:"updated_at" gorm:"default:now()"`
+
+	// Document workflow, see RPSWorkflowTransition for the history
+	WorkflowStatus string     `json:"workflow_status" gorm:"type:text;not null;default:draft;index"` // draft|in_review|changes_requested|approved|published
+	SubmittedAt    *time.Time `json:"submitted_at"`                                                  // terakhir diajukan ke kaprodi
+	ReviewedAt     *time.Time `json:"reviewed_at"`                                                   // keputusan review terakhir
+	ReviewedBy     *uuid.UUID `json:"reviewed_by" gorm:"type:uuid"`                                  // kaprodi/admin yang memutuskan
+	ApprovedAt     *time.Time `json:"approved_at"`
+	PublishedAt    *time.Time `json:"published_at"`
+
+	// Set when the job was queued as part of a GenerationBatch
+	BatchID *uuid.UUID `json:"batch_id" gorm:"type:uuid;index"`
+
+	// Set when the job ran in an experiment (Mongo experiments collection)
+	ExperimentID  *string `json:"experiment_id" gorm:"type:text;index"`
+	ExperimentArm *string `json:"experiment_arm" gorm:"type:text"`
+
+	// Computed by the repository on read, never stored
+	UnresolvedComments int `json:"unresolved_comments" gorm:"->;-:migration"`
 
 	// Relations
 	TemplateVersion *TemplateVersion `json:"template_version,omitempty" gorm:"foreignKey:TemplateVersionID"`
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Batch status, derived from the jobs of the batch
+const (
+	BatchStatusRunning               = "running"
+	BatchStatusCompleted             = "completed"
+	BatchStatusCompletedWithFailures = "completed_with_failures"
+	BatchStatusCancelled             = "cancelled"
+)
+
+// GenerationBatch groups the GeneratedRPS jobs queued together for a program,
+// a semester of it or a list of courses. Progress is aggregated from the jobs.
+type GenerationBatch struct {
+	ID                uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ProgramID         *uuid.UUID     `json:"program_id" gorm:"type:uuid;index"` // nil bila batch dari daftar mata kuliah
+	Semester          *int           `json:"semester"`                          // filter semester saat batch dibuat
+	TemplateVersionID uuid.UUID      `json:"template_version_id" gorm:"type:uuid;not null"`
+	Options           datatypes.JSON `json:"options" gorm:"type:jsonb"` // GenerateRPSOptions bersama untuk semua job
+	CreatedBy         *uuid.UUID     `json:"created_by" gorm:"type:uuid"`
+	CancelledAt       *time.Time     `json:"cancelled_at"`
+	CreatedAt         time.Time      `json:"created_at" gorm:"default:now();index"`
+	UpdatedAt         time.Time      `json:"updated_at" gorm:"default:now()"`
+
+	// Relations
+	Program         *Program         `json:"program,omitempty" gorm:"foreignKey:ProgramID"`
+	TemplateVersion *TemplateVersion `json:"template_version,omitempty" gorm:"foreignKey:TemplateVersionID"`
+	Creator         *User            `json:"creator,omitempty" gorm:"foreignKey:CreatedBy"`
+	Jobs            []GeneratedRPS   `json:"jobs,omitempty" gorm:"foreignKey:BatchID"`
+}
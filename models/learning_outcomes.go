@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LearningOutcome is a CPL (Capaian Pembelajaran Lulusan) of a program
+type LearningOutcome struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ProgramID   uuid.UUID `json:"program_id" gorm:"type:uuid;not null;uniqueIndex:idx_learning_outcome_code"`
+	Code        string    `json:"code" gorm:"type:text;not null;uniqueIndex:idx_learning_outcome_code"` // mis. CPL-01
+	Description string    `json:"description" gorm:"type:text;not null"`
+	Category    *string   `json:"category" gorm:"type:text"` // sikap|pengetahuan|keterampilan_umum|keterampilan_khusus
+	CreatedAt   time.Time `json:"created_at" gorm:"default:now()"`
+
+	// Relations
+	Program *Program `json:"program,omitempty" gorm:"foreignKey:ProgramID"`
+}
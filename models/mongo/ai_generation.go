@@ -15,23 +15,30 @@ type AIGeneration struct {
 	CourseCode        string             `bson:"course_code" json:"course_code"`
 	TemplateVersionID string             `bson:"template_version_id" json:"template_version_id"`
 
-	// Generation attempts (bisa ada retry)
+	// Experiment arm the generation was assigned to, if any
+	ExperimentID  string `bson:"experiment_id,omitempty" json:"experiment_id,omitempty"`
+	ExperimentArm string `bson:"experiment_arm,omitempty" json:"experiment_arm,omitempty"`
+
+	// Generation attempts (bisa ada retry) of every run; a job runs again when it is
+	// requeued, retried or regenerated
 	Attempts      []GenerationAttempt `bson:"attempts" json:"attempts"`
 	TotalAttempts int                 `bson:"total_attempts" json:"total_attempts"`
+	Runs          int                 `bson:"runs" json:"runs"`
 
-	// Final result
-	FinalStatus string                 `bson:"final_status" json:"final_status"` // success, failed
+	// Final result of the latest run
+	FinalStatus string                 `bson:"final_status" json:"final_status"` // processing, success, failed, cancelled
 	FinalResult map[string]interface{} `bson:"final_result,omitempty" json:"final_result,omitempty"`
 
 	// Aggregated stats
 	TotalTokensUsed int64   `bson:"total_tokens_used" json:"total_tokens_used"`
 	TotalDurationMs int64   `bson:"total_duration_ms" json:"total_duration_ms"`
-	TotalCost       float64 `bson:"total_cost" json:"total_cost"` // estimated cost in USD
+	TotalCost       float64 `bson:"total_cost" json:"total_cost"` // USD, every attempt of every run included
 
-	// User info
+	// Who the generation is charged to
+	ProgramID   string `bson:"program_id,omitempty" json:"program_id,omitempty"`
 	RequestedBy string `bson:"requested_by,omitempty" json:"requested_by,omitempty"`
 
-	// Timestamps
+	// Timestamps; StartedAt and CompletedAt are those of the latest run
 	StartedAt   time.Time `bson:"started_at" json:"started_at"`
 	CompletedAt time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
 	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
@@ -39,11 +46,15 @@ type AIGeneration struct {
 
 // GenerationAttempt represents a single attempt in the generation process
 type GenerationAttempt struct {
-	AttemptNumber int                `bson:"attempt_number" json:"attempt_number"`
+	AttemptNumber int                `bson:"attempt_number" json:"attempt_number"` // within its run
+	Run           int                `bson:"run,omitempty" json:"run,omitempty"`
 	PromptID      primitive.ObjectID `bson:"prompt_id" json:"prompt_id"`
 	Status        string             `bson:"status" json:"status"`
 	TokensUsed    int                `bson:"tokens_used" json:"tokens_used"`
 	DurationMs    int64              `bson:"duration_ms" json:"duration_ms"`
+	Cost          float64            `bson:"cost" json:"cost"` // estimated cost in USD
+	StatusCode    int                `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	Retryable     bool               `bson:"retryable" json:"retryable"`
 	ErrorMessage  string             `bson:"error_message,omitempty" json:"error_message,omitempty"`
 	Timestamp     time.Time          `bson:"timestamp" json:"timestamp"`
 }
@@ -13,6 +13,23 @@ type AIPrompt struct {
 	CourseID       string             `bson:"course_id" json:"course_id"`
 	TemplateID     string             `bson:"template_id" json:"template_id"`
 
+	// Who the call is charged to; empty for courses outside a program or jobs without a requester
+	ProgramID   string `bson:"program_id,omitempty" json:"program_id,omitempty"`
+	RequestedBy string `bson:"requested_by,omitempty" json:"requested_by,omitempty"`
+
+	// Prompt template the prompts were rendered from; empty for the built-in prompts
+	PromptTemplateID      string `bson:"prompt_template_id,omitempty" json:"prompt_template_id,omitempty"`
+	PromptTemplateVersion int    `bson:"prompt_template_version,omitempty" json:"prompt_template_version,omitempty"`
+
+	// Experiment arm the generation was assigned to, if any
+	ExperimentID  string `bson:"experiment_id,omitempty" json:"experiment_id,omitempty"`
+	ExperimentArm string `bson:"experiment_arm,omitempty" json:"experiment_arm,omitempty"`
+
+	// Section regeneration; empty for full RPS generations
+	Section  string `bson:"section,omitempty" json:"section,omitempty"`
+	WeekFrom int    `bson:"week_from,omitempty" json:"week_from,omitempty"`
+	WeekTo   int    `bson:"week_to,omitempty" json:"week_to,omitempty"`
+
 	// Prompt details
 	SystemPrompt string `bson:"system_prompt" json:"system_prompt"`
 	UserPrompt   string `bson:"user_prompt" json:"user_prompt"`
@@ -23,24 +40,29 @@ type AIPrompt struct {
 	ParsedResponse map[string]interface{} `bson:"parsed_response" json:"parsed_response"`
 
 	// Model configuration
+	Provider         string  `bson:"provider" json:"provider"`
 	Model            string  `bson:"model" json:"model"`
 	Temperature      float64 `bson:"temperature" json:"temperature"`
 	MaxTokens        int     `bson:"max_tokens" json:"max_tokens"`
 	TopP             float64 `bson:"top_p,omitempty" json:"top_p,omitempty"`
+	TopK             int     `bson:"top_k,omitempty" json:"top_k,omitempty"`
 	FrequencyPenalty float64 `bson:"frequency_penalty,omitempty" json:"frequency_penalty,omitempty"`
 	PresencePenalty  float64 `bson:"presence_penalty,omitempty" json:"presence_penalty,omitempty"`
 
 	// Usage statistics
-	PromptTokens     int `bson:"prompt_tokens" json:"prompt_tokens"`
-	CompletionTokens int `bson:"completion_tokens" json:"completion_tokens"`
-	TotalTokens      int `bson:"total_tokens" json:"total_tokens"`
+	PromptTokens     int     `bson:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int     `bson:"completion_tokens" json:"completion_tokens"`
+	TotalTokens      int     `bson:"total_tokens" json:"total_tokens"`
+	AttemptNumber    int     `bson:"attempt_number" json:"attempt_number"`
+	EstimatedCost    float64 `bson:"estimated_cost" json:"estimated_cost"` // USD
 
 	// Timing
 	RequestDurationMs int64 `bson:"request_duration_ms" json:"request_duration_ms"`
 
 	// Status
-	Status       string `bson:"status" json:"status"` // success, failed, timeout
+	Status       string `bson:"status" json:"status"` // success, failed, timeout, cancelled
 	ErrorMessage string `bson:"error_message,omitempty" json:"error_message,omitempty"`
+	ErrorClass   string `bson:"error_class,omitempty" json:"error_class,omitempty"` // e.g. rate_limited, server_error, invalid_output
 
 	// Metadata
 	RequestID      string `bson:"request_id,omitempty" json:"request_id,omitempty"`
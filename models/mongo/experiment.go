@@ -0,0 +1,46 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Experiment splits RPS generation traffic between arms to compare prompt
+// template versions and model settings
+type Experiment struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description" json:"description"`
+	Status      string             `bson:"status" json:"status"` // draft, running, stopped
+
+	Arms []ExperimentArm `bson:"arms" json:"arms"`
+
+	// Audit
+	CreatedBy string     `bson:"created_by" json:"created_by"`
+	StartedAt *time.Time `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	StoppedAt *time.Time `bson:"stopped_at,omitempty" json:"stopped_at,omitempty"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// ExperimentArm is one variant of an experiment. Generations are assigned to
+// arms by job, in proportion to the weights.
+type ExperimentArm struct {
+	Name   string `bson:"name" json:"name"`
+	Weight int    `bson:"weight" json:"weight"`
+
+	// Prompt template version, copied when the arm is defined so later edits of
+	// the template do not change the arm; empty uses the active template
+	PromptTemplateID      string           `bson:"prompt_template_id,omitempty" json:"prompt_template_id,omitempty"`
+	PromptTemplateName    string           `bson:"prompt_template_name,omitempty" json:"prompt_template_name,omitempty"`
+	PromptTemplateVersion int              `bson:"prompt_template_version,omitempty" json:"prompt_template_version,omitempty"`
+	SystemPrompt          string           `bson:"system_prompt,omitempty" json:"system_prompt,omitempty"`
+	UserPromptTemplate    string           `bson:"user_prompt_template,omitempty" json:"user_prompt_template,omitempty"`
+	Variables             []PromptVariable `bson:"variables,omitempty" json:"variables,omitempty"`
+
+	// Model settings; unset values keep the template or provider defaults
+	Model       string   `bson:"model,omitempty" json:"model,omitempty"`
+	Temperature *float64 `bson:"temperature,omitempty" json:"temperature,omitempty"`
+	MaxTokens   int      `bson:"max_tokens,omitempty" json:"max_tokens,omitempty"`
+}
@@ -35,7 +35,7 @@ type PromptTemplate struct {
 
 	// Usage stats
 	UsageCount  int     `bson:"usage_count" json:"usage_count"`
-	SuccessRate float64 `bson:"success_rate" json:"success_rate"`
+	SuccessRate float64 `bson:"success_rate" json:"success_rate"` // share of finished generations that succeeded, 0..1
 	AvgTokens   int     `bson:"avg_tokens" json:"avg_tokens"`
 
 	// Audit
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// RPSComment is a review comment anchored to a JSON path of a GeneratedRPS result.
+// Replies share the anchor of their root comment; only root comments are resolved.
+type RPSComment struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	GeneratedRPSID uuid.UUID      `json:"generated_rps_id" gorm:"type:uuid;not null;index"`
+	ParentID       *uuid.UUID     `json:"parent_id" gorm:"type:uuid;index"`      // nil untuk komentar utama
+	AnchorPath     string         `json:"anchor_path" gorm:"type:text;not null"` // mis. rencana_mingguan[4].metode_pembelajaran
+	AnchorValue    datatypes.JSON `json:"anchor_value" gorm:"type:jsonb"`        // nilai pada path saat komentar dibuat
+	Body           string         `json:"body" gorm:"type:text;not null"`
+	AuthorID       *uuid.UUID     `json:"author_id" gorm:"type:uuid"`
+	Resolved       bool           `json:"resolved" gorm:"not null;default:false"`
+	ResolvedBy     *uuid.UUID     `json:"resolved_by" gorm:"type:uuid"`
+	ResolvedAt     *time.Time     `json:"resolved_at"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"default:now()"`
+
+	// Relations
+	Author   *User        `json:"author,omitempty" gorm:"foreignKey:AuthorID"`
+	Mentions []User       `json:"mentions,omitempty" gorm:"many2many:rps_comment_mentions"`
+	Replies  []RPSComment `json:"replies,omitempty" gorm:"foreignKey:ParentID"`
+}
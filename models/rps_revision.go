@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Revision sources
+const (
+	RevisionSourceBaseline     = "baseline"     // isi sebelum riwayat revisi ada
+	RevisionSourceGeneration   = "generation"   // hasil generate AI
+	RevisionSourceRegeneration = "regeneration" // generate ulang satu bagian
+	RevisionSourceManual       = "manual"       // edit lewat PUT /generated/:id
+	RevisionSourceSystem       = "system"       // ditulis worker internal
+	RevisionSourceRestore      = "restore"      // pemulihan revisi lama
+)
+
+// RPSRevision is an immutable snapshot of a GeneratedRPS result
+type RPSRevision struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	GeneratedRPSID uuid.UUID      `json:"generated_rps_id" gorm:"type:uuid;not null;uniqueIndex:idx_rps_revision_number"`
+	Number         int            `json:"number" gorm:"not null;uniqueIndex:idx_rps_revision_number"` // 1, 2, 3... per generated RPS
+	Source         string         `json:"source" gorm:"type:text;not null"`
+	Result         datatypes.JSON `json:"result" gorm:"type:jsonb"`
+	Note           *string        `json:"note" gorm:"type:text"` // catatan perubahan
+	RestoredFrom   *int           `json:"restored_from"`         // nomor revisi yang dipulihkan
+	AuthorID       *uuid.UUID     `json:"author_id" gorm:"type:uuid"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"default:now()"`
+
+	// Relations
+	Author *User `json:"author,omitempty" gorm:"foreignKey:AuthorID"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Document workflow states of a generated RPS, independent of the AI job Status
+const (
+	WorkflowDraft            = "draft"
+	WorkflowInReview         = "in_review"
+	WorkflowChangesRequested = "changes_requested"
+	WorkflowApproved         = "approved"
+	WorkflowPublished        = "published"
+)
+
+// ContentLockedStatuses are the workflow states in which the RPS content may not change
+var ContentLockedStatuses = []string{WorkflowInReview, WorkflowApproved, WorkflowPublished}
+
+// RPSWorkflowTransition records one move of a generated RPS through the workflow
+type RPSWorkflowTransition struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	GeneratedRPSID uuid.UUID  `json:"generated_rps_id" gorm:"type:uuid;not null;index"`
+	Action         string     `json:"action" gorm:"type:text;not null"` // submit|request_changes|approve|publish|reopen
+	FromStatus     string     `json:"from_status" gorm:"type:text;not null"`
+	ToStatus       string     `json:"to_status" gorm:"type:text;not null"`
+	ActorID        *uuid.UUID `json:"actor_id" gorm:"type:uuid"`
+	Comment        *string    `json:"comment" gorm:"type:text"` // catatan reviewer/dosen
+	CreatedAt      time.Time  `json:"created_at" gorm:"default:now();index"`
+
+	// Relations
+	Actor *User `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+}
@@ -6,11 +6,24 @@ import (
 	"github.com/google/uuid"
 )
 
+// User roles
+const (
+	RoleAdmin   = "admin"
+	RoleKaprodi = "kaprodi"
+	RoleDosen   = "dosen"
+	RoleViewer  = "viewer"
+)
+
 type User struct {
-	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	Username    string    `json:"username" gorm:"type:text;unique;not null"`
-	Email       *string   `json:"email" gorm:"type:text;unique"`
-	DisplayName *string   `json:"display_name" gorm:"type:text"`
-	Role        string    `json:"role" gorm:"type:text;not null"` // 'admin'|'kaprodi'|'dosen'|'viewer'
-	CreatedAt   time.Time `json:"created_at" gorm:"default:now()"`
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Username     string     `json:"username" gorm:"type:text;unique;not null"`
+	Email        *string    `json:"email" gorm:"type:text;unique"`
+	DisplayName  *string    `json:"display_name" gorm:"type:text"`
+	Role         string     `json:"role" gorm:"type:text;not null"` // 'admin'|'kaprodi'|'dosen'|'viewer'
+	ProgramID    *uuid.UUID `json:"program_id" gorm:"type:uuid"`    // home program of kaprodi/dosen
+	PasswordHash string     `json:"-" gorm:"type:text"`             // bcrypt hash
+	CreatedAt    time.Time  `json:"created_at" gorm:"default:now()"`
+
+	// Relations
+	Program *Program `json:"program,omitempty" gorm:"foreignKey:ProgramID"`
 }
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"gorm.io/gorm"
+)
+
+type AIBudgetRepository interface {
+	FindAll() ([]models.AIBudget, error)
+	FindByID(id uuid.UUID) (*models.AIBudget, error)
+	FindByProgramID(programID uuid.UUID) (*models.AIBudget, error)
+	FindByUserID(userID uuid.UUID) (*models.AIBudget, error)
+	Save(budget *models.AIBudget) error
+	Delete(id uuid.UUID) error
+}
+
+type aiBudgetRepository struct {
+	db *gorm.DB
+}
+
+func NewAIBudgetRepository(db *gorm.DB) AIBudgetRepository {
+	return &aiBudgetRepository{db: db}
+}
+
+func (r *aiBudgetRepository) FindAll() ([]models.AIBudget, error) {
+	var budgets []models.AIBudget
+	err := r.db.Preload("Program").Preload("User").Order("created_at").Find(&budgets).Error
+	return budgets, err
+}
+
+func (r *aiBudgetRepository) FindByID(id uuid.UUID) (*models.AIBudget, error) {
+	var budget models.AIBudget
+	err := r.db.Preload("Program").Preload("User").First(&budget, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (r *aiBudgetRepository) FindByProgramID(programID uuid.UUID) (*models.AIBudget, error) {
+	var budget models.AIBudget
+	err := r.db.Preload("Program").First(&budget, "program_id = ?", programID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (r *aiBudgetRepository) FindByUserID(userID uuid.UUID) (*models.AIBudget, error) {
+	var budget models.AIBudget
+	err := r.db.Preload("User").First(&budget, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// Save creates the budget or updates its limit
+func (r *aiBudgetRepository) Save(budget *models.AIBudget) error {
+	return r.db.Omit("Program", "User").Save(budget).Error
+}
+
+func (r *aiBudgetRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.AIBudget{}, "id = ?", id).Error
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"gorm.io/gorm"
+)
+
+type AuthTokenRepository interface {
+	CreateRefreshToken(token *models.RefreshToken) error
+	FindRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error)
+	RotateRefreshToken(old *models.RefreshToken, replacement *models.RefreshToken) error
+	RevokeRefreshToken(id uuid.UUID) error
+	RevokeAllRefreshTokensByUserID(userID uuid.UUID) error
+	RevokeAccessToken(token *models.RevokedAccessToken) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+	DeleteExpired(before time.Time) error
+}
+
+type authTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthTokenRepository(db *gorm.DB) AuthTokenRepository {
+	return &authTokenRepository{db: db}
+}
+
+func (r *authTokenRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *authTokenRepository) FindRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.First(&token, "token_hash = ?", tokenHash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken revokes old and stores its replacement in one transaction.
+// The revoke only succeeds while old is still active, so a token raced by two
+// refresh calls is rotated at most once.
+func (r *authTokenRepository) RotateRefreshToken(old *models.RefreshToken, replacement *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(replacement).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", old.ID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by": replacement.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *authTokenRepository) RevokeRefreshToken(id uuid.UUID) error {
+	return r.db.Model(&models.RefreshToken{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now()).Error
+}
+
+func (r *authTokenRepository) RevokeAllRefreshTokensByUserID(userID uuid.UUID) error {
+	return r.db.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", time.Now()).Error
+}
+
+func (r *authTokenRepository) RevokeAccessToken(token *models.RevokedAccessToken) error {
+	return r.db.Save(token).Error
+}
+
+func (r *authTokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.RevokedAccessToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *authTokenRepository) DeleteExpired(before time.Time) error {
+	if err := r.db.Delete(&models.RevokedAccessToken{}, "expires_at < ?", before).Error; err != nil {
+		return err
+	}
+	return r.db.Delete(&models.RefreshToken{}, "expires_at < ?", before).Error
+}
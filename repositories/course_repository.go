@@ -14,6 +14,15 @@ type CourseRepository interface {
 	FindByCode(code string) (*models.Course, error)
 	Update(course *models.Course) error
 	Delete(id uuid.UUID) error
+	SaveAll(courses []*models.Course) error
+	AddLecturer(courseID uuid.UUID, user *models.User) error
+	RemoveLecturer(courseID uuid.UUID, user *models.User) error
+	IsLecturer(courseID, userID uuid.UUID) (bool, error)
+	SetLearningOutcomes(courseID uuid.UUID, outcomes []models.LearningOutcome) error
+	SavePrerequisite(edge *models.CoursePrerequisite) error
+	RemovePrerequisite(courseID, prerequisiteID uuid.UUID) (bool, error)
+	FindPrerequisiteEdges() ([]models.CoursePrerequisite, error)
+	FindDependents(courseID uuid.UUID) ([]models.CoursePrerequisite, error)
 }
 
 type courseRepository struct {
@@ -36,7 +45,9 @@ func (r *courseRepository) FindAll() ([]models.Course, error) {
 
 func (r *courseRepository) FindByID(id uuid.UUID) (*models.Course, error) {
 	var course models.Course
-	err := r.db.Preload("Program").First(&course, "id = ?", id).Error
+	err := r.db.Preload("Program").Preload("Lecturers").
+		Preload("LearningOutcomes", func(db *gorm.DB) *gorm.DB { return db.Order("code") }).
+		First(&course, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -58,10 +69,92 @@ func (r *courseRepository) FindByCode(code string) (*models.Course, error) {
 	return &course, nil
 }
 
+// Update saves the course columns; a course moved to another program loses the
+// CPL of its former program
 func (r *courseRepository) Update(course *models.Course) error {
-	return r.db.Save(course).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return saveCourse(tx, course)
+	})
 }
 
+// SaveAll creates or updates courses in a single transaction
+func (r *courseRepository) SaveAll(courses []*models.Course) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, course := range courses {
+			if err := saveCourse(tx, course); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// saveCourse saves the course without its associations and unmaps CPL that belong
+// to a program other than the course's
+func saveCourse(tx *gorm.DB, course *models.Course) error {
+	if err := tx.Omit("Program", "Lecturers", "LearningOutcomes").Save(course).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`DELETE FROM course_learning_outcomes clo USING learning_outcomes lo
+		WHERE clo.learning_outcome_id = lo.id AND clo.course_id = ? AND lo.program_id IS DISTINCT FROM ?`,
+		course.ID, course.ProgramID).Error
+}
+
+// Delete also removes the course from the prerequisite graph, its CPL mapping
+// and its lecturer assignments
 func (r *courseRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Course{}, "id = ?", id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.CoursePrerequisite{}, "course_id = ? OR prerequisite_id = ?", id, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM course_learning_outcomes WHERE course_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM course_lecturers WHERE course_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Course{}, "id = ?", id).Error
+	})
+}
+
+func (r *courseRepository) AddLecturer(courseID uuid.UUID, user *models.User) error {
+	return r.db.Model(&models.Course{ID: courseID}).Association("Lecturers").Append(user)
+}
+
+func (r *courseRepository) RemoveLecturer(courseID uuid.UUID, user *models.User) error {
+	return r.db.Model(&models.Course{ID: courseID}).Association("Lecturers").Delete(user)
+}
+
+func (r *courseRepository) IsLecturer(courseID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Table("course_lecturers").Where("course_id = ? AND user_id = ?", courseID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// SetLearningOutcomes replaces the CPL mapped to a course
+func (r *courseRepository) SetLearningOutcomes(courseID uuid.UUID, outcomes []models.LearningOutcome) error {
+	return r.db.Model(&models.Course{ID: courseID}).Association("LearningOutcomes").Replace(outcomes)
+}
+
+// SavePrerequisite adds an edge or changes the type of an existing one
+func (r *courseRepository) SavePrerequisite(edge *models.CoursePrerequisite) error {
+	return r.db.Save(edge).Error
+}
+
+func (r *courseRepository) RemovePrerequisite(courseID, prerequisiteID uuid.UUID) (bool, error) {
+	result := r.db.Delete(&models.CoursePrerequisite{}, "course_id = ? AND prerequisite_id = ?", courseID, prerequisiteID)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindPrerequisiteEdges loads the whole prerequisite graph with the required courses
+func (r *courseRepository) FindPrerequisiteEdges() ([]models.CoursePrerequisite, error) {
+	var edges []models.CoursePrerequisite
+	err := r.db.Preload("Prerequisite").Order("created_at").Find(&edges).Error
+	return edges, err
+}
+
+func (r *courseRepository) FindDependents(courseID uuid.UUID) ([]models.CoursePrerequisite, error) {
+	var edges []models.CoursePrerequisite
+	err := r.db.Preload("Course").Where("prerequisite_id = ?", courseID).Order("created_at").Find(&edges).Error
+	return edges, err
 }
@@ -1,9 +1,13 @@
 package repositories
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/syrlramadhan/dokumentasi-rps-api/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GeneratedRPSRepository interface {
@@ -16,29 +20,64 @@ type GeneratedRPSRepository interface {
 	Update(rps *models.GeneratedRPS) error
 	UpdateStatus(id uuid.UUID, status string) error
 	Delete(id uuid.UUID) error
+	ClaimNextQueued(workerID string, lease time.Duration) (*models.GeneratedRPS, error)
+	AcquireLease(id uuid.UUID, workerID string, lease time.Duration) error
+	Heartbeat(id uuid.UUID, workerID string, lease time.Duration) (bool, error)
+	ReleaseLease(id uuid.UUID, workerID string, requeue bool) error
+	RequeueExpired(now time.Time, maxAttempts int) (requeued int64, failed int64, err error)
+	Cancel(id uuid.UUID, now time.Time) (bool, error)
+	SetExperiment(id uuid.UUID, experimentID, arm *string) error
+	ExperimentStats(experimentID string) ([]ExperimentArmStats, error)
+	ApplyTransition(transition *models.RPSWorkflowTransition, updates map[string]interface{}) (bool, error)
+	FindTransitions(generatedRPSID uuid.UUID) ([]models.RPSWorkflowTransition, error)
+	UpdateWithRevisions(rps *models.GeneratedRPS, revisions ...*models.RPSRevision) error
+	FinishJob(id uuid.UUID, workerID string, updates map[string]interface{}, revisions ...*models.RPSRevision) (bool, error)
+	FailJob(id uuid.UUID, workerID string, errorMsg string) (bool, error)
+	CountRevisions(generatedRPSID uuid.UUID) (int64, error)
+	FindRevisions(generatedRPSID uuid.UUID) ([]models.RPSRevision, error)
+	FindRevision(generatedRPSID uuid.UUID, number int) (*models.RPSRevision, error)
 }
 
 type generatedRPSRepository struct {
 	db *gorm.DB
 }
 
+// ExperimentArmStats is the validation and review outcome of the jobs of one experiment arm
+type ExperimentArmStats struct {
+	Arm           string
+	Jobs          int64
+	Done          int64
+	Findings      int64 // validation findings of every severity
+	ErrorFindings int64
+	Reviewed      int64 // jobs a reviewer decided on
+	Approved      int64
+}
+
 func NewGeneratedRPSRepository(db *gorm.DB) GeneratedRPSRepository {
 	return &generatedRPSRepository{db: db}
 }
 
+// unresolvedCommentsSelect counts open comment threads for GeneratedRPS.UnresolvedComments
+const unresolvedCommentsSelect = `generated_rps.*, (SELECT COUNT(*) FROM rps_comments c
+	WHERE c.generated_rps_id = generated_rps.id AND c.parent_id IS NULL AND NOT c.resolved) AS unresolved_comments`
+
+func (r *generatedRPSRepository) withSummary() *gorm.DB {
+	return r.db.Select(unresolvedCommentsSelect)
+}
+
 func (r *generatedRPSRepository) Create(rps *models.GeneratedRPS) error {
 	return r.db.Create(rps).Error
 }
 
 func (r *generatedRPSRepository) FindAll() ([]models.GeneratedRPS, error) {
 	var rpsList []models.GeneratedRPS
-	err := r.db.Preload("TemplateVersion").Preload("Course").Preload("Generator").Find(&rpsList).Error
+	err := r.withSummary().Preload("TemplateVersion").Preload("Course").Preload("Generator").Find(&rpsList).Error
 	return rpsList, err
 }
 
 func (r *generatedRPSRepository) FindByID(id uuid.UUID) (*models.GeneratedRPS, error) {
 	var rps models.GeneratedRPS
-	err := r.db.Preload("TemplateVersion").Preload("Course").Preload("Generator").First(&rps, "id = ?", id).Error
+	err := r.withSummary().Preload("TemplateVersion").Preload("Course").Preload("Generator").First(&rps, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -47,19 +86,19 @@ func (r *generatedRPSRepository) FindByID(id uuid.UUID) (*models.GeneratedRPS, e
 
 func (r *generatedRPSRepository) FindByCourseID(courseID uuid.UUID) ([]models.GeneratedRPS, error) {
 	var rpsList []models.GeneratedRPS
-	err := r.db.Preload("TemplateVersion").Preload("Course").Preload("Generator").Where("course_id = ?", courseID).Find(&rpsList).Error
+	err := r.withSummary().Preload("TemplateVersion").Preload("Course").Preload("Generator").Where("course_id = ?", courseID).Find(&rpsList).Error
 	return rpsList, err
 }
 
 func (r *generatedRPSRepository) FindByGeneratedBy(userID uuid.UUID) ([]models.GeneratedRPS, error) {
 	var rpsList []models.GeneratedRPS
-	err := r.db.Preload("TemplateVersion").Preload("Course").Preload("Generator").Where("generated_by = ?", userID).Find(&rpsList).Error
+	err := r.withSummary().Preload("TemplateVersion").Preload("Course").Preload("Generator").Where("generated_by = ?", userID).Find(&rpsList).Error
 	return rpsList, err
 }
 
 func (r *generatedRPSRepository) FindByStatus(status string) ([]models.GeneratedRPS, error) {
 	var rpsList []models.GeneratedRPS
-	err := r.db.Preload("TemplateVersion").Preload("Course").Preload("Generator").Where("status = ?", status).Find(&rpsList).Error
+	err := r.withSummary().Preload("TemplateVersion").Preload("Course").Preload("Generator").Where("status = ?", status).Find(&rpsList).Error
 	return rpsList, err
 }
 
@@ -71,6 +110,309 @@ func (r *generatedRPSRepository) UpdateStatus(id uuid.UUID, status string) error
 	return r.db.Model(&models.GeneratedRPS{}).Where("id = ?", id).Update("status", status).Error
 }
 
+// Delete removes the RPS together with its revisions, workflow history and comments
 func (r *generatedRPSRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.GeneratedRPS{}, "id = ?", id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RPSRevision{}, "generated_rps_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.RPSWorkflowTransition{}, "generated_rps_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM rps_comment_mentions WHERE rps_comment_id IN (SELECT id FROM rps_comments WHERE generated_rps_id = ?)", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.RPSComment{}, "generated_rps_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.GeneratedRPS{}, "id = ?", id).Error
+	})
+}
+
+// ClaimNextQueued leases the oldest queued job to workerID. Concurrent workers
+// skip rows locked by each other, so every job is handed out once.
+// Returns gorm.ErrRecordNotFound when the queue is empty.
+func (r *generatedRPSRepository) ClaimNextQueued(workerID string, lease time.Duration) (*models.GeneratedRPS, error) {
+	var job models.GeneratedRPS
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", "queued").
+			Order("created_at ASC").
+			First(&job).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":       "processing",
+			"locked_by":    workerID,
+			"locked_until": now.Add(lease),
+			"heartbeat_at": now,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"updated_at":   now,
+		}
+		if job.StartedAt == nil {
+			updates["started_at"] = now
+		}
+		if err := tx.Model(&models.GeneratedRPS{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return tx.First(&job, "id = ?", job.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// AcquireLease marks a job as processing under workerID without going through the queue
+func (r *generatedRPSRepository) AcquireLease(id uuid.UUID, workerID string, lease time.Duration) error {
+	now := time.Now()
+	return r.db.Model(&models.GeneratedRPS{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       "processing",
+		"locked_by":    workerID,
+		"locked_until": now.Add(lease),
+		"heartbeat_at": now,
+		"started_at":   now,
+		"attempts":     gorm.Expr("attempts + 1"),
+		"updated_at":   now,
+	}).Error
+}
+
+// Heartbeat extends the lease; it reports false when workerID no longer holds it
+func (r *generatedRPSRepository) Heartbeat(id uuid.UUID, workerID string, lease time.Duration) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.GeneratedRPS{}).
+		Where("id = ? AND locked_by = ? AND status = ?", id, workerID, "processing").
+		Updates(map[string]interface{}{"heartbeat_at": now, "locked_until": now.Add(lease)})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReleaseLease drops workerID's lease. With requeue the job goes back to the
+// queue (interrupted run), otherwise it is stamped as finished.
+func (r *generatedRPSRepository) ReleaseLease(id uuid.UUID, workerID string, requeue bool) error {
+	updates := map[string]interface{}{"locked_by": nil, "locked_until": nil}
+	if requeue {
+		updates["status"] = "queued"
+	} else {
+		updates["finished_at"] = time.Now()
+	}
+
+	return r.db.Model(&models.GeneratedRPS{}).
+		Where("id = ? AND locked_by = ?", id, workerID).
+		Updates(updates).Error
+}
+
+// RequeueExpired returns orphaned processing jobs (lease expired or never leased)
+// to the queue, or fails them once they have used up maxAttempts
+func (r *generatedRPSRepository) RequeueExpired(now time.Time, maxAttempts int) (int64, int64, error) {
+	orphaned := r.db.Model(&models.GeneratedRPS{}).
+		Where("status = ? AND (locked_until IS NULL OR locked_until < ?)", "processing", now)
+
+	failed := orphaned.Session(&gorm.Session{}).Where("attempts >= ?", maxAttempts).Updates(map[string]interface{}{
+		"status":       "failed",
+		"locked_by":    nil,
+		"locked_until": nil,
+		"finished_at":  now,
+		"updated_at":   now,
+		"ai_metadata":  gorm.Expr("COALESCE(ai_metadata, '{}'::jsonb) || ?::jsonb", `{"error": "generation abandoned after reaching max attempts"}`),
+	})
+	if failed.Error != nil {
+		return 0, 0, failed.Error
+	}
+
+	requeued := orphaned.Session(&gorm.Session{}).Where("attempts < ?", maxAttempts).Updates(map[string]interface{}{
+		"status":       "queued",
+		"locked_by":    nil,
+		"locked_until": nil,
+		"updated_at":   now,
+	})
+	if requeued.Error != nil {
+		return 0, failed.RowsAffected, requeued.Error
+	}
+
+	return requeued.RowsAffected, failed.RowsAffected, nil
+}
+
+// Cancel stamps a queued or processing job as cancelled and drops its lease, so
+// the worker running it fails its next heartbeat. It reports false when the job
+// had already finished.
+func (r *generatedRPSRepository) Cancel(id uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.Model(&models.GeneratedRPS{}).
+		Where("id = ? AND status IN ?", id, []string{"queued", "processing"}).
+		Updates(map[string]interface{}{
+			"status":       "cancelled",
+			"locked_by":    nil,
+			"locked_until": nil,
+			"finished_at":  now,
+			"updated_at":   now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// SetExperiment tags the job with the experiment arm it runs in; nil clears the tag
+func (r *generatedRPSRepository) SetExperiment(id uuid.UUID, experimentID, arm *string) error {
+	return r.db.Model(&models.GeneratedRPS{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"experiment_id": experimentID, "experiment_arm": arm}).Error
+}
+
+// ExperimentStats counts, per arm of the experiment, the jobs, their validation
+// findings and how many a reviewer approved
+func (r *generatedRPSRepository) ExperimentStats(experimentID string) ([]ExperimentArmStats, error) {
+	var rows []ExperimentArmStats
+	err := r.db.Table("generated_rps g").
+		Select(`g.experiment_arm AS arm,
+			COUNT(*) AS jobs,
+			COUNT(*) FILTER (WHERE g.status = 'done') AS done,
+			COALESCE(SUM(f.total), 0) AS findings,
+			COALESCE(SUM(f.errors), 0) AS error_findings,
+			COUNT(*) FILTER (WHERE g.reviewed_at IS NOT NULL) AS reviewed,
+			COUNT(*) FILTER (WHERE g.approved_at IS NOT NULL) AS approved`).
+		Joins(`LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE finding->>'severity' = 'error') AS errors
+			FROM jsonb_array_elements(CASE WHEN jsonb_typeof(g.validation_findings) = 'array' THEN g.validation_findings ELSE '[]'::jsonb END) AS finding
+		) f ON true`).
+		Where("g.experiment_id = ?", experimentID).
+		Group("g.experiment_arm").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ApplyTransition moves the RPS from transition.FromStatus to transition.ToStatus
+// with the extra column updates and records the transition, in one transaction.
+// It reports false when the RPS is no longer in FromStatus.
+func (r *generatedRPSRepository) ApplyTransition(transition *models.RPSWorkflowTransition, updates map[string]interface{}) (bool, error) {
+	applied := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		columns := map[string]interface{}{"workflow_status": transition.ToStatus}
+		for column, value := range updates {
+			columns[column] = value
+		}
+
+		result := tx.Model(&models.GeneratedRPS{}).
+			Where("id = ? AND workflow_status = ?", transition.GeneratedRPSID, transition.FromStatus).
+			Updates(columns)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		applied = true
+		return tx.Create(transition).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return applied, nil
+}
+
+func (r *generatedRPSRepository) FindTransitions(generatedRPSID uuid.UUID) ([]models.RPSWorkflowTransition, error) {
+	var transitions []models.RPSWorkflowTransition
+	err := r.db.Preload("Actor").Where("generated_rps_id = ?", generatedRPSID).Order("created_at ASC").Find(&transitions).Error
+	return transitions, err
+}
+
+// UpdateWithRevisions saves the RPS and appends the revisions in one transaction,
+// numbering them after the latest existing revision. The row update locks the RPS,
+// so concurrent writers get consecutive numbers.
+func (r *generatedRPSRepository) UpdateWithRevisions(rps *models.GeneratedRPS, revisions ...*models.RPSRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(rps).Error; err != nil {
+			return err
+		}
+		return appendRevisions(tx, rps.ID, revisions)
+	})
+}
+
+// FinishJob writes the outcome of a job run by workerID and appends the revisions,
+// in one transaction. It reports false, writing nothing, when the job is no longer
+// processing under workerID (cancelled meanwhile or leased to another worker) or
+// its content got locked by the review workflow.
+func (r *generatedRPSRepository) FinishJob(id uuid.UUID, workerID string, updates map[string]interface{}, revisions ...*models.RPSRevision) (bool, error) {
+	finished := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.GeneratedRPS{}).
+			Where("id = ? AND status = ? AND locked_by = ?", id, "processing", workerID).
+			Where("workflow_status NOT IN ?", models.ContentLockedStatuses).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		finished = true
+		return appendRevisions(tx, id, revisions)
+	})
+	if err != nil {
+		return false, err
+	}
+	return finished, nil
+}
+
+// FailJob marks a job run by workerID as failed, merging the error into its AI
+// metadata. Like FinishJob it reports false when workerID no longer runs the job.
+func (r *generatedRPSRepository) FailJob(id uuid.UUID, workerID string, errorMsg string) (bool, error) {
+	errorJSON, err := json.Marshal(map[string]string{"error": errorMsg})
+	if err != nil {
+		return false, err
+	}
+
+	result := r.db.Model(&models.GeneratedRPS{}).
+		Where("id = ? AND status = ? AND locked_by = ?", id, "processing", workerID).
+		Updates(map[string]interface{}{
+			"status":      "failed",
+			"updated_at":  time.Now(),
+			"ai_metadata": gorm.Expr("COALESCE(ai_metadata, '{}'::jsonb) || ?::jsonb", string(errorJSON)),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// appendRevisions numbers the revisions after the latest existing one. Callers
+// update the RPS row first, which locks it, so concurrent writers get consecutive numbers.
+func appendRevisions(tx *gorm.DB, rpsID uuid.UUID, revisions []*models.RPSRevision) error {
+	var latest int
+	if err := tx.Model(&models.RPSRevision{}).
+		Where("generated_rps_id = ?", rpsID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&latest).Error; err != nil {
+		return err
+	}
+
+	for _, revision := range revisions {
+		latest++
+		revision.GeneratedRPSID = rpsID
+		revision.Number = latest
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *generatedRPSRepository) CountRevisions(generatedRPSID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RPSRevision{}).Where("generated_rps_id = ?", generatedRPSID).Count(&count).Error
+	return count, err
+}
+
+// FindRevisions lists revisions newest first, without their result
+func (r *generatedRPSRepository) FindRevisions(generatedRPSID uuid.UUID) ([]models.RPSRevision, error) {
+	var revisions []models.RPSRevision
+	err := r.db.Preload("Author").Omit("result").Where("generated_rps_id = ?", generatedRPSID).Order("number DESC").Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *generatedRPSRepository) FindRevision(generatedRPSID uuid.UUID, number int) (*models.RPSRevision, error) {
+	var revision models.RPSRevision
+	err := r.db.Preload("Author").First(&revision, "generated_rps_id = ? AND number = ?", generatedRPSID, number).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
 }
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"gorm.io/gorm"
+)
+
+type GenerationBatchRepository interface {
+	Create(batch *models.GenerationBatch, jobs []*models.GeneratedRPS) error
+	FindAll() ([]models.GenerationBatch, error)
+	FindByID(id uuid.UUID) (*models.GenerationBatch, error)
+	FindJobs(batchID uuid.UUID) ([]models.GeneratedRPS, error)
+	Stats(batchIDs ...uuid.UUID) (map[uuid.UUID]GenerationBatchStats, error)
+	MarkCancelled(batchID uuid.UUID, now time.Time) error
+	RequeueFailed(batchID uuid.UUID) (int64, error)
+}
+
+type generationBatchRepository struct {
+	db *gorm.DB
+}
+
+// GenerationBatchStats is the progress of a batch aggregated from its jobs
+type GenerationBatchStats struct {
+	BatchID       uuid.UUID
+	Total         int64
+	Queued        int64
+	Processing    int64
+	Done          int64
+	Failed        int64
+	Cancelled     int64
+	TotalTokens   int64
+	EstimatedCost float64
+}
+
+func NewGenerationBatchRepository(db *gorm.DB) GenerationBatchRepository {
+	return &generationBatchRepository{db: db}
+}
+
+// Create stores the batch and its queued jobs together, so the worker never sees half a batch
+func (r *generationBatchRepository) Create(batch *models.GenerationBatch, jobs []*models.GeneratedRPS) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Jobs").Create(batch).Error; err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			job.BatchID = &batch.ID
+		}
+		return tx.CreateInBatches(jobs, 100).Error
+	})
+}
+
+func (r *generationBatchRepository) FindAll() ([]models.GenerationBatch, error) {
+	var batches []models.GenerationBatch
+	err := r.db.Preload("Program").Preload("Creator").Order("created_at DESC").Find(&batches).Error
+	return batches, err
+}
+
+func (r *generationBatchRepository) FindByID(id uuid.UUID) (*models.GenerationBatch, error) {
+	var batch models.GenerationBatch
+	err := r.db.Preload("Program").Preload("TemplateVersion").Preload("Creator").First(&batch, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// FindJobs returns the jobs of a batch with their course, in queue order
+func (r *generationBatchRepository) FindJobs(batchID uuid.UUID) ([]models.GeneratedRPS, error) {
+	var jobs []models.GeneratedRPS
+	err := r.db.Preload("Course").Where("batch_id = ?", batchID).Order("created_at ASC").Find(&jobs).Error
+	return jobs, err
+}
+
+// Stats counts the jobs of each batch per status and sums the token usage and
+// estimated cost recorded in their ai_metadata. Batches without jobs are absent.
+func (r *generationBatchRepository) Stats(batchIDs ...uuid.UUID) (map[uuid.UUID]GenerationBatchStats, error) {
+	stats := make(map[uuid.UUID]GenerationBatchStats, len(batchIDs))
+	if len(batchIDs) == 0 {
+		return stats, nil
+	}
+
+	var rows []GenerationBatchStats
+	err := r.db.Model(&models.GeneratedRPS{}).
+		Select(`batch_id,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status = 'queued') AS queued,
+			COUNT(*) FILTER (WHERE status = 'processing') AS processing,
+			COUNT(*) FILTER (WHERE status = 'done') AS done,
+			COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+			COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled,
+			COALESCE(SUM((ai_metadata->>'total_tokens')::bigint), 0) AS total_tokens,
+			COALESCE(SUM((ai_metadata->>'estimated_cost')::numeric), 0) AS estimated_cost`).
+		Where("batch_id IN ?", batchIDs).
+		Group("batch_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		stats[row.BatchID] = row
+	}
+	return stats, nil
+}
+
+// MarkCancelled stamps the batch as cancelled; its jobs are cancelled one by one
+// through the generation service
+func (r *generationBatchRepository) MarkCancelled(batchID uuid.UUID, now time.Time) error {
+	return r.db.Model(&models.GenerationBatch{}).Where("id = ?", batchID).
+		Updates(map[string]interface{}{"cancelled_at": now, "updated_at": now}).Error
+}
+
+// RequeueFailed puts the failed and cancelled jobs of a batch back in the queue
+// with a fresh attempt budget and lifts a previous cancellation
+func (r *generationBatchRepository) RequeueFailed(batchID uuid.UUID) (int64, error) {
+	var requeued int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.GeneratedRPS{}).
+			Where("batch_id = ? AND status IN ?", batchID, []string{"failed", "cancelled"}).
+			Updates(map[string]interface{}{
+				"status":       "queued",
+				"attempts":     0,
+				"ai_metadata":  nil,
+				"locked_by":    nil,
+				"locked_until": nil,
+				"heartbeat_at": nil,
+				"started_at":   nil,
+				"finished_at":  nil,
+				"updated_at":   now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		requeued = result.RowsAffected
+
+		return tx.Model(&models.GenerationBatch{}).Where("id = ?", batchID).
+			Updates(map[string]interface{}{"cancelled_at": nil, "updated_at": now}).Error
+	})
+	return requeued, err
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"gorm.io/gorm"
+)
+
+type LearningOutcomeRepository interface {
+	Create(outcome *models.LearningOutcome) error
+	FindByID(id uuid.UUID) (*models.LearningOutcome, error)
+	FindByProgramID(programID uuid.UUID) ([]models.LearningOutcome, error)
+	FindByIDs(ids []uuid.UUID) ([]models.LearningOutcome, error)
+	Update(outcome *models.LearningOutcome) error
+	Delete(id uuid.UUID) error
+}
+
+type learningOutcomeRepository struct {
+	db *gorm.DB
+}
+
+func NewLearningOutcomeRepository(db *gorm.DB) LearningOutcomeRepository {
+	return &learningOutcomeRepository{db: db}
+}
+
+func (r *learningOutcomeRepository) Create(outcome *models.LearningOutcome) error {
+	return r.db.Create(outcome).Error
+}
+
+func (r *learningOutcomeRepository) FindByID(id uuid.UUID) (*models.LearningOutcome, error) {
+	var outcome models.LearningOutcome
+	err := r.db.First(&outcome, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &outcome, nil
+}
+
+func (r *learningOutcomeRepository) FindByProgramID(programID uuid.UUID) ([]models.LearningOutcome, error) {
+	var outcomes []models.LearningOutcome
+	err := r.db.Where("program_id = ?", programID).Order("code").Find(&outcomes).Error
+	return outcomes, err
+}
+
+func (r *learningOutcomeRepository) FindByIDs(ids []uuid.UUID) ([]models.LearningOutcome, error) {
+	var outcomes []models.LearningOutcome
+	err := r.db.Where("id IN ?", ids).Order("code").Find(&outcomes).Error
+	return outcomes, err
+}
+
+func (r *learningOutcomeRepository) Update(outcome *models.LearningOutcome) error {
+	return r.db.Save(outcome).Error
+}
+
+// Delete also removes the outcome from every course it is mapped to
+func (r *learningOutcomeRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM course_learning_outcomes WHERE learning_outcome_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.LearningOutcome{}, "id = ?", id).Error
+	})
+}
@@ -0,0 +1,173 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Bucket sizes of the analytics series
+const (
+	AnalyticsHourly = "hour"
+	AnalyticsDaily  = "day"
+)
+
+// AnalyticsFilter narrows the analytics; empty fields match everything
+type AnalyticsFilter struct {
+	Model     string
+	CourseID  string
+	ProgramID string
+	From      time.Time
+	To        time.Time // exclusive
+}
+
+func (f AnalyticsFilter) match() bson.M {
+	match := CostFilter{ProgramID: f.ProgramID, From: f.From, To: f.To}.match()
+	if f.Model != "" {
+		match["model"] = f.Model
+	}
+	if f.CourseID != "" {
+		match["course_id"] = f.CourseID
+	}
+	return match
+}
+
+// AnalyticsStats is the traffic of one bucket, or of the whole range when Bucket is zero.
+// Latencies are those of the successful calls, by nearest rank.
+type AnalyticsStats struct {
+	Bucket           time.Time `bson:"bucket,omitempty"`
+	Requests         int64     `bson:"requests"`
+	Succeeded        int64     `bson:"succeeded"`
+	Failed           int64     `bson:"failed"` // failed and timed out
+	Cancelled        int64     `bson:"cancelled"`
+	LatencyP50Ms     int64     `bson:"latency_p50_ms"`
+	LatencyP95Ms     int64     `bson:"latency_p95_ms"`
+	LatencyP99Ms     int64     `bson:"latency_p99_ms"`
+	AvgLatencyMs     float64   `bson:"avg_latency_ms"`
+	PromptTokens     int64     `bson:"prompt_tokens"`
+	CompletionTokens int64     `bson:"completion_tokens"`
+	TotalTokens      int64     `bson:"total_tokens"`
+	Cost             float64   `bson:"cost"`
+}
+
+// AnalyticsFailures counts the failed calls of one error class in a bucket, or in
+// the whole range when Bucket is zero
+type AnalyticsFailures struct {
+	Bucket time.Time `bson:"bucket,omitempty"`
+	Class  string    `bson:"class"` // unclassified for prompts stored before error classes
+	Count  int64     `bson:"count"`
+}
+
+// AnalyticsResult holds the facets of one analytics aggregation
+type AnalyticsResult struct {
+	Series        []AnalyticsStats    `bson:"series"`
+	Totals        []AnalyticsStats    `bson:"totals"` // at most one
+	Failures      []AnalyticsFailures `bson:"failures"`
+	FailureTotals []AnalyticsFailures `bson:"failure_totals"`
+}
+
+var failedStatuses = bson.A{"failed", "timeout"}
+
+// analyticsBucket truncates created_at to the start of its hour or day in UTC
+func analyticsBucket(interval string) bson.M {
+	parts := bson.M{
+		"year":  bson.M{"$year": "$created_at"},
+		"month": bson.M{"$month": "$created_at"},
+		"day":   bson.M{"$dayOfMonth": "$created_at"},
+	}
+	if interval == AnalyticsHourly {
+		parts["hour"] = bson.M{"$hour": "$created_at"}
+	}
+	return bson.M{"$dateFromParts": parts}
+}
+
+// analyticsStats groups the sorted prompts by id and derives the latency percentiles
+func analyticsStats(id interface{}) []bson.M {
+	succeeded := bson.M{"$eq": bson.A{"$status", "success"}}
+	countIf := func(cond interface{}) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{cond, 1, 0}}}
+	}
+	percentile := func(p float64) bson.M {
+		rank := bson.M{"$ceil": bson.M{"$multiply": bson.A{p, bson.M{"$size": "$latencies"}}}}
+		return bson.M{"$ifNull": bson.A{
+			bson.M{"$arrayElemAt": bson.A{"$latencies", bson.M{"$toInt": bson.M{"$subtract": bson.A{rank, 1}}}}},
+			0,
+		}}
+	}
+
+	return []bson.M{
+		{
+			"$group": bson.M{
+				"_id":               id,
+				"requests":          bson.M{"$sum": 1},
+				"succeeded":         countIf(succeeded),
+				"failed":            countIf(bson.M{"$in": bson.A{"$status", failedStatuses}}),
+				"cancelled":         countIf(bson.M{"$eq": bson.A{"$status", "cancelled"}}),
+				"latencies":         bson.M{"$push": bson.M{"$cond": bson.A{succeeded, "$request_duration_ms", nil}}},
+				"avg_latency_ms":    bson.M{"$avg": bson.M{"$cond": bson.A{succeeded, "$request_duration_ms", nil}}},
+				"prompt_tokens":     bson.M{"$sum": "$prompt_tokens"},
+				"completion_tokens": bson.M{"$sum": "$completion_tokens"},
+				"total_tokens":      bson.M{"$sum": "$total_tokens"},
+				"cost":              bson.M{"$sum": "$estimated_cost"},
+			},
+		},
+		{
+			"$addFields": bson.M{
+				"latencies": bson.M{"$filter": bson.M{"input": "$latencies", "cond": bson.M{"$ne": bson.A{"$$this", nil}}}},
+			},
+		},
+		{
+			"$project": bson.M{
+				"_id":               0,
+				"bucket":            "$_id",
+				"requests":          1,
+				"succeeded":         1,
+				"failed":            1,
+				"cancelled":         1,
+				"latency_p50_ms":    percentile(0.50),
+				"latency_p95_ms":    percentile(0.95),
+				"latency_p99_ms":    percentile(0.99),
+				"avg_latency_ms":    bson.M{"$ifNull": bson.A{"$avg_latency_ms", 0}},
+				"prompt_tokens":     1,
+				"completion_tokens": 1,
+				"total_tokens":      1,
+				"cost":              1,
+			},
+		},
+	}
+}
+
+// analyticsFailures counts the failed prompts per error class within each group id
+func analyticsFailures(id interface{}) []bson.M {
+	return []bson.M{
+		{"$match": bson.M{"status": bson.M{"$in": failedStatuses}}},
+		{
+			"$group": bson.M{
+				"_id":   bson.M{"bucket": id, "class": bson.M{"$ifNull": bson.A{"$error_class", "unclassified"}}},
+				"count": bson.M{"$sum": 1},
+			},
+		},
+		{"$project": bson.M{"_id": 0, "bucket": "$_id.bucket", "class": "$_id.class", "count": 1}},
+		{"$sort": bson.D{{Key: "bucket", Value: 1}, {Key: "count", Value: -1}}},
+	}
+}
+
+// analyticsPipeline builds the whole analytics in one aggregation: the prompts
+// are sorted by latency once so every group pushes its latencies in order
+func analyticsPipeline(filter AnalyticsFilter, interval string) []bson.M {
+	bucket := analyticsBucket(interval)
+	series := append(analyticsStats(bucket), bson.M{"$sort": bson.M{"bucket": 1}})
+
+	return []bson.M{
+		{"$match": filter.match()},
+		{"$sort": bson.M{"request_duration_ms": 1}},
+		{
+			"$facet": bson.M{
+				"series":         series,
+				"totals":         analyticsStats(nil),
+				"failures":       analyticsFailures(bucket),
+				"failure_totals": analyticsFailures(nil),
+			},
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Keys a cost report can be grouped by
+const (
+	CostByProgram = "program"
+	CostByUser    = "user"
+	CostByModel   = "model"
+	CostByMonth   = "month"
+)
+
+// costGroupFields maps each CostBy key to its output field and group expression.
+// Months are calendar months in UTC, like the budget periods.
+var costGroupFields = map[string]struct {
+	name string
+	expr interface{}
+}{
+	CostByProgram: {"program_id", "$program_id"},
+	CostByUser:    {"requested_by", "$requested_by"},
+	CostByModel:   {"model", "$model"},
+	CostByMonth:   {"month", bson.M{"$dateToString": bson.M{"format": "%Y-%m", "date": "$created_at"}}},
+}
+
+// CostFilter narrows cost aggregations; empty fields match everything
+type CostFilter struct {
+	ProgramID   string
+	RequestedBy string
+	From        time.Time
+	To          time.Time // exclusive
+}
+
+func (f CostFilter) match() bson.M {
+	match := bson.M{}
+	if f.ProgramID != "" {
+		match["program_id"] = f.ProgramID
+	}
+	if f.RequestedBy != "" {
+		match["requested_by"] = f.RequestedBy
+	}
+
+	createdAt := bson.M{}
+	if !f.From.IsZero() {
+		createdAt["$gte"] = f.From
+	}
+	if !f.To.IsZero() {
+		createdAt["$lt"] = f.To
+	}
+	if len(createdAt) > 0 {
+		match["created_at"] = createdAt
+	}
+	return match
+}
+
+// CostGroup is the usage and cost of the prompts sharing the grouped keys; keys
+// that were not grouped by are empty
+type CostGroup struct {
+	ProgramID        string  `bson:"program_id"`
+	RequestedBy      string  `bson:"requested_by"`
+	Model            string  `bson:"model"`
+	Month            string  `bson:"month"` // YYYY-MM
+	Calls            int64   `bson:"calls"`
+	PromptTokens     int64   `bson:"prompt_tokens"`
+	CompletionTokens int64   `bson:"completion_tokens"`
+	TotalTokens      int64   `bson:"total_tokens"`
+	Cost             float64 `bson:"cost"`
+}
@@ -14,6 +14,7 @@ import (
 
 type AIGenerationRepository interface {
 	Create(ctx context.Context, generation *models.AIGeneration) (*models.AIGeneration, error)
+	StartRun(ctx context.Context, generation *models.AIGeneration) (*models.AIGeneration, error)
 	FindByID(ctx context.Context, id primitive.ObjectID) (*models.AIGeneration, error)
 	FindByGeneratedRPSID(ctx context.Context, generatedRPSID string) (*models.AIGeneration, error)
 	FindAll(ctx context.Context, limit, offset int64) ([]models.AIGeneration, error)
@@ -22,12 +23,25 @@ type AIGenerationRepository interface {
 	AddAttempt(ctx context.Context, id primitive.ObjectID, attempt models.GenerationAttempt) error
 	UpdateFinalStatus(ctx context.Context, id primitive.ObjectID, status string, result map[string]interface{}) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
+	ArmStats(ctx context.Context, experimentID string) ([]ExperimentArmGenerationStats, error)
 }
 
 type aiGenerationRepository struct {
 	collection *mongo.Collection
 }
 
+// ExperimentArmGenerationStats is the outcome and usage of the generations of one experiment arm
+type ExperimentArmGenerationStats struct {
+	Arm           string  `bson:"_id"`
+	Generations   int64   `bson:"generations"`
+	Succeeded     int64   `bson:"succeeded"`
+	Failed        int64   `bson:"failed"`
+	Cancelled     int64   `bson:"cancelled"`
+	TotalTokens   int64   `bson:"total_tokens"`
+	TotalCost     float64 `bson:"total_cost"`
+	AvgDurationMs float64 `bson:"avg_duration_ms"`
+}
+
 func NewAIGenerationRepository(db *mongo.Database) AIGenerationRepository {
 	collection := db.Collection("ai_generations")
 
@@ -39,6 +53,7 @@ func NewAIGenerationRepository(db *mongo.Database) AIGenerationRepository {
 		{Keys: bson.D{{Key: "generated_rps_id", Value: 1}}, Options: options.Index().SetUnique(true)},
 		{Keys: bson.D{{Key: "final_status", Value: 1}}},
 		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "experiment_id", Value: 1}}, Options: options.Index().SetSparse(true)},
 	}
 
 	collection.Indexes().CreateMany(ctx, indexes)
@@ -59,6 +74,48 @@ func (r *aiGenerationRepository) Create(ctx context.Context, generation *models.
 	return generation, nil
 }
 
+// StartRun creates the generation record of a job, or starts a new run on the
+// existing one: attempts and totals accumulate, the final status resets to processing
+func (r *aiGenerationRepository) StartRun(ctx context.Context, generation *models.AIGeneration) (*models.AIGeneration, error) {
+	now := time.Now()
+	set := bson.M{
+		"course_id":           generation.CourseID,
+		"course_name":         generation.CourseName,
+		"course_code":         generation.CourseCode,
+		"template_version_id": generation.TemplateVersionID,
+		"final_status":        "processing",
+		"started_at":          now,
+	}
+	unset := bson.M{"final_result": "", "completed_at": ""}
+	for field, value := range map[string]string{
+		"experiment_id":  generation.ExperimentID,
+		"experiment_arm": generation.ExperimentArm,
+		"program_id":     generation.ProgramID,
+		"requested_by":   generation.RequestedBy,
+	} {
+		if value == "" {
+			unset[field] = ""
+		} else {
+			set[field] = value
+		}
+	}
+
+	update := bson.M{
+		"$set":         set,
+		"$unset":       unset,
+		"$inc":         bson.M{"runs": 1},
+		"$setOnInsert": bson.M{"attempts": bson.A{}, "created_at": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var started models.AIGeneration
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"generated_rps_id": generation.GeneratedRPSID}, update, opts).Decode(&started)
+	if err != nil {
+		return nil, err
+	}
+	return &started, nil
+}
+
 func (r *aiGenerationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.AIGeneration, error) {
 	var generation models.AIGeneration
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&generation)
@@ -123,7 +180,7 @@ func (r *aiGenerationRepository) Update(ctx context.Context, generation *models.
 func (r *aiGenerationRepository) AddAttempt(ctx context.Context, id primitive.ObjectID, attempt models.GenerationAttempt) error {
 	update := bson.M{
 		"$push": bson.M{"attempts": attempt},
-		"$inc":  bson.M{"total_attempts": 1, "total_tokens_used": attempt.TokensUsed, "total_duration_ms": attempt.DurationMs},
+		"$inc":  bson.M{"total_attempts": 1, "total_tokens_used": attempt.TokensUsed, "total_duration_ms": attempt.DurationMs, "total_cost": attempt.Cost},
 	}
 
 	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
@@ -147,3 +204,38 @@ func (r *aiGenerationRepository) Delete(ctx context.Context, id primitive.Object
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
+
+// ArmStats groups the generations of an experiment by arm. Durations and costs
+// add up every attempt, so retries count against the arm that needed them.
+func (r *aiGenerationRepository) ArmStats(ctx context.Context, experimentID string) ([]ExperimentArmGenerationStats, error) {
+	countStatus := func(status string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$final_status", status}}, 1, 0}}}
+	}
+	pipeline := []bson.M{
+		{"$match": bson.M{"experiment_id": experimentID}},
+		{
+			"$group": bson.M{
+				"_id":             "$experiment_arm",
+				"generations":     bson.M{"$sum": 1},
+				"succeeded":       countStatus("success"),
+				"failed":          countStatus("failed"),
+				"cancelled":       countStatus("cancelled"),
+				"total_tokens":    bson.M{"$sum": "$total_tokens_used"},
+				"total_cost":      bson.M{"$sum": "$total_cost"},
+				"avg_duration_ms": bson.M{"$avg": "$total_duration_ms"},
+			},
+		},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var stats []ExperimentArmGenerationStats
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -23,6 +23,10 @@ type AIPromptRepository interface {
 	Update(ctx context.Context, prompt *models.AIPrompt) (*models.AIPrompt, error)
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	GetStats(ctx context.Context) (*AIPromptStats, error)
+	ArmStats(ctx context.Context, experimentID string) ([]ExperimentArmPromptStats, error)
+	TotalCost(ctx context.Context, filter CostFilter) (float64, error)
+	CostReport(ctx context.Context, filter CostFilter, groupBy []string) ([]CostGroup, error)
+	Analytics(ctx context.Context, filter AnalyticsFilter, interval string) (*AnalyticsResult, error)
 }
 
 type aiPromptRepository struct {
@@ -36,6 +40,16 @@ type AIPromptStats struct {
 	FailedCount     int64   `json:"failed_count"`
 	AvgResponseTime float64 `json:"avg_response_time_ms"`
 	AvgTokensPerReq float64 `json:"avg_tokens_per_request"`
+	TotalCost       float64 `json:"total_cost"` // USD
+}
+
+// ExperimentArmPromptStats is the latency and token usage of the successful
+// provider calls of one experiment arm
+type ExperimentArmPromptStats struct {
+	Arm          string  `bson:"_id"`
+	Prompts      int64   `bson:"prompts"`
+	AvgLatencyMs float64 `bson:"avg_latency_ms"`
+	AvgTokens    float64 `bson:"avg_tokens"`
 }
 
 func NewAIPromptRepository(db *mongo.Database) AIPromptRepository {
@@ -51,6 +65,11 @@ func NewAIPromptRepository(db *mongo.Database) AIPromptRepository {
 		{Keys: bson.D{{Key: "model", Value: 1}}},
 		{Keys: bson.D{{Key: "status", Value: 1}}},
 		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "model", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "course_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "experiment_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "program_id", Value: 1}, {Key: "created_at", Value: -1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "requested_by", Value: 1}, {Key: "created_at", Value: -1}}, Options: options.Index().SetSparse(true)},
 	}
 
 	collection.Indexes().CreateMany(ctx, indexes)
@@ -233,6 +252,7 @@ func (r *aiPromptRepository) GetStats(ctx context.Context) (*AIPromptStats, erro
 				"failed_count":      bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", "failed"}}, 1, 0}}},
 				"avg_response_time": bson.M{"$avg": "$request_duration_ms"},
 				"avg_tokens":        bson.M{"$avg": "$total_tokens"},
+				"total_cost":        bson.M{"$sum": "$estimated_cost"},
 			},
 		},
 	}
@@ -260,9 +280,122 @@ func (r *aiPromptRepository) GetStats(ctx context.Context) (*AIPromptStats, erro
 		FailedCount:     getInt64(result, "failed_count"),
 		AvgResponseTime: getFloat64(result, "avg_response_time"),
 		AvgTokensPerReq: getFloat64(result, "avg_tokens"),
+		TotalCost:       getFloat64(result, "total_cost"),
 	}, nil
 }
 
+// ArmStats groups the successful prompts of an experiment by arm
+func (r *aiPromptRepository) ArmStats(ctx context.Context, experimentID string) ([]ExperimentArmPromptStats, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"experiment_id": experimentID, "status": "success"}},
+		{
+			"$group": bson.M{
+				"_id":            "$experiment_arm",
+				"prompts":        bson.M{"$sum": 1},
+				"avg_latency_ms": bson.M{"$avg": "$request_duration_ms"},
+				"avg_tokens":     bson.M{"$avg": "$total_tokens"},
+			},
+		},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var stats []ExperimentArmPromptStats
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// TotalCost sums the cost of the prompts matching filter
+func (r *aiPromptRepository) TotalCost(ctx context.Context, filter CostFilter) (float64, error) {
+	pipeline := []bson.M{
+		{"$match": filter.match()},
+		{"$group": bson.M{"_id": nil, "cost": bson.M{"$sum": "$estimated_cost"}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []bson.M
+	if err := cursor.All(ctx, &results); err != nil {
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	return getFloat64(results[0], "cost"), nil
+}
+
+// CostReport groups the usage and cost of the prompts matching filter by the
+// given CostBy keys, months first, then by descending cost
+func (r *aiPromptRepository) CostReport(ctx context.Context, filter CostFilter, groupBy []string) ([]CostGroup, error) {
+	keys := bson.M{}
+	fields := bson.M{"_id": 0, "calls": 1, "prompt_tokens": 1, "completion_tokens": 1, "total_tokens": 1, "cost": 1}
+	for _, key := range groupBy {
+		field, ok := costGroupFields[key]
+		if !ok {
+			continue
+		}
+		keys[field.name] = field.expr
+		fields[field.name] = "$_id." + field.name
+	}
+
+	pipeline := []bson.M{
+		{"$match": filter.match()},
+		{
+			"$group": bson.M{
+				"_id":               keys,
+				"calls":             bson.M{"$sum": 1},
+				"prompt_tokens":     bson.M{"$sum": "$prompt_tokens"},
+				"completion_tokens": bson.M{"$sum": "$completion_tokens"},
+				"total_tokens":      bson.M{"$sum": "$total_tokens"},
+				"cost":              bson.M{"$sum": "$estimated_cost"},
+			},
+		},
+		{"$project": fields},
+		{"$sort": bson.D{{Key: "month", Value: 1}, {Key: "cost", Value: -1}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []CostGroup
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Analytics aggregates the prompts matching the filter into hourly or daily
+// buckets, with totals and failures per error class
+func (r *aiPromptRepository) Analytics(ctx context.Context, filter AnalyticsFilter, interval string) (*AnalyticsResult, error) {
+	cursor, err := r.collection.Aggregate(ctx, analyticsPipeline(filter, interval), options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []AnalyticsResult
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return &AnalyticsResult{}, nil
+	}
+	return &results[0], nil
+}
+
 // Helper functions
 func getInt64(m bson.M, key string) int64 {
 	if v, ok := m[key]; ok {
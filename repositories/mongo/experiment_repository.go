@@ -0,0 +1,107 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	models "github.com/syrlramadhan/dokumentasi-rps-api/models/mongo"
+)
+
+type ExperimentRepository interface {
+	Create(ctx context.Context, experiment *models.Experiment) (*models.Experiment, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Experiment, error)
+	FindAll(ctx context.Context) ([]models.Experiment, error)
+	FindRunning(ctx context.Context) (*models.Experiment, error)
+	Update(ctx context.Context, experiment *models.Experiment) (*models.Experiment, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type experimentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewExperimentRepository(db *mongo.Database) ExperimentRepository {
+	collection := db.Collection("experiments")
+
+	// Create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// At most one experiment splits the traffic at a time
+		{Keys: bson.D{{Key: "status", Value: 1}}, Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": "running"})},
+	}
+
+	collection.Indexes().CreateMany(ctx, indexes)
+
+	return &experimentRepository{collection: collection}
+}
+
+func (r *experimentRepository) Create(ctx context.Context, experiment *models.Experiment) (*models.Experiment, error) {
+	experiment.CreatedAt = time.Now()
+	experiment.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, experiment)
+	if err != nil {
+		return nil, err
+	}
+
+	experiment.ID = result.InsertedID.(primitive.ObjectID)
+	return experiment, nil
+}
+
+func (r *experimentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Experiment, error) {
+	var experiment models.Experiment
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&experiment)
+	if err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+func (r *experimentRepository) FindAll(ctx context.Context) ([]models.Experiment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var experiments []models.Experiment
+	if err := cursor.All(ctx, &experiments); err != nil {
+		return nil, err
+	}
+	return experiments, nil
+}
+
+// FindRunning returns the experiment currently splitting generation traffic
+func (r *experimentRepository) FindRunning(ctx context.Context) (*models.Experiment, error) {
+	var experiment models.Experiment
+	err := r.collection.FindOne(ctx, bson.M{"status": "running"}).Decode(&experiment)
+	if err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+func (r *experimentRepository) Update(ctx context.Context, experiment *models.Experiment) (*models.Experiment, error) {
+	experiment.UpdatedAt = time.Now()
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": experiment.ID}, experiment)
+	if err != nil {
+		return nil, err
+	}
+	return experiment, nil
+}
+
+func (r *experimentRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -22,6 +22,7 @@ type PromptTemplateRepository interface {
 	Update(ctx context.Context, template *models.PromptTemplate) (*models.PromptTemplate, error)
 	IncrementUsage(ctx context.Context, id primitive.ObjectID) error
 	UpdateSuccessRate(ctx context.Context, id primitive.ObjectID, successRate float64) error
+	DeactivateOthers(ctx context.Context, category string, keepID primitive.ObjectID) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
 }
 
@@ -153,6 +154,20 @@ func (r *promptTemplateRepository) UpdateSuccessRate(ctx context.Context, id pri
 	return err
 }
 
+// DeactivateOthers leaves keepID as the only active template of its category
+func (r *promptTemplateRepository) DeactivateOthers(ctx context.Context, category string, keepID primitive.ObjectID) error {
+	filter := bson.M{"category": category, "is_active": true, "_id": bson.M{"$ne": keepID}}
+	update := bson.M{
+		"$set": bson.M{
+			"is_active":  false,
+			"updated_at": time.Now(),
+		},
+	}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
 func (r *promptTemplateRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
@@ -13,6 +13,7 @@ type ProgramRepository interface {
 	FindByCode(code string) (*models.Program, error)
 	Update(program *models.Program) error
 	Delete(id uuid.UUID) error
+	SaveAll(programs []*models.Program) error
 }
 
 type programRepository struct {
@@ -58,3 +59,15 @@ func (r *programRepository) Update(program *models.Program) error {
 func (r *programRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.Program{}, "id = ?", id).Error
 }
+
+// SaveAll creates or updates programs in a single transaction
+func (r *programRepository) SaveAll(programs []*models.Program) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, program := range programs {
+			if err := tx.Save(program).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"gorm.io/gorm"
+)
+
+type RPSCommentRepository interface {
+	Create(comment *models.RPSComment) error
+	FindByID(id uuid.UUID) (*models.RPSComment, error)
+	FindThreads(generatedRPSID uuid.UUID, unresolvedOnly bool) ([]models.RPSComment, error)
+	Update(comment *models.RPSComment) error
+}
+
+type rpsCommentRepository struct {
+	db *gorm.DB
+}
+
+func NewRPSCommentRepository(db *gorm.DB) RPSCommentRepository {
+	return &rpsCommentRepository{db: db}
+}
+
+// Create stores the comment and links its mentions without touching the users
+func (r *rpsCommentRepository) Create(comment *models.RPSComment) error {
+	return r.db.Omit("Mentions.*", "Replies").Create(comment).Error
+}
+
+func (r *rpsCommentRepository) FindByID(id uuid.UUID) (*models.RPSComment, error) {
+	var comment models.RPSComment
+	err := r.withThread(r.db).First(&comment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// FindThreads returns the root comments of an RPS with their replies, oldest first
+func (r *rpsCommentRepository) FindThreads(generatedRPSID uuid.UUID, unresolvedOnly bool) ([]models.RPSComment, error) {
+	query := r.withThread(r.db).Where("generated_rps_id = ? AND parent_id IS NULL", generatedRPSID)
+	if unresolvedOnly {
+		query = query.Where("resolved = ?", false)
+	}
+
+	var comments []models.RPSComment
+	err := query.Order("created_at ASC").Find(&comments).Error
+	return comments, err
+}
+
+func (r *rpsCommentRepository) Update(comment *models.RPSComment) error {
+	return r.db.Omit("Author", "Mentions", "Replies").Save(comment).Error
+}
+
+func (r *rpsCommentRepository) withThread(db *gorm.DB) *gorm.DB {
+	return db.Preload("Author").
+		Preload("Mentions").
+		Preload("Replies", func(tx *gorm.DB) *gorm.DB { return tx.Order("created_at ASC") }).
+		Preload("Replies.Author").
+		Preload("Replies.Mentions")
+}
@@ -56,7 +56,7 @@ func (r *templateRepository) FindActiveByProgramID(programID uuid.UUID) ([]model
 }
 
 func (r *templateRepository) Update(template *models.Template) error {
-	return r.db.Save(template).Error
+	return r.db.Omit("Program", "Creator").Save(template).Error
 }
 
 func (r *templateRepository) Delete(id uuid.UUID) error {
@@ -12,6 +12,7 @@ type UserRepository interface {
 	FindByID(id uuid.UUID) (*models.User, error)
 	FindByUsername(username string) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
+	FindByIDs(ids []uuid.UUID) ([]models.User, error)
 	Update(user *models.User) error
 	Delete(id uuid.UUID) error
 }
@@ -61,10 +62,22 @@ func (r *userRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+func (r *userRepository) FindByIDs(ids []uuid.UUID) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
 func (r *userRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
+// Delete also unassigns the user from the courses they lecture
 func (r *userRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.User{}, "id = ?", id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM course_lecturers WHERE user_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, "id = ?", id).Error
+	})
 }
@@ -2,68 +2,118 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/mongo"
 	"gorm.io/gorm"
 
+	"github.com/syrlramadhan/dokumentasi-rps-api/config"
 	"github.com/syrlramadhan/dokumentasi-rps-api/controllers"
+	"github.com/syrlramadhan/dokumentasi-rps-api/middleware"
 	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
 	mongoRepo "github.com/syrlramadhan/dokumentasi-rps-api/repositories/mongo"
 	"github.com/syrlramadhan/dokumentasi-rps-api/services"
 )
 
-func SetupRoutes(r *gin.Engine, db *gorm.DB, mongoDB *mongo.Database) {
+// SetupRoutes wires the API and returns the generation worker pool for main to start and drain
+func SetupRoutes(r *gin.Engine, db *gorm.DB, mongoDB *mongo.Database) services.GenerationWorker {
 	// Initialize PostgreSQL repositories
 	userRepo := repositories.NewUserRepository(db)
 	programRepo := repositories.NewProgramRepository(db)
 	courseRepo := repositories.NewCourseRepository(db)
+	learningOutcomeRepo := repositories.NewLearningOutcomeRepository(db)
 	templateRepo := repositories.NewTemplateRepository(db)
 	templateVersionRepo := repositories.NewTemplateVersionRepository(db)
 	generatedRPSRepo := repositories.NewGeneratedRPSRepository(db)
 	auditLogRepo := repositories.NewAuditLogRepository(db)
+	authTokenRepo := repositories.NewAuthTokenRepository(db)
+	rpsCommentRepo := repositories.NewRPSCommentRepository(db)
+	generationBatchRepo := repositories.NewGenerationBatchRepository(db)
+	aiBudgetRepo := repositories.NewAIBudgetRepository(db)
 
 	// Initialize MongoDB repositories
 	aiPromptRepo := mongoRepo.NewAIPromptRepository(mongoDB)
 	aiGenerationRepo := mongoRepo.NewAIGenerationRepository(mongoDB)
 	promptTemplateRepo := mongoRepo.NewPromptTemplateRepository(mongoDB)
+	experimentRepo := mongoRepo.NewExperimentRepository(mongoDB)
 
 	// Initialize services
+	authService := services.NewAuthService(userRepo, authTokenRepo, config.GetJWTConfig())
 	userService := services.NewUserService(userRepo)
 	programService := services.NewProgramService(programRepo)
-	courseService := services.NewCourseService(courseRepo)
+	courseService := services.NewCourseService(courseRepo, userRepo, learningOutcomeRepo)
+	learningOutcomeService := services.NewLearningOutcomeService(learningOutcomeRepo, programRepo)
+	importService := services.NewImportService(programRepo, courseRepo)
 	templateService := services.NewTemplateService(templateRepo)
 	templateVersionService := services.NewTemplateVersionService(templateVersionRepo)
 	generatedRPSService := services.NewGeneratedRPSService(generatedRPSRepo)
+	rpsWorkflowService := services.NewRPSWorkflowService(generatedRPSRepo)
+	rpsRevisionService := services.NewRPSRevisionService(generatedRPSRepo)
+	rpsCommentService := services.NewRPSCommentService(rpsCommentRepo, generatedRPSRepo, userRepo)
 	auditLogService := services.NewAuditLogService(auditLogRepo)
-	aiService := services.NewAIService(aiPromptRepo, aiGenerationRepo, promptTemplateRepo)
+	promptTemplateService := services.NewPromptTemplateService(promptTemplateRepo)
+	aiCostService := services.NewAICostService(aiBudgetRepo, programRepo, userRepo, aiPromptRepo)
+	aiAnalyticsService := services.NewAIAnalyticsService(aiPromptRepo)
+	generationEvents := services.NewGenerationEvents()
+	aiService := services.NewAIService(aiPromptRepo, aiGenerationRepo, promptTemplateRepo, experimentRepo, generationEvents)
+	experimentService := services.NewExperimentService(experimentRepo, promptTemplateRepo, aiPromptRepo, aiGenerationRepo, generatedRPSRepo, aiService)
+	workerConfig := config.GetWorkerConfig()
+	generationService := services.NewGenerationService(generatedRPSRepo, aiService, templateVersionService, courseService, aiCostService, generationEvents, workerConfig)
+	generationWorker := services.NewGenerationWorker(generatedRPSRepo, generationService, workerConfig)
 	exportService := services.NewExportService()
+	accessService := services.NewAccessService(courseRepo, templateRepo, templateVersionRepo, generatedRPSRepo, learningOutcomeRepo, generationBatchRepo)
+	generationBatchService := services.NewGenerationBatchService(generationBatchRepo, courseRepo, templateVersionService, accessService, aiService, aiCostService, generationService)
 
 	// Initialize controllers
+	authController := controllers.NewAuthController(authService)
 	userController := controllers.NewUserController(userService)
 	programController := controllers.NewProgramController(programService)
 	courseController := controllers.NewCourseController(courseService)
+	learningOutcomeController := controllers.NewLearningOutcomeController(learningOutcomeService)
+	importController := controllers.NewImportController(importService)
 	templateController := controllers.NewTemplateController(templateService)
 	templateVersionController := controllers.NewTemplateVersionController(templateVersionService)
-	generatedRPSController := controllers.NewGeneratedRPSController(generatedRPSService)
+	generatedRPSController := controllers.NewGeneratedRPSController(generatedRPSService, exportService)
+	rpsWorkflowController := controllers.NewRPSWorkflowController(rpsWorkflowService)
+	rpsRevisionController := controllers.NewRPSRevisionController(rpsRevisionService)
+	rpsCommentController := controllers.NewRPSCommentController(rpsCommentService)
 	auditLogController := controllers.NewAuditLogController(auditLogService)
-	aiController := controllers.NewAIController(aiService, generatedRPSService, templateVersionService, courseService)
+	aiController := controllers.NewAIController(aiService, generationService)
+	promptTemplateController := controllers.NewPromptTemplateController(promptTemplateService)
+	experimentController := controllers.NewExperimentController(experimentService)
+	aiCostController := controllers.NewAICostController(aiCostService)
+	aiAnalyticsController := controllers.NewAIAnalyticsController(aiAnalyticsService)
+	generationBatchController := controllers.NewGenerationBatchController(generationBatchService)
+	generationEventController := controllers.NewGenerationEventController(generatedRPSService, generationEvents)
 	exportController := controllers.NewExportController(exportService, generatedRPSService)
 
+	// Initialize middleware
+	authRequired := middleware.AuthRequired(authService)
+	adminOnly := middleware.Authorize(middleware.Policy{Read: middleware.AdminOnly, Write: middleware.AdminOnly})
+
+	// Audit middleware records every mutating request with a before/after diff
+	auditUsers := middleware.Audit(auditLogService, "user", func(id uuid.UUID) (interface{}, error) { return userService.FindByID(id) })
+	auditPrograms := middleware.Audit(auditLogService, "program", func(id uuid.UUID) (interface{}, error) { return programService.FindByID(id) })
+	auditCourses := middleware.Audit(auditLogService, "course", func(id uuid.UUID) (interface{}, error) { return courseService.FindByID(id) })
+	auditLearningOutcomes := middleware.Audit(auditLogService, "learning_outcome", func(id uuid.UUID) (interface{}, error) { return learningOutcomeService.FindByID(id) })
+	auditTemplates := middleware.Audit(auditLogService, "template", func(id uuid.UUID) (interface{}, error) { return templateService.FindByID(id) })
+	auditTemplateVersions := middleware.Audit(auditLogService, "template_version", func(id uuid.UUID) (interface{}, error) { return templateVersionService.FindByID(id) })
+	auditGeneratedRPS := middleware.Audit(auditLogService, "generated_rps", func(id uuid.UUID) (interface{}, error) { return generatedRPSService.FindByID(id) })
+	auditGenerationBatches := middleware.Audit(auditLogService, "generation_batch", func(id uuid.UUID) (interface{}, error) { return generationBatchService.FindByID(id) })
+
 	// API v1 group
 	v1 := r.Group("/api/v1")
 	{
-		// Auth routes (TODO: implement auth controller)
+		// Auth routes
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/login", func(c *gin.Context) {
-				c.JSON(200, gin.H{"message": "Login endpoint - TODO: implement JWT auth"})
-			})
-			auth.POST("/refresh", func(c *gin.Context) {
-				c.JSON(200, gin.H{"message": "Refresh token endpoint - TODO: implement"})
-			})
+			auth.POST("/login", authController.Login)
+			auth.POST("/refresh", authController.Refresh)
+			auth.POST("/logout", authRequired, authController.Logout)
+			auth.GET("/me", authRequired, authController.Me)
 		}
 
-		// Users routes
-		users := v1.Group("/users")
+		// Users routes - admin manages accounts, kaprodi can look up lecturers
+		users := v1.Group("/users", authRequired, middleware.Authorize(middleware.Policy{Read: middleware.Managers, Write: middleware.AdminOnly}), auditUsers)
 		{
 			users.POST("", userController.Create)
 			users.GET("", userController.FindAll)
@@ -73,7 +123,7 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, mongoDB *mongo.Database) {
 		}
 
 		// Programs routes
-		programs := v1.Group("/programs")
+		programs := v1.Group("/programs", authRequired, middleware.Authorize(middleware.Policy{Read: middleware.AllRoles, Write: middleware.AdminOnly}), auditPrograms)
 		{
 			programs.POST("", programController.Create)
 			programs.GET("", programController.FindAll)
@@ -82,75 +132,130 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, mongoDB *mongo.Database) {
 			programs.DELETE("/:id", programController.Delete)
 		}
 
-		// Courses routes
-		courses := v1.Group("/courses")
+		// Courses routes - kaprodi manages courses of their own program
+		courses := v1.Group("/courses", authRequired, middleware.Authorize(middleware.Policy{Read: middleware.AllRoles, Write: middleware.Managers}), auditCourses)
 		{
-			courses.POST("", courseController.Create)
+			courses.POST("", middleware.ScopeBody("program_id", accessService.CanManageProgram), courseController.Create)
 			courses.GET("", courseController.FindAll)
 			courses.GET("/:id", courseController.FindByID)
 			courses.GET("/program/:program_id", courseController.FindByProgramID)
-			courses.PUT("/:id", courseController.Update)
-			courses.DELETE("/:id", courseController.Delete)
+			courses.PUT("/:id", middleware.ScopeParam("id", accessService.CanManageCourse), middleware.ScopeBodyIfSet("program_id", accessService.CanManageProgram), courseController.Update)
+			courses.DELETE("/:id", middleware.ScopeParam("id", accessService.CanManageCourse), courseController.Delete)
+			courses.POST("/:id/lecturers", middleware.ScopeParam("id", accessService.CanManageCourse), courseController.AssignLecturer)
+			courses.DELETE("/:id/lecturers/:user_id", middleware.ScopeParam("id", accessService.CanManageCourse), courseController.UnassignLecturer)
+			courses.GET("/:id/learning-outcomes", courseController.FindLearningOutcomes)
+			courses.PUT("/:id/learning-outcomes", middleware.ScopeParam("id", accessService.CanManageCourse), courseController.SetLearningOutcomes)
+			courses.GET("/:id/prerequisites", courseController.FindPrerequisiteTree)
+			courses.GET("/:id/dependents", courseController.FindDependents)
+			courses.POST("/:id/prerequisites", middleware.ScopeParam("id", accessService.CanManageCourse), courseController.AddPrerequisite)
+			courses.DELETE("/:id/prerequisites/:prerequisite_id", middleware.ScopeParam("id", accessService.CanManageCourse), courseController.RemovePrerequisite)
+		}
+
+		// Learning outcomes (CPL) routes - kaprodi manages the CPL of their own program
+		learningOutcomes := v1.Group("/learning-outcomes", authRequired, middleware.Authorize(middleware.Policy{Read: middleware.AllRoles, Write: middleware.Managers}), auditLearningOutcomes)
+		{
+			learningOutcomes.POST("", middleware.ScopeBody("program_id", accessService.CanManageProgram), learningOutcomeController.Create)
+			learningOutcomes.GET("/:id", learningOutcomeController.FindByID)
+			learningOutcomes.GET("/program/:program_id", learningOutcomeController.FindByProgramID)
+			learningOutcomes.PUT("/:id", middleware.ScopeParam("id", accessService.CanManageLearningOutcome), learningOutcomeController.Update)
+			learningOutcomes.DELETE("/:id", middleware.ScopeParam("id", accessService.CanManageLearningOutcome), learningOutcomeController.Delete)
 		}
 
-		// Templates routes
-		templates := v1.Group("/templates")
+		// Templates routes - kaprodi manages templates of their own program
+		templates := v1.Group("/templates", authRequired, middleware.Authorize(middleware.Policy{Read: middleware.AllRoles, Write: middleware.Managers}), auditTemplates)
 		{
-			templates.POST("", templateController.Create)
+			templates.POST("", middleware.ScopeBody("program_id", accessService.CanManageProgram), templateController.Create)
 			templates.GET("", templateController.FindAll)
 			templates.GET("/:id", templateController.FindByID)
 			templates.GET("/program/:program_id", templateController.FindByProgramID)
 			templates.GET("/program/:program_id/active", templateController.FindActiveByProgramID)
-			templates.PUT("/:id", templateController.Update)
-			templates.DELETE("/:id", templateController.Delete)
+			templates.PUT("/:id", middleware.ScopeParam("id", accessService.CanManageTemplate), middleware.ScopeBodyIfSet("program_id", accessService.CanManageProgram), templateController.Update)
+			templates.DELETE("/:id", middleware.ScopeParam("id", accessService.CanManageTemplate), templateController.Delete)
 
 			// Template versions (nested under templates)
-			templates.POST("/:id/versions", templateVersionController.Create)
+			templates.POST("/:id/versions", middleware.ScopeBody("template_id", accessService.CanManageTemplate), templateVersionController.Create)
 			templates.GET("/:id/versions", templateVersionController.FindByTemplateID)
 			templates.GET("/:id/versions/latest", templateVersionController.FindLatestByTemplateID)
 		}
 
 		// Template versions routes (standalone)
-		templateVersions := v1.Group("/template-versions")
+		templateVersions := v1.Group("/template-versions", authRequired, middleware.Authorize(middleware.Policy{Read: middleware.AllRoles, Write: middleware.Managers}), auditTemplateVersions)
 		{
 			templateVersions.GET("", templateVersionController.FindAll)
 			templateVersions.GET("/:id", templateVersionController.FindByID)
-			templateVersions.PUT("/:id", templateVersionController.Update)
-			templateVersions.DELETE("/:id", templateVersionController.Delete)
+			templateVersions.PUT("/:id", middleware.ScopeParam("id", accessService.CanManageTemplateVersion), templateVersionController.Update)
+			templateVersions.DELETE("/:id", middleware.ScopeParam("id", accessService.CanManageTemplateVersion), templateVersionController.Delete)
 		}
 
-		// Generate routes - AI powered
-		generate := v1.Group("/generate")
+		// Generate routes - AI powered, dosen only for their assigned courses
+		generate := v1.Group("/generate", authRequired, middleware.Authorize(middleware.Policy{Read: middleware.AllRoles, Write: middleware.RPSAuthors}))
 		{
-			generate.POST("", aiController.GenerateRPSAsync)       // Async - returns job_id immediately
-			generate.POST("/sync", aiController.GenerateRPSWithAI) // Sync - waits for result
+			generate.POST("", middleware.ScopeBody("course_id", accessService.CanManageCourse), aiController.GenerateRPSAsync)       // Async - returns job_id immediately
+			generate.POST("/sync", middleware.ScopeBody("course_id", accessService.CanManageCourse), aiController.GenerateRPSWithAI) // Sync - waits for result
 			generate.GET("/:job_id/status", generatedRPSController.FindByID)
+			generate.GET("/:job_id/events", generationEventController.Stream) // SSE, replaces polling /status
+			generate.POST("/:job_id/cancel", middleware.ScopeParam("job_id", accessService.CanManageGeneratedRPS), aiController.CancelJob)
+
+			// Batches - one job per course of a program, semester or course list; access is checked per course
+			batches := generate.Group("/batches", auditGenerationBatches)
+			{
+				batches.POST("", generationBatchController.Create)
+				batches.GET("", generationBatchController.FindAll)
+				batches.GET("/:id", generationBatchController.FindByID)
+				batches.POST("/:id/cancel", middleware.ScopeParam("id", accessService.CanManageGenerationBatch), generationBatchController.Cancel)
+				batches.POST("/:id/retry-failed", middleware.ScopeParam("id", accessService.CanManageGenerationBatch), generationBatchController.RetryFailed)
+			}
 		}
 
 		// Generated RPS routes
-		generated := v1.Group("/generated")
+		generated := v1.Group("/generated", authRequired, middleware.Authorize(middleware.Policy{Read: middleware.AllRoles, Write: middleware.RPSAuthors}), auditGeneratedRPS)
 		{
 			generated.GET("", generatedRPSController.FindAll)
 			generated.GET("/:id", generatedRPSController.FindByID)
 			generated.GET("/:id/export", generatedRPSController.Export)
+			generated.GET("/:id/validation", generatedRPSController.Validation)
+			generated.GET("/:id/audit", auditLogController.FindByTargetID) // who changed this RPS and what changed
 			generated.GET("/course/:course_id", generatedRPSController.FindByCourseID)
 			generated.GET("/status/:status", generatedRPSController.FindByStatus)
-			generated.PUT("/:id", generatedRPSController.Update)
-			generated.PATCH("/:id/status", generatedRPSController.UpdateStatus)
-			generated.DELETE("/:id", generatedRPSController.Delete)
+			generated.PUT("/:id", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), generatedRPSController.Update)
+			generated.PATCH("/:id/status", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), generatedRPSController.UpdateStatus)
+			generated.POST("/:id/sections/:section/regenerate", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), aiController.RegenerateSection)
+			generated.DELETE("/:id", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), generatedRPSController.Delete)
+
+			// Document workflow - the service checks which role may take each transition
+			generated.GET("/:id/workflow", rpsWorkflowController.FindTransitions)
+			generated.POST("/:id/workflow/submit", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), rpsWorkflowController.Submit)
+			generated.POST("/:id/workflow/request-changes", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), rpsWorkflowController.RequestChanges)
+			generated.POST("/:id/workflow/approve", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), rpsWorkflowController.Approve)
+			generated.POST("/:id/workflow/publish", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), rpsWorkflowController.Publish)
+			generated.POST("/:id/workflow/reopen", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), rpsWorkflowController.Reopen)
+
+			// Revisions - every change of the result is kept
+			generated.GET("/:id/revisions", rpsRevisionController.FindAll)
+			generated.GET("/:id/revisions/diff", rpsRevisionController.Diff)
+			generated.GET("/:id/revisions/:number", rpsRevisionController.FindByNumber)
+			generated.POST("/:id/revisions/:number/restore", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), rpsRevisionController.Restore)
+
+			// Review comments anchored to JSON paths of the result
+			generated.GET("/:id/comments", rpsCommentController.FindThreads)
+			generated.POST("/:id/comments", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), rpsCommentController.Create)
+			generated.POST("/:id/comments/:comment_id/replies", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), rpsCommentController.Reply)
+			generated.POST("/:id/comments/:comment_id/resolve", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), rpsCommentController.Resolve)
+			generated.POST("/:id/comments/:comment_id/unresolve", middleware.ScopeParam("id", accessService.CanManageGeneratedRPS), rpsCommentController.Unresolve)
 		}
 
 		// Export routes - PDF, HTML, DOCX
-		export := v1.Group("/export")
+		export := v1.Group("/export", authRequired, middleware.Authorize(middleware.Policy{Read: middleware.AllRoles, Write: middleware.AdminOnly}))
 		{
 			export.GET("/formats", exportController.GetExportFormats)
 			export.GET("/:id/pdf", exportController.ExportToPDF)
+			export.GET("/:id/docx", exportController.ExportToDOCX)
 			export.GET("/:id/html", exportController.ExportToHTML)
 			export.GET("/:id/preview", exportController.ExportToHTMLPreview)
 		}
 
 		// Admin routes
-		admin := v1.Group("/admin")
+		admin := v1.Group("/admin", authRequired, adminOnly)
 		{
 			// Audit logs
 			audit := admin.Group("/audit")
@@ -160,25 +265,72 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, mongoDB *mongo.Database) {
 				audit.GET("/user/:user_id", auditLogController.FindByUserID)
 				audit.GET("/action/:action", auditLogController.FindByAction)
 				audit.GET("/date-range", auditLogController.FindByDateRange)
+				audit.GET("/target/:id", auditLogController.FindByTargetID)
 				audit.DELETE("/:id", auditLogController.Delete)
 			}
 
+			// Bulk import of the catalog from CSV/XLSX, dry run unless dry_run=false
+			importGroup := admin.Group("/import")
+			{
+				importGroup.POST("/programs", importController.ImportPrograms)
+				importGroup.POST("/courses", importController.ImportCourses)
+			}
+
 			// AI Admin routes - MongoDB data
 			ai := admin.Group("/ai")
 			{
 				ai.GET("/stats", aiController.GetPromptStats)
+				ai.GET("/analytics", aiAnalyticsController.Analytics)
 				ai.GET("/prompts", aiController.GetAllPrompts)
+				ai.GET("/prompts/date-range", aiController.GetPromptsByDateRange)
+				ai.GET("/prompts/model/:model", aiController.GetPromptsByModel)
+				ai.GET("/prompts/status/:status", aiController.GetPromptsByStatus)
 				ai.GET("/prompts/:id", aiController.GetPromptByID)
 				ai.GET("/prompts/rps/:generated_rps_id", aiController.GetPromptsByGeneratedRPSID)
 				ai.GET("/generations", aiController.GetAllGenerations)
 				ai.GET("/generations/:generated_rps_id", aiController.GetGenerationByRPSID)
+				ai.GET("/costs", aiCostController.Report)
+			}
+
+			// Monthly AI budgets of programs and users
+			budgets := admin.Group("/budgets")
+			{
+				budgets.GET("", aiCostController.FindBudgets)
+				budgets.PUT("/programs/:id", aiCostController.SetProgramBudget)
+				budgets.PUT("/users/:id", aiCostController.SetUserBudget)
+				budgets.DELETE("/:id", aiCostController.DeleteBudget)
+			}
+
+			// Prompt templates rendered by RPS generation - MongoDB data
+			promptTemplates := admin.Group("/prompt-templates")
+			{
+				promptTemplates.POST("", promptTemplateController.Create)
+				promptTemplates.GET("", promptTemplateController.FindAll)
+				promptTemplates.GET("/variables", promptTemplateController.FindVariables)
+				promptTemplates.GET("/:id", promptTemplateController.FindByID)
+				promptTemplates.PUT("/:id", promptTemplateController.Update)
+				promptTemplates.DELETE("/:id", promptTemplateController.Delete)
+			}
+
+			// A/B experiments between prompt template versions and model settings
+			experiments := admin.Group("/experiments")
+			{
+				experiments.POST("", experimentController.Create)
+				experiments.GET("", experimentController.FindAll)
+				experiments.GET("/:id", experimentController.FindByID)
+				experiments.PUT("/:id", experimentController.Update)
+				experiments.DELETE("/:id", experimentController.Delete)
+				experiments.POST("/:id/start", experimentController.Start)
+				experiments.POST("/:id/stop", experimentController.Stop)
+				experiments.GET("/:id/report", experimentController.Report)
 			}
 		}
 
 		// Internal routes (for worker/microservices)
-		internal := v1.Group("/internal")
+		internal := v1.Group("/internal", authRequired, adminOnly)
 		{
 			internal.POST("/complete_generation", generatedRPSController.CompleteGeneration)
 		}
 	}
+	return generationWorker
 }
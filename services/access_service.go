@@ -0,0 +1,176 @@
+package services
+
+import (
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+)
+
+// AccessService decides whether a user may modify a specific resource.
+// Every check returns nil when allowed, helper.ErrForbidden when denied and
+// helper.ErrNotFound when the resource does not exist. Admins are always allowed.
+type AccessService interface {
+	CanManageProgram(user *models.User, programID uuid.UUID) error
+	CanManageCourse(user *models.User, courseID uuid.UUID) error
+	CanManageLearningOutcome(user *models.User, outcomeID uuid.UUID) error
+	CanManageTemplate(user *models.User, templateID uuid.UUID) error
+	CanManageTemplateVersion(user *models.User, versionID uuid.UUID) error
+	CanManageGeneratedRPS(user *models.User, generatedRPSID uuid.UUID) error
+	CanManageGenerationBatch(user *models.User, batchID uuid.UUID) error
+}
+
+type accessService struct {
+	courseRepo          repositories.CourseRepository
+	templateRepo        repositories.TemplateRepository
+	templateVersionRepo repositories.TemplateVersionRepository
+	generatedRPSRepo    repositories.GeneratedRPSRepository
+	outcomeRepo         repositories.LearningOutcomeRepository
+	batchRepo           repositories.GenerationBatchRepository
+}
+
+func NewAccessService(
+	courseRepo repositories.CourseRepository,
+	templateRepo repositories.TemplateRepository,
+	templateVersionRepo repositories.TemplateVersionRepository,
+	generatedRPSRepo repositories.GeneratedRPSRepository,
+	outcomeRepo repositories.LearningOutcomeRepository,
+	batchRepo repositories.GenerationBatchRepository,
+) AccessService {
+	return &accessService{
+		courseRepo:          courseRepo,
+		templateRepo:        templateRepo,
+		templateVersionRepo: templateVersionRepo,
+		generatedRPSRepo:    generatedRPSRepo,
+		outcomeRepo:         outcomeRepo,
+		batchRepo:           batchRepo,
+	}
+}
+
+// CanManageProgram allows kaprodi on their own program
+func (s *accessService) CanManageProgram(user *models.User, programID uuid.UUID) error {
+	if user.Role == models.RoleAdmin {
+		return nil
+	}
+	if user.Role == models.RoleKaprodi && sameProgram(user.ProgramID, &programID) {
+		return nil
+	}
+	return helper.ErrForbidden
+}
+
+// CanManageCourse allows kaprodi on courses of their program and dosen on courses assigned to them
+func (s *accessService) CanManageCourse(user *models.User, courseID uuid.UUID) error {
+	if user.Role == models.RoleAdmin {
+		return nil
+	}
+
+	course, err := s.courseRepo.FindByID(courseID)
+	if err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+
+	switch user.Role {
+	case models.RoleKaprodi:
+		if sameProgram(user.ProgramID, course.ProgramID) {
+			return nil
+		}
+	case models.RoleDosen:
+		assigned, err := s.courseRepo.IsLecturer(course.ID, user.ID)
+		if err != nil {
+			return helper.WrapDatabaseError(err)
+		}
+		if assigned {
+			return nil
+		}
+	}
+	return helper.ErrForbidden
+}
+
+// CanManageLearningOutcome follows the rule of the owning program
+func (s *accessService) CanManageLearningOutcome(user *models.User, outcomeID uuid.UUID) error {
+	if user.Role == models.RoleAdmin {
+		return nil
+	}
+
+	outcome, err := s.outcomeRepo.FindByID(outcomeID)
+	if err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+
+	return s.CanManageProgram(user, outcome.ProgramID)
+}
+
+// CanManageTemplate allows kaprodi on templates of their program
+func (s *accessService) CanManageTemplate(user *models.User, templateID uuid.UUID) error {
+	if user.Role == models.RoleAdmin {
+		return nil
+	}
+
+	template, err := s.templateRepo.FindByID(templateID)
+	if err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+
+	if user.Role == models.RoleKaprodi && sameProgram(user.ProgramID, template.ProgramID) {
+		return nil
+	}
+	return helper.ErrForbidden
+}
+
+// CanManageTemplateVersion follows the rule of the owning template
+func (s *accessService) CanManageTemplateVersion(user *models.User, versionID uuid.UUID) error {
+	if user.Role == models.RoleAdmin {
+		return nil
+	}
+
+	version, err := s.templateVersionRepo.FindByID(versionID)
+	if err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+	if version.TemplateID == nil {
+		return helper.ErrForbidden
+	}
+
+	return s.CanManageTemplate(user, *version.TemplateID)
+}
+
+// CanManageGeneratedRPS follows the rule of the RPS course
+func (s *accessService) CanManageGeneratedRPS(user *models.User, generatedRPSID uuid.UUID) error {
+	if user.Role == models.RoleAdmin {
+		return nil
+	}
+
+	rps, err := s.generatedRPSRepo.FindByID(generatedRPSID)
+	if err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+	if rps.CourseID == nil {
+		return helper.ErrForbidden
+	}
+
+	return s.CanManageCourse(user, *rps.CourseID)
+}
+
+// CanManageGenerationBatch allows the user who started the batch and kaprodi of the batch program
+func (s *accessService) CanManageGenerationBatch(user *models.User, batchID uuid.UUID) error {
+	if user.Role == models.RoleAdmin {
+		return nil
+	}
+
+	batch, err := s.batchRepo.FindByID(batchID)
+	if err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+
+	if batch.CreatedBy != nil && *batch.CreatedBy == user.ID {
+		return nil
+	}
+	if user.Role == models.RoleKaprodi && sameProgram(user.ProgramID, batch.ProgramID) {
+		return nil
+	}
+	return helper.ErrForbidden
+}
+
+func sameProgram(a, b *uuid.UUID) bool {
+	return a != nil && b != nil && *a != uuid.Nil && *a == *b
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	mongoRepo "github.com/syrlramadhan/dokumentasi-rps-api/repositories/mongo"
+)
+
+var (
+	ErrInvalidAnalyticsInterval = fmt.Errorf("%w: interval accepts hour and day", helper.ErrInvalidInput)
+	ErrInvalidAnalyticsRange    = fmt.Errorf("%w: from must be before to", helper.ErrInvalidInput)
+)
+
+// Default and longest range of each interval, keeping the series a few hundred buckets long
+var analyticsRanges = map[string]struct {
+	step, window, limit time.Duration
+}{
+	mongoRepo.AnalyticsHourly: {time.Hour, 24 * time.Hour, 31 * 24 * time.Hour},
+	mongoRepo.AnalyticsDaily:  {24 * time.Hour, 30 * 24 * time.Hour, 366 * 24 * time.Hour},
+}
+
+// AIAnalyticsService reports the AI traffic over time from the prompt logs
+type AIAnalyticsService interface {
+	Analytics(ctx context.Context, query dto.AIAnalyticsQuery) (*dto.AIAnalyticsResponse, error)
+}
+
+type aiAnalyticsService struct {
+	aiPromptRepo mongoRepo.AIPromptRepository
+}
+
+func NewAIAnalyticsService(aiPromptRepo mongoRepo.AIPromptRepository) AIAnalyticsService {
+	return &aiAnalyticsService{aiPromptRepo: aiPromptRepo}
+}
+
+func (s *aiAnalyticsService) Analytics(ctx context.Context, query dto.AIAnalyticsQuery) (*dto.AIAnalyticsResponse, error) {
+	interval := query.Interval
+	if interval == "" {
+		interval = mongoRepo.AnalyticsDaily
+	}
+	bounds, ok := analyticsRanges[interval]
+	if !ok {
+		return nil, ErrInvalidAnalyticsInterval
+	}
+
+	to := time.Now().UTC()
+	if query.To != nil {
+		to = query.To.UTC()
+	}
+	from := to.Add(-bounds.window)
+	if query.From != nil {
+		from = query.From.UTC()
+	}
+	// Start at a bucket boundary so the first bucket is complete
+	from = from.Truncate(bounds.step)
+	if !from.Before(to) {
+		return nil, ErrInvalidAnalyticsRange
+	}
+	if to.Sub(from) > bounds.limit {
+		return nil, fmt.Errorf("%w: from and to are at most %d days apart for interval %s", helper.ErrInvalidInput, int(bounds.limit/(24*time.Hour)), interval)
+	}
+
+	filter := mongoRepo.AnalyticsFilter{Model: query.Model, From: from, To: to}
+	if query.CourseID != nil {
+		filter.CourseID = query.CourseID.String()
+	}
+	if query.ProgramID != nil {
+		filter.ProgramID = query.ProgramID.String()
+	}
+
+	result, err := s.aiPromptRepo.Analytics(ctx, filter, interval)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", helper.ErrDatabaseOperation, err)
+	}
+
+	failures := map[time.Time]map[string]int64{}
+	for _, failure := range result.Failures {
+		bucket := failure.Bucket.UTC()
+		if failures[bucket] == nil {
+			failures[bucket] = map[string]int64{}
+		}
+		failures[bucket][failure.Class] = failure.Count
+	}
+	stats := make(map[time.Time]mongoRepo.AnalyticsStats, len(result.Series))
+	for _, bucket := range result.Series {
+		stats[bucket.Bucket.UTC()] = bucket
+	}
+
+	response := &dto.AIAnalyticsResponse{
+		Interval:  interval,
+		From:      from,
+		To:        to,
+		Model:     query.Model,
+		CourseID:  query.CourseID,
+		ProgramID: query.ProgramID,
+		Totals:    analyticsPoint(mongoRepo.AnalyticsStats{}, nil),
+		Series:    []dto.AIAnalyticsPoint{},
+	}
+	if len(result.Totals) > 0 {
+		totals := map[string]int64{}
+		for _, failure := range result.FailureTotals {
+			totals[failure.Class] = failure.Count
+		}
+		response.Totals = analyticsPoint(result.Totals[0], totals)
+	}
+
+	// Buckets without prompts are missing from the aggregation; fill them with zeros
+	for bucket := from; bucket.Before(to); bucket = bucket.Add(bounds.step) {
+		start := bucket
+		point := analyticsPoint(stats[start], failures[start])
+		point.Bucket = &start
+		response.Series = append(response.Series, point)
+	}
+	return response, nil
+}
+
+func analyticsPoint(stats mongoRepo.AnalyticsStats, failures map[string]int64) dto.AIAnalyticsPoint {
+	if failures == nil {
+		failures = map[string]int64{}
+	}
+	point := dto.AIAnalyticsPoint{
+		Requests:        stats.Requests,
+		Succeeded:       stats.Succeeded,
+		Failed:          stats.Failed,
+		Cancelled:       stats.Cancelled,
+		FailuresByClass: failures,
+		LatencyMs: dto.AILatency{
+			P50: stats.LatencyP50Ms,
+			P95: stats.LatencyP95Ms,
+			P99: stats.LatencyP99Ms,
+			Avg: stats.AvgLatencyMs,
+		},
+		PromptTokens:     stats.PromptTokens,
+		CompletionTokens: stats.CompletionTokens,
+		TotalTokens:      stats.TotalTokens,
+		Cost:             stats.Cost,
+	}
+	if finished := stats.Succeeded + stats.Failed; finished > 0 {
+		point.FailureRate = float64(stats.Failed) / float64(finished)
+	}
+	return point
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+	mongoRepo "github.com/syrlramadhan/dokumentasi-rps-api/repositories/mongo"
+)
+
+var (
+	ErrBudgetExceeded        = errors.New("monthly AI budget exceeded")
+	ErrBudgetNotFound        = fmt.Errorf("AI budget %w", helper.ErrNotFound)
+	ErrBudgetProgramNotFound = fmt.Errorf("program %w", helper.ErrNotFound)
+	ErrBudgetUserNotFound    = fmt.Errorf("user %w", helper.ErrNotFound)
+	ErrInvalidCostGroup      = fmt.Errorf("%w: group_by accepts program, user, model and month", helper.ErrInvalidInput)
+)
+
+// Budget scopes
+const (
+	BudgetScopeProgram = "program"
+	BudgetScopeUser    = "user"
+)
+
+// CostOwner is the program and user the AI calls of a generation are charged to
+type CostOwner struct {
+	ProgramID *uuid.UUID // nil for courses outside a program
+	UserID    *uuid.UUID // nil for jobs without a requester
+}
+
+// ids returns the owner IDs as recorded on the AI logs, empty when unset
+func (o CostOwner) ids() (programID, userID string) {
+	if o.ProgramID != nil {
+		programID = o.ProgramID.String()
+	}
+	if o.UserID != nil {
+		userID = o.UserID.String()
+	}
+	return programID, userID
+}
+
+// AICostService enforces the monthly AI budgets of programs and users and
+// reports the recorded spend
+type AICostService interface {
+	CheckBudget(ctx context.Context, owner CostOwner) error
+	FindBudgets(ctx context.Context) ([]dto.AIBudgetResponse, error)
+	SetProgramBudget(ctx context.Context, programID uuid.UUID, req *dto.SetAIBudgetRequest, updatedBy *uuid.UUID) (*dto.AIBudgetResponse, error)
+	SetUserBudget(ctx context.Context, userID uuid.UUID, req *dto.SetAIBudgetRequest, updatedBy *uuid.UUID) (*dto.AIBudgetResponse, error)
+	DeleteBudget(id uuid.UUID) error
+	Report(ctx context.Context, query dto.AICostReportQuery) (*dto.AICostReport, error)
+}
+
+type aiCostService struct {
+	repo         repositories.AIBudgetRepository
+	programRepo  repositories.ProgramRepository
+	userRepo     repositories.UserRepository
+	aiPromptRepo mongoRepo.AIPromptRepository
+}
+
+func NewAICostService(
+	repo repositories.AIBudgetRepository,
+	programRepo repositories.ProgramRepository,
+	userRepo repositories.UserRepository,
+	aiPromptRepo mongoRepo.AIPromptRepository,
+) AICostService {
+	return &aiCostService{
+		repo:         repo,
+		programRepo:  programRepo,
+		userRepo:     userRepo,
+		aiPromptRepo: aiPromptRepo,
+	}
+}
+
+// CheckBudget fails with ErrBudgetExceeded once the program or the user has
+// spent its whole budget for the current month
+func (s *aiCostService) CheckBudget(ctx context.Context, owner CostOwner) error {
+	if err := s.checkBudget(ctx, s.repo.FindByProgramID, owner.ProgramID); err != nil {
+		return err
+	}
+	return s.checkBudget(ctx, s.repo.FindByUserID, owner.UserID)
+}
+
+// checkBudget checks the budget find returns for id; no budget means no limit
+func (s *aiCostService) checkBudget(ctx context.Context, find func(uuid.UUID) (*models.AIBudget, error), id *uuid.UUID) error {
+	if id == nil {
+		return nil
+	}
+
+	budget, err := find(*id)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil
+		}
+		return helper.WrapDatabaseError(err)
+	}
+	return s.checkLimit(ctx, budget)
+}
+
+func (s *aiCostService) checkLimit(ctx context.Context, budget *models.AIBudget) error {
+	spent, err := s.monthSpend(ctx, budget)
+	if err != nil {
+		return err
+	}
+	if spent < budget.MonthlyLimit {
+		return nil
+	}
+
+	owner := "this user"
+	switch {
+	case budget.Program != nil:
+		owner = "program " + budget.Program.Code
+	case budget.User != nil:
+		owner = "user " + budget.User.Username
+	}
+	return fmt.Errorf("%w: %s has spent $%.2f of its $%.2f budget for %s",
+		ErrBudgetExceeded, owner, spent, budget.MonthlyLimit, time.Now().UTC().Format("2006-01"))
+}
+
+func (s *aiCostService) FindBudgets(ctx context.Context) ([]dto.AIBudgetResponse, error) {
+	budgets, err := s.repo.FindAll()
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	result := make([]dto.AIBudgetResponse, len(budgets))
+	for i := range budgets {
+		response, err := s.toBudgetResponse(ctx, &budgets[i])
+		if err != nil {
+			return nil, err
+		}
+		result[i] = *response
+	}
+	return result, nil
+}
+
+func (s *aiCostService) SetProgramBudget(ctx context.Context, programID uuid.UUID, req *dto.SetAIBudgetRequest, updatedBy *uuid.UUID) (*dto.AIBudgetResponse, error) {
+	if _, err := s.programRepo.FindByID(programID); err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, ErrBudgetProgramNotFound
+		}
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	budget, err := s.repo.FindByProgramID(programID)
+	if err != nil && !helper.IsNotFoundError(err) {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if budget == nil {
+		budget = &models.AIBudget{ID: uuid.New(), ProgramID: &programID}
+	}
+	return s.saveBudget(ctx, budget, req, updatedBy)
+}
+
+func (s *aiCostService) SetUserBudget(ctx context.Context, userID uuid.UUID, req *dto.SetAIBudgetRequest, updatedBy *uuid.UUID) (*dto.AIBudgetResponse, error) {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, ErrBudgetUserNotFound
+		}
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	budget, err := s.repo.FindByUserID(userID)
+	if err != nil && !helper.IsNotFoundError(err) {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if budget == nil {
+		budget = &models.AIBudget{ID: uuid.New(), UserID: &userID}
+	}
+	return s.saveBudget(ctx, budget, req, updatedBy)
+}
+
+func (s *aiCostService) saveBudget(ctx context.Context, budget *models.AIBudget, req *dto.SetAIBudgetRequest, updatedBy *uuid.UUID) (*dto.AIBudgetResponse, error) {
+	budget.MonthlyLimit = *req.MonthlyLimit
+	budget.UpdatedBy = updatedBy
+	if err := s.repo.Save(budget); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	saved, err := s.repo.FindByID(budget.ID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	return s.toBudgetResponse(ctx, saved)
+}
+
+func (s *aiCostService) DeleteBudget(id uuid.UUID) error {
+	if _, err := s.repo.FindByID(id); err != nil {
+		if helper.IsNotFoundError(err) {
+			return ErrBudgetNotFound
+		}
+		return helper.WrapDatabaseError(err)
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+	return nil
+}
+
+// Report groups the cost of every recorded AI call, section regenerations and
+// failed attempts included, by the requested keys
+func (s *aiCostService) Report(ctx context.Context, query dto.AICostReportQuery) (*dto.AICostReport, error) {
+	groupBy := query.GroupBy
+	if len(groupBy) == 0 {
+		groupBy = []string{mongoRepo.CostByMonth}
+	}
+	for _, key := range groupBy {
+		if !slices.Contains([]string{mongoRepo.CostByProgram, mongoRepo.CostByUser, mongoRepo.CostByModel, mongoRepo.CostByMonth}, key) {
+			return nil, ErrInvalidCostGroup
+		}
+	}
+
+	filter := mongoRepo.CostFilter{}
+	if query.ProgramID != nil {
+		filter.ProgramID = query.ProgramID.String()
+	}
+	if query.UserID != nil {
+		filter.RequestedBy = query.UserID.String()
+	}
+	if query.From != nil {
+		filter.From = *query.From
+	}
+	if query.To != nil {
+		filter.To = *query.To
+	}
+
+	groups, err := s.aiPromptRepo.CostReport(ctx, filter, groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", helper.ErrDatabaseOperation, err)
+	}
+
+	programCodes, usernames, err := s.ownerNames(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.AICostReport{GroupBy: groupBy, From: query.From, To: query.To, Rows: make([]dto.AICostReportRow, len(groups))}
+	for i, group := range groups {
+		report.Rows[i] = dto.AICostReportRow{
+			ProgramID:        group.ProgramID,
+			ProgramCode:      programCodes[group.ProgramID],
+			UserID:           group.RequestedBy,
+			Username:         usernames[group.RequestedBy],
+			Model:            group.Model,
+			Month:            group.Month,
+			Calls:            group.Calls,
+			PromptTokens:     group.PromptTokens,
+			CompletionTokens: group.CompletionTokens,
+			TotalTokens:      group.TotalTokens,
+			Cost:             group.Cost,
+		}
+		report.TotalCost += group.Cost
+	}
+	return report, nil
+}
+
+// ownerNames looks up the program codes and usernames of the report groups
+func (s *aiCostService) ownerNames(groups []mongoRepo.CostGroup) (map[string]string, map[string]string, error) {
+	programCodes := map[string]string{}
+	usernames := map[string]string{}
+
+	var userIDs []uuid.UUID
+	needPrograms := false
+	for _, group := range groups {
+		if id, err := uuid.Parse(group.RequestedBy); err == nil {
+			userIDs = append(userIDs, id)
+		}
+		needPrograms = needPrograms || group.ProgramID != ""
+	}
+
+	if needPrograms {
+		programs, err := s.programRepo.FindAll()
+		if err != nil {
+			return nil, nil, helper.WrapDatabaseError(err)
+		}
+		for _, program := range programs {
+			programCodes[program.ID.String()] = program.Code
+		}
+	}
+	if len(userIDs) > 0 {
+		users, err := s.userRepo.FindByIDs(userIDs)
+		if err != nil {
+			return nil, nil, helper.WrapDatabaseError(err)
+		}
+		for _, user := range users {
+			usernames[user.ID.String()] = user.Username
+		}
+	}
+	return programCodes, usernames, nil
+}
+
+// monthSpend is what the owner of budget has spent in the current month
+func (s *aiCostService) monthSpend(ctx context.Context, budget *models.AIBudget) (float64, error) {
+	now := time.Now().UTC()
+	filter := mongoRepo.CostFilter{From: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)}
+	if budget.ProgramID != nil {
+		filter.ProgramID = budget.ProgramID.String()
+	}
+	if budget.UserID != nil {
+		filter.RequestedBy = budget.UserID.String()
+	}
+
+	spent, err := s.aiPromptRepo.TotalCost(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", helper.ErrDatabaseOperation, err)
+	}
+	return spent, nil
+}
+
+func (s *aiCostService) toBudgetResponse(ctx context.Context, budget *models.AIBudget) (*dto.AIBudgetResponse, error) {
+	spent, err := s.monthSpend(ctx, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.AIBudgetResponse{
+		ID:           budget.ID,
+		Scope:        BudgetScopeUser,
+		ProgramID:    budget.ProgramID,
+		UserID:       budget.UserID,
+		MonthlyLimit: budget.MonthlyLimit,
+		Month:        time.Now().UTC().Format("2006-01"),
+		Spent:        spent,
+		Remaining:    max(budget.MonthlyLimit-spent, 0),
+		UpdatedAt:    budget.UpdatedAt,
+	}
+	if budget.ProgramID != nil {
+		response.Scope = BudgetScopeProgram
+		response.Program = helper.ToProgramResponse(budget.Program)
+	}
+	if budget.User != nil {
+		response.Username = budget.User.Username
+	}
+	return response, nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/config"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+)
+
+// CheckModelParams validates the model parameters of a generation request against
+// the selected provider's limits and the admin-configured ranges
+func (s *aiService) CheckModelParams(options dto.GenerateRPSOptions) error {
+	if !hasModelParams(options) {
+		return nil
+	}
+
+	name := cmp.Or(options.Provider, s.defaultProvider)
+	provider, err := s.providerFor(name)
+	if err != nil {
+		return fmt.Errorf("%w: %v", helper.ErrInvalidInput, err)
+	}
+	return checkModelParams(provider, s.params.AllowedModels[name], s.params, options)
+}
+
+// hasModelParams reports whether the request sets any model parameter itself
+func hasModelParams(options dto.GenerateRPSOptions) bool {
+	return options.Model != "" || options.Temperature != nil || options.TopP != nil ||
+		options.TopK != nil || options.MaxTokens != nil
+}
+
+// checkModelParams collects every parameter outside the range allowed by both
+// the provider and the configuration; allowedModels are those of the provider
+func checkModelParams(provider LLMProvider, allowedModels []string, cfg config.AIParamConfig, options dto.GenerateRPSOptions) error {
+	limits := provider.Limits()
+	var problems []string
+
+	if options.Model != "" && options.Model != provider.Model() && !slices.Contains(allowedModels, options.Model) {
+		problems = append(problems, fmt.Sprintf("model %q is not allowed for %s", options.Model, provider.Name()))
+	}
+	if t := options.Temperature; t != nil {
+		maxTemperature := min(cfg.MaxTemperature, limits.MaxTemperature)
+		if *t < cfg.MinTemperature || *t > maxTemperature {
+			problems = append(problems, fmt.Sprintf("temperature must be between %g and %g", cfg.MinTemperature, maxTemperature))
+		}
+	}
+	if p := options.TopP; p != nil {
+		if *p <= 0 || *p < cfg.MinTopP || *p > min(cfg.MaxTopP, 1) {
+			problems = append(problems, fmt.Sprintf("top_p must be above 0, at least %g and at most %g", cfg.MinTopP, min(cfg.MaxTopP, 1)))
+		}
+	}
+	if k := options.TopK; k != nil {
+		switch maxTopK := min(cfg.MaxTopK, limits.MaxTopK); {
+		case limits.MaxTopK == 0:
+			problems = append(problems, fmt.Sprintf("top_k is not supported by %s", provider.Name()))
+		case *k < 1 || *k > maxTopK:
+			problems = append(problems, fmt.Sprintf("top_k must be between 1 and %d", maxTopK))
+		}
+	}
+	if n := options.MaxTokens; n != nil {
+		maxTokens := min(cfg.MaxTokens, limits.MaxTokens)
+		if *n < 1 || *n > maxTokens {
+			problems = append(problems, fmt.Sprintf("max_tokens must be between 1 and %d", maxTokens))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", helper.ErrInvalidInput, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// applyModelParams overrides the request with the parameters the caller set
+func applyModelParams(llmReq *LLMRequest, options dto.GenerateRPSOptions) {
+	if options.Model != "" {
+		llmReq.Model = options.Model
+	}
+	if options.Temperature != nil {
+		llmReq.Temperature = *options.Temperature
+	}
+	if options.TopP != nil {
+		llmReq.TopP = *options.TopP
+	}
+	if options.TopK != nil {
+		llmReq.TopK = *options.TopK
+	}
+	if options.MaxTokens != nil {
+		llmReq.MaxTokens = *options.MaxTokens
+	}
+}
+
+// defaultModelParams returns the built-in parameters of a request, narrowed to the
+// provider limits and the configured ranges so they pass checkModelParams
+func defaultModelParams(provider LLMProvider, cfg config.AIParamConfig) LLMRequest {
+	limits := provider.Limits()
+	return LLMRequest{
+		Model:       provider.Model(),
+		Temperature: min(max(0.7, cfg.MinTemperature), cfg.MaxTemperature, limits.MaxTemperature),
+		TopP:        min(max(0.95, cfg.MinTopP), cfg.MaxTopP, 1),
+		TopK:        min(40, cfg.MaxTopK, limits.MaxTopK),
+		MaxTokens:   min(8192, cfg.MaxTokens, limits.MaxTokens),
+	}
+}
+
+// checkRequestParams validates the parameters a request is sent with, once the
+// template, experiment and caller settings are merged into the defaults
+func (s *aiService) checkRequestParams(name string, provider LLMProvider, llmReq LLMRequest) error {
+	params := dto.GenerateRPSOptions{
+		Model:       llmReq.Model,
+		Temperature: &llmReq.Temperature,
+		TopP:        &llmReq.TopP,
+		MaxTokens:   &llmReq.MaxTokens,
+	}
+	// Zero leaves top_k unset, see dropUnsupportedParams
+	if llmReq.TopK != 0 {
+		params.TopK = &llmReq.TopK
+	}
+	return checkModelParams(provider, s.params.AllowedModels[name], s.params, params)
+}
+
+// dropUnsupportedParams clears parameters the provider does not send, so the
+// recorded request matches what the provider received
+func dropUnsupportedParams(provider LLMProvider, llmReq *LLMRequest) {
+	if provider.Limits().MaxTopK == 0 {
+		llmReq.TopK = 0
+	}
+}
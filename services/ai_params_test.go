@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/config"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+)
+
+// limitedProvider is a fake provider with custom limits and name
+type limitedProvider struct {
+	LLMProvider
+	name   string
+	limits ModelLimits
+}
+
+func (p limitedProvider) Name() string        { return p.name }
+func (p limitedProvider) Limits() ModelLimits { return p.limits }
+
+func float64Ptr(v float64) *float64 { return &v }
+
+func TestCheckModelParams(t *testing.T) {
+	cfg := config.AIParamConfig{
+		MinTemperature: 0.1,
+		MaxTemperature: 1.5,
+		MinTopP:        0.5,
+		MaxTopP:        1,
+		MaxTopK:        40,
+		MaxTokens:      8192,
+	}
+	gemini := limitedProvider{
+		LLMProvider: newFakeProvider("gemini-2.5-flash"),
+		name:        "gemini",
+		limits:      ModelLimits{MaxTemperature: 2, MaxTopK: 64, MaxTokens: 65536},
+	}
+	openai := limitedProvider{
+		LLMProvider: newFakeProvider("gpt-4o-mini"),
+		name:        "openai",
+		limits:      ModelLimits{MaxTemperature: 1, MaxTokens: 4096},
+	}
+
+	tests := []struct {
+		name     string
+		provider LLMProvider
+		allowed  []string
+		options  dto.GenerateRPSOptions
+		want     []string // fragments of the error; none when the options are valid
+	}{
+		{name: "no parameters", provider: gemini},
+		{name: "configured model", provider: gemini, options: dto.GenerateRPSOptions{Model: "gemini-2.5-flash"}},
+		{name: "allowed model", provider: gemini, allowed: []string{"gemini-2.5-pro"}, options: dto.GenerateRPSOptions{Model: "gemini-2.5-pro"}},
+		{
+			name:     "model allowed for another provider",
+			provider: openai,
+			options:  dto.GenerateRPSOptions{Model: "gemini-2.5-pro"},
+			want:     []string{`model "gemini-2.5-pro" is not allowed for openai`},
+		},
+		{
+			name:     "parameters within range",
+			provider: gemini,
+			options:  dto.GenerateRPSOptions{Temperature: float64Ptr(1.5), TopP: float64Ptr(0.5), TopK: intPtr(40), MaxTokens: intPtr(8192)},
+		},
+		{
+			name:     "temperature capped by the configuration",
+			provider: gemini,
+			options:  dto.GenerateRPSOptions{Temperature: float64Ptr(1.8)},
+			want:     []string{"temperature must be between 0.1 and 1.5"},
+		},
+		{
+			name:     "temperature capped by the provider",
+			provider: openai,
+			options:  dto.GenerateRPSOptions{Temperature: float64Ptr(1.2)},
+			want:     []string{"temperature must be between 0.1 and 1"},
+		},
+		{
+			name:     "temperature below the minimum",
+			provider: gemini,
+			options:  dto.GenerateRPSOptions{Temperature: float64Ptr(0)},
+			want:     []string{"temperature must be between 0.1 and 1.5"},
+		},
+		{
+			name:     "top_p out of range",
+			provider: gemini,
+			options:  dto.GenerateRPSOptions{TopP: float64Ptr(0.4)},
+			want:     []string{"top_p must be above 0, at least 0.5 and at most 1"},
+		},
+		{
+			name:     "top_k unsupported",
+			provider: openai,
+			options:  dto.GenerateRPSOptions{TopK: intPtr(10)},
+			want:     []string{"top_k is not supported by openai"},
+		},
+		{
+			name:     "top_k out of range",
+			provider: gemini,
+			options:  dto.GenerateRPSOptions{TopK: intPtr(0)},
+			want:     []string{"top_k must be between 1 and 40"},
+		},
+		{
+			name:     "max_tokens capped by the provider",
+			provider: openai,
+			options:  dto.GenerateRPSOptions{MaxTokens: intPtr(8192)},
+			want:     []string{"max_tokens must be between 1 and 4096"},
+		},
+		{
+			name:     "every problem is reported",
+			provider: openai,
+			options:  dto.GenerateRPSOptions{Model: "gpt-5", Temperature: float64Ptr(3), TopK: intPtr(5), MaxTokens: intPtr(0)},
+			want: []string{
+				`model "gpt-5" is not allowed for openai`,
+				"temperature must be between 0.1 and 1",
+				"top_k is not supported by openai",
+				"max_tokens must be between 1 and 4096",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkModelParams(tt.provider, tt.allowed, cfg, tt.options)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("checkModelParams() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, helper.ErrInvalidInput) {
+				t.Fatalf("checkModelParams() = %v, want ErrInvalidInput", err)
+			}
+			if problems := strings.Count(err.Error(), ";") + 1; problems != len(tt.want) {
+				t.Fatalf("checkModelParams() = %v, want %d problems", err, len(tt.want))
+			}
+			for _, fragment := range tt.want {
+				if !strings.Contains(err.Error(), fragment) {
+					t.Fatalf("checkModelParams() = %v, want it to mention %q", err, fragment)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckModelParamsUsesAllowedModelsOfProvider(t *testing.T) {
+	s := &aiService{
+		providers: map[string]LLMProvider{
+			"gemini": limitedProvider{LLMProvider: newFakeProvider("gemini-2.5-flash"), name: "gemini", limits: ModelLimits{MaxTemperature: 2, MaxTopK: 64, MaxTokens: 65536}},
+			"openai": limitedProvider{LLMProvider: newFakeProvider("gpt-4o-mini"), name: "openai", limits: ModelLimits{MaxTemperature: 2, MaxTokens: 4096}},
+		},
+		defaultProvider: "gemini",
+		params: config.AIParamConfig{
+			AllowedModels: map[string][]string{
+				"gemini": {"gemini-2.5-pro"},
+				"openai": {"gpt-4o"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		options dto.GenerateRPSOptions
+		wantErr bool
+	}{
+		{"default provider model", dto.GenerateRPSOptions{Model: "gemini-2.5-pro"}, false},
+		{"selected provider model", dto.GenerateRPSOptions{Provider: "openai", Model: "gpt-4o"}, false},
+		{"model of the default provider on another", dto.GenerateRPSOptions{Provider: "openai", Model: "gemini-2.5-pro"}, true},
+		{"model of another provider on the default", dto.GenerateRPSOptions{Model: "gpt-4o"}, true},
+		{"unconfigured provider", dto.GenerateRPSOptions{Provider: "fake", Model: "fake-rps-v1"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.CheckModelParams(tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckModelParams() = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, helper.ErrInvalidInput) {
+				t.Fatalf("CheckModelParams() = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	models "github.com/syrlramadhan/dokumentasi-rps-api/models/mongo"
+)
+
+// SectionRequest describes one RPS section to regenerate against the current result.
+// WeekFrom/WeekTo narrow rencana_mingguan to a range of weeks; zero means the whole section.
+type SectionRequest struct {
+	GeneratedRPSID string
+	Section        string
+	WeekFrom       int
+	WeekTo         int
+	Instructions   string
+	Current        *dto.RPSStructuredOutput
+	CourseData     map[string]interface{}
+	TemplateDef    map[string]interface{}
+	Options        dto.GenerateRPSOptions
+	Owner          CostOwner
+}
+
+// GenerateSection asks the provider for a single section only, using the matching
+// sub-schema of GetRPSJSONSchema. The returned Result holds just that section;
+// every attempt is stored as an AIPrompt of the same generated RPS.
+func (s *aiService) GenerateSection(ctx context.Context, req SectionRequest) (*dto.AIGenerationResult, error) {
+	provider, err := s.providerFor(req.Options.Provider)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("✅ Regenerating section %s of %s with provider: %s, model: %s", req.Section, req.GeneratedRPSID, provider.Name(), cmp.Or(req.Options.Model, provider.Model()))
+
+	schema, err := s.sectionSchema(req.Section)
+	if err != nil {
+		return nil, err
+	}
+
+	systemPrompt := s.buildSystemPrompt()
+	userPrompt := s.buildSectionPrompt(req)
+
+	// Sections get a lower output cap by default; the options carry the generation's settings
+	llmReq := defaultModelParams(provider, s.params)
+	llmReq.MaxTokens = min(llmReq.MaxTokens, 4096)
+	llmReq.SystemPrompt = systemPrompt
+	llmReq.UserPrompt = userPrompt
+	llmReq.Schema = schema
+	llmReq.CourseData = req.CourseData
+	applyModelParams(&llmReq, req.Options)
+	dropUnsupportedParams(provider, &llmReq)
+
+	if err := s.checkRequestParams(cmp.Or(req.Options.Provider, s.defaultProvider), provider, llmReq); err != nil {
+		return nil, err
+	}
+
+	basePrompt := models.AIPrompt{
+		GeneratedRPSID: req.GeneratedRPSID,
+		CourseID:       fmt.Sprintf("%v", req.CourseData["id"]),
+		TemplateID:     fmt.Sprintf("%v", req.TemplateDef["id"]),
+		Section:        req.Section,
+		WeekFrom:       req.WeekFrom,
+		WeekTo:         req.WeekTo,
+		SystemPrompt:   systemPrompt,
+		UserPrompt:     userPrompt,
+		FullPrompt:     fmt.Sprintf("System: %s\n\nUser: %s", systemPrompt, userPrompt),
+		Provider:       provider.Name(),
+		Model:          llmReq.Model,
+		Temperature:    llmReq.Temperature,
+		MaxTokens:      llmReq.MaxTokens,
+		TopP:           llmReq.TopP,
+		TopK:           llmReq.TopK,
+		ResponseFormat: "json_object",
+		CourseData:     req.CourseData,
+		TemplateData:   req.TemplateDef,
+		Options:        map[string]interface{}{"language": req.Options.Language, "tone": req.Options.Tone, "instructions": req.Instructions},
+		Status:         "pending",
+	}
+	basePrompt.ProgramID, basePrompt.RequestedBy = req.Owner.ids()
+
+	for attemptNumber := 1; ; attemptNumber++ {
+		prompt := basePrompt
+		prompt.AttemptNumber = attemptNumber
+
+		result, attemptErr := s.attemptSection(ctx, provider, &prompt, llmReq, req.Section)
+		if attemptErr == nil {
+			result.AIMetadata["attempts"] = attemptNumber
+			return result, nil
+		}
+
+		s.saveFailedPrompt(ctx, &prompt, attemptNumber, attemptErr)
+
+		if !attemptErr.retryable || attemptNumber >= s.retry.MaxAttempts || ctx.Err() != nil {
+			return nil, attemptErr.err
+		}
+
+		delay := s.retryDelay(attemptNumber, attemptErr.retryAfter)
+		log.Printf("🔁 Section attempt %d/%d failed (%s), retrying in %s", attemptNumber, s.retry.MaxAttempts, attemptErr.message, delay)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// attemptSection makes a single provider call and parses the section out of the response
+func (s *aiService) attemptSection(ctx context.Context, provider LLMProvider, aiPrompt *models.AIPrompt, llmReq LLMRequest, section string) (*dto.AIGenerationResult, *attemptError) {
+	startTime := time.Now()
+	defer func() {
+		aiPrompt.RequestDurationMs = time.Since(startTime).Milliseconds()
+	}()
+
+	llmResp, attemptErr := s.callProvider(ctx, provider, aiPrompt, llmReq)
+	if attemptErr != nil {
+		return nil, attemptErr
+	}
+	aiPrompt.Response = llmResp.Content
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(llmResp.Content), &fields); err != nil {
+		return nil, &attemptError{err: fmt.Errorf("failed to parse section output: %w", err), message: "failed to parse section output", retryable: true, statusCode: llmResp.StatusCode, class: ErrorClassInvalidOutput}
+	}
+	if _, ok := fields[section]; !ok {
+		return nil, &attemptError{err: fmt.Errorf("response is missing section %s", section), message: "response is missing the requested section", retryable: true, statusCode: llmResp.StatusCode, class: ErrorClassInvalidOutput}
+	}
+
+	var partial dto.RPSStructuredOutput
+	if err := json.Unmarshal(fields[section], sectionField(&partial, section)); err != nil {
+		return nil, &attemptError{err: fmt.Errorf("failed to parse section output: %w", err), message: "failed to parse section output", retryable: true, statusCode: llmResp.StatusCode, class: ErrorClassInvalidOutput}
+	}
+
+	if cpl := mappedCPL(llmReq.CourseData); len(cpl) > 0 && section == "capaian_pembelajaran" {
+		partial.CapaianPembelajaran.CPLProdi = cpl
+	}
+
+	requestDuration := time.Since(startTime).Milliseconds()
+	log.Printf("✅ Section %s regenerated, tokens used: %d, duration: %dms", section, llmResp.TotalTokens, requestDuration)
+
+	var parsed interface{}
+	json.Unmarshal(fields[section], &parsed)
+
+	aiPrompt.ParsedResponse = map[string]interface{}{section: parsed}
+	aiPrompt.RequestDurationMs = requestDuration
+	aiPrompt.Status = "success"
+
+	savedPrompt, err := s.aiPromptRepo.Create(ctx, aiPrompt)
+	if err != nil {
+		log.Printf("Warning: failed to save AI prompt to MongoDB: %v", err)
+	}
+
+	aiMetadata := map[string]interface{}{
+		"section":            section,
+		"model":              aiPrompt.Model,
+		"provider":           provider.Name(),
+		"temperature":        llmReq.Temperature,
+		"top_p":              llmReq.TopP,
+		"top_k":              llmReq.TopK,
+		"max_tokens":         llmReq.MaxTokens,
+		"prompt_tokens":      llmResp.PromptTokens,
+		"completion_tokens":  llmResp.CompletionTokens,
+		"total_tokens":       llmResp.TotalTokens,
+		"estimated_cost":     aiPrompt.EstimatedCost,
+		"generation_time_ms": requestDuration,
+		"finish_reason":      llmResp.FinishReason,
+		"mongo_prompt_id":    "",
+	}
+	if aiPrompt.WeekFrom > 0 {
+		aiMetadata["week_from"] = aiPrompt.WeekFrom
+		aiMetadata["week_to"] = aiPrompt.WeekTo
+	}
+	if savedPrompt != nil {
+		aiMetadata["mongo_prompt_id"] = savedPrompt.ID.Hex()
+	}
+
+	return &dto.AIGenerationResult{
+		Result:     &partial,
+		AIMetadata: aiMetadata,
+	}, nil
+}
+
+// sectionSchema wraps the schema of one top-level RPS section in an object, since
+// structured output requires an object at the root
+func (s *aiService) sectionSchema(section string) (map[string]interface{}, error) {
+	properties := s.GetRPSJSONSchema()["properties"].(map[string]interface{})
+	sub, ok := properties[section]
+	if !ok {
+		return nil, ErrUnknownSection
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{section: sub},
+		"required":   []string{section},
+	}, nil
+}
+
+func (s *aiService) buildSectionPrompt(req SectionRequest) string {
+	currentJSON, _ := json.MarshalIndent(req.Current, "", "  ")
+
+	var instructions strings.Builder
+	fmt.Fprintf(&instructions, "- Bahasa: %s\n- Gaya penulisan: %s\n", req.Options.Language, req.Options.Tone)
+	fmt.Fprintf(&instructions, "- Kembalikan HANYA objek JSON dengan satu field \"%s\"\n", req.Section)
+	if req.WeekFrom > 0 {
+		fmt.Fprintf(&instructions, "- Buat ulang HANYA minggu ke-%d sampai minggu ke-%d; kembalikan minggu-minggu tersebut saja dengan nomor minggu yang sama\n", req.WeekFrom, req.WeekTo)
+		instructions.WriteString("- Jaga kesinambungan dengan minggu lainnya (UTS minggu ke-8, UAS minggu ke-16)\n")
+	} else {
+		instructions.WriteString("- Jaga konsistensi dengan bagian lain RPS yang tidak diubah\n")
+	}
+	if cpl := mappedCPL(req.CourseData); len(cpl) > 0 {
+		fmt.Fprintf(&instructions, "- CPL program studi (salin apa adanya ke cpl_prodi): %s\n", strings.Join(cpl, "; "))
+	}
+	if req.Instructions != "" {
+		fmt.Fprintf(&instructions, "- Catatan dosen: %s\n", req.Instructions)
+	}
+
+	return fmt.Sprintf(`Perbarui bagian "%s" dari Rencana Pembelajaran Semester (RPS) berikut tanpa mengubah bagian lainnya.
+
+## INFORMASI MATA KULIAH
+- Nama Mata Kuliah: %v
+- Kode Mata Kuliah: %v
+- Jumlah SKS: %v
+
+## RPS SAAT INI
+%s
+
+## INSTRUKSI KHUSUS
+%s`,
+		req.Section,
+		req.CourseData["title"],
+		req.CourseData["code"],
+		req.CourseData["credits"],
+		string(currentJSON),
+		instructions.String(),
+	)
+}
+
+// sectionField returns a pointer to the field of rps holding the given section
+func sectionField(rps *dto.RPSStructuredOutput, section string) interface{} {
+	switch section {
+	case "identitas":
+		return &rps.Identitas
+	case "capaian_pembelajaran":
+		return &rps.CapaianPembelajaran
+	case "deskripsi_mata_kuliah":
+		return &rps.DeskripsiMataKuliah
+	case "rencana_mingguan":
+		return &rps.RencanaMingguan
+	case "rencana_penilaian":
+		return &rps.RencanaPenilaian
+	case "daftar_referensi":
+		return &rps.DaftarReferensi
+	}
+	return nil
+}
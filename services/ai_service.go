@@ -1,77 +1,88 @@
 package services
 
 import (
-	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
+	"math/rand/v2"
+	"net"
 	"net/http"
-	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 
+	"github.com/syrlramadhan/dokumentasi-rps-api/config"
 	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
 	models "github.com/syrlramadhan/dokumentasi-rps-api/models/mongo"
 	mongoRepo "github.com/syrlramadhan/dokumentasi-rps-api/repositories/mongo"
 )
 
 type AIService interface {
-	GenerateRPS(ctx context.Context, generatedRPSID string, courseData map[string]interface{}, templateDef map[string]interface{}, options dto.GenerateRPSOptions) (*dto.AIGenerationResult, error)
+	GenerateRPS(ctx context.Context, generatedRPSID string, owner CostOwner, courseData map[string]interface{}, templateDef map[string]interface{}, options dto.GenerateRPSOptions) (*dto.AIGenerationResult, error)
+	CheckModelParams(options dto.GenerateRPSOptions) error
+	AssignExperiment(ctx context.Context, generatedRPSID string, options dto.GenerateRPSOptions) *ExperimentAssignment
+	GenerateSection(ctx context.Context, req SectionRequest) (*dto.AIGenerationResult, error)
 	GetPromptByID(ctx context.Context, id string) (*models.AIPrompt, error)
 	GetPromptsByGeneratedRPSID(ctx context.Context, generatedRPSID string) ([]models.AIPrompt, error)
 	GetGenerationByRPSID(ctx context.Context, generatedRPSID string) (*models.AIGeneration, error)
 	GetPromptStats(ctx context.Context) (*mongoRepo.AIPromptStats, error)
 	GetAllPrompts(ctx context.Context, limit, offset int64) ([]models.AIPromptSummary, error)
+	GetPromptsByModel(ctx context.Context, model string) ([]models.AIPromptSummary, error)
+	GetPromptsByStatus(ctx context.Context, status string) ([]models.AIPromptSummary, error)
+	GetPromptsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]models.AIPromptSummary, error)
 	GetAllGenerations(ctx context.Context, limit, offset int64) ([]models.AIGeneration, error)
 }
 
 type aiService struct {
-	apiKey             string
-	model              string
-	httpClient         *http.Client
+	providers          map[string]LLMProvider
+	defaultProvider    string
+	retry              config.AIRetryConfig
+	params             config.AIParamConfig
+	prices             map[string]config.ModelPrice
 	aiPromptRepo       mongoRepo.AIPromptRepository
 	aiGenerationRepo   mongoRepo.AIGenerationRepository
 	promptTemplateRepo mongoRepo.PromptTemplateRepository
+	experimentRepo     mongoRepo.ExperimentRepository
+	events             GenerationEvents
 }
 
 func NewAIService(
 	aiPromptRepo mongoRepo.AIPromptRepository,
 	aiGenerationRepo mongoRepo.AIGenerationRepository,
 	promptTemplateRepo mongoRepo.PromptTemplateRepository,
+	experimentRepo mongoRepo.ExperimentRepository,
+	events GenerationEvents,
 ) AIService {
-	model := os.Getenv("GEMINI_MODEL")
-	if model == "" {
-		model = "gemini-2.0-flash"
-	}
+	providerConfig := config.GetAIProviderConfig()
 
 	return &aiService{
-		apiKey:             os.Getenv("GEMINI_API_KEY"),
-		model:              model,
-		httpClient:         &http.Client{Timeout: 120 * time.Second},
+		providers:          newLLMProviders(providerConfig),
+		defaultProvider:    providerConfig.DefaultProvider,
+		retry:              config.GetAIRetryConfig(),
+		params:             config.GetAIParamConfig(),
+		prices:             config.GetAIModelPrices(),
 		aiPromptRepo:       aiPromptRepo,
 		aiGenerationRepo:   aiGenerationRepo,
 		promptTemplateRepo: promptTemplateRepo,
+		experimentRepo:     experimentRepo,
+		events:             events,
 	}
 }
 
-// getGeminiAPIURL builds the Gemini API URL
-func (s *aiService) getGeminiAPIURL() string {
-	return fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", s.model, s.apiKey)
-}
-
-func (s *aiService) GenerateRPS(ctx context.Context, generatedRPSID string, courseData map[string]interface{}, templateDef map[string]interface{}, options dto.GenerateRPSOptions) (*dto.AIGenerationResult, error) {
-	startTime := time.Now()
-
-	// Check API Key
-	if s.apiKey == "" {
-		log.Println("❌ ERROR: GEMINI_API_KEY is empty!")
-		return nil, fmt.Errorf("GEMINI_API_KEY is not set. Please set it in .env file")
+func (s *aiService) GenerateRPS(ctx context.Context, generatedRPSID string, owner CostOwner, courseData map[string]interface{}, templateDef map[string]interface{}, options dto.GenerateRPSOptions) (*dto.AIGenerationResult, error) {
+	provider, err := s.providerFor(options.Provider)
+	if err != nil {
+		log.Printf("❌ ERROR: %v", err)
+		return nil, err
 	}
-	log.Printf("✅ Gemini API Key detected (length: %d)", len(s.apiKey))
-	log.Printf("✅ Using model: %s", s.model)
+	log.Printf("✅ Using provider: %s, model: %s", provider.Name(), provider.Model())
 
 	// Set defaults
 	if options.Language == "" {
@@ -81,6 +92,9 @@ func (s *aiService) GenerateRPS(ctx context.Context, generatedRPSID string, cour
 		options.Tone = "formal dan akademis"
 	}
 
+	// The running experiment, if any, decides the prompts and model settings
+	assignment := s.AssignExperiment(ctx, generatedRPSID, options)
+
 	// Create AI Generation record in MongoDB
 	generation := &models.AIGeneration{
 		GeneratedRPSID:    generatedRPSID,
@@ -92,167 +106,333 @@ func (s *aiService) GenerateRPS(ctx context.Context, generatedRPSID string, cour
 		TotalAttempts:     0,
 		FinalStatus:       "processing",
 	}
+	generation.ProgramID, generation.RequestedBy = owner.ids()
+	if assignment != nil {
+		generation.ExperimentID = assignment.ExperimentID
+		generation.ExperimentArm = assignment.Arm.Name
+	}
 
-	generation, err := s.aiGenerationRepo.Create(ctx, generation)
+	// A job that runs again (requeued, retried or regenerated) adds a run to its record
+	generation, err = s.aiGenerationRepo.StartRun(ctx, generation)
 	if err != nil {
-		log.Printf("Warning: failed to create AI generation record: %v", err)
+		log.Printf("Warning: failed to start AI generation record: %v", err)
 		generation = &models.AIGeneration{ID: primitive.NewObjectID()}
 	}
 
-	// Build prompts
-	systemPrompt := s.buildSystemPrompt()
-	userPrompt := s.buildUserPrompt(courseData, templateDef, options)
+	// Build prompts from the active prompt template, or the built-in ones without one
+	systemPrompt, userPrompt := "", ""
+	promptTemplate := s.activePromptTemplate(ctx)
+	if assignment != nil && assignment.Arm.PromptTemplateID != "" {
+		promptTemplate = armPromptTemplate(assignment.Arm)
+	}
+	if promptTemplate == nil {
+		systemPrompt = s.buildSystemPrompt()
+		userPrompt = s.buildUserPrompt(courseData, templateDef, options)
+	} else {
+		systemPrompt, userPrompt, err = renderPromptTemplate(promptTemplate, promptValues(courseData, templateDef, options))
+		if err != nil {
+			log.Printf("❌ ERROR: prompt template %s v%d: %v", promptTemplate.Name, promptTemplate.Version, err)
+			s.aiGenerationRepo.UpdateFinalStatus(context.WithoutCancel(ctx), generation.ID, "failed", nil)
+			s.recordTemplateUsage(ctx, promptTemplate, false)
+			return nil, err
+		}
+		log.Printf("📝 Using prompt template %s v%d", promptTemplate.Name, promptTemplate.Version)
+	}
 
 	log.Printf("📝 System prompt length: %d chars", len(systemPrompt))
 	log.Printf("📝 User prompt length: %d chars", len(userPrompt))
 
-	// Create AI Prompt record
-	aiPrompt := &models.AIPrompt{
+	llmReq := defaultModelParams(provider, s.params)
+	llmReq.SystemPrompt = systemPrompt
+	llmReq.UserPrompt = userPrompt
+	llmReq.Schema = s.GetRPSJSONSchema()
+	llmReq.CourseData = courseData
+	if promptTemplate != nil && promptTemplate.DefaultModel != "" {
+		llmReq.Model = promptTemplate.DefaultModel
+	}
+	if promptTemplate != nil && promptTemplate.DefaultTemperature > 0 {
+		llmReq.Temperature = promptTemplate.DefaultTemperature
+	}
+	if promptTemplate != nil && promptTemplate.DefaultMaxTokens > 0 {
+		llmReq.MaxTokens = promptTemplate.DefaultMaxTokens
+	}
+	if assignment != nil {
+		log.Printf("🧪 Experiment %s, arm %s", assignment.ExperimentID, assignment.Arm.Name)
+		if assignment.Arm.Model != "" {
+			llmReq.Model = assignment.Arm.Model
+		}
+		if assignment.Arm.Temperature != nil {
+			llmReq.Temperature = *assignment.Arm.Temperature
+		}
+		if assignment.Arm.MaxTokens > 0 {
+			llmReq.MaxTokens = assignment.Arm.MaxTokens
+		}
+	}
+	applyModelParams(&llmReq, options)
+	dropUnsupportedParams(provider, &llmReq)
+
+	// Checked on the merged request: the template and the experiment arm set parameters
+	// too, and the allowed ranges may have changed since the job was queued
+	if err := s.checkRequestParams(cmp.Or(options.Provider, s.defaultProvider), provider, llmReq); err != nil {
+		log.Printf("❌ ERROR: %v", err)
+		s.aiGenerationRepo.UpdateFinalStatus(context.WithoutCancel(ctx), generation.ID, "failed", nil)
+		return nil, err
+	}
+
+	// Prompt record template, copied for every attempt
+	basePrompt := models.AIPrompt{
 		GeneratedRPSID: generatedRPSID,
 		CourseID:       fmt.Sprintf("%v", courseData["id"]),
 		TemplateID:     fmt.Sprintf("%v", templateDef["id"]),
 		SystemPrompt:   systemPrompt,
 		UserPrompt:     userPrompt,
 		FullPrompt:     fmt.Sprintf("System: %s\n\nUser: %s", systemPrompt, userPrompt),
-		Model:          s.model,
-		Temperature:    0.7,
-		MaxTokens:      8192,
+		Provider:       provider.Name(),
+		Model:          llmReq.Model,
+		Temperature:    llmReq.Temperature,
+		MaxTokens:      llmReq.MaxTokens,
+		TopP:           llmReq.TopP,
+		TopK:           llmReq.TopK,
 		ResponseFormat: "json_object",
 		CourseData:     courseData,
 		TemplateData:   templateDef,
 		Options:        map[string]interface{}{"language": options.Language, "tone": options.Tone, "overrides": options.Overrides},
 		Status:         "pending",
 	}
+	basePrompt.ProgramID, basePrompt.RequestedBy = owner.ids()
+	if promptTemplate != nil {
+		basePrompt.PromptTemplateID = promptTemplate.ID.Hex()
+		basePrompt.PromptTemplateVersion = promptTemplate.Version
+	}
+	if assignment != nil {
+		basePrompt.ExperimentID = assignment.ExperimentID
+		basePrompt.ExperimentArm = assignment.Arm.Name
+	}
+
+	var totalDuration int64
+	for attemptNumber := 1; ; attemptNumber++ {
+		prompt := basePrompt
+		prompt.AttemptNumber = attemptNumber
+
+		result, attemptErr := s.attemptGeneration(ctx, generation, provider, &prompt, llmReq)
+		totalDuration += prompt.RequestDurationMs
+		if attemptErr == nil {
+			s.publishAttempt(generatedRPSID, attemptNumber, "success", "", map[string]interface{}{
+				"total_tokens": result.AIMetadata["total_tokens"],
+				"duration_ms":  prompt.RequestDurationMs,
+			})
+			result.AIMetadata["attempts"] = attemptNumber
+			result.AIMetadata["total_duration_ms"] = totalDuration
+			result.AIMetadata["mongo_generation_id"] = generation.ID.Hex()
+			if promptTemplate != nil {
+				result.AIMetadata["prompt_template_id"] = basePrompt.PromptTemplateID
+				result.AIMetadata["prompt_template_version"] = basePrompt.PromptTemplateVersion
+			}
+			if assignment != nil {
+				result.AIMetadata["experiment_id"] = assignment.ExperimentID
+				result.AIMetadata["experiment_arm"] = assignment.Arm.Name
+			}
+			s.recordTemplateUsage(ctx, promptTemplate, true)
+			return result, nil
+		}
 
-	// Build Gemini request with structured output
-	reqBody := dto.GeminiRequest{
-		SystemInstruction: &dto.GeminiContent{
-			Parts: []dto.GeminiPart{{Text: systemPrompt}},
-		},
-		Contents: []dto.GeminiContent{
-			{
-				Role:  "user",
-				Parts: []dto.GeminiPart{{Text: userPrompt}},
-			},
-		},
-		GenerationConfig: &dto.GeminiGenConfig{
-			Temperature:      0.7,
-			TopP:             0.95,
-			TopK:             40,
-			MaxOutputTokens:  8192,
-			ResponseMimeType: "application/json",
-			ResponseSchema:   s.GetRPSJSONSchema(),
-		},
-		SafetySettings: []dto.GeminiSafety{
-			{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_NONE"},
-			{Category: "HARM_CATEGORY_HATE_SPEECH", Threshold: "BLOCK_NONE"},
-			{Category: "HARM_CATEGORY_SEXUALLY_EXPLICIT", Threshold: "BLOCK_NONE"},
-			{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Threshold: "BLOCK_NONE"},
-		},
-	}
+		s.recordFailedAttempt(ctx, generation, &prompt, attemptNumber, attemptErr)
+		attemptData := map[string]interface{}{"retryable": attemptErr.retryable, "status_code": attemptErr.statusCode}
+
+		if !attemptErr.retryable || attemptNumber >= s.retry.MaxAttempts || ctx.Err() != nil {
+			s.publishAttempt(generatedRPSID, attemptNumber, prompt.Status, attemptErr.message, attemptData)
+			s.aiGenerationRepo.UpdateFinalStatus(context.WithoutCancel(ctx), generation.ID, failureStatus(ctx), nil)
+			if ctx.Err() == nil {
+				// Cancelled or interrupted runs say nothing about the template
+				s.recordTemplateUsage(ctx, promptTemplate, false)
+			}
+			if attemptNumber > 1 {
+				return nil, fmt.Errorf("generation failed after %d attempts: %w", attemptNumber, attemptErr.err)
+			}
+			return nil, attemptErr.err
+		}
 
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		log.Printf("❌ Failed to marshal request: %v", err)
-		s.recordFailedAttempt(ctx, generation.ID, aiPrompt, "failed to marshal request", 0, time.Since(startTime).Milliseconds())
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		delay := s.retryDelay(attemptNumber, attemptErr.retryAfter)
+		attemptData["retry_in_ms"] = delay.Milliseconds()
+		s.publishAttempt(generatedRPSID, attemptNumber, prompt.Status, attemptErr.message, attemptData)
+		log.Printf("🔁 Attempt %d/%d failed (%s), retrying in %s", attemptNumber, s.retry.MaxAttempts, attemptErr.message, delay)
+
+		select {
+		case <-ctx.Done():
+			if jobCancelled(ctx) {
+				// Cancelled while waiting for the next attempt
+				s.aiGenerationRepo.AddAttempt(context.WithoutCancel(ctx), generation.ID, models.GenerationAttempt{
+					AttemptNumber: attemptNumber + 1,
+					Run:           generation.Runs,
+					Status:        "cancelled",
+					ErrorMessage:  "cancelled before the attempt started",
+					Timestamp:     time.Now(),
+				})
+			}
+			s.aiGenerationRepo.UpdateFinalStatus(context.WithoutCancel(ctx), generation.ID, failureStatus(ctx), nil)
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
 	}
+}
 
-	log.Printf("📤 Sending request to Gemini API...")
-
-	// Make HTTP request
-	apiURL := s.getGeminiAPIURL()
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonBody))
+// activePromptTemplate returns the active RPS generation template, the highest
+// version when several are active, or nil to use the built-in prompts
+func (s *aiService) activePromptTemplate(ctx context.Context) *models.PromptTemplate {
+	templates, err := s.promptTemplateRepo.FindByCategory(ctx, PromptCategoryRPS)
 	if err != nil {
-		log.Printf("❌ Failed to create request: %v", err)
-		s.recordFailedAttempt(ctx, generation.ID, aiPrompt, "failed to create request", 0, time.Since(startTime).Milliseconds())
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		log.Printf("Warning: failed to load prompt templates, using built-in prompts: %v", err)
+		return nil
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	var active *models.PromptTemplate
+	for i := range templates {
+		if active == nil || templates[i].Version > active.Version {
+			active = &templates[i]
+		}
+	}
+	return active
+}
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		log.Printf("❌ Failed to call Gemini API: %v", err)
-		s.recordFailedAttempt(ctx, generation.ID, aiPrompt, err.Error(), 0, time.Since(startTime).Milliseconds())
-		return nil, fmt.Errorf("failed to call Gemini API: %w", err)
+// AssignExperiment returns the arm of the running experiment the generation
+// belongs to, or nil when no experiment is running. Requests that set their own
+// model parameters stay out of experiments.
+func (s *aiService) AssignExperiment(ctx context.Context, generatedRPSID string, options dto.GenerateRPSOptions) *ExperimentAssignment {
+	if hasModelParams(options) {
+		return nil
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	experiment, err := s.experimentRepo.FindRunning(ctx)
 	if err != nil {
-		log.Printf("❌ Failed to read response: %v", err)
-		s.recordFailedAttempt(ctx, generation.ID, aiPrompt, "failed to read response", 0, time.Since(startTime).Milliseconds())
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Printf("Warning: failed to load the running experiment: %v", err)
+		}
+		return nil
 	}
 
-	log.Printf("📥 Gemini Response Status: %d", resp.StatusCode)
+	arm := pickArm(experiment, generatedRPSID)
+	if arm == nil {
+		return nil
+	}
+	return &ExperimentAssignment{ExperimentID: experiment.ID.Hex(), Arm: *arm}
+}
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("❌ Gemini API Error Response: %s", string(body))
-		var geminiErr dto.GeminiError
-		json.Unmarshal(body, &geminiErr)
-		errMsg := fmt.Sprintf("Gemini API error (status %d): %s", resp.StatusCode, geminiErr.Error.Message)
-		s.recordFailedAttempt(ctx, generation.ID, aiPrompt, errMsg, 0, time.Since(startTime).Milliseconds())
-		return nil, fmt.Errorf(errMsg)
+// recordTemplateUsage counts a finished generation against its prompt template
+// and folds the outcome into the template's success rate (0..1)
+func (s *aiService) recordTemplateUsage(ctx context.Context, template *models.PromptTemplate, success bool) {
+	if template == nil {
+		return
 	}
+	ctx = context.WithoutCancel(ctx)
 
-	// Parse Gemini response
-	var geminiResp dto.GeminiResponse
-	if err := json.Unmarshal(body, &geminiResp); err != nil {
-		log.Printf("❌ Failed to parse Gemini response: %v", err)
-		log.Printf("Raw response: %s", string(body))
-		s.recordFailedAttempt(ctx, generation.ID, aiPrompt, "failed to parse Gemini response", 0, time.Since(startTime).Milliseconds())
-		return nil, fmt.Errorf("failed to parse Gemini response: %w", err)
+	if err := s.promptTemplateRepo.IncrementUsage(ctx, template.ID); err != nil {
+		log.Printf("Warning: failed to count usage of prompt template %s: %v", template.Name, err)
+		return
+	}
+	current, err := s.promptTemplateRepo.FindByID(ctx, template.ID)
+	if err != nil || current.UsageCount == 0 {
+		return
 	}
 
-	if len(geminiResp.Candidates) == 0 {
-		log.Printf("❌ No candidates in Gemini response")
-		s.recordFailedAttempt(ctx, generation.ID, aiPrompt, "no candidates in response", 0, time.Since(startTime).Milliseconds())
-		return nil, fmt.Errorf("no candidates in Gemini response")
+	outcome := 0.0
+	if success {
+		outcome = 1
+	}
+	rate := current.SuccessRate + (outcome-current.SuccessRate)/float64(current.UsageCount)
+	if err := s.promptTemplateRepo.UpdateSuccessRate(ctx, template.ID, rate); err != nil {
+		log.Printf("Warning: failed to update success rate of prompt template %s: %v", template.Name, err)
 	}
+}
+
+// attemptError describes a failed generation attempt and whether it is worth retrying
+type attemptError struct {
+	err        error
+	message    string
+	retryable  bool
+	cancelled  bool // the job was cancelled while the request was in flight
+	statusCode int
+	retryAfter time.Duration
+	class      string // set when the provider answered but the output is unusable
+}
+
+func (e *attemptError) Error() string {
+	return e.err.Error()
+}
 
-	// Get response text
-	var responseContent string
-	if len(geminiResp.Candidates[0].Content.Parts) > 0 {
-		responseContent = geminiResp.Candidates[0].Content.Parts[0].Text
+// Error classes recorded on failed prompts for the analytics
+const (
+	ErrorClassCancelled     = "cancelled"
+	ErrorClassTimeout       = "timeout"
+	ErrorClassRateLimited   = "rate_limited"
+	ErrorClassServerError   = "server_error"
+	ErrorClassClientError   = "client_error"
+	ErrorClassEmptyResponse = "empty_response"
+	ErrorClassInvalidOutput = "invalid_output"
+	ErrorClassOther         = "other"
+)
+
+// errorClass groups a failed attempt by what went wrong
+func errorClass(attemptErr *attemptError) string {
+	switch {
+	case attemptErr.class != "":
+		return attemptErr.class
+	case attemptErr.cancelled:
+		return ErrorClassCancelled
+	case isTimeout(attemptErr.err):
+		return ErrorClassTimeout
+	case attemptErr.statusCode == http.StatusTooManyRequests:
+		return ErrorClassRateLimited
+	case attemptErr.statusCode >= http.StatusInternalServerError:
+		return ErrorClassServerError
+	case attemptErr.statusCode >= http.StatusBadRequest:
+		return ErrorClassClientError
+	default:
+		return ErrorClassOther
 	}
+}
+
+// attemptGeneration makes a single provider call. On success the prompt and the
+// attempt are stored and the generation is finalized; failures are returned
+// classified so the caller can decide to retry.
+func (s *aiService) attemptGeneration(ctx context.Context, generation *models.AIGeneration, provider LLMProvider, aiPrompt *models.AIPrompt, llmReq LLMRequest) (*dto.AIGenerationResult, *attemptError) {
+	startTime := time.Now()
+	defer func() {
+		aiPrompt.RequestDurationMs = time.Since(startTime).Milliseconds()
+	}()
 
-	if responseContent == "" {
-		log.Printf("❌ Empty response content from Gemini")
-		s.recordFailedAttempt(ctx, generation.ID, aiPrompt, "empty response content", 0, time.Since(startTime).Milliseconds())
-		return nil, fmt.Errorf("empty response content from Gemini")
+	llmResp, attemptErr := s.callProvider(ctx, provider, aiPrompt, llmReq)
+	if attemptErr != nil {
+		return nil, attemptErr
 	}
+	responseContent := llmResp.Content
 
 	log.Printf("📄 Response content length: %d chars", len(responseContent))
+	aiPrompt.Response = responseContent
 
 	// Parse structured output
 	var rpsResult dto.RPSStructuredOutput
 	if err := json.Unmarshal([]byte(responseContent), &rpsResult); err != nil {
 		log.Printf("❌ Failed to parse RPS output: %v", err)
 		log.Printf("Response content: %s", responseContent[:min(500, len(responseContent))])
-		s.recordFailedAttempt(ctx, generation.ID, aiPrompt, "failed to parse RPS output", geminiResp.UsageMetadata.TotalTokenCount, time.Since(startTime).Milliseconds())
-		return nil, fmt.Errorf("failed to parse RPS structured output: %w", err)
+		return nil, &attemptError{err: fmt.Errorf("failed to parse RPS structured output: %w", err), message: "failed to parse RPS output", retryable: true, statusCode: llmResp.StatusCode, class: ErrorClassInvalidOutput}
+	}
+	if cpl := mappedCPL(llmReq.CourseData); len(cpl) > 0 {
+		rpsResult.CapaianPembelajaran.CPLProdi = cpl
 	}
 
 	requestDuration := time.Since(startTime).Milliseconds()
 
 	log.Printf("✅ Generation successful!")
 	log.Printf("📊 Tokens used: %d (prompt: %d, completion: %d)",
-		geminiResp.UsageMetadata.TotalTokenCount,
-		geminiResp.UsageMetadata.PromptTokenCount,
-		geminiResp.UsageMetadata.CandidatesTokenCount)
+		llmResp.TotalTokens,
+		llmResp.PromptTokens,
+		llmResp.CompletionTokens)
 	log.Printf("⏱️ Duration: %dms", requestDuration)
 
 	// Update AI Prompt with success data
-	aiPrompt.Response = responseContent
 	aiPrompt.ParsedResponse = map[string]interface{}{"rps": rpsResult}
-	aiPrompt.PromptTokens = geminiResp.UsageMetadata.PromptTokenCount
-	aiPrompt.CompletionTokens = geminiResp.UsageMetadata.CandidatesTokenCount
-	aiPrompt.TotalTokens = geminiResp.UsageMetadata.TotalTokenCount
 	aiPrompt.RequestDurationMs = requestDuration
 	aiPrompt.Status = "success"
-	aiPrompt.FinishReason = geminiResp.Candidates[0].FinishReason
 
 	// Save prompt to MongoDB
 	savedPrompt, err := s.aiPromptRepo.Create(ctx, aiPrompt)
@@ -262,10 +442,13 @@ func (s *aiService) GenerateRPS(ctx context.Context, generatedRPSID string, cour
 
 	// Update generation record with success
 	attempt := models.GenerationAttempt{
-		AttemptNumber: 1,
+		AttemptNumber: aiPrompt.AttemptNumber,
+		Run:           generation.Runs,
 		Status:        "success",
-		TokensUsed:    geminiResp.UsageMetadata.TotalTokenCount,
+		TokensUsed:    llmResp.TotalTokens,
 		DurationMs:    requestDuration,
+		Cost:          aiPrompt.EstimatedCost,
+		StatusCode:    llmResp.StatusCode,
 		Timestamp:     time.Now(),
 	}
 	if savedPrompt != nil {
@@ -283,17 +466,20 @@ func (s *aiService) GenerateRPS(ctx context.Context, generatedRPSID string, cour
 
 	// Build AI metadata
 	aiMetadata := map[string]interface{}{
-		"model":               s.model,
-		"prompt_tokens":       geminiResp.UsageMetadata.PromptTokenCount,
-		"completion_tokens":   geminiResp.UsageMetadata.CandidatesTokenCount,
-		"total_tokens":        geminiResp.UsageMetadata.TotalTokenCount,
-		"temperature":         0.7,
-		"generation_time_ms":  requestDuration,
-		"finish_reason":       geminiResp.Candidates[0].FinishReason,
-		"response_format":     "structured_output",
-		"provider":            "google_gemini",
-		"mongo_prompt_id":     "",
-		"mongo_generation_id": generation.ID.Hex(),
+		"model":              aiPrompt.Model,
+		"prompt_tokens":      llmResp.PromptTokens,
+		"completion_tokens":  llmResp.CompletionTokens,
+		"total_tokens":       llmResp.TotalTokens,
+		"estimated_cost":     aiPrompt.EstimatedCost,
+		"temperature":        llmReq.Temperature,
+		"top_p":              llmReq.TopP,
+		"top_k":              llmReq.TopK,
+		"max_tokens":         llmReq.MaxTokens,
+		"generation_time_ms": requestDuration,
+		"finish_reason":      llmResp.FinishReason,
+		"response_format":    "structured_output",
+		"provider":           provider.Name(),
+		"mongo_prompt_id":    "",
 	}
 
 	if savedPrompt != nil {
@@ -306,28 +492,158 @@ func (s *aiService) GenerateRPS(ctx context.Context, generatedRPSID string, cour
 	}, nil
 }
 
-func (s *aiService) recordFailedAttempt(ctx context.Context, generationID primitive.ObjectID, prompt *models.AIPrompt, errorMsg string, tokens int, duration int64) {
-	prompt.Status = "failed"
-	prompt.ErrorMessage = errorMsg
-	prompt.TotalTokens = tokens
-	prompt.RequestDurationMs = duration
+// callProvider sends one request to the provider with the per-attempt timeout,
+// records token usage on aiPrompt and classifies failures for retrying
+func (s *aiService) callProvider(ctx context.Context, provider LLMProvider, aiPrompt *models.AIPrompt, llmReq LLMRequest) (*LLMResponse, *attemptError) {
+	log.Printf("📤 Sending request to %s (attempt %d)...", provider.Name(), aiPrompt.AttemptNumber)
+
+	attemptCtx, cancel := context.WithTimeout(ctx, s.retry.RequestTimeout)
+	defer cancel()
 
-	savedPrompt, _ := s.aiPromptRepo.Create(ctx, prompt)
+	llmResp, err := provider.Generate(attemptCtx, llmReq)
+	if llmResp != nil {
+		aiPrompt.PromptTokens = llmResp.PromptTokens
+		aiPrompt.CompletionTokens = llmResp.CompletionTokens
+		aiPrompt.TotalTokens = llmResp.TotalTokens
+		aiPrompt.FinishReason = llmResp.FinishReason
+		aiPrompt.EstimatedCost = s.callCost(aiPrompt.Model, llmResp.PromptTokens, llmResp.CompletionTokens)
+	}
+	if err != nil {
+		log.Printf("❌ %s call failed: %v", provider.Name(), err)
+		if jobCancelled(ctx) {
+			return nil, &attemptError{err: ErrJobCancelled, message: "cancelled by user", cancelled: true}
+		}
+		var providerErr *ProviderError
+		if errors.As(err, &providerErr) {
+			return nil, &attemptError{
+				err:        err,
+				message:    providerErr.Message,
+				retryable:  providerErr.Retryable,
+				statusCode: providerErr.StatusCode,
+				retryAfter: providerErr.RetryAfter,
+			}
+		}
+		return nil, &attemptError{err: err, message: err.Error(), retryable: ctx.Err() == nil && isTimeout(err)}
+	}
+
+	if llmResp.Content == "" {
+		log.Printf("❌ Empty response content from %s", provider.Name())
+		return nil, &attemptError{err: fmt.Errorf("empty response content from %s", provider.Name()), message: "empty response content", retryable: true, statusCode: llmResp.StatusCode, class: ErrorClassEmptyResponse}
+	}
+
+	return llmResp, nil
+}
+
+// publishAttempt reports the outcome of one LLM attempt of a generation job
+func (s *aiService) publishAttempt(generatedRPSID string, attemptNumber int, status, message string, data map[string]interface{}) {
+	jobID, err := uuid.Parse(generatedRPSID)
+	if err != nil {
+		return
+	}
+	s.events.Publish(dto.GenerationEvent{
+		JobID:   jobID,
+		Type:    GenerationEventAttempt,
+		Status:  status,
+		Attempt: attemptNumber,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// failureStatus is the final AIGeneration status of a generation that ended without a result
+func failureStatus(ctx context.Context) string {
+	if jobCancelled(ctx) {
+		return "cancelled"
+	}
+	return "failed"
+}
+
+// retryDelay returns an exponential backoff with jitter for the given attempt,
+// honoring a server supplied Retry-After when it is longer
+func (s *aiService) retryDelay(attemptNumber int, retryAfter time.Duration) time.Duration {
+	backoff := s.retry.BaseDelay << (attemptNumber - 1)
+	if backoff <= 0 || backoff > s.retry.MaxDelay {
+		backoff = s.retry.MaxDelay
+	}
+
+	// Equal jitter: half fixed, half random, so concurrent workers spread out
+	delay := backoff/2 + rand.N(backoff/2+1)
+	if retryAfter > delay {
+		delay = min(retryAfter, s.retry.MaxDelay)
+	}
+	return delay
+}
+
+// callCost returns the USD cost of a call from the price table, 0 for unpriced models
+func (s *aiService) callCost(model string, promptTokens, completionTokens int) float64 {
+	price, ok := s.prices[model]
+	if !ok {
+		log.Printf("Warning: no price for model %s, recording a cost of 0 (set AI_MODEL_PRICES)", model)
+		return 0
+	}
+	return (float64(promptTokens)*price.Prompt + float64(completionTokens)*price.Completion) / 1_000_000
+}
+
+// isTimeout reports whether err is a client side or per-attempt timeout
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (s *aiService) recordFailedAttempt(ctx context.Context, generation *models.AIGeneration, prompt *models.AIPrompt, attemptNumber int, attemptErr *attemptError) {
+	// Persist the attempt even when ctx has been cancelled
+	ctx = context.WithoutCancel(ctx)
+
+	savedPrompt := s.saveFailedPrompt(ctx, prompt, attemptNumber, attemptErr)
 
 	attempt := models.GenerationAttempt{
-		AttemptNumber: 1,
-		Status:        "failed",
-		TokensUsed:    tokens,
-		DurationMs:    duration,
-		ErrorMessage:  errorMsg,
+		AttemptNumber: attemptNumber,
+		Run:           generation.Runs,
+		Status:        prompt.Status,
+		TokensUsed:    prompt.TotalTokens,
+		DurationMs:    prompt.RequestDurationMs,
+		Cost:          prompt.EstimatedCost,
+		StatusCode:    attemptErr.statusCode,
+		Retryable:     attemptErr.retryable,
+		ErrorMessage:  attemptErr.message,
 		Timestamp:     time.Now(),
 	}
 	if savedPrompt != nil {
 		attempt.PromptID = savedPrompt.ID
 	}
 
-	s.aiGenerationRepo.AddAttempt(ctx, generationID, attempt)
-	s.aiGenerationRepo.UpdateFinalStatus(ctx, generationID, "failed", nil)
+	s.aiGenerationRepo.AddAttempt(ctx, generation.ID, attempt)
+}
+
+// saveFailedPrompt stores the prompt of a failed attempt with its failure status
+func (s *aiService) saveFailedPrompt(ctx context.Context, prompt *models.AIPrompt, attemptNumber int, attemptErr *attemptError) *models.AIPrompt {
+	switch {
+	case attemptErr.cancelled:
+		prompt.Status = "cancelled"
+	case isTimeout(attemptErr.err):
+		prompt.Status = "timeout"
+	default:
+		prompt.Status = "failed"
+	}
+	prompt.ErrorMessage = attemptErr.message
+	prompt.ErrorClass = errorClass(attemptErr)
+	prompt.AttemptNumber = attemptNumber
+
+	savedPrompt, err := s.aiPromptRepo.Create(context.WithoutCancel(ctx), prompt)
+	if err != nil {
+		log.Printf("Warning: failed to save AI prompt to MongoDB: %v", err)
+		return nil
+	}
+	return savedPrompt
 }
 
 func (s *aiService) GetPromptByID(ctx context.Context, id string) (*models.AIPrompt, error) {
@@ -354,6 +670,18 @@ func (s *aiService) GetAllPrompts(ctx context.Context, limit, offset int64) ([]m
 	return s.aiPromptRepo.FindAll(ctx, limit, offset)
 }
 
+func (s *aiService) GetPromptsByModel(ctx context.Context, model string) ([]models.AIPromptSummary, error) {
+	return s.aiPromptRepo.FindByModel(ctx, model)
+}
+
+func (s *aiService) GetPromptsByStatus(ctx context.Context, status string) ([]models.AIPromptSummary, error) {
+	return s.aiPromptRepo.FindByStatus(ctx, status)
+}
+
+func (s *aiService) GetPromptsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]models.AIPromptSummary, error) {
+	return s.aiPromptRepo.FindByDateRange(ctx, startDate, endDate)
+}
+
 func (s *aiService) GetAllGenerations(ctx context.Context, limit, offset int64) ([]models.AIGeneration, error) {
 	return s.aiGenerationRepo.FindAll(ctx, limit, offset)
 }
@@ -452,55 +780,114 @@ Tugas Anda:
 4. Membuat rencana pembelajaran mingguan yang detail untuk 16 minggu (termasuk UTS di minggu 8 dan UAS di minggu 16)
 
 Panduan penyusunan:
-- CPL (Capaian Pembelajaran Lulusan) harus sesuai dengan profil lulusan program studi
+- CPL (Capaian Pembelajaran Lulusan) adalah milik program studi; jika daftar CPL diberikan, salin apa adanya dan jangan membuat CPL baru
 - CPMK (Capaian Pembelajaran Mata Kuliah) harus mendukung CPL
 - Sub-CPMK harus terukur dan dapat dicapai dalam satu atau beberapa pertemuan
 - Metode pembelajaran harus bervariasi dan sesuai dengan karakteristik materi
 - Penilaian harus mencakup aspek kognitif, afektif, dan psikomotorik`
 }
 
-func (s *aiService) buildUserPrompt(courseData map[string]interface{}, templateDef map[string]interface{}, options dto.GenerateRPSOptions) string {
+// mappedCPL returns the program learning outcomes mapped to the course, as "CODE: description"
+func mappedCPL(courseData map[string]interface{}) []string {
+	cpl, _ := courseData["cpl"].([]string)
+	return cpl
+}
+
+// promptValues resolves the variables a prompt template can reference from the
+// course, the RPS template definition and the options. Values the course does
+// not have are left out, so templates fall back to the variable defaults.
+func promptValues(courseData map[string]interface{}, templateDef map[string]interface{}, options dto.GenerateRPSOptions) map[string]string {
 	templateJSON, _ := json.MarshalIndent(templateDef, "", "  ")
 
-	// Default values
-	semester := "Ganjil 2024/2025"
-	dosenPengampu := "Tim Dosen"
-	prasyarat := "-"
-	programStudi := ""
-	fakultas := ""
+	values := map[string]string{
+		"nama_mata_kuliah":  fmt.Sprintf("%v", courseData["title"]),
+		"kode_mata_kuliah":  fmt.Sprintf("%v", courseData["code"]),
+		"sks":               fmt.Sprintf("%v", courseData["credits"]),
+		"semester":          "Ganjil 2024/2025",
+		"dosen_pengampu":    "Tim Dosen",
+		"prasyarat":         "-",
+		"bahasa":            options.Language,
+		"gaya_penulisan":    options.Tone,
+		"template_struktur": string(templateJSON),
+	}
 
 	// Override with options
 	if options.Semester != "" {
-		semester = options.Semester
+		values["semester"] = options.Semester
 	}
 	if options.DosenPengampu != "" {
-		dosenPengampu = options.DosenPengampu
+		values["dosen_pengampu"] = options.DosenPengampu
 	}
 	if options.Prasyarat != "" {
-		prasyarat = options.Prasyarat
+		values["prasyarat"] = options.Prasyarat
 	}
 	if options.ProgramStudi != "" {
-		programStudi = options.ProgramStudi
+		values["program_studi"] = options.ProgramStudi
 	}
 	if options.Fakultas != "" {
-		fakultas = options.Fakultas
+		values["fakultas"] = options.Fakultas
 	}
 
 	// Legacy overrides support
 	if options.Overrides != nil {
-		if sem, ok := options.Overrides["semester"].(string); ok && sem != "" {
-			semester = sem
-		}
-		if dosen, ok := options.Overrides["dosen_pengampu"].(string); ok && dosen != "" {
-			dosenPengampu = dosen
-		}
-		if prasy, ok := options.Overrides["prasyarat"].(string); ok && prasy != "" {
-			prasyarat = prasy
+		for _, key := range []string{"semester", "dosen_pengampu", "prasyarat"} {
+			if value, ok := options.Overrides[key].(string); ok && value != "" {
+				values[key] = value
+			}
 		}
 	}
 
+	// Catalog details of the course
+	if courseType, ok := courseData["course_type"].(string); ok {
+		values["sifat_mata_kuliah"] = courseType
+	}
+	if theory, ok := courseData["theory_credits"].(int); ok {
+		values["sks_teori"] = strconv.Itoa(theory)
+	}
+	if practice, ok := courseData["practice_credits"].(int); ok {
+		values["sks_praktikum"] = strconv.Itoa(practice)
+	}
+	if description, ok := courseData["description"].(string); ok {
+		values["deskripsi"] = description
+	}
+	if bahanKajian, ok := courseData["bahan_kajian"].([]string); ok && len(bahanKajian) > 0 {
+		values["bahan_kajian"] = bulletList(bahanKajian)
+	}
+	if cpl := mappedCPL(courseData); len(cpl) > 0 {
+		values["cpl"] = bulletList(cpl)
+	}
+
+	return values
+}
+
+// bulletList renders items as markdown list lines
+func bulletList(items []string) string {
+	return "- " + strings.Join(items, "\n- ")
+}
+
+func (s *aiService) buildUserPrompt(courseData map[string]interface{}, templateDef map[string]interface{}, options dto.GenerateRPSOptions) string {
+	values := promptValues(courseData, templateDef, options)
+
+	// Catalog details of the course
+	courseInfo := ""
+	if courseType, ok := values["sifat_mata_kuliah"]; ok {
+		courseInfo += fmt.Sprintf("\n- Sifat Mata Kuliah: %s", courseType)
+	}
+	theory, hasTheory := values["sks_teori"]
+	practice, hasPractice := values["sks_praktikum"]
+	if hasTheory || hasPractice {
+		courseInfo += fmt.Sprintf("\n- Komposisi SKS: %s teori, %s praktikum", cmp.Or(theory, "0"), cmp.Or(practice, "0"))
+	}
+	if description, ok := values["deskripsi"]; ok {
+		courseInfo += "\n\n## DESKRIPSI RESMI MATA KULIAH\nGunakan sebagai dasar deskripsi_mata_kuliah:\n" + description
+	}
+	if bahanKajian, ok := values["bahan_kajian"]; ok {
+		courseInfo += "\n\n## BAHAN KAJIAN\nRencana mingguan harus mencakup seluruh bahan kajian berikut:\n" + bahanKajian
+	}
+
 	// Build program info section
 	programInfo := ""
+	programStudi, fakultas := values["program_studi"], values["fakultas"]
 	if programStudi != "" || fakultas != "" {
 		programInfo = "\n\n## INFORMASI PROGRAM STUDI"
 		if programStudi != "" {
@@ -511,6 +898,11 @@ func (s *aiService) buildUserPrompt(courseData map[string]interface{}, templateD
 		}
 	}
 
+	// CPL mapped to the course; the model must not invent its own
+	if cpl, ok := values["cpl"]; ok {
+		programInfo += "\n\n## CPL PROGRAM STUDI\nSalin CPL berikut apa adanya ke field cpl_prodi (format \"KODE: deskripsi\"), lalu susun CPMK yang mendukungnya:\n" + cpl
+	}
+
 	return fmt.Sprintf(`Buatkan Rencana Pembelajaran Semester (RPS) untuk mata kuliah berikut:
 
 ## INFORMASI MATA KULIAH
@@ -519,7 +911,7 @@ func (s *aiService) buildUserPrompt(courseData map[string]interface{}, templateD
 - Jumlah SKS: %v
 - Semester: %s
 - Dosen Pengampu: %s
-- Prasyarat: %s%s
+- Prasyarat: %s%s%s
 
 ## TEMPLATE STRUKTUR (untuk referensi)
 %s
@@ -538,17 +930,18 @@ func (s *aiService) buildUserPrompt(courseData map[string]interface{}, templateD
 - Bisa ditambah: Praktikum, Proyek, Presentasi
 
 Buatkan RPS yang lengkap dan berkualitas.`,
-		courseData["title"],
-		courseData["code"],
-		courseData["credits"],
-		semester,
-		dosenPengampu,
-		prasyarat,
+		values["nama_mata_kuliah"],
+		values["kode_mata_kuliah"],
+		values["sks"],
+		values["semester"],
+		values["dosen_pengampu"],
+		values["prasyarat"],
+		courseInfo,
 		programInfo,
-		string(templateJSON),
-		options.Language,
-		options.Tone,
-		dosenPengampu,
-		prasyarat,
+		values["template_struktur"],
+		values["bahasa"],
+		values["gaya_penulisan"],
+		values["dosen_pengampu"],
+		values["prasyarat"],
 	)
 }
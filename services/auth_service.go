@@ -0,0 +1,254 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/config"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+)
+
+// AccessTokenClaims are the claims carried by an access token
+type AccessTokenClaims struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type AuthService interface {
+	Login(req *dto.LoginRequest) (*dto.AuthResponse, error)
+	Refresh(req *dto.RefreshTokenRequest) (*dto.AuthResponse, error)
+	Logout(claims *AccessTokenClaims, req *dto.LogoutRequest) error
+	ValidateAccessToken(tokenString string) (*models.User, *AccessTokenClaims, error)
+}
+
+type authService struct {
+	userRepo  repositories.UserRepository
+	tokenRepo repositories.AuthTokenRepository
+	config    config.JWTConfig
+}
+
+func NewAuthService(userRepo repositories.UserRepository, tokenRepo repositories.AuthTokenRepository, jwtConfig config.JWTConfig) AuthService {
+	return &authService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		config:    jwtConfig,
+	}
+}
+
+func (s *authService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByUsername(req.Username)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, helper.ErrUnauthorized
+		}
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	if !helper.CheckPassword(user.PasswordHash, req.Password) {
+		return nil, helper.ErrUnauthorized
+	}
+
+	refreshToken, rawRefreshToken, err := s.newRefreshToken(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokenRepo.CreateRefreshToken(refreshToken); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return s.buildAuthResponse(user, rawRefreshToken)
+}
+
+func (s *authService) Refresh(req *dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	current, err := s.tokenRepo.FindRefreshTokenByHash(hashToken(req.RefreshToken))
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, helper.ErrUnauthorized
+		}
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	// A revoked token being presented again means it leaked; drop every session of the user
+	if current.RevokedAt != nil {
+		s.tokenRepo.RevokeAllRefreshTokensByUserID(current.UserID)
+		return nil, helper.ErrUnauthorized
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return nil, helper.ErrUnauthorized
+	}
+
+	user, err := s.userRepo.FindByID(current.UserID)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, helper.ErrUnauthorized
+		}
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	replacement, rawRefreshToken, err := s.newRefreshToken(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokenRepo.RotateRefreshToken(current, replacement); err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, helper.ErrUnauthorized
+		}
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return s.buildAuthResponse(user, rawRefreshToken)
+}
+
+func (s *authService) Logout(claims *AccessTokenClaims, req *dto.LogoutRequest) error {
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return helper.ErrUnauthorized
+	}
+
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		revoked := &models.RevokedAccessToken{
+			JTI:       claims.ID,
+			UserID:    userID,
+			ExpiresAt: claims.ExpiresAt.Time,
+		}
+		if err := s.tokenRepo.RevokeAccessToken(revoked); err != nil {
+			return helper.WrapDatabaseError(err)
+		}
+	}
+
+	// Without a specific refresh token, log out every session of the user
+	if req == nil || req.RefreshToken == "" {
+		return s.tokenRepo.RevokeAllRefreshTokensByUserID(userID)
+	}
+
+	token, err := s.tokenRepo.FindRefreshTokenByHash(hashToken(req.RefreshToken))
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil
+		}
+		return helper.WrapDatabaseError(err)
+	}
+	if token.UserID != userID {
+		return helper.ErrForbidden
+	}
+
+	return s.tokenRepo.RevokeRefreshToken(token.ID)
+}
+
+func (s *authService) ValidateAccessToken(tokenString string) (*models.User, *AccessTokenClaims, error) {
+	if s.config.Secret == "" {
+		return nil, nil, helper.ErrUnauthorized
+	}
+
+	claims := &AccessTokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(s.config.Secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return nil, nil, helper.ErrUnauthorized
+	}
+
+	revoked, err := s.tokenRepo.IsAccessTokenRevoked(claims.ID)
+	if err != nil {
+		return nil, nil, helper.WrapDatabaseError(err)
+	}
+	if revoked {
+		return nil, nil, helper.ErrUnauthorized
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, nil, helper.ErrUnauthorized
+	}
+
+	// Reload the user so deleted accounts and role changes take effect immediately
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, nil, helper.ErrUnauthorized
+		}
+		return nil, nil, helper.WrapDatabaseError(err)
+	}
+
+	return user, claims, nil
+}
+
+func (s *authService) buildAuthResponse(user *models.User, rawRefreshToken string) (*dto.AuthResponse, error) {
+	accessToken, err := s.newAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: rawRefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.config.AccessExpiry.Seconds()),
+		User:         helper.ToUserResponse(user),
+	}, nil
+}
+
+func (s *authService) newAccessToken(user *models.User) (string, error) {
+	if s.config.Secret == "" {
+		return "", errors.New("JWT_SECRET is not set")
+	}
+
+	now := time.Now()
+	claims := AccessTokenClaims{
+		Username: user.Username,
+		Role:     user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.config.AccessExpiry)),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.config.Secret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return signed, nil
+}
+
+// newRefreshToken returns the row to persist and the raw token handed to the client
+func (s *authService) newRefreshToken(userID uuid.UUID) (*models.RefreshToken, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	raw := base64.RawURLEncoding.EncodeToString(buf)
+
+	return &models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(s.config.RefreshExpiry),
+	}, raw, nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
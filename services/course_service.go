@@ -1,12 +1,23 @@
 package services
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
 	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
 	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
 )
 
+var (
+	ErrSelfPrerequisite     = fmt.Errorf("%w: a course cannot require itself", helper.ErrInvalidInput)
+	ErrPrerequisiteCycle    = fmt.Errorf("%w: prerequisite would create a cycle", helper.ErrInvalidInput)
+	ErrPrerequisiteNotFound = fmt.Errorf("prerequisite %w", helper.ErrNotFound)
+	ErrCreditSplit          = fmt.Errorf("%w: theory and practice credits must add up to the course credits", helper.ErrInvalidInput)
+)
+
 type CourseService interface {
 	Create(req *dto.CreateCourseRequest) (*dto.CourseResponse, error)
 	FindAll() ([]dto.CourseResponse, error)
@@ -14,14 +25,23 @@ type CourseService interface {
 	FindByProgramID(programID uuid.UUID) ([]dto.CourseResponse, error)
 	Update(id uuid.UUID, req *dto.UpdateCourseRequest) (*dto.CourseResponse, error)
 	Delete(id uuid.UUID) error
+	AssignLecturer(courseID uuid.UUID, req *dto.AssignLecturerRequest) (*dto.CourseResponse, error)
+	UnassignLecturer(courseID, userID uuid.UUID) (*dto.CourseResponse, error)
+	SetLearningOutcomes(courseID uuid.UUID, req *dto.SetCourseLearningOutcomesRequest) (*dto.CourseResponse, error)
+	AddPrerequisite(courseID uuid.UUID, req *dto.AddPrerequisiteRequest) (*dto.CoursePrerequisiteNode, error)
+	RemovePrerequisite(courseID, prerequisiteID uuid.UUID) error
+	FindPrerequisiteTree(courseID uuid.UUID) (*dto.CoursePrerequisiteNode, error)
+	FindDependents(courseID uuid.UUID) ([]dto.CourseDependentResponse, error)
 }
 
 type courseService struct {
-	repo repositories.CourseRepository
+	repo        repositories.CourseRepository
+	userRepo    repositories.UserRepository
+	outcomeRepo repositories.LearningOutcomeRepository
 }
 
-func NewCourseService(repo repositories.CourseRepository) CourseService {
-	return &courseService{repo: repo}
+func NewCourseService(repo repositories.CourseRepository, userRepo repositories.UserRepository, outcomeRepo repositories.LearningOutcomeRepository) CourseService {
+	return &courseService{repo: repo, userRepo: userRepo, outcomeRepo: outcomeRepo}
 }
 
 func (s *courseService) Create(req *dto.CreateCourseRequest) (*dto.CourseResponse, error) {
@@ -30,6 +50,9 @@ func (s *courseService) Create(req *dto.CreateCourseRequest) (*dto.CourseRespons
 	}
 
 	course := helper.ToCourseModel(req)
+	if err := checkCreditSplit(course); err != nil {
+		return nil, err
+	}
 	if err := s.repo.Create(course); err != nil {
 		return nil, helper.WrapDatabaseError(err)
 	}
@@ -74,6 +97,27 @@ func (s *courseService) Update(id uuid.UUID, req *dto.UpdateCourseRequest) (*dto
 		return nil, helper.WrapDatabaseError(err)
 	}
 
+	moved := req.ProgramID != nil && !sameProgram(course.ProgramID, req.ProgramID)
+	applyCourseUpdate(course, req)
+	if err := checkCreditSplit(course); err != nil {
+		return nil, err
+	}
+
+	// Moving to another program also unmaps the CPL of the former program
+	if err := s.repo.Update(course); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if moved {
+		if course, err = s.repo.FindByID(id); err != nil {
+			return nil, helper.WrapDatabaseError(err)
+		}
+	}
+
+	return helper.ToCourseResponse(course), nil
+}
+
+// applyCourseUpdate copies the fields set in req onto course
+func applyCourseUpdate(course *models.Course, req *dto.UpdateCourseRequest) {
 	if req.ProgramID != nil {
 		course.ProgramID = req.ProgramID
 	}
@@ -86,12 +130,24 @@ func (s *courseService) Update(id uuid.UUID, req *dto.UpdateCourseRequest) (*dto
 	if req.Credits != nil {
 		course.Credits = req.Credits
 	}
-
-	if err := s.repo.Update(course); err != nil {
-		return nil, helper.WrapDatabaseError(err)
+	if req.Semester != nil {
+		course.Semester = req.Semester
+	}
+	if req.CourseType != nil {
+		course.CourseType = req.CourseType
+	}
+	if req.TheoryCredits != nil {
+		course.TheoryCredits = req.TheoryCredits
+	}
+	if req.PracticeCredits != nil {
+		course.PracticeCredits = req.PracticeCredits
+	}
+	if req.Description != nil {
+		course.Description = req.Description
+	}
+	if req.BahanKajian != nil {
+		course.BahanKajian = helper.ToBahanKajianJSON(req.BahanKajian)
 	}
-
-	return helper.ToCourseResponse(course), nil
 }
 
 func (s *courseService) Delete(id uuid.UUID) error {
@@ -101,3 +157,252 @@ func (s *courseService) Delete(id uuid.UUID) error {
 
 	return s.repo.Delete(id)
 }
+
+func (s *courseService) AssignLecturer(courseID uuid.UUID, req *dto.AssignLecturerRequest) (*dto.CourseResponse, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.FindByID(courseID); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	user, err := s.userRepo.FindByID(req.UserID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if user.Role != models.RoleDosen && user.Role != models.RoleKaprodi {
+		return nil, helper.ErrInvalidInput
+	}
+
+	if err := s.repo.AddLecturer(courseID, user); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return s.FindByID(courseID)
+}
+
+func (s *courseService) UnassignLecturer(courseID, userID uuid.UUID) (*dto.CourseResponse, error) {
+	if _, err := s.repo.FindByID(courseID); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	if err := s.repo.RemoveLecturer(courseID, user); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return s.FindByID(courseID)
+}
+
+// SetLearningOutcomes replaces the CPL a course supports; they must belong to the course program
+func (s *courseService) SetLearningOutcomes(courseID uuid.UUID, req *dto.SetCourseLearningOutcomesRequest) (*dto.CourseResponse, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	course, err := s.repo.FindByID(courseID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	unique := make([]uuid.UUID, 0, len(req.LearningOutcomeIDs))
+	seen := map[uuid.UUID]bool{}
+	for _, id := range req.LearningOutcomeIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	var outcomes []models.LearningOutcome
+	if len(unique) > 0 {
+		outcomes, err = s.outcomeRepo.FindByIDs(unique)
+		if err != nil {
+			return nil, helper.WrapDatabaseError(err)
+		}
+		if len(outcomes) != len(unique) {
+			return nil, ErrLearningOutcomeProgramMismatch
+		}
+		for _, outcome := range outcomes {
+			if course.ProgramID == nil || outcome.ProgramID != *course.ProgramID {
+				return nil, ErrLearningOutcomeProgramMismatch
+			}
+		}
+	}
+
+	if err := s.repo.SetLearningOutcomes(courseID, outcomes); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return s.FindByID(courseID)
+}
+
+// AddPrerequisite makes courseID require another course. Every edge points from a course
+// to what it requires, corequisites included, and the graph must stay acyclic.
+func (s *courseService) AddPrerequisite(courseID uuid.UUID, req *dto.AddPrerequisiteRequest) (*dto.CoursePrerequisiteNode, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+	if req.PrerequisiteID == courseID {
+		return nil, ErrSelfPrerequisite
+	}
+
+	if _, err := s.repo.FindByID(courseID); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if _, err := s.repo.FindByID(req.PrerequisiteID); err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, ErrPrerequisiteNotFound
+		}
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	edges, err := s.repo.FindPrerequisiteEdges()
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if requires(prerequisiteGraph(edges), req.PrerequisiteID, courseID) {
+		return nil, ErrPrerequisiteCycle
+	}
+
+	edgeType := req.Type
+	if edgeType == "" {
+		edgeType = models.PrerequisiteTypePrerequisite
+	}
+	edge := &models.CoursePrerequisite{
+		CourseID:       courseID,
+		PrerequisiteID: req.PrerequisiteID,
+		Type:           edgeType,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.repo.SavePrerequisite(edge); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return s.FindPrerequisiteTree(courseID)
+}
+
+func (s *courseService) RemovePrerequisite(courseID, prerequisiteID uuid.UUID) error {
+	if _, err := s.repo.FindByID(courseID); err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+
+	removed, err := s.repo.RemovePrerequisite(courseID, prerequisiteID)
+	if err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+	if !removed {
+		return ErrPrerequisiteNotFound
+	}
+	return nil
+}
+
+// FindPrerequisiteTree returns the course with everything it requires, transitively
+func (s *courseService) FindPrerequisiteTree(courseID uuid.UUID) (*dto.CoursePrerequisiteNode, error) {
+	course, err := s.repo.FindByID(courseID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	edges, err := s.repo.FindPrerequisiteEdges()
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	root := &dto.CoursePrerequisiteNode{Course: *helper.ToCourseResponse(course)}
+	root.Prerequisites = prerequisiteNodes(prerequisiteGraph(edges), courseID, map[uuid.UUID]bool{courseID: true})
+	return root, nil
+}
+
+// FindDependents returns the courses that directly require courseID
+func (s *courseService) FindDependents(courseID uuid.UUID) ([]dto.CourseDependentResponse, error) {
+	if _, err := s.repo.FindByID(courseID); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	edges, err := s.repo.FindDependents(courseID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	dependents := make([]dto.CourseDependentResponse, 0, len(edges))
+	for _, edge := range edges {
+		if edge.Course == nil {
+			continue
+		}
+		dependents = append(dependents, dto.CourseDependentResponse{Course: *helper.ToCourseResponse(edge.Course), Type: edge.Type})
+	}
+	return dependents, nil
+}
+
+// checkCreditSplit requires the theory and practice SKS to add up to the course SKS
+func checkCreditSplit(course *models.Course) error {
+	if course.TheoryCredits == nil && course.PracticeCredits == nil {
+		return nil
+	}
+
+	split := 0
+	if course.TheoryCredits != nil {
+		split += *course.TheoryCredits
+	}
+	if course.PracticeCredits != nil {
+		split += *course.PracticeCredits
+	}
+	if course.Credits == nil || split != *course.Credits {
+		return ErrCreditSplit
+	}
+	return nil
+}
+
+// prerequisiteGraph indexes edges by the course that requires them
+func prerequisiteGraph(edges []models.CoursePrerequisite) map[uuid.UUID][]models.CoursePrerequisite {
+	graph := map[uuid.UUID][]models.CoursePrerequisite{}
+	for _, edge := range edges {
+		graph[edge.CourseID] = append(graph[edge.CourseID], edge)
+	}
+	return graph
+}
+
+// requires reports whether from already requires target, directly or transitively
+func requires(graph map[uuid.UUID][]models.CoursePrerequisite, from, target uuid.UUID) bool {
+	visited := map[uuid.UUID]bool{}
+	stack := []uuid.UUID{from}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == target {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		for _, edge := range graph[current] {
+			stack = append(stack, edge.PrerequisiteID)
+		}
+	}
+	return false
+}
+
+// prerequisiteNodes builds the subtree below courseID; path holds the courses of the current branch
+func prerequisiteNodes(graph map[uuid.UUID][]models.CoursePrerequisite, courseID uuid.UUID, path map[uuid.UUID]bool) []dto.CoursePrerequisiteNode {
+	nodes := []dto.CoursePrerequisiteNode{}
+	for _, edge := range graph[courseID] {
+		if edge.Prerequisite == nil || path[edge.PrerequisiteID] {
+			continue
+		}
+		path[edge.PrerequisiteID] = true
+		nodes = append(nodes, dto.CoursePrerequisiteNode{
+			Course:        *helper.ToCourseResponse(edge.Prerequisite),
+			Type:          edge.Type,
+			Prerequisites: prerequisiteNodes(graph, edge.PrerequisiteID, path),
+		})
+		delete(path, edge.PrerequisiteID)
+	}
+	return nodes
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestCheckCreditSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		course  models.Course
+		wantErr bool
+	}{
+		{"no split", models.Course{Credits: intPtr(3)}, false},
+		{"no split and no credits", models.Course{}, false},
+		{"split matches", models.Course{Credits: intPtr(3), TheoryCredits: intPtr(2), PracticeCredits: intPtr(1)}, false},
+		{"theory only matches", models.Course{Credits: intPtr(2), TheoryCredits: intPtr(2)}, false},
+		{"practice only matches", models.Course{Credits: intPtr(1), PracticeCredits: intPtr(1)}, false},
+		{"split too small", models.Course{Credits: intPtr(4), TheoryCredits: intPtr(2), PracticeCredits: intPtr(1)}, true},
+		{"split too large", models.Course{Credits: intPtr(2), TheoryCredits: intPtr(2), PracticeCredits: intPtr(1)}, true},
+		{"split without credits", models.Course{TheoryCredits: intPtr(2)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCreditSplit(&tt.course)
+			if tt.wantErr && !errors.Is(err, ErrCreditSplit) {
+				t.Fatalf("checkCreditSplit() = %v, want ErrCreditSplit", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("checkCreditSplit() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+// testCourses returns n courses coded A, B, C and so on
+func testCourses(n int) []models.Course {
+	courses := make([]models.Course, n)
+	for i := range courses {
+		courses[i] = models.Course{ID: uuid.New(), Code: string(rune('A' + i)), Title: "Course " + string(rune('A'+i))}
+	}
+	return courses
+}
+
+func edge(course, prerequisite *models.Course, kind string) models.CoursePrerequisite {
+	return models.CoursePrerequisite{
+		CourseID:       course.ID,
+		PrerequisiteID: prerequisite.ID,
+		Type:           kind,
+		Course:         course,
+		Prerequisite:   prerequisite,
+	}
+}
+
+func TestRequires(t *testing.T) {
+	c := testCourses(5)
+	a, b, d, e, f := &c[0], &c[1], &c[2], &c[3], &c[4]
+
+	// a -> b -> d, a -> e; f stands alone
+	graph := prerequisiteGraph([]models.CoursePrerequisite{
+		edge(a, b, models.PrerequisiteTypePrerequisite),
+		edge(b, d, models.PrerequisiteTypeCorequisite),
+		edge(a, e, models.PrerequisiteTypePrerequisite),
+	})
+
+	tests := []struct {
+		name     string
+		from, to uuid.UUID
+		want     bool
+	}{
+		{"itself", f.ID, f.ID, true},
+		{"direct", a.ID, b.ID, true},
+		{"transitive", a.ID, d.ID, true},
+		{"other branch", a.ID, e.ID, true},
+		{"reverse direction", d.ID, a.ID, false},
+		{"siblings", b.ID, e.ID, false},
+		{"unrelated", a.ID, f.ID, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requires(graph, tt.from, tt.to); got != tt.want {
+				t.Fatalf("requires() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiresStopsOnCycle(t *testing.T) {
+	c := testCourses(3)
+	a, b, d := &c[0], &c[1], &c[2]
+
+	// a -> b -> a is stored only if an earlier check was bypassed; the walk must still end
+	graph := prerequisiteGraph([]models.CoursePrerequisite{
+		edge(a, b, models.PrerequisiteTypePrerequisite),
+		edge(b, a, models.PrerequisiteTypePrerequisite),
+	})
+	if requires(graph, a.ID, d.ID) {
+		t.Fatal("requires() = true for a course outside the cycle")
+	}
+}
+
+// flatten lists the codes of a prerequisite tree depth first, with the depth of each node
+func flatten(nodes []dto.CoursePrerequisiteNode, depth int, out *[]string) {
+	for _, node := range nodes {
+		*out = append(*out, string(rune('0'+depth))+node.Course.Code+":"+node.Type)
+		flatten(node.Prerequisites, depth+1, out)
+	}
+}
+
+func TestPrerequisiteNodes(t *testing.T) {
+	c := testCourses(4)
+	a, b, d, e := &c[0], &c[1], &c[2], &c[3]
+
+	tests := []struct {
+		name  string
+		edges []models.CoursePrerequisite
+		root  uuid.UUID
+		want  []string
+	}{
+		{
+			name: "no prerequisites",
+			root: a.ID,
+			want: nil,
+		},
+		{
+			name: "chain",
+			edges: []models.CoursePrerequisite{
+				edge(a, b, models.PrerequisiteTypePrerequisite),
+				edge(b, d, models.PrerequisiteTypeCorequisite),
+			},
+			root: a.ID,
+			want: []string{"0B:prerequisite", "1C:corequisite"},
+		},
+		{
+			name: "shared prerequisite appears under each branch",
+			edges: []models.CoursePrerequisite{
+				edge(a, b, models.PrerequisiteTypePrerequisite),
+				edge(a, d, models.PrerequisiteTypePrerequisite),
+				edge(b, e, models.PrerequisiteTypePrerequisite),
+				edge(d, e, models.PrerequisiteTypePrerequisite),
+			},
+			root: a.ID,
+			want: []string{"0B:prerequisite", "1D:prerequisite", "0C:prerequisite", "1D:prerequisite"},
+		},
+		{
+			name: "cycle back to the root is cut",
+			edges: []models.CoursePrerequisite{
+				edge(a, b, models.PrerequisiteTypePrerequisite),
+				edge(b, a, models.PrerequisiteTypePrerequisite),
+			},
+			root: a.ID,
+			want: []string{"0B:prerequisite"},
+		},
+		{
+			name: "edge without loaded course is skipped",
+			edges: []models.CoursePrerequisite{
+				{CourseID: a.ID, PrerequisiteID: b.ID, Type: models.PrerequisiteTypePrerequisite},
+			},
+			root: a.ID,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := prerequisiteNodes(prerequisiteGraph(tt.edges), tt.root, map[uuid.UUID]bool{tt.root: true})
+			if nodes == nil {
+				t.Fatal("prerequisiteNodes() = nil, want an empty list")
+			}
+			var got []string
+			flatten(nodes, 0, &got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("prerequisiteNodes() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("prerequisiteNodes() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
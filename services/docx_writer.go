@@ -0,0 +1,394 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image"
+	"strings"
+)
+
+// docxDocument is a minimal WordprocessingML (.docx) writer covering what the RPS
+// exports need: paragraphs, headings, lists, bordered tables, page setup,
+// a page header/footer and a single embedded image (the template logo)
+type docxDocument struct {
+	body      strings.Builder
+	landscape bool
+	header    string
+	footer    string
+	image     []byte
+	imageExt  string // png|jpeg
+}
+
+// docxPart is a file inside the .docx package
+type docxPart struct {
+	name    string
+	content string
+}
+
+// docxCell is a table cell; Span merges it over several grid columns
+type docxCell struct {
+	Text  string
+	Bold  bool
+	Align string // left|center|right|both
+	Span  int
+	Fill  string // hex background, e.g. "F5F5F5"
+	Color string // hex text color
+}
+
+const (
+	docxPageWidthA4  = 11906 // twips
+	docxPageHeightA4 = 16838
+	docxMargin       = 850 // 15mm
+	docxEMUPerMM     = 36000
+)
+
+func newDocxDocument(landscape bool) *docxDocument {
+	return &docxDocument{landscape: landscape}
+}
+
+// contentWidth returns the usable page width in twips
+func (d *docxDocument) contentWidth() int {
+	if d.landscape {
+		return docxPageHeightA4 - 2*docxMargin
+	}
+	return docxPageWidthA4 - 2*docxMargin
+}
+
+// mmToTwips converts column widths given in mm (as used by the PDF exporter)
+func mmToTwips(mm float64) int {
+	return int(mm * 56.7)
+}
+
+func (d *docxDocument) addParagraph(text string, bold bool, size int, align string) {
+	d.body.WriteString(`<w:p><w:pPr><w:spacing w:after="80"/>`)
+	if align != "" {
+		fmt.Fprintf(&d.body, `<w:jc w:val="%s"/>`, align)
+	}
+	d.body.WriteString("</w:pPr>")
+	d.writeRun(text, bold, size, "")
+	d.body.WriteString("</w:p>")
+}
+
+func (d *docxDocument) addTitle(text string) {
+	d.addParagraph(text, true, 36, "center")
+}
+
+func (d *docxDocument) addSectionTitle(text string) {
+	d.body.WriteString(`<w:p><w:pPr><w:pBdr><w:bottom w:val="single" w:sz="8" w:space="1" w:color="2980B9"/></w:pBdr>` +
+		`<w:spacing w:before="240" w:after="120"/></w:pPr>`)
+	d.writeRun(text, true, 26, "2980B9")
+	d.body.WriteString("</w:p>")
+}
+
+func (d *docxDocument) addSubTitle(text string) {
+	d.addParagraph(text, true, 22, "")
+}
+
+func (d *docxDocument) addNumberedList(items []string) {
+	if len(items) == 0 {
+		d.addParagraph("-", false, 20, "")
+		return
+	}
+	for i, item := range items {
+		d.body.WriteString(`<w:p><w:pPr><w:spacing w:after="40"/><w:ind w:left="567" w:hanging="340"/></w:pPr>`)
+		d.writeRun(fmt.Sprintf("%d. %s", i+1, item), false, 20, "")
+		d.body.WriteString("</w:p>")
+	}
+}
+
+// setHeader and setFooter set the text repeated on every page; {page} and
+// {pages} become PAGE and NUMPAGES fields
+func (d *docxDocument) setHeader(text string) {
+	d.header = text
+}
+
+func (d *docxDocument) setFooter(text string) {
+	d.footer = text
+}
+
+// addImage embeds a PNG or JPEG image scaled to widthMM; only one image per document is supported
+func (d *docxDocument) addImage(data []byte, widthMM float64, align string) error {
+	if d.image != nil {
+		return fmt.Errorf("docx: only one image is supported")
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("docx: failed to decode image: %w", err)
+	}
+	if format != "png" && format != "jpeg" {
+		return fmt.Errorf("docx: unsupported image format %s", format)
+	}
+	d.image = data
+	d.imageExt = format
+
+	cx := int(widthMM * docxEMUPerMM)
+	cy := cx * cfg.Height / cfg.Width
+
+	d.body.WriteString(`<w:p><w:pPr><w:spacing w:after="120"/>`)
+	if align != "" {
+		fmt.Fprintf(&d.body, `<w:jc w:val="%s"/>`, align)
+	}
+	d.body.WriteString("</w:pPr>")
+	fmt.Fprintf(&d.body, `<w:r><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0">`+
+		`<wp:extent cx="%d" cy="%d"/><wp:docPr id="1" name="Logo"/>`+
+		`<a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">`+
+		`<a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:pic xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:nvPicPr><pic:cNvPr id="1" name="logo.%s"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="rIdImage1"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r></w:p>`,
+		cx, cy, format, cx, cy)
+	return nil
+}
+
+func (d *docxDocument) addPageBreak() {
+	d.body.WriteString(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
+}
+
+// addTable writes a bordered table; widths are grid column widths in twips
+func (d *docxDocument) addTable(widths []int, rows [][]docxCell, headerRows int) {
+	d.body.WriteString(`<w:tbl><w:tblPr><w:tblW w:w="0" w:type="auto"/><w:tblBorders>`)
+	for _, side := range []string{"top", "left", "bottom", "right", "insideH", "insideV"} {
+		fmt.Fprintf(&d.body, `<w:%s w:val="single" w:sz="4" w:space="0" w:color="999999"/>`, side)
+	}
+	d.body.WriteString(`</w:tblBorders><w:tblLayout w:type="fixed"/><w:tblCellMar><w:left w:w="80" w:type="dxa"/><w:right w:w="80" w:type="dxa"/></w:tblCellMar></w:tblPr><w:tblGrid>`)
+	for _, w := range widths {
+		fmt.Fprintf(&d.body, `<w:gridCol w:w="%d"/>`, w)
+	}
+	d.body.WriteString("</w:tblGrid>")
+
+	for r, row := range rows {
+		d.body.WriteString("<w:tr>")
+		if r < headerRows {
+			d.body.WriteString(`<w:trPr><w:tblHeader/></w:trPr>`)
+		}
+		col := 0
+		for _, cell := range row {
+			span := cell.Span
+			if span < 1 {
+				span = 1
+			}
+			width := 0
+			for i := col; i < col+span && i < len(widths); i++ {
+				width += widths[i]
+			}
+			col += span
+
+			fmt.Fprintf(&d.body, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/>`, width)
+			if span > 1 {
+				fmt.Fprintf(&d.body, `<w:gridSpan w:val="%d"/>`, span)
+			}
+			if cell.Fill != "" {
+				fmt.Fprintf(&d.body, `<w:shd w:val="clear" w:color="auto" w:fill="%s"/>`, cell.Fill)
+			}
+			d.body.WriteString("</w:tcPr>")
+
+			d.body.WriteString(`<w:p><w:pPr><w:spacing w:after="0"/>`)
+			if cell.Align != "" {
+				fmt.Fprintf(&d.body, `<w:jc w:val="%s"/>`, cell.Align)
+			}
+			d.body.WriteString("</w:pPr>")
+			d.writeRun(cell.Text, cell.Bold, 18, cell.Color)
+			d.body.WriteString("</w:p></w:tc>")
+		}
+		d.body.WriteString("</w:tr>")
+	}
+	d.body.WriteString("</w:tbl>")
+	// Word needs a paragraph between consecutive tables
+	d.body.WriteString(`<w:p><w:pPr><w:spacing w:after="120"/></w:pPr></w:p>`)
+}
+
+// writeRun writes text as a run, turning newlines into line breaks
+func (d *docxDocument) writeRun(text string, bold bool, size int, color string) {
+	writeDocxRun(&d.body, text, bold, size, color)
+}
+
+func writeDocxRun(sb *strings.Builder, text string, bold bool, size int, color string) {
+	sb.WriteString("<w:r><w:rPr>")
+	if bold {
+		sb.WriteString("<w:b/>")
+	}
+	if color != "" {
+		fmt.Fprintf(sb, `<w:color w:val="%s"/>`, color)
+	}
+	if size > 0 {
+		fmt.Fprintf(sb, `<w:sz w:val="%d"/><w:szCs w:val="%d"/>`, size, size)
+	}
+	sb.WriteString("</w:rPr>")
+
+	text = strings.ReplaceAll(text, "\r", "")
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			sb.WriteString("<w:br/>")
+		}
+		fmt.Fprintf(sb, `<w:t xml:space="preserve">%s</w:t>`, xmlEscape(line))
+	}
+	sb.WriteString("</w:r>")
+}
+
+// docxHeaderFooterPart renders a header (hdr) or footer (ftr) part with page number fields
+func docxHeaderFooterPart(tag, text string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<w:%s xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`+
+		` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`+
+		`<w:p><w:pPr><w:spacing w:after="0"/><w:jc w:val="center"/></w:pPr>`, tag)
+
+	for text != "" {
+		i := strings.Index(text, "{page")
+		if i < 0 {
+			writeDocxRun(&sb, text, false, 16, "808080")
+			break
+		}
+		if i > 0 {
+			writeDocxRun(&sb, text[:i], false, 16, "808080")
+		}
+		rest := text[i:]
+		switch {
+		case strings.HasPrefix(rest, "{pages}"):
+			sb.WriteString(`<w:fldSimple w:instr=" NUMPAGES ">`)
+			writeDocxRun(&sb, "1", false, 16, "808080")
+			sb.WriteString(`</w:fldSimple>`)
+			text = rest[len("{pages}"):]
+		case strings.HasPrefix(rest, "{page}"):
+			sb.WriteString(`<w:fldSimple w:instr=" PAGE ">`)
+			writeDocxRun(&sb, "1", false, 16, "808080")
+			sb.WriteString(`</w:fldSimple>`)
+			text = rest[len("{page}"):]
+		default:
+			writeDocxRun(&sb, "{page", false, 16, "808080")
+			text = rest[len("{page"):]
+		}
+	}
+
+	fmt.Fprintf(&sb, `</w:p></w:%s>`, tag)
+	return sb.String()
+}
+
+// bytes packages the document as a .docx zip archive
+func (d *docxDocument) bytes() ([]byte, error) {
+	width, height, orient := docxPageWidthA4, docxPageHeightA4, "portrait"
+	if d.landscape {
+		width, height, orient = docxPageHeightA4, docxPageWidthA4, "landscape"
+	}
+
+	contentTypes := docxContentTypesHead
+	documentRels := docxDocumentRelsHead
+	sectionRefs := ""
+	if d.header != "" {
+		contentTypes += `<Override PartName="/word/header1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/>`
+		documentRels += `<Relationship Id="rIdHeader1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>`
+		sectionRefs += `<w:headerReference w:type="default" r:id="rIdHeader1"/>`
+	}
+	if d.footer != "" {
+		contentTypes += `<Override PartName="/word/footer1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"/>`
+		documentRels += `<Relationship Id="rIdFooter1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer" Target="footer1.xml"/>`
+		sectionRefs += `<w:footerReference w:type="default" r:id="rIdFooter1"/>`
+	}
+	if d.image != nil {
+		documentRels += fmt.Sprintf(`<Relationship Id="rIdImage1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/logo.%s"/>`, d.imageExt)
+	}
+	contentTypes += "</Types>"
+	documentRels += "</Relationships>"
+
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"` +
+		` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"` +
+		` xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"><w:body>` +
+		d.body.String() +
+		fmt.Sprintf(`<w:sectPr>%s<w:pgSz w:w="%d" w:h="%d" w:orient="%s"/>`+
+			`<w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="425" w:footer="425" w:gutter="0"/></w:sectPr>`,
+			sectionRefs, width, height, orient, docxMargin, docxMargin, docxMargin, docxMargin) +
+		`</w:body></w:document>`
+
+	parts := []docxPart{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", docxRootRels},
+		{"word/_rels/document.xml.rels", documentRels},
+		{"word/styles.xml", docxStyles},
+		{"word/document.xml", document},
+	}
+	if d.header != "" {
+		parts = append(parts, docxPart{"word/header1.xml", docxHeaderFooterPart("hdr", d.header)})
+	}
+	if d.footer != "" {
+		parts = append(parts, docxPart{"word/footer1.xml", docxHeaderFooterPart("ftr", d.footer)})
+	}
+	if d.image != nil {
+		parts = append(parts, docxPart{"word/media/logo." + d.imageExt, string(d.image)})
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, part := range parts {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize docx: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func xmlEscape(text string) string {
+	var buf bytes.Buffer
+	for _, r := range text {
+		// Drop control characters that are invalid in XML 1.0
+		if r < 0x20 && r != '\t' {
+			continue
+		}
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		case '"':
+			buf.WriteString("&quot;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+// docxContentTypesHead and docxDocumentRelsHead are left open so bytes() can
+// append the optional header, footer and image entries
+const docxContentTypesHead = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Default Extension="png" ContentType="image/png"/>
+<Default Extension="jpeg" ContentType="image/jpeg"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+`
+
+const docxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+const docxDocumentRelsHead = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+`
+
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:docDefaults>
+<w:rPrDefault><w:rPr><w:rFonts w:ascii="Arial" w:hAnsi="Arial" w:eastAsia="Arial" w:cs="Arial"/><w:sz w:val="20"/><w:szCs w:val="20"/><w:lang w:val="id-ID"/></w:rPr></w:rPrDefault>
+<w:pPrDefault><w:pPr><w:spacing w:after="80" w:line="259" w:lineRule="auto"/></w:pPr></w:pPrDefault>
+</w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+<w:style w:type="table" w:default="1" w:styleId="TableNormal"><w:name w:val="Normal Table"/><w:tblPr><w:tblInd w:w="0" w:type="dxa"/><w:tblCellMar><w:top w:w="0" w:type="dxa"/><w:left w:w="108" w:type="dxa"/><w:bottom w:w="0" w:type="dxa"/><w:right w:w="108" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>
+</w:styles>`
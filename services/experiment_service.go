@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	models "github.com/syrlramadhan/dokumentasi-rps-api/models/mongo"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+	mongoRepo "github.com/syrlramadhan/dokumentasi-rps-api/repositories/mongo"
+)
+
+var (
+	ErrExperimentNotFound       = fmt.Errorf("experiment %w", helper.ErrNotFound)
+	ErrExperimentNameTaken      = errors.New("experiment name already exists")
+	ErrExperimentAlreadyRunning = errors.New("another experiment is already running")
+	ErrExperimentNotDraft       = errors.New("experiment has already started")
+	ErrExperimentNotRunning     = errors.New("experiment is not running")
+	ErrExperimentRunning        = errors.New("experiment is running, stop it first")
+)
+
+// Experiment statuses
+const (
+	ExperimentStatusDraft   = "draft"
+	ExperimentStatusRunning = "running"
+	ExperimentStatusStopped = "stopped"
+)
+
+// ExperimentAssignment is the experiment arm a generation runs in
+type ExperimentAssignment struct {
+	ExperimentID string
+	Arm          models.ExperimentArm
+}
+
+type ExperimentService interface {
+	Create(ctx context.Context, req *dto.CreateExperimentRequest, userID string) (*models.Experiment, error)
+	FindAll(ctx context.Context) ([]models.Experiment, error)
+	FindByID(ctx context.Context, id string) (*models.Experiment, error)
+	Update(ctx context.Context, id string, req *dto.UpdateExperimentRequest) (*models.Experiment, error)
+	Start(ctx context.Context, id string) (*models.Experiment, error)
+	Stop(ctx context.Context, id string) (*models.Experiment, error)
+	Delete(ctx context.Context, id string) error
+	Report(ctx context.Context, id string) (*dto.ExperimentReport, error)
+}
+
+type experimentService struct {
+	repo               mongoRepo.ExperimentRepository
+	promptTemplateRepo mongoRepo.PromptTemplateRepository
+	aiPromptRepo       mongoRepo.AIPromptRepository
+	aiGenerationRepo   mongoRepo.AIGenerationRepository
+	generatedRPSRepo   repositories.GeneratedRPSRepository
+	aiService          AIService
+}
+
+func NewExperimentService(
+	repo mongoRepo.ExperimentRepository,
+	promptTemplateRepo mongoRepo.PromptTemplateRepository,
+	aiPromptRepo mongoRepo.AIPromptRepository,
+	aiGenerationRepo mongoRepo.AIGenerationRepository,
+	generatedRPSRepo repositories.GeneratedRPSRepository,
+	aiService AIService,
+) ExperimentService {
+	return &experimentService{
+		repo:               repo,
+		promptTemplateRepo: promptTemplateRepo,
+		aiPromptRepo:       aiPromptRepo,
+		aiGenerationRepo:   aiGenerationRepo,
+		generatedRPSRepo:   generatedRPSRepo,
+		aiService:          aiService,
+	}
+}
+
+func (s *experimentService) Create(ctx context.Context, req *dto.CreateExperimentRequest, userID string) (*models.Experiment, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	arms, err := s.buildArms(ctx, req.Arms)
+	if err != nil {
+		return nil, err
+	}
+
+	experiment, err := s.repo.Create(ctx, &models.Experiment{
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      ExperimentStatusDraft,
+		Arms:        arms,
+		CreatedBy:   userID,
+	})
+	if err != nil {
+		return nil, experimentError(err)
+	}
+	return experiment, nil
+}
+
+func (s *experimentService) FindAll(ctx context.Context) ([]models.Experiment, error) {
+	experiments, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, experimentError(err)
+	}
+	return experiments, nil
+}
+
+func (s *experimentService) FindByID(ctx context.Context, id string) (*models.Experiment, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrExperimentNotFound
+	}
+
+	experiment, err := s.repo.FindByID(ctx, objectID)
+	if err != nil {
+		return nil, experimentError(err)
+	}
+	return experiment, nil
+}
+
+func (s *experimentService) Update(ctx context.Context, id string, req *dto.UpdateExperimentRequest) (*models.Experiment, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	experiment, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Arms != nil {
+		// Reports compare arms as they were run, so they are fixed once started
+		if experiment.Status != ExperimentStatusDraft {
+			return nil, ErrExperimentNotDraft
+		}
+		arms, err := s.buildArms(ctx, req.Arms)
+		if err != nil {
+			return nil, err
+		}
+		experiment.Arms = arms
+	}
+	if req.Name != nil {
+		experiment.Name = *req.Name
+	}
+	if req.Description != nil {
+		experiment.Description = *req.Description
+	}
+
+	experiment, err = s.repo.Update(ctx, experiment)
+	if err != nil {
+		return nil, experimentError(err)
+	}
+	return experiment, nil
+}
+
+// Start makes the experiment split the generation traffic. Only one experiment runs at a time.
+func (s *experimentService) Start(ctx context.Context, id string) (*models.Experiment, error) {
+	experiment, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if experiment.Status != ExperimentStatusDraft {
+		return nil, ErrExperimentNotDraft
+	}
+
+	if _, err := s.repo.FindRunning(ctx); err == nil {
+		return nil, ErrExperimentAlreadyRunning
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, experimentError(err)
+	}
+
+	now := time.Now()
+	experiment.Status = ExperimentStatusRunning
+	experiment.StartedAt = &now
+
+	experiment, err = s.repo.Update(ctx, experiment)
+	if err != nil {
+		// The partial unique index on running experiments settles a concurrent start
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrExperimentAlreadyRunning
+		}
+		return nil, experimentError(err)
+	}
+	return experiment, nil
+}
+
+// Stop ends the experiment; later generations use the active template and defaults again
+func (s *experimentService) Stop(ctx context.Context, id string) (*models.Experiment, error) {
+	experiment, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if experiment.Status != ExperimentStatusRunning {
+		return nil, ErrExperimentNotRunning
+	}
+
+	now := time.Now()
+	experiment.Status = ExperimentStatusStopped
+	experiment.StoppedAt = &now
+
+	experiment, err = s.repo.Update(ctx, experiment)
+	if err != nil {
+		return nil, experimentError(err)
+	}
+	return experiment, nil
+}
+
+func (s *experimentService) Delete(ctx context.Context, id string) error {
+	experiment, err := s.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if experiment.Status == ExperimentStatusRunning {
+		return ErrExperimentRunning
+	}
+
+	if err := s.repo.Delete(ctx, experiment.ID); err != nil {
+		return experimentError(err)
+	}
+	return nil
+}
+
+// Report compares the arms of the experiment
+func (s *experimentService) Report(ctx context.Context, id string) (*dto.ExperimentReport, error) {
+	experiment, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	experimentID := experiment.ID.Hex()
+
+	generationStats, err := s.aiGenerationRepo.ArmStats(ctx, experimentID)
+	if err != nil {
+		return nil, experimentError(err)
+	}
+	promptStats, err := s.aiPromptRepo.ArmStats(ctx, experimentID)
+	if err != nil {
+		return nil, experimentError(err)
+	}
+	jobStats, err := s.generatedRPSRepo.ExperimentStats(experimentID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	report := &dto.ExperimentReport{
+		ExperimentID: experimentID,
+		Name:         experiment.Name,
+		Status:       experiment.Status,
+		StartedAt:    experiment.StartedAt,
+		StoppedAt:    experiment.StoppedAt,
+		Arms:         make([]dto.ExperimentArmReport, 0, len(experiment.Arms)),
+	}
+	for _, arm := range experiment.Arms {
+		armReport := dto.ExperimentArmReport{
+			Arm:                   arm.Name,
+			Weight:                arm.Weight,
+			PromptTemplateID:      arm.PromptTemplateID,
+			PromptTemplateVersion: arm.PromptTemplateVersion,
+			Model:                 arm.Model,
+			Temperature:           arm.Temperature,
+			MaxTokens:             arm.MaxTokens,
+		}
+
+		for _, stats := range generationStats {
+			if stats.Arm != arm.Name {
+				continue
+			}
+			armReport.Generations = stats.Generations
+			armReport.Succeeded = stats.Succeeded
+			armReport.Failed = stats.Failed
+			armReport.Cancelled = stats.Cancelled
+			armReport.SuccessRate = ratio(stats.Succeeded, stats.Succeeded+stats.Failed)
+			armReport.TotalTokens = stats.TotalTokens
+			armReport.AvgTokens = ratio(stats.TotalTokens, stats.Generations)
+			armReport.TotalCost = stats.TotalCost
+			if stats.Generations > 0 {
+				armReport.AvgCost = stats.TotalCost / float64(stats.Generations)
+			}
+			armReport.AvgDurationMs = stats.AvgDurationMs
+		}
+		for _, stats := range promptStats {
+			if stats.Arm == arm.Name {
+				armReport.AvgLatencyMs = stats.AvgLatencyMs
+			}
+		}
+		for _, stats := range jobStats {
+			if stats.Arm != arm.Name {
+				continue
+			}
+			armReport.Jobs = stats.Jobs
+			armReport.Done = stats.Done
+			armReport.AvgFindings = ratio(stats.Findings, stats.Done)
+			armReport.AvgErrorFindings = ratio(stats.ErrorFindings, stats.Done)
+			armReport.Reviewed = stats.Reviewed
+			armReport.Approved = stats.Approved
+			armReport.AcceptanceRate = ratio(stats.Approved, stats.Reviewed)
+		}
+
+		report.Arms = append(report.Arms, armReport)
+	}
+	return report, nil
+}
+
+// buildArms checks the arm definitions, model settings included, and copies the prompt template version each one pins
+func (s *experimentService) buildArms(ctx context.Context, reqs []dto.ExperimentArmRequest) ([]models.ExperimentArm, error) {
+	arms := make([]models.ExperimentArm, 0, len(reqs))
+	seen := make(map[string]bool, len(reqs))
+	for _, req := range reqs {
+		if seen[req.Name] {
+			return nil, fmt.Errorf("%w: arm %q is defined twice", helper.ErrInvalidInput, req.Name)
+		}
+		seen[req.Name] = true
+
+		// Checked against the default provider; a generation checks the merged request
+		// again on the provider it runs on
+		options := dto.GenerateRPSOptions{Model: req.Model, Temperature: req.Temperature}
+		if req.MaxTokens > 0 {
+			options.MaxTokens = &req.MaxTokens
+		}
+		if err := s.aiService.CheckModelParams(options); err != nil {
+			return nil, fmt.Errorf("%w (arm %q)", err, req.Name)
+		}
+
+		arm := models.ExperimentArm{
+			Name:        req.Name,
+			Weight:      req.Weight,
+			Model:       req.Model,
+			Temperature: req.Temperature,
+			MaxTokens:   req.MaxTokens,
+		}
+
+		if req.PromptTemplateID != "" {
+			objectID, err := primitive.ObjectIDFromHex(req.PromptTemplateID)
+			if err != nil {
+				return nil, fmt.Errorf("%w: arm %q has an invalid prompt template ID", helper.ErrInvalidInput, req.Name)
+			}
+			template, err := s.promptTemplateRepo.FindByID(ctx, objectID)
+			if err != nil {
+				if errors.Is(err, mongo.ErrNoDocuments) {
+					return nil, fmt.Errorf("%w: prompt template of arm %q not found", helper.ErrInvalidInput, req.Name)
+				}
+				return nil, experimentError(err)
+			}
+			if template.Category != PromptCategoryRPS {
+				return nil, fmt.Errorf("%w: prompt template %q of arm %q is not an %s template", helper.ErrInvalidInput, template.Name, req.Name, PromptCategoryRPS)
+			}
+			if req.PromptTemplateVersion != 0 && req.PromptTemplateVersion != template.Version {
+				return nil, fmt.Errorf("%w: prompt template %q is at version %d, not %d", helper.ErrInvalidInput, template.Name, template.Version, req.PromptTemplateVersion)
+			}
+
+			arm.PromptTemplateID = template.ID.Hex()
+			arm.PromptTemplateName = template.Name
+			arm.PromptTemplateVersion = template.Version
+			arm.SystemPrompt = template.SystemPrompt
+			arm.UserPromptTemplate = template.UserPromptTemplate
+			arm.Variables = template.Variables
+		}
+
+		arms = append(arms, arm)
+	}
+	return arms, nil
+}
+
+// pickArm maps key onto the arms of experiment in proportion to their weights.
+// The same key always lands in the same arm, so retries of a job stay in it.
+func pickArm(experiment *models.Experiment, key string) *models.ExperimentArm {
+	total := 0
+	for _, arm := range experiment.Arms {
+		total += arm.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(experiment.ID.Hex() + key))
+	bucket := int(hash.Sum32() % uint32(total))
+	for i := range experiment.Arms {
+		bucket -= experiment.Arms[i].Weight
+		if bucket < 0 {
+			return &experiment.Arms[i]
+		}
+	}
+	return nil
+}
+
+// armPromptTemplate is the prompt template version pinned by arm, or nil when it uses the active one
+func armPromptTemplate(arm models.ExperimentArm) *models.PromptTemplate {
+	if arm.PromptTemplateID == "" {
+		return nil
+	}
+	id, err := primitive.ObjectIDFromHex(arm.PromptTemplateID)
+	if err != nil {
+		return nil
+	}
+	return &models.PromptTemplate{
+		ID:                 id,
+		Name:               arm.PromptTemplateName,
+		Version:            arm.PromptTemplateVersion,
+		SystemPrompt:       arm.SystemPrompt,
+		UserPromptTemplate: arm.UserPromptTemplate,
+		Variables:          arm.Variables,
+		Category:           PromptCategoryRPS,
+	}
+}
+
+func ratio(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+func experimentError(err error) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return ErrExperimentNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return ErrExperimentNameTaken
+	default:
+		return fmt.Errorf("%w: %v", helper.ErrDatabaseOperation, err)
+	}
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/config"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	models "github.com/syrlramadhan/dokumentasi-rps-api/models/mongo"
+	mongoRepo "github.com/syrlramadhan/dokumentasi-rps-api/repositories/mongo"
+)
+
+// testExperimentID is fixed so the arm shares below do not vary between runs
+var testExperimentID, _ = primitive.ObjectIDFromHex("65a1f0c2e4b0a1b2c3d4e5f6")
+
+func TestPickArm(t *testing.T) {
+	const keys = 10000
+
+	tests := []struct {
+		name  string
+		arms  []models.ExperimentArm
+		share map[string]float64 // expected share of the keys per arm; nil when no arm is picked
+	}{
+		{
+			name: "no arms",
+		},
+		{
+			name: "all weights zero",
+			arms: []models.ExperimentArm{{Name: "a"}, {Name: "b"}},
+		},
+		{
+			name:  "single arm",
+			arms:  []models.ExperimentArm{{Name: "a", Weight: 3}},
+			share: map[string]float64{"a": 1},
+		},
+		{
+			name:  "even split",
+			arms:  []models.ExperimentArm{{Name: "a", Weight: 1}, {Name: "b", Weight: 1}},
+			share: map[string]float64{"a": 0.5, "b": 0.5},
+		},
+		{
+			name:  "weighted split",
+			arms:  []models.ExperimentArm{{Name: "a", Weight: 70}, {Name: "b", Weight: 20}, {Name: "c", Weight: 10}},
+			share: map[string]float64{"a": 0.7, "b": 0.2, "c": 0.1},
+		},
+		{
+			name:  "zero-weight arm is never picked",
+			arms:  []models.ExperimentArm{{Name: "a", Weight: 0}, {Name: "b", Weight: 5}},
+			share: map[string]float64{"b": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			experiment := &models.Experiment{ID: testExperimentID, Arms: tt.arms}
+
+			counts := map[string]int{}
+			for i := 0; i < keys; i++ {
+				arm := pickArm(experiment, fmt.Sprintf("job-%d", i))
+				if arm == nil {
+					if tt.share != nil {
+						t.Fatalf("pickArm() = nil for key %d", i)
+					}
+					continue
+				}
+				if tt.share == nil {
+					t.Fatalf("pickArm() = %q, want nil", arm.Name)
+				}
+				counts[arm.Name]++
+			}
+
+			for name, count := range counts {
+				if _, ok := tt.share[name]; !ok {
+					t.Fatalf("arm %q picked %d times, want never", name, count)
+				}
+			}
+			for name, want := range tt.share {
+				if got := float64(counts[name]) / keys; math.Abs(got-want) > 0.03 {
+					t.Errorf("arm %q got %.3f of the keys, want about %.2f", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPickArmIsStable(t *testing.T) {
+	experiment := &models.Experiment{
+		ID:   primitive.NewObjectID(),
+		Arms: []models.ExperimentArm{{Name: "a", Weight: 1}, {Name: "b", Weight: 1}, {Name: "c", Weight: 1}},
+	}
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("job-%d", i)
+		first := pickArm(experiment, key)
+		if again := pickArm(experiment, key); again != first {
+			t.Fatalf("pickArm(%q) = %q then %q", key, first.Name, again.Name)
+		}
+	}
+}
+
+func TestArmPromptTemplate(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name string
+		arm  models.ExperimentArm
+		want *primitive.ObjectID
+	}{
+		{"active template", models.ExperimentArm{Name: "control"}, nil},
+		{"pinned version", models.ExperimentArm{Name: "variant", PromptTemplateID: id.Hex(), PromptTemplateVersion: 3}, &id},
+		{"malformed id", models.ExperimentArm{Name: "broken", PromptTemplateID: "not-an-id"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := armPromptTemplate(tt.arm)
+			if tt.want == nil {
+				if template != nil {
+					t.Fatalf("armPromptTemplate() = %+v, want nil", template)
+				}
+				return
+			}
+			if template == nil || template.ID != *tt.want || template.Version != tt.arm.PromptTemplateVersion || template.Category != PromptCategoryRPS {
+				t.Fatalf("armPromptTemplate() = %+v, want template %s version %d", template, tt.want.Hex(), tt.arm.PromptTemplateVersion)
+			}
+		})
+	}
+}
+
+func TestBuildArmsChecksModelParams(t *testing.T) {
+	s := &experimentService{aiService: &aiService{
+		providers:       map[string]LLMProvider{"fake": newFakeProvider("fake-rps-v1")},
+		defaultProvider: "fake",
+		params: config.AIParamConfig{
+			AllowedModels:  map[string][]string{"fake": {"fake-rps-v2"}},
+			MaxTemperature: 1.5,
+			MaxTopP:        1,
+			MaxTopK:        100,
+			MaxTokens:      16384,
+		},
+	}}
+
+	tests := []struct {
+		name    string
+		arm     dto.ExperimentArmRequest
+		wantErr bool
+	}{
+		{"no model settings", dto.ExperimentArmRequest{Name: "b", Weight: 1}, false},
+		{"allowed settings", dto.ExperimentArmRequest{Name: "b", Weight: 1, Model: "fake-rps-v2", Temperature: float64Ptr(1.2), MaxTokens: 16384}, false},
+		{"model not allowed", dto.ExperimentArmRequest{Name: "b", Weight: 1, Model: "gpt-4o"}, true},
+		{"temperature above the configured cap", dto.ExperimentArmRequest{Name: "b", Weight: 1, Temperature: float64Ptr(1.8)}, true},
+		{"max tokens above the configured cap", dto.ExperimentArmRequest{Name: "b", Weight: 1, MaxTokens: 32768}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arms, err := s.buildArms(context.Background(), []dto.ExperimentArmRequest{{Name: "a", Weight: 1}, tt.arm})
+			if !tt.wantErr {
+				if err != nil || len(arms) != 2 {
+					t.Fatalf("buildArms() = %d arms, %v; want 2 arms", len(arms), err)
+				}
+				return
+			}
+			if !errors.Is(err, helper.ErrInvalidInput) {
+				t.Fatalf("buildArms() error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
+
+// runningExperimentRepository always has experiment running
+type runningExperimentRepository struct {
+	mongoRepo.ExperimentRepository
+	experiment *models.Experiment
+}
+
+func (r *runningExperimentRepository) FindRunning(ctx context.Context) (*models.Experiment, error) {
+	return r.experiment, nil
+}
+
+func TestGenerateSyncChecksExperimentArm(t *testing.T) {
+	tests := []struct {
+		name string
+		arm  models.ExperimentArm
+		want string // model sent; empty when the request is rejected
+	}{
+		{name: "allowed arm", arm: models.ExperimentArm{Model: "fake-rps-v2", Temperature: float64Ptr(1.2)}, want: "fake-rps-v2"},
+		// Arms are checked when defined; the ranges may have narrowed since
+		{name: "temperature above the configured cap", arm: models.ExperimentArm{Temperature: float64Ptr(1.8)}},
+		{name: "model no longer allowed", arm: models.ExperimentArm{Model: "fake-rps-v3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGenerationFixture(t)
+			f.ai.params.AllowedModels = map[string][]string{"fake": {"fake-rps-v2"}}
+			f.ai.params.MaxTemperature = 1.5
+			arm := tt.arm
+			arm.Name, arm.Weight = "only", 1
+			f.ai.experimentRepo = &runningExperimentRepository{experiment: &models.Experiment{ID: testExperimentID, Arms: []models.ExperimentArm{arm}}}
+
+			_, err := f.service.GenerateSync(context.Background(), &dto.GenerateRPSRequest{TemplateVersionID: f.version.ID, CourseID: f.course.ID})
+			if tt.want == "" {
+				if !errors.Is(err, helper.ErrInvalidInput) {
+					t.Fatalf("GenerateSync() error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateSync() error = %v", err)
+			}
+			if len(f.prompts.prompts) != 1 || f.prompts.prompts[0].Model != tt.want || f.prompts.prompts[0].ExperimentArm != "only" {
+				t.Fatalf("prompts = %+v, want one prompt on %s in arm only", f.prompts.prompts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"gorm.io/datatypes"
+)
+
+var defaultSectionHeadings = map[string]string{
+	"identitas":             "IDENTITAS MATA KULIAH",
+	"capaian_pembelajaran":  "CAPAIAN PEMBELAJARAN",
+	"deskripsi_mata_kuliah": "DESKRIPSI MATA KULIAH",
+	"rencana_mingguan":      "RENCANA PEMBELAJARAN MINGGUAN",
+	"rencana_penilaian":     "RENCANA PENILAIAN",
+	"daftar_referensi":      "DAFTAR REFERENSI",
+}
+
+var defaultWeeklyColumnLabels = map[string]string{
+	"minggu":              "Minggu",
+	"topik":               "Topik",
+	"sub_topik":           "Sub Topik",
+	"indikator_capaian":   "Indikator Capaian",
+	"metode_pembelajaran": "Metode",
+	"waktu_menit":         "Waktu (menit)",
+	"referensi":           "Referensi",
+	"bentuk_penilaian":    "Penilaian",
+}
+
+// DefaultRPSLayout returns the standard RPS layout used when a template has no directives
+func DefaultRPSLayout() *dto.RPSLayout {
+	return &dto.RPSLayout{
+		Title:            "RENCANA PEMBELAJARAN SEMESTER (RPS)",
+		Orientation:      "landscape",
+		HeadingNumbering: "roman",
+		Sections: []dto.RPSLayoutSection{
+			{Key: "identitas"},
+			{Key: "capaian_pembelajaran"},
+			{Key: "deskripsi_mata_kuliah"},
+			{Key: "rencana_mingguan", PageBreakBefore: true},
+			{Key: "rencana_penilaian", PageBreakBefore: true},
+			{Key: "daftar_referensi"},
+		},
+		WeeklyColumns: []dto.RPSLayoutColumn{
+			{Key: "minggu", Width: 15},
+			{Key: "topik", Width: 50},
+			{Key: "sub_topik", Width: 50},
+			{Key: "indikator_capaian", Width: 55},
+			{Key: "metode_pembelajaran", Width: 40},
+			{Key: "waktu_menit", Width: 17},
+			{Key: "bentuk_penilaian", Width: 40},
+		},
+	}
+}
+
+// ParseRPSLayout reads the "layout" key of a template definition and fills
+// missing directives from DefaultRPSLayout. Invalid directives return either
+// validator errors or an error wrapping helper.ErrInvalidInput.
+func ParseRPSLayout(definition datatypes.JSON) (*dto.RPSLayout, error) {
+	layout := DefaultRPSLayout()
+	if len(definition) == 0 {
+		return layout, nil
+	}
+
+	var def struct {
+		Layout *dto.RPSLayout `json:"layout"`
+	}
+	if err := json.Unmarshal(definition, &def); err != nil {
+		return nil, fmt.Errorf("%w: layout: %v", helper.ErrInvalidInput, err)
+	}
+	if def.Layout == nil {
+		return layout, nil
+	}
+
+	custom := def.Layout
+	if err := helper.ValidateStruct(custom); err != nil {
+		return nil, err
+	}
+	if custom.Logo != nil {
+		if _, _, err := decodeLogo(custom.Logo.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if custom.Title != "" {
+		layout.Title = custom.Title
+	}
+	if custom.Orientation != "" {
+		layout.Orientation = custom.Orientation
+	}
+	if custom.HeadingNumbering != "" {
+		layout.HeadingNumbering = custom.HeadingNumbering
+	}
+	if len(custom.Sections) > 0 {
+		layout.Sections = custom.Sections
+	}
+	if len(custom.WeeklyColumns) > 0 {
+		layout.WeeklyColumns = custom.WeeklyColumns
+	}
+	layout.Header = custom.Header
+	layout.Footer = custom.Footer
+	layout.Logo = custom.Logo
+
+	for i := range layout.WeeklyColumns {
+		if layout.WeeklyColumns[i].Label == "" {
+			layout.WeeklyColumns[i].Label = defaultWeeklyColumnLabels[layout.WeeklyColumns[i].Key]
+		}
+		if layout.WeeklyColumns[i].Width == 0 {
+			layout.WeeklyColumns[i].Width = 30
+		}
+	}
+	if layout.Logo != nil {
+		if layout.Logo.Width == 0 {
+			layout.Logo.Width = 25
+		}
+		if layout.Logo.Position == "" {
+			layout.Logo.Position = "center"
+		}
+	}
+
+	return layout, nil
+}
+
+// LayoutForGeneratedRPS resolves the layout of the template version an RPS was generated from
+func LayoutForGeneratedRPS(rps *dto.GeneratedRPSResponse) (*dto.RPSLayout, error) {
+	if rps.TemplateVersion == nil {
+		return DefaultRPSLayout(), nil
+	}
+	return ParseRPSLayout(rps.TemplateVersion.Definition)
+}
+
+// layoutHeading returns the numbered heading of the section at index
+func layoutHeading(layout *dto.RPSLayout, index int, section dto.RPSLayoutSection) string {
+	heading := section.Heading
+	if heading == "" {
+		heading = defaultSectionHeadings[section.Key]
+	}
+
+	switch layout.HeadingNumbering {
+	case "roman":
+		return fmt.Sprintf("%s. %s", toRoman(index+1), heading)
+	case "numeric":
+		return fmt.Sprintf("%d. %s", index+1, heading)
+	case "alpha":
+		return fmt.Sprintf("%c. %s", 'A'+rune(index%26), heading)
+	default:
+		return heading
+	}
+}
+
+func toRoman(n int) string {
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+
+	var sb strings.Builder
+	for i, v := range values {
+		for n >= v {
+			sb.WriteString(symbols[i])
+			n -= v
+		}
+	}
+	return sb.String()
+}
+
+// weeklyCellValue returns the text of a rencana_mingguan column for one week
+func weeklyCellValue(plan dto.RPSRencanaMingguan, key string) string {
+	switch key {
+	case "minggu":
+		return fmt.Sprintf("%d", plan.Minggu)
+	case "topik":
+		return plan.Topik
+	case "sub_topik":
+		return strings.Join(plan.SubTopik, "\n")
+	case "indikator_capaian":
+		return plan.IndikatorCapaian
+	case "metode_pembelajaran":
+		return plan.MetodePembelajaran
+	case "waktu_menit":
+		return fmt.Sprintf("%d", plan.WaktuMenit)
+	case "referensi":
+		return plan.Referensi
+	case "bentuk_penilaian":
+		return plan.BentukPenilaian
+	}
+	return ""
+}
+
+// weeklyColumnCentered reports whether a column holds short numeric values
+func weeklyColumnCentered(key string) bool {
+	return key == "minggu" || key == "waktu_menit"
+}
+
+// scaleWidths scales relative column weights to the given total width
+func scaleWidths(weights []float64, total float64) []float64 {
+	sum := 0.0
+	for _, w := range weights {
+		sum += w
+	}
+	widths := make([]float64, len(weights))
+	for i, w := range weights {
+		widths[i] = w / sum * total
+	}
+	return widths
+}
+
+// layoutPageText expands {page}/{pages} placeholders in header and footer text
+func layoutPageText(text, page, pages string) string {
+	return strings.NewReplacer("{page}", page, "{pages}", pages).Replace(text)
+}
+
+// decodeLogo decodes a base64 data URI holding a PNG or JPEG image, returning
+// the image bytes and its format as understood by gofpdf (png|jpg)
+func decodeLogo(dataURI string) ([]byte, string, error) {
+	meta, payload, ok := strings.Cut(dataURI, ",")
+	if !ok || !strings.HasPrefix(meta, "data:") || !strings.HasSuffix(meta, ";base64") {
+		return nil, "", fmt.Errorf("%w: logo must be a base64 data URI", helper.ErrInvalidInput)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: logo is not valid base64", helper.ErrInvalidInput)
+	}
+
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: logo must be a PNG or JPEG image", helper.ErrInvalidInput)
+	}
+	switch format {
+	case "png":
+		return data, "png", nil
+	case "jpeg":
+		return data, "jpg", nil
+	}
+	return nil, "", fmt.Errorf("%w: logo must be a PNG or JPEG image", helper.ErrInvalidInput)
+}
+
+// identitasFields lists the label/value rows of the identitas section shared by every
+// exporter; catalog fields only appear when the result carries them
+func identitasFields(identitas dto.RPSIdentitas) [][]string {
+	rows := [][]string{
+		{"Nama Mata Kuliah", identitas.NamaMataKuliah},
+		{"Kode Mata Kuliah", identitas.KodeMataKuliah},
+		{"SKS", fmt.Sprintf("%d", identitas.SKS)},
+	}
+	if identitas.SKSTeori > 0 || identitas.SKSPraktikum > 0 {
+		rows = append(rows, []string{"Komposisi SKS", fmt.Sprintf("%d teori, %d praktikum", identitas.SKSTeori, identitas.SKSPraktikum)})
+	}
+	if identitas.SifatMataKuliah != "" {
+		rows = append(rows, []string{"Sifat Mata Kuliah", strings.ToUpper(identitas.SifatMataKuliah[:1]) + identitas.SifatMataKuliah[1:]})
+	}
+	return append(rows,
+		[]string{"Semester", identitas.Semester},
+		[]string{"Prasyarat", identitas.Prasyarat},
+		[]string{"Dosen Pengampu", identitas.DosenPengampu},
+	)
+}
@@ -3,15 +3,22 @@ package services
 import (
 	"bytes"
 	"fmt"
+	"html"
 	"strings"
 
 	"github.com/jung-kurt/gofpdf"
 	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
 )
 
+// DOCXMimeType is the content type of Word documents produced by ExportToDOCX
+const DOCXMimeType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// ExportService renders a generated RPS; every exporter follows the given layout,
+// or DefaultRPSLayout when layout is nil
 type ExportService interface {
-	ExportToPDF(rps *dto.RPSStructuredOutput) ([]byte, error)
-	ExportToHTML(rps *dto.RPSStructuredOutput) (string, error)
+	ExportToPDF(rps *dto.RPSStructuredOutput, layout *dto.RPSLayout) ([]byte, error)
+	ExportToHTML(rps *dto.RPSStructuredOutput, layout *dto.RPSLayout) (string, error)
+	ExportToDOCX(rps *dto.RPSStructuredOutput, layout *dto.RPSLayout) ([]byte, error)
 }
 
 type exportService struct{}
@@ -21,91 +28,151 @@ func NewExportService() ExportService {
 }
 
 // ExportToPDF generates a PDF document from RPS data
-func (s *exportService) ExportToPDF(rps *dto.RPSStructuredOutput) ([]byte, error) {
-	pdf := gofpdf.New("L", "mm", "A4", "") // Landscape for better table display
+func (s *exportService) ExportToPDF(rps *dto.RPSStructuredOutput, layout *dto.RPSLayout) ([]byte, error) {
+	if layout == nil {
+		layout = DefaultRPSLayout()
+	}
+
+	orientation := "L" // Landscape for better table display
+	if layout.Orientation == "portrait" {
+		orientation = "P"
+	}
+	pdf := gofpdf.New(orientation, "mm", "A4", "")
 	pdf.SetMargins(15, 15, 15)
 	pdf.SetAutoPageBreak(true, 15)
+	s.setPDFHeaderFooter(pdf, layout)
 
 	// Add first page
 	pdf.AddPage()
 
+	if layout.Logo != nil {
+		if err := s.addPDFLogo(pdf, layout.Logo); err != nil {
+			return nil, err
+		}
+	}
+
 	// Title
 	pdf.SetFont("Arial", "B", 18)
-	pdf.CellFormat(0, 12, "RENCANA PEMBELAJARAN SEMESTER (RPS)", "", 1, "C", false, 0, "")
+	pdf.CellFormat(0, 12, layout.Title, "", 1, "C", false, 0, "")
 	pdf.Ln(8)
 
-	// ==================== IDENTITAS ====================
-	s.addSectionTitle(pdf, "I. IDENTITAS MATA KULIAH")
+	for i, section := range layout.Sections {
+		if section.PageBreakBefore {
+			pdf.AddPage()
+		}
+		s.addSectionTitle(pdf, layoutHeading(layout, i, section))
+		s.addPDFSection(pdf, rps, layout, section.Key)
+	}
 
-	identitasData := [][]string{
-		{"Nama Mata Kuliah", rps.Identitas.NamaMataKuliah},
-		{"Kode Mata Kuliah", rps.Identitas.KodeMataKuliah},
-		{"SKS", fmt.Sprintf("%d", rps.Identitas.SKS)},
-		{"Semester", rps.Identitas.Semester},
-		{"Dosen Pengampu", rps.Identitas.DosenPengampu},
+	// Generate PDF bytes
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
 	}
-	s.addKeyValueTable(pdf, identitasData)
 
-	// ==================== CAPAIAN PEMBELAJARAN ====================
-	s.addSectionTitle(pdf, "II. CAPAIAN PEMBELAJARAN")
+	return buf.Bytes(), nil
+}
 
-	// CPL Prodi
-	pdf.SetFont("Arial", "B", 11)
-	pdf.CellFormat(0, 7, "A. Capaian Pembelajaran Lulusan (CPL) Prodi", "", 1, "L", false, 0, "")
-	s.addNumberedList(pdf, rps.CapaianPembelajaran.CPLProdi)
+func (s *exportService) addPDFSection(pdf *gofpdf.Fpdf, rps *dto.RPSStructuredOutput, layout *dto.RPSLayout, key string) {
+	switch key {
+	case "identitas":
+		s.addKeyValueTable(pdf, identitasFields(rps.Identitas))
 
-	// CPMK
-	pdf.SetFont("Arial", "B", 11)
-	pdf.CellFormat(0, 7, "B. Capaian Pembelajaran Mata Kuliah (CPMK)", "", 1, "L", false, 0, "")
-	s.addNumberedList(pdf, rps.CapaianPembelajaran.CPMK)
+	case "capaian_pembelajaran":
+		// CPL Prodi
+		pdf.SetFont("Arial", "B", 11)
+		pdf.CellFormat(0, 7, "A. Capaian Pembelajaran Lulusan (CPL) Prodi", "", 1, "L", false, 0, "")
+		s.addNumberedList(pdf, rps.CapaianPembelajaran.CPLProdi)
 
-	// Sub-CPMK
-	pdf.SetFont("Arial", "B", 11)
-	pdf.CellFormat(0, 7, "C. Sub-CPMK", "", 1, "L", false, 0, "")
-	s.addNumberedList(pdf, rps.CapaianPembelajaran.SubCPMK)
+		// CPMK
+		pdf.SetFont("Arial", "B", 11)
+		pdf.CellFormat(0, 7, "B. Capaian Pembelajaran Mata Kuliah (CPMK)", "", 1, "L", false, 0, "")
+		s.addNumberedList(pdf, rps.CapaianPembelajaran.CPMK)
 
-	// ==================== DESKRIPSI MATA KULIAH ====================
-	s.addSectionTitle(pdf, "III. DESKRIPSI MATA KULIAH")
+		// Sub-CPMK
+		pdf.SetFont("Arial", "B", 11)
+		pdf.CellFormat(0, 7, "C. Sub-CPMK", "", 1, "L", false, 0, "")
+		s.addNumberedList(pdf, rps.CapaianPembelajaran.SubCPMK)
 
-	pdf.SetFont("Arial", "", 10)
-	pdf.MultiCell(0, 5, s.sanitizeText(rps.DeskripsiMataKuliah.DeskripsiSingkat), "", "J", false)
-	pdf.Ln(3)
+	case "deskripsi_mata_kuliah":
+		pdf.SetFont("Arial", "", 10)
+		pdf.MultiCell(0, 5, s.sanitizeText(rps.DeskripsiMataKuliah.DeskripsiSingkat), "", "J", false)
+		pdf.Ln(3)
 
-	pdf.SetFont("Arial", "B", 11)
-	pdf.CellFormat(0, 7, "Bahan Kajian:", "", 1, "L", false, 0, "")
-	s.addNumberedList(pdf, rps.DeskripsiMataKuliah.BahanKajian)
+		pdf.SetFont("Arial", "B", 11)
+		pdf.CellFormat(0, 7, "Bahan Kajian:", "", 1, "L", false, 0, "")
+		s.addNumberedList(pdf, rps.DeskripsiMataKuliah.BahanKajian)
 
-	// ==================== RENCANA PEMBELAJARAN MINGGUAN ====================
-	pdf.AddPage()
-	s.addSectionTitle(pdf, "IV. RENCANA PEMBELAJARAN MINGGUAN")
+	case "rencana_mingguan":
+		s.addWeeklyPlanTable(pdf, rps.RencanaMingguan, layout.WeeklyColumns)
 
-	s.addWeeklyPlanTable(pdf, rps.RencanaMingguan)
+	case "rencana_penilaian":
+		s.addAssessmentTable(pdf, rps.RencanaPenilaian.Komponen)
 
-	// ==================== RENCANA PENILAIAN ====================
-	pdf.AddPage()
-	s.addSectionTitle(pdf, "V. RENCANA PENILAIAN")
+	case "daftar_referensi":
+		pdf.SetFont("Arial", "B", 11)
+		pdf.CellFormat(0, 7, "A. Referensi Utama", "", 1, "L", false, 0, "")
+		s.addNumberedList(pdf, rps.DaftarReferensi.Utama)
 
-	s.addAssessmentTable(pdf, rps.RencanaPenilaian.Komponen)
+		pdf.SetFont("Arial", "B", 11)
+		pdf.CellFormat(0, 7, "B. Referensi Pendukung", "", 1, "L", false, 0, "")
+		s.addNumberedList(pdf, rps.DaftarReferensi.Pendukung)
+	}
+}
 
-	// ==================== DAFTAR REFERENSI ====================
-	s.addSectionTitle(pdf, "VI. DAFTAR REFERENSI")
+// setPDFHeaderFooter prints the layout header and footer on every page
+func (s *exportService) setPDFHeaderFooter(pdf *gofpdf.Fpdf, layout *dto.RPSLayout) {
+	if layout.Header == "" && layout.Footer == "" {
+		return
+	}
+	pdf.AliasNbPages("{nb}")
+
+	if layout.Header != "" {
+		pdf.SetHeaderFunc(func() {
+			pdf.SetY(7)
+			pdf.SetFont("Arial", "I", 8)
+			pdf.SetTextColor(128, 128, 128)
+			pdf.CellFormat(0, 5, layoutPageText(layout.Header, fmt.Sprintf("%d", pdf.PageNo()), "{nb}"), "", 1, "C", false, 0, "")
+			pdf.SetY(15)
+		})
+	}
+	if layout.Footer != "" {
+		pdf.SetFooterFunc(func() {
+			pdf.SetY(-12)
+			pdf.SetFont("Arial", "I", 8)
+			pdf.SetTextColor(128, 128, 128)
+			pdf.CellFormat(0, 5, layoutPageText(layout.Footer, fmt.Sprintf("%d", pdf.PageNo()), "{nb}"), "", 0, "C", false, 0, "")
+		})
+	}
+}
 
-	pdf.SetFont("Arial", "B", 11)
-	pdf.CellFormat(0, 7, "A. Referensi Utama", "", 1, "L", false, 0, "")
-	s.addNumberedList(pdf, rps.DaftarReferensi.Utama)
+func (s *exportService) addPDFLogo(pdf *gofpdf.Fpdf, logo *dto.RPSLayoutLogo) error {
+	data, format, err := decodeLogo(logo.Data)
+	if err != nil {
+		return err
+	}
 
-	pdf.SetFont("Arial", "B", 11)
-	pdf.CellFormat(0, 7, "B. Referensi Pendukung", "", 1, "L", false, 0, "")
-	s.addNumberedList(pdf, rps.DaftarReferensi.Pendukung)
+	options := gofpdf.ImageOptions{ImageType: format}
+	info := pdf.RegisterImageOptionsReader("logo", options, bytes.NewReader(data))
+	if !pdf.Ok() {
+		return fmt.Errorf("failed to load logo: %w", pdf.Error())
+	}
 
-	// Generate PDF bytes
-	var buf bytes.Buffer
-	err := pdf.Output(&buf)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	x := left
+	switch logo.Position {
+	case "center":
+		x = (pageWidth - logo.Width) / 2
+	case "right":
+		x = pageWidth - right - logo.Width
 	}
 
-	return buf.Bytes(), nil
+	height := logo.Width * info.Height() / info.Width()
+	pdf.ImageOptions("logo", x, pdf.GetY(), logo.Width, height, false, options, 0, "")
+	pdf.SetY(pdf.GetY() + height + 3)
+	return nil
 }
 
 // Helper functions for PDF generation
@@ -140,18 +207,23 @@ func (s *exportService) addNumberedList(pdf *gofpdf.Fpdf, items []string) {
 	pdf.Ln(3)
 }
 
-func (s *exportService) addWeeklyPlanTable(pdf *gofpdf.Fpdf, plans []dto.RPSRencanaMingguan) {
+func (s *exportService) addWeeklyPlanTable(pdf *gofpdf.Fpdf, plans []dto.RPSRencanaMingguan, columns []dto.RPSLayoutColumn) {
+	// Column widths are relative weights scaled to the usable page width
+	pageWidth, pageHeight := pdf.GetPageSize()
+	left, _, right, bottom := pdf.GetMargins()
+	weights := make([]float64, len(columns))
+	for i, column := range columns {
+		weights[i] = column.Width
+	}
+	widths := scaleWidths(weights, pageWidth-left-right)
+
 	// Table header
 	pdf.SetFont("Arial", "B", 9)
 	pdf.SetFillColor(41, 128, 185) // Blue header
 	pdf.SetTextColor(255, 255, 255)
 
-	// Landscape A4: 297mm width - 30mm margins = 267mm usable
-	headers := []string{"Minggu", "Topik", "Indikator", "Metode", "Waktu", "Penilaian"}
-	widths := []float64{15, 70, 70, 45, 17, 50}
-
-	for i, header := range headers {
-		pdf.CellFormat(widths[i], 8, header, "1", 0, "C", true, 0, "")
+	for i, column := range columns {
+		pdf.CellFormat(widths[i], 8, column.Label, "1", 0, "C", true, 0, "")
 	}
 	pdf.Ln(-1)
 	pdf.SetTextColor(0, 0, 0)
@@ -167,18 +239,11 @@ func (s *exportService) addWeeklyPlanTable(pdf *gofpdf.Fpdf, plans []dto.RPSRenc
 		}
 
 		// Calculate max lines needed for this row
-		topik := s.wrapText(plan.Topik, 68)
-		indikator := s.wrapText(plan.IndikatorCapaian, 68)
-		metode := s.wrapText(plan.MetodePembelajaran, 43)
-		penilaian := s.wrapText(plan.BentukPenilaian, 48)
-
-		// Get max lines
-		maxLines := s.maxInt(
-			len(topik),
-			s.maxInt(len(indikator), s.maxInt(len(metode), len(penilaian))),
-		)
-		if maxLines < 1 {
-			maxLines = 1
+		cells := make([][]string, len(columns))
+		maxLines := 1
+		for c, column := range columns {
+			cells[c] = s.wrapText(weeklyCellValue(plan, column.Key), int(widths[c])-2)
+			maxLines = s.maxInt(maxLines, len(cells[c]))
 		}
 
 		rowHeight := float64(maxLines) * 5.0
@@ -191,34 +256,21 @@ func (s *exportService) addWeeklyPlanTable(pdf *gofpdf.Fpdf, plans []dto.RPSRenc
 		y := pdf.GetY()
 
 		// Check if we need a new page
-		if y+rowHeight > 190 {
+		if y+rowHeight > pageHeight-bottom-5 {
 			pdf.AddPage()
 			y = pdf.GetY()
 		}
 
-		// Minggu
-		pdf.SetXY(x, y)
-		pdf.CellFormat(widths[0], rowHeight, fmt.Sprintf("%d", plan.Minggu), "1", 0, "C", true, 0, "")
-
-		// Topik
-		pdf.SetXY(x+widths[0], y)
-		s.multiCellInTable(pdf, widths[1], rowHeight, strings.Join(topik, "\n"), true)
-
-		// Indikator
-		pdf.SetXY(x+widths[0]+widths[1], y)
-		s.multiCellInTable(pdf, widths[2], rowHeight, strings.Join(indikator, "\n"), true)
-
-		// Metode
-		pdf.SetXY(x+widths[0]+widths[1]+widths[2], y)
-		s.multiCellInTable(pdf, widths[3], rowHeight, strings.Join(metode, "\n"), true)
-
-		// Waktu
-		pdf.SetXY(x+widths[0]+widths[1]+widths[2]+widths[3], y)
-		pdf.CellFormat(widths[4], rowHeight, fmt.Sprintf("%d", plan.WaktuMenit), "1", 0, "C", true, 0, "")
-
-		// Penilaian
-		pdf.SetXY(x+widths[0]+widths[1]+widths[2]+widths[3]+widths[4], y)
-		s.multiCellInTable(pdf, widths[5], rowHeight, strings.Join(penilaian, "\n"), true)
+		cellX := x
+		for c, column := range columns {
+			pdf.SetXY(cellX, y)
+			if weeklyColumnCentered(column.Key) {
+				pdf.CellFormat(widths[c], rowHeight, strings.Join(cells[c], " "), "1", 0, "C", true, 0, "")
+			} else {
+				s.multiCellInTable(pdf, widths[c], rowHeight, strings.Join(cells[c], "\n"), true)
+			}
+			cellX += widths[c]
+		}
 
 		pdf.SetXY(x, y+rowHeight)
 	}
@@ -294,8 +346,11 @@ func (s *exportService) addAssessmentTable(pdf *gofpdf.Fpdf, komponen []dto.RPSK
 	pdf.SetFillColor(41, 128, 185)
 	pdf.SetTextColor(255, 255, 255)
 
+	// Widths designed for landscape A4 (267mm usable), scaled to the actual page
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
 	headers := []string{"No", "Komponen", "Bobot (%)", "Teknik", "Instrumen"}
-	widths := []float64{12, 50, 25, 85, 95}
+	widths := scaleWidths([]float64{12, 50, 25, 85, 95}, pageWidth-left-right)
 
 	for i, header := range headers {
 		pdf.CellFormat(widths[i], 8, header, "1", 0, "C", true, 0, "")
@@ -317,8 +372,8 @@ func (s *exportService) addAssessmentTable(pdf *gofpdf.Fpdf, komponen []dto.RPSK
 		pdf.CellFormat(widths[0], 7, fmt.Sprintf("%d", i+1), "1", 0, "C", true, 0, "")
 		pdf.CellFormat(widths[1], 7, s.sanitizeText(k.Nama), "1", 0, "L", true, 0, "")
 		pdf.CellFormat(widths[2], 7, fmt.Sprintf("%d%%", k.Bobot), "1", 0, "C", true, 0, "")
-		pdf.CellFormat(widths[3], 7, s.truncateText(k.Teknik, 100), "1", 0, "L", true, 0, "")
-		pdf.CellFormat(widths[4], 7, s.truncateText(k.Instrumen, 110), "1", 0, "L", true, 0, "")
+		pdf.CellFormat(widths[3], 7, s.truncateText(k.Teknik, int(widths[3]*1.2)), "1", 0, "L", true, 0, "")
+		pdf.CellFormat(widths[4], 7, s.truncateText(k.Instrumen, int(widths[4]*1.2)), "1", 0, "L", true, 0, "")
 		pdf.Ln(-1)
 		totalBobot += k.Bobot
 	}
@@ -348,21 +403,174 @@ func (s *exportService) truncateText(text string, maxLen int) string {
 	return text
 }
 
+// ExportToDOCX generates an editable Word document from RPS data
+func (s *exportService) ExportToDOCX(rps *dto.RPSStructuredOutput, layout *dto.RPSLayout) ([]byte, error) {
+	if layout == nil {
+		layout = DefaultRPSLayout()
+	}
+
+	doc := newDocxDocument(layout.Orientation != "portrait")
+	doc.setHeader(layout.Header)
+	doc.setFooter(layout.Footer)
+
+	if layout.Logo != nil {
+		data, _, err := decodeLogo(layout.Logo.Data)
+		if err != nil {
+			return nil, err
+		}
+		align := layout.Logo.Position
+		if align == "" {
+			align = "center"
+		}
+		if err := doc.addImage(data, layout.Logo.Width, align); err != nil {
+			return nil, err
+		}
+	}
+
+	doc.addTitle(layout.Title)
+
+	for i, section := range layout.Sections {
+		if section.PageBreakBefore {
+			doc.addPageBreak()
+		}
+		doc.addSectionTitle(layoutHeading(layout, i, section))
+		s.addDOCXSection(doc, rps, layout, section.Key)
+	}
+
+	return doc.bytes()
+}
+
+func (s *exportService) addDOCXSection(doc *docxDocument, rps *dto.RPSStructuredOutput, layout *dto.RPSLayout, key string) {
+	switch key {
+	case "identitas":
+		labelWidth := mmToTwips(60)
+		identitasRows := [][]docxCell{}
+		for _, row := range identitasFields(rps.Identitas) {
+			identitasRows = append(identitasRows, []docxCell{
+				{Text: row[0], Bold: true, Fill: "F5F5F5"},
+				{Text: row[1]},
+			})
+		}
+		doc.addTable([]int{labelWidth, doc.contentWidth() - labelWidth}, identitasRows, 0)
+
+	case "capaian_pembelajaran":
+		doc.addSubTitle("A. Capaian Pembelajaran Lulusan (CPL) Prodi")
+		doc.addNumberedList(rps.CapaianPembelajaran.CPLProdi)
+
+		doc.addSubTitle("B. Capaian Pembelajaran Mata Kuliah (CPMK)")
+		doc.addNumberedList(rps.CapaianPembelajaran.CPMK)
+
+		doc.addSubTitle("C. Sub-CPMK")
+		doc.addNumberedList(rps.CapaianPembelajaran.SubCPMK)
+
+	case "deskripsi_mata_kuliah":
+		doc.addParagraph(rps.DeskripsiMataKuliah.DeskripsiSingkat, false, 20, "both")
+		doc.addSubTitle("Bahan Kajian:")
+		doc.addNumberedList(rps.DeskripsiMataKuliah.BahanKajian)
+
+	case "rencana_mingguan":
+		headers := make([]string, len(layout.WeeklyColumns))
+		weights := make([]float64, len(layout.WeeklyColumns))
+		for i, column := range layout.WeeklyColumns {
+			headers[i] = column.Label
+			weights[i] = column.Width
+		}
+
+		weeklyRows := [][]docxCell{s.docxHeaderRow(headers...)}
+		for i, plan := range rps.RencanaMingguan {
+			fill := ""
+			if i%2 == 1 {
+				fill = "F5F5F5"
+			}
+			row := make([]docxCell, len(layout.WeeklyColumns))
+			for c, column := range layout.WeeklyColumns {
+				row[c] = docxCell{Text: weeklyCellValue(plan, column.Key), Fill: fill}
+				if weeklyColumnCentered(column.Key) {
+					row[c].Align = "center"
+				}
+			}
+			weeklyRows = append(weeklyRows, row)
+		}
+		doc.addTable(s.docxScaledWidths(weights, doc.contentWidth()), weeklyRows, 1)
+
+	case "rencana_penilaian":
+		assessmentRows := [][]docxCell{s.docxHeaderRow("No", "Komponen", "Bobot (%)", "Teknik", "Instrumen")}
+		totalBobot := 0
+		for i, k := range rps.RencanaPenilaian.Komponen {
+			fill := ""
+			if i%2 == 1 {
+				fill = "F5F5F5"
+			}
+			assessmentRows = append(assessmentRows, []docxCell{
+				{Text: fmt.Sprintf("%d", i+1), Align: "center", Fill: fill},
+				{Text: k.Nama, Fill: fill},
+				{Text: fmt.Sprintf("%d%%", k.Bobot), Align: "center", Fill: fill},
+				{Text: k.Teknik, Fill: fill},
+				{Text: k.Instrumen, Fill: fill},
+			})
+			totalBobot += k.Bobot
+		}
+		assessmentRows = append(assessmentRows, []docxCell{
+			{Text: "TOTAL", Bold: true, Align: "center", Span: 2, Fill: "DCDCDC"},
+			{Text: fmt.Sprintf("%d%%", totalBobot), Bold: true, Align: "center", Fill: "DCDCDC"},
+			{Text: "", Span: 2, Fill: "DCDCDC"},
+		})
+		doc.addTable(s.docxScaledWidths([]float64{12, 50, 25, 85, 95}, doc.contentWidth()), assessmentRows, 1)
+
+	case "daftar_referensi":
+		doc.addSubTitle("A. Referensi Utama")
+		doc.addNumberedList(rps.DaftarReferensi.Utama)
+
+		doc.addSubTitle("B. Referensi Pendukung")
+		doc.addNumberedList(rps.DaftarReferensi.Pendukung)
+	}
+}
+
+// Helper functions for DOCX generation
+func (s *exportService) docxHeaderRow(headers ...string) []docxCell {
+	row := make([]docxCell, len(headers))
+	for i, header := range headers {
+		row[i] = docxCell{Text: header, Bold: true, Align: "center", Fill: "2980B9", Color: "FFFFFF"}
+	}
+	return row
+}
+
+// docxScaledWidths scales relative column weights to total twips
+func (s *exportService) docxScaledWidths(weights []float64, total int) []int {
+	scaled := scaleWidths(weights, float64(total))
+	widths := make([]int, len(scaled))
+	for i, w := range scaled {
+		widths[i] = int(w)
+	}
+	return widths
+}
+
 // ExportToHTML generates an HTML document from RPS data
-func (s *exportService) ExportToHTML(rps *dto.RPSStructuredOutput) (string, error) {
-	html := `<!DOCTYPE html>
+func (s *exportService) ExportToHTML(rps *dto.RPSStructuredOutput, layout *dto.RPSLayout) (string, error) {
+	if layout == nil {
+		layout = DefaultRPSLayout()
+	}
+
+	pageWidth, orientation := "297mm", "landscape"
+	if layout.Orientation == "portrait" {
+		pageWidth, orientation = "210mm", "portrait"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<!DOCTYPE html>
 <html lang="id">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>RPS - ` + rps.Identitas.NamaMataKuliah + `</title>
-    <style>
+    <title>RPS - ` + html.EscapeString(rps.Identitas.NamaMataKuliah) + `</title>
+`)
+	fmt.Fprintf(&sb, `    <style>
         * {
             box-sizing: border-box;
         }
         body {
             font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif;
-            max-width: 297mm;
+            max-width: %s;
             margin: 0 auto;
             padding: 20mm;
             line-height: 1.6;
@@ -384,7 +592,7 @@ func (s *exportService) ExportToHTML(rps *dto.RPSStructuredOutput) (string, erro
         }
         h2 {
             font-size: 14pt;
-            background: linear-gradient(135deg, #2980b9 0%, #3498db 100%);
+            background: linear-gradient(135deg, #2980b9 0%%, #3498db 100%%);
             color: white;
             padding: 12px 15px;
             margin-top: 25px;
@@ -399,7 +607,7 @@ func (s *exportService) ExportToHTML(rps *dto.RPSStructuredOutput) (string, erro
             padding-left: 10px;
         }
         table {
-            width: 100%;
+            width: 100%%;
             border-collapse: collapse;
             margin: 15px 0;
             font-size: 10pt;
@@ -410,7 +618,7 @@ func (s *exportService) ExportToHTML(rps *dto.RPSStructuredOutput) (string, erro
             text-align: left;
         }
         th {
-            background: linear-gradient(135deg, #2980b9 0%, #3498db 100%);
+            background: linear-gradient(135deg, #2980b9 0%%, #3498db 100%%);
             color: white;
             font-weight: 600;
         }
@@ -443,13 +651,10 @@ func (s *exportService) ExportToHTML(rps *dto.RPSStructuredOutput) (string, erro
         .weekly-table th {
             text-align: center;
         }
-        .weekly-table td:first-child {
+        .weekly-table td.center {
             text-align: center;
             font-weight: 600;
         }
-        .weekly-table td:nth-child(5) {
-            text-align: center;
-        }
         .assessment-table td:first-child {
             text-align: center;
         }
@@ -468,6 +673,21 @@ func (s *exportService) ExportToHTML(rps *dto.RPSStructuredOutput) (string, erro
             text-align: justify;
             margin-bottom: 15px;
         }
+        .page-header, .page-footer {
+            text-align: center;
+            font-size: 8pt;
+            font-style: italic;
+            color: #808080;
+        }
+        .logo {
+            margin-bottom: 10px;
+        }
+        .page-break {
+            page-break-before: always;
+        }
+        @page {
+            size: A4 %s;
+        }
         @media print {
             body { 
                 padding: 10mm;
@@ -491,22 +711,62 @@ func (s *exportService) ExportToHTML(rps *dto.RPSStructuredOutput) (string, erro
             }
         }
     </style>
-</head>
+`, pageWidth, orientation)
+	sb.WriteString(`</head>
 <body>
     <div class="container">
-    <h1>RENCANA PEMBELAJARAN SEMESTER (RPS)</h1>
-    
-    <h2>I. IDENTITAS MATA KULIAH</h2>
-    <table class="info-table">
-        <tr><td>Nama Mata Kuliah</td><td>` + rps.Identitas.NamaMataKuliah + `</td></tr>
-        <tr><td>Kode Mata Kuliah</td><td>` + rps.Identitas.KodeMataKuliah + `</td></tr>
-        <tr><td>SKS</td><td>` + fmt.Sprintf("%d", rps.Identitas.SKS) + `</td></tr>
-        <tr><td>Semester</td><td>` + rps.Identitas.Semester + `</td></tr>
-        <tr><td>Dosen Pengampu</td><td>` + rps.Identitas.DosenPengampu + `</td></tr>
-    </table>
-    
-    <h2>II. CAPAIAN PEMBELAJARAN</h2>
-    <h3>A. Capaian Pembelajaran Lulusan (CPL) Prodi</h3>
+`)
+
+	// HTML has no page numbers, so {page} placeholders are dropped
+	if layout.Header != "" {
+		sb.WriteString(`    <div class="page-header">` + html.EscapeString(strings.TrimSpace(layoutPageText(layout.Header, "", ""))) + "</div>\n")
+	}
+	if layout.Logo != nil {
+		if _, _, err := decodeLogo(layout.Logo.Data); err != nil {
+			return "", err
+		}
+		align := layout.Logo.Position
+		if align == "" {
+			align = "center"
+		}
+		fmt.Fprintf(&sb, `    <div class="logo" style="text-align: %s;"><img src="%s" alt="Logo" style="width: %.1fmm;"></div>`+"\n",
+			align, html.EscapeString(layout.Logo.Data), layout.Logo.Width)
+	}
+	sb.WriteString("    <h1>" + html.EscapeString(layout.Title) + "</h1>\n")
+
+	for i, section := range layout.Sections {
+		class := ""
+		if section.PageBreakBefore {
+			class = ` class="page-break"`
+		}
+		fmt.Fprintf(&sb, "    \n    <h2%s>%s</h2>\n", class, html.EscapeString(layoutHeading(layout, i, section)))
+		sb.WriteString(s.generateHTMLSection(rps, layout, section.Key))
+	}
+
+	if layout.Footer != "" {
+		sb.WriteString(`    <div class="page-footer">` + html.EscapeString(strings.TrimSpace(layoutPageText(layout.Footer, "", ""))) + "</div>\n")
+	}
+	sb.WriteString(`    </div>
+</body>
+</html>`)
+
+	return sb.String(), nil
+}
+
+func (s *exportService) generateHTMLSection(rps *dto.RPSStructuredOutput, layout *dto.RPSLayout, key string) string {
+	switch key {
+	case "identitas":
+		var rows strings.Builder
+		for _, row := range identitasFields(rps.Identitas) {
+			rows.WriteString(`        <tr><td>` + html.EscapeString(row[0]) + `</td><td>` + html.EscapeString(row[1]) + `</td></tr>
+`)
+		}
+		return `    <table class="info-table">
+` + rows.String() + `    </table>
+`
+
+	case "capaian_pembelajaran":
+		return `    <h3>A. Capaian Pembelajaran Lulusan (CPL) Prodi</h3>
     <ol>` + s.generateListItems(rps.CapaianPembelajaran.CPLProdi) + `</ol>
     
     <h3>B. Capaian Pembelajaran Mata Kuliah (CPMK)</h3>
@@ -514,30 +774,30 @@ func (s *exportService) ExportToHTML(rps *dto.RPSStructuredOutput) (string, erro
     
     <h3>C. Sub-CPMK</h3>
     <ol>` + s.generateListItems(rps.CapaianPembelajaran.SubCPMK) + `</ol>
-    
-    <h2>III. DESKRIPSI MATA KULIAH</h2>
-    <p>` + rps.DeskripsiMataKuliah.DeskripsiSingkat + `</p>
+`
+
+	case "deskripsi_mata_kuliah":
+		return `    <p>` + html.EscapeString(rps.DeskripsiMataKuliah.DeskripsiSingkat) + `</p>
     <h3>Bahan Kajian:</h3>
     <ol>` + s.generateListItems(rps.DeskripsiMataKuliah.BahanKajian) + `</ol>
-    
-    <h2>IV. RENCANA PEMBELAJARAN MINGGUAN</h2>
-    <table class="weekly-table">
+`
+
+	case "rencana_mingguan":
+		var headers strings.Builder
+		for _, column := range layout.WeeklyColumns {
+			headers.WriteString("\n                <th>" + html.EscapeString(column.Label) + "</th>")
+		}
+		return `    <table class="weekly-table">
         <thead>
-            <tr>
-                <th>Minggu</th>
-                <th>Topik</th>
-                <th>Sub Topik</th>
-                <th>Indikator Capaian</th>
-                <th>Metode</th>
-                <th>Waktu (menit)</th>
-                <th>Penilaian</th>
+            <tr>` + headers.String() + `
             </tr>
         </thead>
-        <tbody>` + s.generateWeeklyRows(rps.RencanaMingguan) + `</tbody>
+        <tbody>` + s.generateWeeklyRows(rps.RencanaMingguan, layout.WeeklyColumns) + `</tbody>
     </table>
-    
-    <h2>V. RENCANA PENILAIAN</h2>
-    <table class="assessment-table">
+`
+
+	case "rencana_penilaian":
+		return `    <table class="assessment-table">
         <thead>
             <tr>
                 <th>No</th>
@@ -549,44 +809,43 @@ func (s *exportService) ExportToHTML(rps *dto.RPSStructuredOutput) (string, erro
         </thead>
         <tbody>` + s.generateAssessmentRows(rps.RencanaPenilaian.Komponen) + `</tbody>
     </table>
-    
-    <h2>VI. DAFTAR REFERENSI</h2>
-    <h3>A. Referensi Utama</h3>
+`
+
+	case "daftar_referensi":
+		return `    <h3>A. Referensi Utama</h3>
     <ol>` + s.generateListItems(rps.DaftarReferensi.Utama) + `</ol>
     
     <h3>B. Referensi Pendukung</h3>
     <ol>` + s.generateListItems(rps.DaftarReferensi.Pendukung) + `</ol>
-    </div>
-</body>
-</html>`
-
-	return html, nil
+`
+	}
+	return ""
 }
 
 func (s *exportService) generateListItems(items []string) string {
 	var sb strings.Builder
 	for _, item := range items {
-		sb.WriteString("<li>" + item + "</li>")
+		sb.WriteString("<li>" + html.EscapeString(item) + "</li>")
 	}
 	return sb.String()
 }
 
-func (s *exportService) generateWeeklyRows(plans []dto.RPSRencanaMingguan) string {
+func (s *exportService) generateWeeklyRows(plans []dto.RPSRencanaMingguan, columns []dto.RPSLayoutColumn) string {
 	var sb strings.Builder
 	for _, p := range plans {
-		subTopiks := strings.Join(p.SubTopik, ", ")
-		sb.WriteString(fmt.Sprintf(`
-            <tr>
-                <td>%d</td>
-                <td>%s</td>
-                <td>%s</td>
-                <td>%s</td>
-                <td>%s</td>
-                <td>%d</td>
-                <td>%s</td>
-            </tr>`,
-			p.Minggu, p.Topik, subTopiks, p.IndikatorCapaian,
-			p.MetodePembelajaran, p.WaktuMenit, p.BentukPenilaian))
+		sb.WriteString(`
+            <tr>`)
+		for _, column := range columns {
+			class := ""
+			if weeklyColumnCentered(column.Key) {
+				class = ` class="center"`
+			}
+			value := strings.ReplaceAll(html.EscapeString(weeklyCellValue(p, column.Key)), "\n", "<br>")
+			fmt.Fprintf(&sb, `
+                <td%s>%s</td>`, class, value)
+		}
+		sb.WriteString(`
+            </tr>`)
 	}
 	return sb.String()
 }
@@ -603,7 +862,7 @@ func (s *exportService) generateAssessmentRows(komponen []dto.RPSKomponenPenilai
                 <td>%s</td>
                 <td>%s</td>
             </tr>`,
-			i+1, k.Nama, k.Bobot, k.Teknik, k.Instrumen))
+			i+1, html.EscapeString(k.Nama), k.Bobot, html.EscapeString(k.Teknik), html.EscapeString(k.Instrumen)))
 		totalBobot += k.Bobot
 	}
 	sb.WriteString(fmt.Sprintf(`
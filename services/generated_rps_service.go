@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
 	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
 	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
 )
 
@@ -19,6 +20,7 @@ type GeneratedRPSService interface {
 	Update(id uuid.UUID, req *dto.UpdateGeneratedRPSRequest) (*dto.GeneratedRPSResponse, error)
 	UpdateStatus(id uuid.UUID, status string) error
 	Delete(id uuid.UUID) error
+	GetValidation(id uuid.UUID) (*dto.RPSValidationReport, error)
 }
 
 type generatedRPSService struct {
@@ -100,8 +102,23 @@ func (s *generatedRPSService) Update(id uuid.UUID, req *dto.UpdateGeneratedRPSRe
 	if req.Status != nil {
 		rps.Status = *req.Status
 	}
+
+	// A new result is kept as a revision; identical content does not add one
+	var revision *models.RPSRevision
+	previous := rps.Result
 	if req.Result != nil {
+		if isContentLocked(rps) {
+			return nil, ErrRPSLocked
+		}
+		if changes, err := helper.DiffJSON(previous, req.Result); err != nil || len(changes) > 0 {
+			source := models.RevisionSourceManual
+			if req.EditedBy == nil {
+				source = models.RevisionSourceSystem
+			}
+			revision = newRevision(req.Result, source, req.EditedBy, req.ChangeNote)
+		}
 		rps.Result = req.Result
+		applyValidation(rps)
 	}
 	if req.ExportedFileURL != nil {
 		rps.ExportedFileURL = req.ExportedFileURL
@@ -111,7 +128,18 @@ func (s *generatedRPSService) Update(id uuid.UUID, req *dto.UpdateGeneratedRPSRe
 	}
 	rps.UpdatedAt = time.Now()
 
-	if err := s.repo.Update(rps); err != nil {
+	if revision == nil {
+		if err := s.repo.Update(rps); err != nil {
+			return nil, helper.WrapDatabaseError(err)
+		}
+		return helper.ToGeneratedRPSResponse(rps), nil
+	}
+
+	revisions, err := revisionsFor(s.repo, rps.ID, previous, revision)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if err := s.repo.UpdateWithRevisions(rps, revisions...); err != nil {
 		return nil, helper.WrapDatabaseError(err)
 	}
 
@@ -133,3 +161,23 @@ func (s *generatedRPSService) Delete(id uuid.UUID) error {
 
 	return s.repo.Delete(id)
 }
+
+// GetValidation returns the stored validator findings, validating RPS that predate the validator
+func (s *generatedRPSService) GetValidation(id uuid.UUID) (*dto.RPSValidationReport, error) {
+	rps, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if len(rps.Result) == 0 {
+		return nil, ErrRPSNotReady
+	}
+
+	if rps.ValidatedAt == nil {
+		applyValidation(rps)
+		if err := s.repo.Update(rps); err != nil {
+			return nil, helper.WrapDatabaseError(err)
+		}
+	}
+
+	return toValidationReport(rps)
+}
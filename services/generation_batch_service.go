@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+	"gorm.io/datatypes"
+)
+
+var (
+	ErrEmptyBatch          = fmt.Errorf("%w: no courses match the batch", helper.ErrInvalidInput)
+	ErrBatchNotActive      = errors.New("batch has no queued or running jobs")
+	ErrBatchNothingToRetry = errors.New("batch has no failed or cancelled jobs")
+)
+
+// GenerationBatchService queues one generation job per course of a program,
+// a semester of it or an explicit course list, and reports their progress
+type GenerationBatchService interface {
+	Create(req *dto.CreateGenerationBatchRequest, user *models.User) (*dto.GenerationBatchResponse, error)
+	FindAll() ([]dto.GenerationBatchResponse, error)
+	FindByID(id uuid.UUID) (*dto.GenerationBatchResponse, error)
+	Cancel(id uuid.UUID) (*dto.GenerationBatchResponse, error)
+	RetryFailed(id uuid.UUID) (*dto.GenerationBatchResponse, error)
+}
+
+type generationBatchService struct {
+	repo                   repositories.GenerationBatchRepository
+	courseRepo             repositories.CourseRepository
+	templateVersionService TemplateVersionService
+	accessService          AccessService
+	aiService              AIService
+	costService            AICostService
+	generationService      GenerationService
+}
+
+func NewGenerationBatchService(
+	repo repositories.GenerationBatchRepository,
+	courseRepo repositories.CourseRepository,
+	templateVersionService TemplateVersionService,
+	accessService AccessService,
+	aiService AIService,
+	costService AICostService,
+	generationService GenerationService,
+) GenerationBatchService {
+	return &generationBatchService{
+		repo:                   repo,
+		courseRepo:             courseRepo,
+		templateVersionService: templateVersionService,
+		accessService:          accessService,
+		aiService:              aiService,
+		costService:            costService,
+		generationService:      generationService,
+	}
+}
+
+// Create checks that user may generate for every selected course and queues
+// the jobs with the shared options in one transaction
+func (s *generationBatchService) Create(req *dto.CreateGenerationBatchRequest, user *models.User) (*dto.GenerationBatchResponse, error) {
+	if _, err := s.templateVersionService.FindByID(req.TemplateVersionID); err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, ErrTemplateVersionNotFound
+		}
+		return nil, err
+	}
+
+	courses, err := s.batchCourses(req, user)
+	if err != nil {
+		return nil, err
+	}
+	if len(courses) == 0 {
+		return nil, ErrEmptyBatch
+	}
+
+	options := resolveGenerateOptions(req.Options)
+	if err := s.checkCanGenerate(options, req.CreatedBy, courses); err != nil {
+		return nil, err
+	}
+
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &models.GenerationBatch{
+		ID:                uuid.New(),
+		ProgramID:         req.ProgramID,
+		Semester:          req.Semester,
+		TemplateVersionID: req.TemplateVersionID,
+		Options:           datatypes.JSON(optionsJSON),
+		CreatedBy:         req.CreatedBy,
+	}
+
+	jobs := make([]*models.GeneratedRPS, len(courses))
+	for i, course := range courses {
+		courseID := course.ID
+		jobs[i] = helper.ToGeneratedRPSModel(&dto.CreateGeneratedRPSRequest{
+			TemplateVersionID: &req.TemplateVersionID,
+			CourseID:          &courseID,
+			GeneratedBy:       req.CreatedBy,
+		})
+		jobs[i].JobOptions = datatypes.JSON(optionsJSON)
+	}
+
+	if err := s.repo.Create(batch, jobs); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return s.FindByID(batch.ID)
+}
+
+func (s *generationBatchService) FindAll() ([]dto.GenerationBatchResponse, error) {
+	batches, err := s.repo.FindAll()
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	ids := make([]uuid.UUID, len(batches))
+	for i, batch := range batches {
+		ids[i] = batch.ID
+	}
+	stats, err := s.repo.Stats(ids...)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	result := make([]dto.GenerationBatchResponse, len(batches))
+	for i := range batches {
+		result[i] = *batchResponse(&batches[i], stats[batches[i].ID])
+	}
+	return result, nil
+}
+
+// FindByID returns the batch with its aggregate progress, every job and the failures
+func (s *generationBatchService) FindByID(id uuid.UUID) (*dto.GenerationBatchResponse, error) {
+	batch, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	stats, err := s.repo.Stats(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	jobs, err := s.repo.FindJobs(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	response := batchResponse(batch, stats[id])
+	for _, job := range jobs {
+		item := batchJob(&job)
+		response.Jobs = append(response.Jobs, item)
+		if job.Status == "failed" {
+			response.Failures = append(response.Failures, item)
+		}
+	}
+	return response, nil
+}
+
+// Cancel stops a batch: its queued and running jobs are cancelled like single
+// jobs, so running ones stop right away and subscribers get their events
+func (s *generationBatchService) Cancel(id uuid.UUID) (*dto.GenerationBatchResponse, error) {
+	batch, err := s.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.CancelledAt != nil || batch.Progress.Queued+batch.Progress.Processing == 0 {
+		return nil, ErrBatchNotActive
+	}
+
+	if err := s.repo.MarkCancelled(id, time.Now()); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	jobs, err := s.repo.FindJobs(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	for _, job := range jobs {
+		if job.Status != "queued" && job.Status != "processing" {
+			continue
+		}
+		// Jobs that finished in the meantime are left as they are
+		if _, err := s.generationService.Cancel(job.ID); err != nil && !errors.Is(err, ErrJobNotCancellable) {
+			return nil, err
+		}
+	}
+	return s.FindByID(id)
+}
+
+// RetryFailed requeues the failed jobs of a batch and those stopped by a cancel
+func (s *generationBatchService) RetryFailed(id uuid.UUID) (*dto.GenerationBatchResponse, error) {
+	batch, err := s.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Progress.Failed+batch.Progress.Cancelled == 0 {
+		return nil, ErrBatchNothingToRetry
+	}
+
+	// Checked as in Create: the allowed ranges and the budgets may have changed since
+	var options dto.GenerateRPSOptions
+	if len(batch.Options) > 0 {
+		if err := json.Unmarshal(batch.Options, &options); err != nil {
+			return nil, fmt.Errorf("failed to parse batch options: %w", err)
+		}
+	}
+	jobs, err := s.repo.FindJobs(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	var courses []models.Course
+	for _, job := range jobs {
+		if (job.Status == "failed" || job.Status == "cancelled") && job.Course != nil {
+			courses = append(courses, *job.Course)
+		}
+	}
+	if err := s.checkCanGenerate(options, batch.CreatedBy, courses); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.RequeueFailed(id); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	return s.FindByID(id)
+}
+
+// checkCanGenerate refuses options outside the allowed ranges, and a batch whose
+// requester or one of whose programs has no budget left
+func (s *generationBatchService) checkCanGenerate(options dto.GenerateRPSOptions, createdBy *uuid.UUID, courses []models.Course) error {
+	if err := s.aiService.CheckModelParams(options); err != nil {
+		return err
+	}
+
+	if err := s.costService.CheckBudget(context.Background(), CostOwner{UserID: createdBy}); err != nil {
+		return err
+	}
+	checked := make(map[uuid.UUID]bool)
+	for _, course := range courses {
+		if course.ProgramID == nil || checked[*course.ProgramID] {
+			continue
+		}
+		checked[*course.ProgramID] = true
+		if err := s.costService.CheckBudget(context.Background(), CostOwner{ProgramID: course.ProgramID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchCourses resolves the courses selected by the request, failing with
+// helper.ErrForbidden when user may not generate for one of them
+func (s *generationBatchService) batchCourses(req *dto.CreateGenerationBatchRequest, user *models.User) ([]models.Course, error) {
+	if req.ProgramID != nil {
+		if err := s.accessService.CanManageProgram(user, *req.ProgramID); err != nil {
+			return nil, err
+		}
+
+		courses, err := s.courseRepo.FindByProgramID(*req.ProgramID)
+		if err != nil {
+			return nil, helper.WrapDatabaseError(err)
+		}
+		if req.Semester == nil {
+			return courses, nil
+		}
+
+		var selected []models.Course
+		for _, course := range courses {
+			if course.Semester != nil && *course.Semester == *req.Semester {
+				selected = append(selected, course)
+			}
+		}
+		return selected, nil
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.CourseIDs))
+	var courses []models.Course
+	for _, id := range req.CourseIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		course, err := s.courseRepo.FindByID(id)
+		if err != nil {
+			if helper.IsNotFoundError(helper.WrapDatabaseError(err)) {
+				return nil, ErrCourseNotFound
+			}
+			return nil, helper.WrapDatabaseError(err)
+		}
+		if err := s.accessService.CanManageCourse(user, id); err != nil {
+			return nil, err
+		}
+		courses = append(courses, *course)
+	}
+	return courses, nil
+}
+
+func batchResponse(batch *models.GenerationBatch, stats repositories.GenerationBatchStats) *dto.GenerationBatchResponse {
+	response := helper.ToGenerationBatchResponse(batch)
+	response.Progress = dto.GenerationBatchProgress{
+		Total:      stats.Total,
+		Queued:     stats.Queued,
+		Processing: stats.Processing,
+		Done:       stats.Done,
+		Failed:     stats.Failed,
+		Cancelled:  stats.Cancelled,
+	}
+	if stats.Total > 0 {
+		response.Progress.Percent = int((stats.Done + stats.Failed + stats.Cancelled) * 100 / stats.Total)
+	}
+	response.Usage = dto.GenerationBatchUsage{
+		TotalTokens:   stats.TotalTokens,
+		EstimatedCost: stats.EstimatedCost,
+	}
+
+	switch {
+	case batch.CancelledAt != nil:
+		response.Status = models.BatchStatusCancelled
+	case stats.Queued+stats.Processing > 0:
+		response.Status = models.BatchStatusRunning
+	case stats.Failed > 0:
+		response.Status = models.BatchStatusCompletedWithFailures
+	default:
+		response.Status = models.BatchStatusCompleted
+	}
+	return response
+}
+
+func batchJob(job *models.GeneratedRPS) dto.GenerationBatchJob {
+	item := dto.GenerationBatchJob{
+		JobID:      job.ID,
+		CourseID:   job.CourseID,
+		Status:     job.Status,
+		Attempts:   job.Attempts,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Course != nil {
+		item.CourseCode = job.Course.Code
+		item.CourseTitle = job.Course.Title
+	}
+	if job.Status == "failed" && job.AIMetadata != nil {
+		var metadata struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(job.AIMetadata, &metadata) == nil {
+			item.Error = metadata.Error
+		}
+	}
+	return item
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/config"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+)
+
+// memGenerationBatchRepository holds one batch and its jobs
+type memGenerationBatchRepository struct {
+	repositories.GenerationBatchRepository
+	batch    models.GenerationBatch
+	jobs     []models.GeneratedRPS
+	requeued bool
+}
+
+func (r *memGenerationBatchRepository) FindByID(id uuid.UUID) (*models.GenerationBatch, error) {
+	if id != r.batch.ID {
+		return nil, helper.ErrNotFound
+	}
+	batch := r.batch
+	return &batch, nil
+}
+
+func (r *memGenerationBatchRepository) FindJobs(batchID uuid.UUID) ([]models.GeneratedRPS, error) {
+	return r.jobs, nil
+}
+
+func (r *memGenerationBatchRepository) Stats(batchIDs ...uuid.UUID) (map[uuid.UUID]repositories.GenerationBatchStats, error) {
+	stats := repositories.GenerationBatchStats{BatchID: r.batch.ID}
+	for _, job := range r.jobs {
+		stats.Total++
+		switch job.Status {
+		case "queued":
+			stats.Queued++
+		case "done":
+			stats.Done++
+		case "failed":
+			stats.Failed++
+		case "cancelled":
+			stats.Cancelled++
+		}
+	}
+	return map[uuid.UUID]repositories.GenerationBatchStats{r.batch.ID: stats}, nil
+}
+
+func (r *memGenerationBatchRepository) RequeueFailed(batchID uuid.UUID) (int64, error) {
+	r.requeued = true
+	var requeued int64
+	for i := range r.jobs {
+		if r.jobs[i].Status == "failed" || r.jobs[i].Status == "cancelled" {
+			r.jobs[i].Status = "queued"
+			requeued++
+		}
+	}
+	return requeued, nil
+}
+
+// budgetCostService refuses the owners listed in exceeded
+type budgetCostService struct {
+	AICostService
+	exceeded map[uuid.UUID]bool
+}
+
+func (s *budgetCostService) CheckBudget(ctx context.Context, owner CostOwner) error {
+	for _, id := range []*uuid.UUID{owner.ProgramID, owner.UserID} {
+		if id != nil && s.exceeded[*id] {
+			return ErrBudgetExceeded
+		}
+	}
+	return nil
+}
+
+func TestRetryFailedChecksLikeCreate(t *testing.T) {
+	creator, doneProgram, failedProgram := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name     string
+		options  string
+		exceeded []uuid.UUID
+		wantErr  error
+	}{
+		{name: "within budget", options: `{"temperature": 0.5}`},
+		{name: "requester over budget", exceeded: []uuid.UUID{creator}, wantErr: ErrBudgetExceeded},
+		{name: "program of a failed job over budget", exceeded: []uuid.UUID{failedProgram}, wantErr: ErrBudgetExceeded},
+		{name: "program of finished jobs only over budget", exceeded: []uuid.UUID{doneProgram}},
+		{name: "temperature no longer allowed", options: `{"temperature": 1.8}`, wantErr: helper.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memGenerationBatchRepository{
+				batch: models.GenerationBatch{ID: uuid.New(), CreatedBy: &creator},
+				jobs: []models.GeneratedRPS{
+					{ID: uuid.New(), Status: "done", Course: &models.Course{ID: uuid.New(), ProgramID: &doneProgram}},
+					{ID: uuid.New(), Status: "failed", Course: &models.Course{ID: uuid.New(), ProgramID: &failedProgram}},
+				},
+			}
+			if tt.options != "" {
+				repo.batch.Options = []byte(tt.options)
+			}
+			costs := &budgetCostService{exceeded: map[uuid.UUID]bool{}}
+			for _, id := range tt.exceeded {
+				costs.exceeded[id] = true
+			}
+			service := &generationBatchService{
+				repo: repo,
+				aiService: &aiService{
+					providers:       map[string]LLMProvider{"fake": newFakeProvider("fake-rps-v1")},
+					defaultProvider: "fake",
+					params:          config.AIParamConfig{MaxTemperature: 1.5, MaxTopP: 1, MaxTopK: 100, MaxTokens: 65536},
+				},
+				costService: costs,
+			}
+
+			batch, err := service.RetryFailed(repo.batch.ID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("RetryFailed() error = %v, want %v", err, tt.wantErr)
+				}
+				if repo.requeued {
+					t.Fatal("jobs were requeued")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RetryFailed() error = %v", err)
+			}
+			if !repo.requeued || batch.Progress.Queued != 1 {
+				t.Fatalf("progress = %+v, want the failed job queued again", batch.Progress)
+			}
+		})
+	}
+}
+
+func TestRetryFailedNothingToRetry(t *testing.T) {
+	repo := &memGenerationBatchRepository{
+		batch: models.GenerationBatch{ID: uuid.New()},
+		jobs:  []models.GeneratedRPS{{ID: uuid.New(), Status: "done"}},
+	}
+	service := &generationBatchService{repo: repo}
+
+	if _, err := service.RetryFailed(repo.batch.ID); !errors.Is(err, ErrBatchNothingToRetry) {
+		t.Fatalf("RetryFailed() error = %v, want ErrBatchNothingToRetry", err)
+	}
+}
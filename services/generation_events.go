@@ -0,0 +1,89 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+)
+
+// Generation event types
+const (
+	GenerationEventStatus     = "status"
+	GenerationEventAttempt    = "attempt"
+	GenerationEventValidation = "validation"
+	GenerationEventResult     = "result"
+)
+
+// eventBuffer is how many events a slow subscriber may lag behind before events are dropped
+const eventBuffer = 32
+
+// GenerationEvents is an in-process pub/sub of generation job progress. Only
+// jobs running in this process are published; subscribers that must also follow
+// jobs of other instances poll the job status as a fallback.
+type GenerationEvents interface {
+	Publish(event dto.GenerationEvent)
+	Subscribe(jobID uuid.UUID) (<-chan dto.GenerationEvent, func())
+}
+
+type generationEvents struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[chan dto.GenerationEvent]struct{}
+}
+
+func NewGenerationEvents() GenerationEvents {
+	return &generationEvents{subscribers: make(map[uuid.UUID]map[chan dto.GenerationEvent]struct{})}
+}
+
+// Publish never blocks the pipeline: a subscriber with a full buffer misses the event
+func (b *generationEvents) Publish(event dto.GenerationEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers[event.JobID] {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Warning: dropped %s event of job %s for a slow subscriber", event.Type, event.JobID)
+		}
+	}
+}
+
+// Subscribe returns the events of jobID and the func that ends the subscription
+func (b *generationEvents) Subscribe(jobID uuid.UUID) (<-chan dto.GenerationEvent, func()) {
+	ch := make(chan dto.GenerationEvent, eventBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[jobID] == nil {
+		b.subscribers[jobID] = make(map[chan dto.GenerationEvent]struct{})
+	}
+	b.subscribers[jobID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers[jobID], ch)
+			if len(b.subscribers[jobID]) == 0 {
+				delete(b.subscribers, jobID)
+			}
+		})
+	}
+}
+
+// statusEvent builds the event of a job status change
+func statusEvent(jobID uuid.UUID, status, message string) dto.GenerationEvent {
+	return dto.GenerationEvent{JobID: jobID, Type: GenerationEventStatus, Status: status, Message: message}
+}
+
+// IsTerminalStatus reports whether a job in status will not change any more
+func IsTerminalStatus(status string) bool {
+	return status == "done" || status == "failed" || status == "cancelled"
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"gorm.io/datatypes"
+)
+
+// RegenerateSection regenerates one section of a finished RPS and merges it into
+// the stored result, keeping manual edits to every other section
+func (s *generationService) RegenerateSection(ctx context.Context, id uuid.UUID, section string, req *dto.RegenerateSectionRequest) (*dto.GeneratedRPSResponse, error) {
+	if _, ok := defaultSectionHeadings[section]; !ok {
+		return nil, ErrUnknownSection
+	}
+
+	rps, err := s.repo.FindByID(id)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, ErrGeneratedRPSNotFound
+		}
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if rps.Status != "done" || len(rps.Result) == 0 {
+		return nil, ErrRPSNotReady
+	}
+	if isContentLocked(rps) {
+		return nil, ErrRPSLocked
+	}
+
+	var current dto.RPSStructuredOutput
+	if err := json.Unmarshal(rps.Result, &current); err != nil {
+		return nil, fmt.Errorf("failed to parse RPS result: %w", err)
+	}
+
+	weekFrom, weekTo := req.WeekFrom, req.WeekTo
+	if weekFrom > 0 || weekTo > 0 {
+		if section != "rencana_mingguan" {
+			return nil, ErrInvalidWeekRange
+		}
+		if weekFrom == 0 {
+			weekFrom = 1
+		}
+		if weekTo == 0 {
+			weekTo = weekFrom
+		}
+		if weekTo > len(current.RencanaMingguan) {
+			return nil, ErrInvalidWeekRange
+		}
+	}
+
+	var options dto.GenerateRPSOptions
+	if rps.JobOptions != nil {
+		if err := json.Unmarshal(rps.JobOptions, &options); err != nil {
+			return nil, fmt.Errorf("failed to parse job options: %w", err)
+		}
+	}
+	options = resolveGenerateOptions(&options)
+	if err := reuseGenerationParams(rps.AIMetadata, &options); err != nil {
+		return nil, err
+	}
+	if req.Provider != "" && req.Provider != options.Provider {
+		// The model settings of the generation belong to its provider
+		options = withoutModelParams(options)
+		options.Provider = req.Provider
+	}
+
+	templateDef, courseData, err := s.loadGenerationInputs(rps)
+	if err != nil {
+		return nil, err
+	}
+	applyCourse := fillFromCourse(&options, courseData)
+
+	owner := CostOwner{UserID: req.RequestedBy}
+	if rps.Course != nil {
+		owner.ProgramID = rps.Course.ProgramID
+	}
+	if err := s.costService.CheckBudget(ctx, owner); err != nil {
+		return nil, err
+	}
+
+	aiResult, err := s.aiService.GenerateSection(ctx, SectionRequest{
+		GeneratedRPSID: rps.ID.String(),
+		Section:        section,
+		WeekFrom:       weekFrom,
+		WeekTo:         weekTo,
+		Instructions:   req.Instructions,
+		Current:        &current,
+		CourseData:     courseData,
+		TemplateDef:    templateDef,
+		Options:        options,
+		Owner:          owner,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if section == "identitas" {
+		applyCourse(&aiResult.Result.Identitas)
+	}
+
+	// Merge into a fresh copy so edits saved while the provider was answering are kept
+	rps, err = s.repo.FindByID(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	var latest dto.RPSStructuredOutput
+	if err := json.Unmarshal(rps.Result, &latest); err != nil {
+		return nil, fmt.Errorf("failed to parse RPS result: %w", err)
+	}
+	if err := mergeRPSSection(&latest, aiResult.Result, section, weekFrom, weekTo); err != nil {
+		return nil, err
+	}
+
+	resultJSON, err := json.Marshal(latest)
+	if err != nil {
+		return nil, err
+	}
+	metadataJSON, err := appendSectionRegeneration(rps.AIMetadata, aiResult.AIMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := rps.Result
+	rps.Result = datatypes.JSON(resultJSON)
+	rps.AIMetadata = metadataJSON
+	applyValidation(rps)
+	rps.UpdatedAt = time.Now()
+
+	note := fmt.Sprintf("Regenerated section %s", section)
+	if weekFrom > 0 {
+		note = fmt.Sprintf("Regenerated weeks %d-%d of %s", weekFrom, weekTo, section)
+	}
+	revisions, err := revisionsFor(s.repo, rps.ID, previous, newRevision(rps.Result, models.RevisionSourceRegeneration, req.RequestedBy, &note))
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if err := s.repo.UpdateWithRevisions(rps, revisions...); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return helper.ToGeneratedRPSResponse(rps), nil
+}
+
+// mergeRPSSection copies section from generated into current. With a week range only
+// those weeks of rencana_mingguan are replaced, matched by their week number.
+func mergeRPSSection(current, generated *dto.RPSStructuredOutput, section string, weekFrom, weekTo int) error {
+	switch section {
+	case "identitas":
+		current.Identitas = generated.Identitas
+	case "capaian_pembelajaran":
+		current.CapaianPembelajaran = generated.CapaianPembelajaran
+	case "deskripsi_mata_kuliah":
+		current.DeskripsiMataKuliah = generated.DeskripsiMataKuliah
+	case "rencana_penilaian":
+		current.RencanaPenilaian = generated.RencanaPenilaian
+	case "daftar_referensi":
+		current.DaftarReferensi = generated.DaftarReferensi
+	case "rencana_mingguan":
+		if weekFrom == 0 {
+			current.RencanaMingguan = generated.RencanaMingguan
+			return nil
+		}
+
+		regenerated := map[int]dto.RPSRencanaMingguan{}
+		for _, plan := range generated.RencanaMingguan {
+			if plan.Minggu >= weekFrom && plan.Minggu <= weekTo {
+				regenerated[plan.Minggu] = plan
+			}
+		}
+		for week := weekFrom; week <= weekTo; week++ {
+			if _, ok := regenerated[week]; !ok {
+				return fmt.Errorf("regenerated rencana_mingguan is missing week %d", week)
+			}
+		}
+
+		weeks := make([]dto.RPSRencanaMingguan, 0, len(current.RencanaMingguan))
+		for _, plan := range current.RencanaMingguan {
+			if plan.Minggu < weekFrom || plan.Minggu > weekTo {
+				weeks = append(weeks, plan)
+			}
+		}
+		for _, plan := range regenerated {
+			weeks = append(weeks, plan)
+		}
+		sort.Slice(weeks, func(i, j int) bool { return weeks[i].Minggu < weeks[j].Minggu })
+		current.RencanaMingguan = weeks
+	default:
+		return ErrUnknownSection
+	}
+	return nil
+}
+
+// appendSectionRegeneration adds an entry to ai_metadata.section_regenerations
+func appendSectionRegeneration(metadata datatypes.JSON, entry map[string]interface{}) (datatypes.JSON, error) {
+	meta := map[string]interface{}{}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &meta); err != nil {
+			return nil, fmt.Errorf("failed to parse ai metadata: %w", err)
+		}
+	}
+
+	entry["regenerated_at"] = time.Now()
+	history, _ := meta["section_regenerations"].([]interface{})
+	meta["section_regenerations"] = append(history, entry)
+
+	metadataJSON, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(metadataJSON), nil
+}
+
+// reuseGenerationParams fills the provider and model parameters options leave
+// unset with those the RPS was generated with, as recorded in ai_metadata
+func reuseGenerationParams(metadata datatypes.JSON, options *dto.GenerateRPSOptions) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+	var used struct {
+		Provider    string   `json:"provider"`
+		Model       string   `json:"model"`
+		Temperature *float64 `json:"temperature"`
+		TopP        *float64 `json:"top_p"`
+		TopK        *int     `json:"top_k"`
+		MaxTokens   *int     `json:"max_tokens"`
+	}
+	if err := json.Unmarshal(metadata, &used); err != nil {
+		return fmt.Errorf("failed to parse ai metadata: %w", err)
+	}
+
+	if options.Provider == "" {
+		options.Provider = used.Provider
+	}
+	if options.Model == "" {
+		options.Model = used.Model
+	}
+	if options.Temperature == nil {
+		options.Temperature = used.Temperature
+	}
+	if options.TopP == nil {
+		options.TopP = used.TopP
+	}
+	// Zero is recorded for providers without top_k
+	if options.TopK == nil && used.TopK != nil && *used.TopK > 0 {
+		options.TopK = used.TopK
+	}
+	if options.MaxTokens == nil {
+		options.MaxTokens = used.MaxTokens
+	}
+	return nil
+}
+
+// withoutModelParams clears the model and sampling parameters of options
+func withoutModelParams(options dto.GenerateRPSOptions) dto.GenerateRPSOptions {
+	options.Model = ""
+	options.Temperature, options.TopP = nil, nil
+	options.TopK, options.MaxTokens = nil, nil
+	return options
+}
@@ -0,0 +1,631 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/config"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+	"gorm.io/datatypes"
+)
+
+var (
+	ErrTemplateVersionNotFound = fmt.Errorf("template version %w", helper.ErrNotFound)
+	ErrCourseNotFound          = fmt.Errorf("course %w", helper.ErrNotFound)
+	ErrGeneratedRPSNotFound    = fmt.Errorf("generated RPS %w", helper.ErrNotFound)
+	ErrRPSNotReady             = errors.New("RPS generation is not completed yet")
+	ErrUnknownSection          = fmt.Errorf("%w: unknown RPS section", helper.ErrInvalidInput)
+	ErrInvalidWeekRange        = fmt.Errorf("%w: week range only applies to rencana_mingguan within its weeks", helper.ErrInvalidInput)
+	ErrJobCancelled            = errors.New("generation job cancelled")
+	ErrJobNotCancellable       = errors.New("only queued or processing jobs can be cancelled")
+)
+
+// GenerationService runs the RPS generation pipeline on generated_rps jobs,
+// either queued for the worker pool or synchronously inside a request
+type GenerationService interface {
+	Enqueue(req *dto.GenerateRPSRequest) (*dto.GeneratedRPSResponse, error)
+	GenerateSync(ctx context.Context, req *dto.GenerateRPSRequest) (*dto.GeneratedRPSResponse, error)
+	RunJob(ctx context.Context, job *models.GeneratedRPS, workerID string) error
+	RegenerateSection(ctx context.Context, id uuid.UUID, section string, req *dto.RegenerateSectionRequest) (*dto.GeneratedRPSResponse, error)
+	Cancel(id uuid.UUID) (*dto.GeneratedRPSResponse, error)
+}
+
+type generationService struct {
+	repo                   repositories.GeneratedRPSRepository
+	aiService              AIService
+	templateVersionService TemplateVersionService
+	courseService          CourseService
+	costService            AICostService
+	events                 GenerationEvents
+	cfg                    config.WorkerConfig
+
+	// Cancel funcs of the jobs running in this process
+	mu      sync.Mutex
+	running map[uuid.UUID]context.CancelCauseFunc
+}
+
+func NewGenerationService(
+	repo repositories.GeneratedRPSRepository,
+	aiService AIService,
+	templateVersionService TemplateVersionService,
+	courseService CourseService,
+	costService AICostService,
+	events GenerationEvents,
+	cfg config.WorkerConfig,
+) GenerationService {
+	return &generationService{
+		repo:                   repo,
+		aiService:              aiService,
+		templateVersionService: templateVersionService,
+		courseService:          courseService,
+		costService:            costService,
+		events:                 events,
+		cfg:                    cfg,
+		running:                make(map[uuid.UUID]context.CancelCauseFunc),
+	}
+}
+
+// Enqueue stores a queued job; a worker picks it up from generated_rps
+func (s *generationService) Enqueue(req *dto.GenerateRPSRequest) (*dto.GeneratedRPSResponse, error) {
+	job, err := s.newJob(req, "queued")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(job); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	s.events.Publish(statusEvent(job.ID, "queued", ""))
+
+	return helper.ToGeneratedRPSResponse(job), nil
+}
+
+// GenerateSync stores the job already leased to this request and runs it inline.
+// If the request is interrupted the job is requeued for the worker pool.
+func (s *generationService) GenerateSync(ctx context.Context, req *dto.GenerateRPSRequest) (*dto.GeneratedRPSResponse, error) {
+	job, err := s.newJob(req, "processing")
+	if err != nil {
+		return nil, err
+	}
+
+	workerID := "sync-" + uuid.NewString()
+	now := time.Now()
+	lockedUntil := now.Add(s.cfg.LeaseDuration)
+	job.LockedBy = &workerID
+	job.LockedUntil = &lockedUntil
+	job.HeartbeatAt = &now
+	job.StartedAt = &now
+	job.Attempts = 1
+
+	if err := s.repo.Create(job); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	if err := s.RunJob(ctx, job, workerID); err != nil {
+		return nil, err
+	}
+
+	rps, err := s.repo.FindByID(job.ID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return helper.ToGeneratedRPSResponse(rps), nil
+}
+
+// RunJob processes a job leased to workerID, keeping the lease alive with
+// heartbeats. Jobs interrupted by ctx go back to the queue instead of failing.
+func (s *generationService) RunJob(ctx context.Context, job *models.GeneratedRPS, workerID string) error {
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	s.track(job.ID, cancel)
+	defer s.untrack(job.ID)
+	s.events.Publish(dto.GenerationEvent{JobID: job.ID, Type: GenerationEventStatus, Status: "processing", Attempt: job.Attempts})
+
+	var leaseLost atomic.Bool
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		s.keepLease(jobCtx, cancel, job.ID, workerID, &leaseLost)
+	}()
+
+	err := s.process(jobCtx, job, workerID)
+	cancel(nil)
+	<-heartbeatDone
+
+	// Cancel already released the lease and stamped the job
+	if jobCancelled(jobCtx) {
+		log.Printf("🛑 Job %s cancelled", job.ID)
+		return ErrJobCancelled
+	}
+	if leaseLost.Load() {
+		log.Printf("⚠️ Job %s: lease lost by %s, leaving it to the new owner", job.ID, workerID)
+		return errLeaseLost(job.ID)
+	}
+
+	interrupted := err != nil && ctx.Err() != nil
+	if releaseErr := s.repo.ReleaseLease(job.ID, workerID, interrupted); releaseErr != nil {
+		log.Printf("Warning: failed to release lease on job %s: %v", job.ID, releaseErr)
+	}
+	if interrupted {
+		log.Printf("↩️ Job %s interrupted, requeued", job.ID)
+		s.events.Publish(statusEvent(job.ID, "queued", "interrupted, requeued"))
+	}
+
+	return err
+}
+
+// keepLease extends the job lease every heartbeat interval until ctx is done.
+// A job cancelled from another process is stopped here as cancelled.
+func (s *generationService) keepLease(ctx context.Context, cancel context.CancelCauseFunc, jobID uuid.UUID, workerID string, leaseLost *atomic.Bool) {
+	ticker := time.NewTicker(s.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			held, err := s.repo.Heartbeat(jobID, workerID, s.cfg.LeaseDuration)
+			if err != nil {
+				log.Printf("Warning: heartbeat for job %s failed: %v", jobID, err)
+				continue
+			}
+			if !held {
+				// Cancel from another process drops the lease too; the row tells them apart
+				if job, err := s.repo.FindByID(jobID); err == nil && job.Status == "cancelled" {
+					cancel(ErrJobCancelled)
+					return
+				}
+				leaseLost.Store(true)
+				cancel(nil)
+				return
+			}
+		}
+	}
+}
+
+// Cancel stops a queued or processing job. A job running in this process is
+// interrupted right away; one running elsewhere stops at its next heartbeat.
+func (s *generationService) Cancel(id uuid.UUID) (*dto.GeneratedRPSResponse, error) {
+	cancelled, err := s.repo.Cancel(id, time.Now())
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if !cancelled {
+		if _, err := s.repo.FindByID(id); err != nil {
+			if helper.IsNotFoundError(helper.WrapDatabaseError(err)) {
+				return nil, ErrGeneratedRPSNotFound
+			}
+			return nil, helper.WrapDatabaseError(err)
+		}
+		return nil, ErrJobNotCancellable
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.running[id]; ok {
+		cancel(ErrJobCancelled)
+	}
+	s.mu.Unlock()
+	s.events.Publish(statusEvent(id, "cancelled", ""))
+
+	rps, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	return helper.ToGeneratedRPSResponse(rps), nil
+}
+
+func (s *generationService) track(id uuid.UUID, cancel context.CancelCauseFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running[id] = cancel
+}
+
+func (s *generationService) untrack(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, id)
+}
+
+// jobCancelled reports whether ctx was stopped by Cancel rather than by shutdown or a lost lease
+func jobCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrJobCancelled)
+}
+
+// errLeaseLost reports a job that went on under another worker
+func errLeaseLost(id uuid.UUID) error {
+	return fmt.Errorf("lease on job %s lost", id)
+}
+
+func (s *generationService) process(ctx context.Context, job *models.GeneratedRPS, workerID string) error {
+	var options dto.GenerateRPSOptions
+	if job.JobOptions != nil {
+		if err := json.Unmarshal(job.JobOptions, &options); err != nil {
+			s.markAsFailed(job.ID, workerID, "Invalid job options")
+			return err
+		}
+	}
+
+	templateDef, courseData, err := s.loadGenerationInputs(job)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTemplateVersionNotFound):
+			s.markAsFailed(job.ID, workerID, "Template version not found")
+		case errors.Is(err, ErrCourseNotFound):
+			s.markAsFailed(job.ID, workerID, "Course not found")
+		default:
+			s.markAsFailed(job.ID, workerID, err.Error())
+		}
+		return err
+	}
+	applyCourse := fillFromCourse(&options, courseData)
+
+	// The claimed job carries no associations; the validator needs the course
+	snapshot, err := s.repo.FindByID(job.ID)
+	if err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+
+	// Budgets may have run out while the job was queued
+	owner := CostOwner{UserID: job.GeneratedBy}
+	if snapshot.Course != nil {
+		owner.ProgramID = snapshot.Course.ProgramID
+	}
+	if err := s.costService.CheckBudget(ctx, owner); err != nil {
+		s.markAsFailed(job.ID, workerID, err.Error())
+		return err
+	}
+
+	// Tag the job with its experiment arm up front, so failed jobs are counted too
+	var experimentID, experimentArm *string
+	if assignment := s.aiService.AssignExperiment(ctx, job.ID.String(), options); assignment != nil {
+		experimentID, experimentArm = &assignment.ExperimentID, &assignment.Arm.Name
+	}
+	if err := s.repo.SetExperiment(job.ID, experimentID, experimentArm); err != nil {
+		log.Printf("Warning: failed to tag job %s with its experiment: %v", job.ID, err)
+	}
+
+	// Regenerate while the validator finds errors, up to the requested number of retries
+	var aiResult *dto.AIGenerationResult
+	var resultJSON []byte
+	for round := 0; ; round++ {
+		aiResult, err = s.aiService.GenerateRPS(ctx, job.ID.String(), owner, courseData, templateDef, options)
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			s.markAsFailed(job.ID, workerID, err.Error())
+			return err
+		}
+
+		applyCourse(&aiResult.Result.Identitas)
+		resultJSON, _ = json.Marshal(aiResult.Result)
+		findings := ValidateRPSResult(datatypes.JSON(resultJSON), snapshot.Course)
+		s.events.Publish(dto.GenerationEvent{
+			JobID:   job.ID,
+			Type:    GenerationEventValidation,
+			Attempt: round + 1,
+			Message: fmt.Sprintf("%d findings", len(findings)),
+			Data:    findings,
+		})
+		if !hasValidationErrors(findings) || round >= options.ValidationRetries {
+			aiResult.AIMetadata["validation_retries"] = round
+			break
+		}
+		log.Printf("🔁 Job %s: result failed validation, regenerating (%d/%d)", job.ID, round+1, options.ValidationRetries)
+	}
+	if jobCancelled(ctx) {
+		return ErrJobCancelled
+	}
+
+	rps, err := s.repo.FindByID(job.ID)
+	if err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+	// A job requeued through the status endpoints must not replace reviewed content
+	if isContentLocked(rps) {
+		s.markAsFailed(job.ID, workerID, ErrRPSLocked.Error())
+		return ErrRPSLocked
+	}
+	previous := rps.Result
+	rps.Result = datatypes.JSON(resultJSON)
+	applyValidation(rps)
+
+	metadataJSON, _ := json.Marshal(aiResult.AIMetadata)
+	rps.Status = "done"
+	rps.AIMetadata = datatypes.JSON(metadataJSON)
+	rps.UpdatedAt = time.Now()
+
+	revisions, err := revisionsFor(s.repo, rps.ID, previous, newRevision(rps.Result, models.RevisionSourceGeneration, rps.GeneratedBy, nil))
+	if err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+	// Only while the job is still ours: a cancel or a new lease holder wins over this run
+	finished, err := s.repo.FinishJob(rps.ID, workerID, map[string]interface{}{
+		"result":              rps.Result,
+		"validation_findings": rps.ValidationFindings,
+		"validated_at":        rps.ValidatedAt,
+		"status":              rps.Status,
+		"ai_metadata":         rps.AIMetadata,
+		"updated_at":          rps.UpdatedAt,
+	}, revisions...)
+	if err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+	if !finished {
+		return s.lostJob(job.ID, workerID)
+	}
+
+	s.events.Publish(dto.GenerationEvent{JobID: job.ID, Type: GenerationEventResult, Status: "done", Data: helper.ToGeneratedRPSResponse(rps)})
+	s.events.Publish(statusEvent(job.ID, "done", ""))
+	return nil
+}
+
+// loadGenerationInputs builds the template definition and course data sent to the AI service
+func (s *generationService) loadGenerationInputs(job *models.GeneratedRPS) (map[string]interface{}, map[string]interface{}, error) {
+	if job.TemplateVersionID == nil {
+		return nil, nil, ErrTemplateVersionNotFound
+	}
+	templateVersion, err := s.templateVersionService.FindByID(*job.TemplateVersionID)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, nil, ErrTemplateVersionNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load template version: %w", err)
+	}
+
+	if job.CourseID == nil {
+		return nil, nil, ErrCourseNotFound
+	}
+	course, err := s.courseService.FindByID(*job.CourseID)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, nil, ErrCourseNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load course: %w", err)
+	}
+
+	// Parse template definition
+	templateDef := map[string]interface{}{}
+	if templateVersion.Definition != nil {
+		defBytes, _ := json.Marshal(templateVersion.Definition)
+		json.Unmarshal(defBytes, &templateDef)
+	}
+	templateDef["id"] = templateVersion.ID.String()
+
+	// Build course data
+	courseData := map[string]interface{}{
+		"id":      course.ID.String(),
+		"title":   course.Title,
+		"code":    course.Code,
+		"credits": course.Credits,
+	}
+	if course.Credits != nil {
+		courseData["credits"] = *course.Credits
+	}
+	if course.Semester != nil {
+		courseData["semester"] = *course.Semester
+	}
+	if course.CourseType != nil {
+		courseData["course_type"] = *course.CourseType
+	}
+	if course.TheoryCredits != nil {
+		courseData["theory_credits"] = *course.TheoryCredits
+	}
+	if course.PracticeCredits != nil {
+		courseData["practice_credits"] = *course.PracticeCredits
+	}
+	if course.Description != nil && *course.Description != "" {
+		courseData["description"] = *course.Description
+	}
+	// The course DTO already holds bahan kajian as []string, the type promptValues reads
+	if len(course.BahanKajian) > 0 {
+		courseData["bahan_kajian"] = course.BahanKajian
+	}
+	if len(course.Lecturers) > 0 {
+		lecturers := make([]string, len(course.Lecturers))
+		for i, lecturer := range course.Lecturers {
+			lecturers[i] = lecturer.Username
+			if lecturer.DisplayName != nil && *lecturer.DisplayName != "" {
+				lecturers[i] = *lecturer.DisplayName
+			}
+		}
+		courseData["lecturers"] = lecturers
+	}
+	if len(course.LearningOutcomes) > 0 {
+		cpl := make([]string, len(course.LearningOutcomes))
+		for i, outcome := range course.LearningOutcomes {
+			cpl[i] = fmt.Sprintf("%s: %s", outcome.Code, outcome.Description)
+		}
+		courseData["cpl"] = cpl
+	}
+
+	tree, err := s.courseService.FindPrerequisiteTree(course.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tree.Prerequisites) > 0 {
+		prasyarat := make([]string, len(tree.Prerequisites))
+		for i, node := range tree.Prerequisites {
+			prasyarat[i] = fmt.Sprintf("%s %s", node.Course.Code, node.Course.Title)
+			if node.Type == models.PrerequisiteTypeCorequisite {
+				prasyarat[i] += " (bersamaan)"
+			}
+		}
+		courseData["prasyarat"] = strings.Join(prasyarat, ", ")
+	}
+
+	return templateDef, courseData, nil
+}
+
+// markAsFailed fails a job still run by workerID, keeping its AI metadata
+func (s *generationService) markAsFailed(jobID uuid.UUID, workerID string, errorMsg string) {
+	failed, err := s.repo.FailJob(jobID, workerID, errorMsg)
+	if err != nil {
+		log.Printf("Warning: failed to mark job %s as failed: %v", jobID, err)
+		return
+	}
+	if !failed {
+		log.Printf("⚠️ Job %s is no longer run by %s, not marking it as failed", jobID, workerID)
+		return
+	}
+	s.events.Publish(statusEvent(jobID, "failed", errorMsg))
+}
+
+// lostJob explains a final write that found the job no longer processing under
+// workerID: it was cancelled from elsewhere, its lease went to another worker or
+// its content got locked for review
+func (s *generationService) lostJob(jobID uuid.UUID, workerID string) error {
+	rps, err := s.repo.FindByID(jobID)
+	if err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+	if rps.Status == "cancelled" {
+		log.Printf("🛑 Job %s was cancelled while running, result discarded", jobID)
+		return ErrJobCancelled
+	}
+	if isContentLocked(rps) {
+		s.markAsFailed(jobID, workerID, ErrRPSLocked.Error())
+		return ErrRPSLocked
+	}
+	log.Printf("⚠️ Job %s: lease lost by %s, result discarded", jobID, workerID)
+	return errLeaseLost(jobID)
+}
+
+// newJob validates the request and builds the generated_rps row carrying the resolved options
+func (s *generationService) newJob(req *dto.GenerateRPSRequest, status string) (*models.GeneratedRPS, error) {
+	if _, err := s.templateVersionService.FindByID(req.TemplateVersionID); err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, ErrTemplateVersionNotFound
+		}
+		return nil, err
+	}
+	course, err := s.courseService.FindByID(req.CourseID)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, ErrCourseNotFound
+		}
+		return nil, err
+	}
+
+	options := resolveGenerateOptions(req.Options)
+	if err := s.aiService.CheckModelParams(options); err != nil {
+		return nil, err
+	}
+	if err := s.costService.CheckBudget(context.Background(), CostOwner{ProgramID: course.ProgramID, UserID: req.GeneratedBy}); err != nil {
+		return nil, err
+	}
+
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	job := helper.ToGeneratedRPSModel(&dto.CreateGeneratedRPSRequest{
+		TemplateVersionID: &req.TemplateVersionID,
+		CourseID:          &req.CourseID,
+		GeneratedBy:       req.GeneratedBy,
+	})
+	job.Status = status
+	job.JobOptions = datatypes.JSON(optionsJSON)
+	return job, nil
+}
+
+// fillFromCourse fills the semester, lecturers and prasyarat the request left empty from
+// the course catalog and prerequisite graph. The returned func copies those values and
+// the catalog-only fields into the identitas of a generated result.
+func fillFromCourse(options *dto.GenerateRPSOptions, courseData map[string]interface{}) func(*dto.RPSIdentitas) {
+	var semesterFilled, dosenFilled, prasyaratFilled bool
+
+	if n, ok := courseData["semester"].(int); ok && !optionGiven(options.Semester, options.Overrides, "semester") {
+		parity := "Ganjil"
+		if n%2 == 0 {
+			parity = "Genap"
+		}
+		options.Semester = fmt.Sprintf("%d (%s)", n, parity)
+		if options.TahunAkademik != "" {
+			options.Semester = fmt.Sprintf("%d (%s %s)", n, parity, options.TahunAkademik)
+		}
+		semesterFilled = true
+	}
+	if lecturers, ok := courseData["lecturers"].([]string); ok && !optionGiven(options.DosenPengampu, options.Overrides, "dosen_pengampu") {
+		options.DosenPengampu = strings.Join(lecturers, ", ")
+		dosenFilled = true
+	}
+	if prasyarat, ok := courseData["prasyarat"].(string); ok && !optionGiven(options.Prasyarat, options.Overrides, "prasyarat") {
+		options.Prasyarat = prasyarat
+		prasyaratFilled = true
+	}
+
+	filled := *options
+	return func(identitas *dto.RPSIdentitas) {
+		if semesterFilled {
+			identitas.Semester = filled.Semester
+		}
+		if dosenFilled {
+			identitas.DosenPengampu = filled.DosenPengampu
+		}
+		if prasyaratFilled {
+			identitas.Prasyarat = filled.Prasyarat
+		}
+		if courseType, ok := courseData["course_type"].(string); ok {
+			identitas.SifatMataKuliah = courseType
+		}
+		identitas.SKSTeori, _ = courseData["theory_credits"].(int)
+		identitas.SKSPraktikum, _ = courseData["practice_credits"].(int)
+	}
+}
+
+// optionGiven reports whether the request set a generation option, directly or as a legacy override
+func optionGiven(value string, overrides map[string]interface{}, key string) bool {
+	if value != "" {
+		return true
+	}
+	override, ok := overrides[key].(string)
+	return ok && override != ""
+}
+
+// resolveGenerateOptions applies the default language and tone to the requested options
+func resolveGenerateOptions(requested *dto.GenerateRPSOptions) dto.GenerateRPSOptions {
+	options := dto.GenerateRPSOptions{
+		Language: "Indonesia",
+		Tone:     "formal",
+	}
+	if requested == nil {
+		return options
+	}
+
+	if requested.Language != "" {
+		options.Language = requested.Language
+	}
+	if requested.Tone != "" {
+		options.Tone = requested.Tone
+	}
+	options.DosenPengampu = requested.DosenPengampu
+	options.Semester = requested.Semester
+	options.Prasyarat = requested.Prasyarat
+	options.ProgramStudi = requested.ProgramStudi
+	options.Fakultas = requested.Fakultas
+	options.TahunAkademik = requested.TahunAkademik
+	options.Overrides = requested.Overrides
+	options.Provider = requested.Provider
+	options.ValidationRetries = requested.ValidationRetries
+	options.Model = requested.Model
+	options.Temperature = requested.Temperature
+	options.TopP = requested.TopP
+	options.TopK = requested.TopK
+	options.MaxTokens = requested.MaxTokens
+	return options
+}
@@ -0,0 +1,874 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/datatypes"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/config"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	mongoModels "github.com/syrlramadhan/dokumentasi-rps-api/models/mongo"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+	mongoRepo "github.com/syrlramadhan/dokumentasi-rps-api/repositories/mongo"
+)
+
+// The fakes below embed their interface and implement only what the generation
+// pipeline calls; any other call panics on the nil interface.
+
+// memGeneratedRPSRepository keeps generated_rps rows in memory
+type memGeneratedRPSRepository struct {
+	repositories.GeneratedRPSRepository
+	mu        sync.Mutex
+	rows      map[uuid.UUID]models.GeneratedRPS
+	courses   map[uuid.UUID]*models.Course
+	revisions []*models.RPSRevision
+}
+
+func newMemGeneratedRPSRepository(courses ...*models.Course) *memGeneratedRPSRepository {
+	repo := &memGeneratedRPSRepository{rows: map[uuid.UUID]models.GeneratedRPS{}, courses: map[uuid.UUID]*models.Course{}}
+	for _, course := range courses {
+		repo.courses[course.ID] = course
+	}
+	return repo
+}
+
+func (r *memGeneratedRPSRepository) Create(rps *models.GeneratedRPS) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rows[rps.ID] = *rps
+	return nil
+}
+
+func (r *memGeneratedRPSRepository) FindByID(id uuid.UUID) (*models.GeneratedRPS, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.rows[id]
+	if !ok {
+		return nil, helper.ErrNotFound
+	}
+	if row.CourseID != nil {
+		row.Course = r.courses[*row.CourseID]
+	}
+	return &row, nil
+}
+
+func (r *memGeneratedRPSRepository) SetExperiment(id uuid.UUID, experimentID, arm *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row := r.rows[id]
+	row.ExperimentID, row.ExperimentArm = experimentID, arm
+	r.rows[id] = row
+	return nil
+}
+
+func (r *memGeneratedRPSRepository) Heartbeat(id uuid.UUID, workerID string, lease time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row := r.rows[id]
+	return row.Status == "processing" && row.LockedBy != nil && *row.LockedBy == workerID, nil
+}
+
+func (r *memGeneratedRPSRepository) Cancel(id uuid.UUID, now time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.rows[id]
+	if !ok || (row.Status != "queued" && row.Status != "processing") {
+		return false, nil
+	}
+	row.Status, row.LockedBy, row.LockedUntil, row.FinishedAt = "cancelled", nil, nil, &now
+	r.rows[id] = row
+	return true, nil
+}
+
+func (r *memGeneratedRPSRepository) ReleaseLease(id uuid.UUID, workerID string, requeue bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row := r.rows[id]
+	if row.LockedBy == nil || *row.LockedBy != workerID {
+		return nil
+	}
+	row.LockedBy, row.LockedUntil = nil, nil
+	if requeue {
+		row.Status = "queued"
+	} else {
+		now := time.Now()
+		row.FinishedAt = &now
+	}
+	r.rows[id] = row
+	return nil
+}
+
+func (r *memGeneratedRPSRepository) FinishJob(id uuid.UUID, workerID string, updates map[string]interface{}, revisions ...*models.RPSRevision) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row := r.rows[id]
+	if row.Status != "processing" || row.LockedBy == nil || *row.LockedBy != workerID || isContentLocked(&row) {
+		return false, nil
+	}
+
+	for column, value := range updates {
+		switch column {
+		case "result":
+			row.Result = value.(datatypes.JSON)
+		case "validation_findings":
+			row.ValidationFindings = value.(datatypes.JSON)
+		case "validated_at":
+			row.ValidatedAt = value.(*time.Time)
+		case "status":
+			row.Status = value.(string)
+		case "ai_metadata":
+			row.AIMetadata = value.(datatypes.JSON)
+		case "updated_at":
+			row.UpdatedAt = value.(time.Time)
+		}
+	}
+	r.rows[id] = row
+
+	for _, revision := range revisions {
+		revision.GeneratedRPSID = id
+		revision.Number = len(r.revisions) + 1
+		r.revisions = append(r.revisions, revision)
+	}
+	return true, nil
+}
+
+func (r *memGeneratedRPSRepository) FailJob(id uuid.UUID, workerID string, errorMsg string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row := r.rows[id]
+	if row.Status != "processing" || row.LockedBy == nil || *row.LockedBy != workerID {
+		return false, nil
+	}
+	metadata := map[string]interface{}{}
+	json.Unmarshal(row.AIMetadata, &metadata)
+	metadata["error"] = errorMsg
+	row.AIMetadata, _ = json.Marshal(metadata)
+	row.Status = "failed"
+	r.rows[id] = row
+	return true, nil
+}
+
+func (r *memGeneratedRPSRepository) UpdateWithRevisions(rps *models.GeneratedRPS, revisions ...*models.RPSRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row := *rps
+	row.Course = nil
+	r.rows[rps.ID] = row
+	for _, revision := range revisions {
+		revision.GeneratedRPSID = rps.ID
+		revision.Number = len(r.revisions) + 1
+		r.revisions = append(r.revisions, revision)
+	}
+	return nil
+}
+
+func (r *memGeneratedRPSRepository) CountRevisions(generatedRPSID uuid.UUID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.revisions)), nil
+}
+
+type stubCourseService struct {
+	CourseService
+	course *dto.CourseResponse
+	tree   *dto.CoursePrerequisiteNode
+	err    error // returned by FindByID when set
+}
+
+func (s *stubCourseService) FindByID(id uuid.UUID) (*dto.CourseResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if id != s.course.ID {
+		return nil, helper.ErrNotFound
+	}
+	return s.course, nil
+}
+
+func (s *stubCourseService) FindPrerequisiteTree(courseID uuid.UUID) (*dto.CoursePrerequisiteNode, error) {
+	return s.tree, nil
+}
+
+type stubTemplateVersionService struct {
+	TemplateVersionService
+	version *dto.TemplateVersionResponse
+	err     error // returned by FindByID when set
+}
+
+func (s *stubTemplateVersionService) FindByID(id uuid.UUID) (*dto.TemplateVersionResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if id != s.version.ID {
+		return nil, helper.ErrNotFound
+	}
+	return s.version, nil
+}
+
+type stubCostService struct {
+	AICostService
+}
+
+func (s *stubCostService) CheckBudget(ctx context.Context, owner CostOwner) error { return nil }
+
+type memAIGenerationRepository struct {
+	mongoRepo.AIGenerationRepository
+	mu          sync.Mutex
+	generations map[primitive.ObjectID]*mongoModels.AIGeneration
+}
+
+func (r *memAIGenerationRepository) StartRun(ctx context.Context, generation *mongoModels.AIGeneration) (*mongoModels.AIGeneration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.generations {
+		if existing.GeneratedRPSID == generation.GeneratedRPSID {
+			existing.Runs++
+			existing.FinalStatus = generation.FinalStatus
+			copied := *existing
+			return &copied, nil
+		}
+	}
+	generation.ID = primitive.NewObjectID()
+	generation.Runs = 1
+	r.generations[generation.ID] = generation
+	copied := *generation
+	return &copied, nil
+}
+
+func (r *memAIGenerationRepository) AddAttempt(ctx context.Context, id primitive.ObjectID, attempt mongoModels.GenerationAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	generation := r.generations[id]
+	generation.Attempts = append(generation.Attempts, attempt)
+	generation.TotalAttempts = len(generation.Attempts)
+	return nil
+}
+
+func (r *memAIGenerationRepository) UpdateFinalStatus(ctx context.Context, id primitive.ObjectID, status string, result map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generations[id].FinalStatus = status
+	r.generations[id].FinalResult = result
+	return nil
+}
+
+type memAIPromptRepository struct {
+	mongoRepo.AIPromptRepository
+	mu      sync.Mutex
+	prompts []mongoModels.AIPrompt
+}
+
+func (r *memAIPromptRepository) Create(ctx context.Context, prompt *mongoModels.AIPrompt) (*mongoModels.AIPrompt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prompt.ID = primitive.NewObjectID()
+	r.prompts = append(r.prompts, *prompt)
+	return prompt, nil
+}
+
+type memPromptTemplateRepository struct {
+	mongoRepo.PromptTemplateRepository
+	templates []mongoModels.PromptTemplate
+}
+
+func (r *memPromptTemplateRepository) FindByCategory(ctx context.Context, category string) ([]mongoModels.PromptTemplate, error) {
+	var found []mongoModels.PromptTemplate
+	for _, template := range r.templates {
+		if template.Category == category {
+			found = append(found, template)
+		}
+	}
+	return found, nil
+}
+
+func (r *memPromptTemplateRepository) IncrementUsage(ctx context.Context, id primitive.ObjectID) error {
+	for i := range r.templates {
+		if r.templates[i].ID == id {
+			r.templates[i].UsageCount++
+		}
+	}
+	return nil
+}
+
+func (r *memPromptTemplateRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*mongoModels.PromptTemplate, error) {
+	for i := range r.templates {
+		if r.templates[i].ID == id {
+			return &r.templates[i], nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *memPromptTemplateRepository) UpdateSuccessRate(ctx context.Context, id primitive.ObjectID, rate float64) error {
+	for i := range r.templates {
+		if r.templates[i].ID == id {
+			r.templates[i].SuccessRate = rate
+		}
+	}
+	return nil
+}
+
+type noExperimentRepository struct {
+	mongoRepo.ExperimentRepository
+}
+
+func (r *noExperimentRepository) FindRunning(ctx context.Context) (*mongoModels.Experiment, error) {
+	return nil, mongo.ErrNoDocuments
+}
+
+type recordedEvents struct {
+	GenerationEvents
+	mu     sync.Mutex
+	events []dto.GenerationEvent
+}
+
+func (e *recordedEvents) Publish(event dto.GenerationEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+// generationFixture wires the generation pipeline to the fake provider and in-memory stores
+type generationFixture struct {
+	service     GenerationService
+	ai          *aiService
+	courses     *stubCourseService
+	versions    *stubTemplateVersionService
+	rpsRepo     *memGeneratedRPSRepository
+	generations *memAIGenerationRepository
+	prompts     *memAIPromptRepository
+	templates   *memPromptTemplateRepository
+	events      *recordedEvents
+	course      *models.Course
+	version     *dto.TemplateVersionResponse
+}
+
+func newGenerationFixture(t *testing.T) *generationFixture {
+	t.Helper()
+
+	prerequisite := models.Course{ID: uuid.New(), Code: "IF101", Title: "Algoritma dan Pemrograman"}
+	bahanKajian, _ := json.Marshal([]string{"Model data relasional", "SQL"})
+	course := &models.Course{
+		ID:          uuid.New(),
+		Code:        "IF201",
+		Title:       "Basis Data",
+		Credits:     intPtr(3),
+		Semester:    intPtr(3),
+		BahanKajian: datatypes.JSON(bahanKajian),
+	}
+	version := &dto.TemplateVersionResponse{ID: uuid.New(), Version: 1, Definition: datatypes.JSON(`{"sections": ["identitas"]}`)}
+
+	f := &generationFixture{
+		rpsRepo:     newMemGeneratedRPSRepository(course),
+		generations: &memAIGenerationRepository{generations: map[primitive.ObjectID]*mongoModels.AIGeneration{}},
+		prompts:     &memAIPromptRepository{},
+		templates:   &memPromptTemplateRepository{},
+		events:      &recordedEvents{},
+		course:      course,
+		version:     version,
+	}
+
+	ai := &aiService{
+		providers:          map[string]LLMProvider{"fake": newFakeProvider("fake-rps-v1")},
+		defaultProvider:    "fake",
+		retry:              config.AIRetryConfig{MaxAttempts: 1, RequestTimeout: time.Minute},
+		params:             config.AIParamConfig{MaxTemperature: 2, MaxTopP: 1, MaxTopK: 100, MaxTokens: 65536},
+		aiPromptRepo:       f.prompts,
+		aiGenerationRepo:   f.generations,
+		promptTemplateRepo: f.templates,
+		experimentRepo:     &noExperimentRepository{},
+		events:             f.events,
+	}
+	f.ai = ai
+	courses := &stubCourseService{
+		course: helper.ToCourseResponse(course),
+		tree: &dto.CoursePrerequisiteNode{
+			Course: *helper.ToCourseResponse(course),
+			Prerequisites: []dto.CoursePrerequisiteNode{
+				{Course: *helper.ToCourseResponse(&prerequisite), Type: models.PrerequisiteTypePrerequisite},
+			},
+		},
+	}
+	f.courses = courses
+	f.versions = &stubTemplateVersionService{version: version}
+	f.service = NewGenerationService(
+		f.rpsRepo,
+		ai,
+		f.versions,
+		courses,
+		&stubCostService{},
+		f.events,
+		config.WorkerConfig{LeaseDuration: time.Minute, HeartbeatInterval: time.Minute},
+	)
+	return f
+}
+
+func TestGenerateSyncWithFakeProvider(t *testing.T) {
+	f := newGenerationFixture(t)
+	requester := uuid.New()
+
+	response, err := f.service.GenerateSync(context.Background(), &dto.GenerateRPSRequest{
+		TemplateVersionID: f.version.ID,
+		CourseID:          f.course.ID,
+		GeneratedBy:       &requester,
+	})
+	if err != nil {
+		t.Fatalf("GenerateSync() error = %v", err)
+	}
+
+	if response.Status != "done" {
+		t.Fatalf("status = %q, want done", response.Status)
+	}
+	if response.FinishedAt == nil {
+		t.Error("finished_at is not set")
+	}
+	row, _ := f.rpsRepo.FindByID(response.ID)
+	if row.LockedBy != nil {
+		t.Errorf("lease still held by %s", *row.LockedBy)
+	}
+
+	var result dto.RPSStructuredOutput
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("result is not an RPS: %v", err)
+	}
+	identitas := result.Identitas
+	if identitas.KodeMataKuliah != "IF201" || identitas.NamaMataKuliah != "Basis Data" || identitas.SKS != 3 {
+		t.Errorf("identitas = %+v, want the course IF201 Basis Data with 3 SKS", identitas)
+	}
+	if identitas.Semester != "3 (Ganjil)" {
+		t.Errorf("semester = %q, want it filled from the course", identitas.Semester)
+	}
+	if identitas.Prasyarat != "IF101 Algoritma dan Pemrograman" {
+		t.Errorf("prasyarat = %q, want it filled from the prerequisite graph", identitas.Prasyarat)
+	}
+	if len(result.RencanaMingguan) != rpsWeekCount {
+		t.Errorf("got %d weeks, want %d", len(result.RencanaMingguan), rpsWeekCount)
+	}
+
+	var findings []dto.ValidationFinding
+	if err := json.Unmarshal(response.ValidationFindings, &findings); err != nil {
+		t.Fatalf("validation findings: %v", err)
+	}
+	if hasValidationErrors(findings) || response.ValidatedAt == nil {
+		t.Errorf("validation findings = %+v, want no errors", findings)
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(response.AIMetadata, &metadata); err != nil {
+		t.Fatalf("ai metadata: %v", err)
+	}
+	if metadata["provider"] != "fake" || metadata["model"] != "fake-rps-v1" || metadata["attempts"] != 1.0 {
+		t.Errorf("ai metadata = %v, want one attempt on fake-rps-v1", metadata)
+	}
+
+	if len(f.rpsRepo.revisions) != 1 || f.rpsRepo.revisions[0].Source != models.RevisionSourceGeneration {
+		t.Errorf("revisions = %+v, want one generation revision", f.rpsRepo.revisions)
+	}
+	if len(f.prompts.prompts) != 1 || f.prompts.prompts[0].Status != "success" {
+		t.Errorf("prompts = %+v, want one successful prompt", f.prompts.prompts)
+	}
+	if len(f.generations.generations) != 1 {
+		t.Fatalf("got %d generation records, want 1", len(f.generations.generations))
+	}
+	for _, generation := range f.generations.generations {
+		if generation.FinalStatus != "success" || len(generation.Attempts) != 1 || generation.RequestedBy != requester.String() {
+			t.Errorf("generation = %+v, want one successful attempt for the requester", generation)
+		}
+	}
+
+	last := f.events.events[len(f.events.events)-1]
+	if last.Type != GenerationEventStatus || last.Status != "done" {
+		t.Errorf("last event = %+v, want status done", last)
+	}
+}
+
+func TestGenerateSyncUnknownCourse(t *testing.T) {
+	f := newGenerationFixture(t)
+
+	_, err := f.service.GenerateSync(context.Background(), &dto.GenerateRPSRequest{
+		TemplateVersionID: f.version.ID,
+		CourseID:          uuid.New(),
+	})
+	if err != ErrCourseNotFound {
+		t.Fatalf("GenerateSync() error = %v, want ErrCourseNotFound", err)
+	}
+	if len(f.rpsRepo.rows) != 0 {
+		t.Fatalf("stored %d jobs for an unknown course", len(f.rpsRepo.rows))
+	}
+}
+
+func TestGenerateSyncKeepsLockedContent(t *testing.T) {
+	f := newGenerationFixture(t)
+	ctx := context.Background()
+
+	first, err := f.service.GenerateSync(ctx, &dto.GenerateRPSRequest{TemplateVersionID: f.version.ID, CourseID: f.course.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The reviewed RPS is requeued and leased again, as the status endpoints allow
+	row := f.rpsRepo.rows[first.ID]
+	workerID := "worker-1"
+	row.Status, row.LockedBy, row.WorkflowStatus = "processing", &workerID, models.WorkflowInReview
+	f.rpsRepo.rows[first.ID] = row
+
+	if err := f.service.RunJob(ctx, &row, workerID); err != ErrRPSLocked {
+		t.Fatalf("RunJob() error = %v, want ErrRPSLocked", err)
+	}
+	after, _ := f.rpsRepo.FindByID(first.ID)
+	if string(after.Result) != string(first.Result) {
+		t.Error("locked content was overwritten")
+	}
+	if after.Status != "failed" || len(f.rpsRepo.revisions) != 1 {
+		t.Errorf("status = %q with %d revisions, want failed with the first revision only", after.Status, len(f.rpsRepo.revisions))
+	}
+	if !strings.Contains(string(after.AIMetadata), ErrRPSLocked.Error()) {
+		t.Errorf("ai metadata = %s, want the lock error", after.AIMetadata)
+	}
+}
+
+func TestGenerateSyncSendsBahanKajian(t *testing.T) {
+	want := "- Model data relasional\n- SQL"
+
+	tests := []struct {
+		name      string
+		templates []mongoModels.PromptTemplate
+	}{
+		{name: "built-in prompts"},
+		{
+			name: "prompt template",
+			templates: []mongoModels.PromptTemplate{{
+				ID:                 primitive.NewObjectID(),
+				Name:               "rps-bahan-kajian",
+				Version:            1,
+				Category:           PromptCategoryRPS,
+				SystemPrompt:       "Susun RPS.",
+				UserPromptTemplate: "RPS {{nama_mata_kuliah}}\nBahan kajian:\n{{bahan_kajian}}",
+				Variables:          []mongoModels.PromptVariable{{Name: "nama_mata_kuliah", Required: true}, {Name: "bahan_kajian", Required: true}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGenerationFixture(t)
+			f.templates.templates = tt.templates
+
+			if _, err := f.service.GenerateSync(context.Background(), &dto.GenerateRPSRequest{TemplateVersionID: f.version.ID, CourseID: f.course.ID}); err != nil {
+				t.Fatalf("GenerateSync() error = %v", err)
+			}
+			if len(f.prompts.prompts) != 1 {
+				t.Fatalf("got %d prompts, want 1", len(f.prompts.prompts))
+			}
+			if prompt := f.prompts.prompts[0].UserPrompt; !strings.Contains(prompt, want) {
+				t.Fatalf("user prompt does not list the bahan kajian of the course:\n%s", prompt)
+			}
+		})
+	}
+}
+
+func TestGenerateSyncChecksRequestParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxTokens int // configured AI_MAX_OUTPUT_TOKENS
+		template  *mongoModels.PromptTemplate
+		options   *dto.GenerateRPSOptions
+		want      int // max_tokens sent; 0 when the request is rejected
+	}{
+		{name: "built-in default", maxTokens: 65536, want: 8192},
+		{name: "built-in default narrowed to the configuration", maxTokens: 4096, want: 4096},
+		{
+			name:      "template default within range",
+			maxTokens: 65536,
+			template:  &mongoModels.PromptTemplate{DefaultMaxTokens: 16384},
+			want:      16384,
+		},
+		{
+			name:      "template default above the configured cap",
+			maxTokens: 4096,
+			template:  &mongoModels.PromptTemplate{DefaultMaxTokens: 8192},
+		},
+		{
+			name:      "caller lowers a template default above the cap",
+			maxTokens: 4096,
+			template:  &mongoModels.PromptTemplate{DefaultMaxTokens: 8192},
+			options:   &dto.GenerateRPSOptions{MaxTokens: intPtr(2048)},
+			want:      2048,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGenerationFixture(t)
+			f.ai.params.MaxTokens = tt.maxTokens
+			if tt.template != nil {
+				template := *tt.template
+				template.ID, template.Name, template.Version, template.Category = primitive.NewObjectID(), "rps", 1, PromptCategoryRPS
+				template.UserPromptTemplate = "RPS {{nama_mata_kuliah}}"
+				f.templates.templates = []mongoModels.PromptTemplate{template}
+			}
+
+			response, err := f.service.GenerateSync(context.Background(), &dto.GenerateRPSRequest{
+				TemplateVersionID: f.version.ID,
+				CourseID:          f.course.ID,
+				Options:           tt.options,
+			})
+			if tt.want == 0 {
+				if !errors.Is(err, helper.ErrInvalidInput) {
+					t.Fatalf("GenerateSync() error = %v, want ErrInvalidInput", err)
+				}
+				if len(f.prompts.prompts) != 0 {
+					t.Fatalf("sent %d prompts, want none", len(f.prompts.prompts))
+				}
+				for _, generation := range f.generations.generations {
+					if generation.FinalStatus != "failed" {
+						t.Errorf("generation status = %q, want failed", generation.FinalStatus)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateSync() error = %v", err)
+			}
+			if response.Status != "done" || len(f.prompts.prompts) != 1 {
+				t.Fatalf("status = %q with %d prompts, want done with one", response.Status, len(f.prompts.prompts))
+			}
+			if got := f.prompts.prompts[0].MaxTokens; got != tt.want {
+				t.Errorf("max_tokens = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateSyncAppliesTemplateDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		template mongoModels.PromptTemplate
+		options  *dto.GenerateRPSOptions
+		want     string // model sent; empty when the request is rejected
+	}{
+		{name: "allowed default model", template: mongoModels.PromptTemplate{DefaultModel: "fake-rps-v2"}, want: "fake-rps-v2"},
+		{name: "no default model", template: mongoModels.PromptTemplate{DefaultTemperature: 0.3}, want: "fake-rps-v1"},
+		{
+			name:     "caller model wins",
+			template: mongoModels.PromptTemplate{DefaultModel: "fake-rps-v2"},
+			options:  &dto.GenerateRPSOptions{Model: "fake-rps-v1"},
+			want:     "fake-rps-v1",
+		},
+		{name: "default model not allowed", template: mongoModels.PromptTemplate{DefaultModel: "gpt-4o"}},
+		{name: "default temperature above the configured cap", template: mongoModels.PromptTemplate{DefaultTemperature: 1.8}},
+		{name: "default max tokens above the configured cap", template: mongoModels.PromptTemplate{DefaultMaxTokens: 32768}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGenerationFixture(t)
+			f.ai.params.AllowedModels = map[string][]string{"fake": {"fake-rps-v2"}}
+			f.ai.params.MaxTemperature = 1.5
+			f.ai.params.MaxTokens = 16384
+			template := tt.template
+			template.ID, template.Name, template.Version, template.Category = primitive.NewObjectID(), "rps", 1, PromptCategoryRPS
+			template.UserPromptTemplate = "RPS {{nama_mata_kuliah}}"
+			f.templates.templates = []mongoModels.PromptTemplate{template}
+
+			_, err := f.service.GenerateSync(context.Background(), &dto.GenerateRPSRequest{
+				TemplateVersionID: f.version.ID,
+				CourseID:          f.course.ID,
+				Options:           tt.options,
+			})
+			if tt.want == "" {
+				if !errors.Is(err, helper.ErrInvalidInput) {
+					t.Fatalf("GenerateSync() error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateSync() error = %v", err)
+			}
+			if len(f.prompts.prompts) != 1 {
+				t.Fatalf("got %d prompts, want 1", len(f.prompts.prompts))
+			}
+			if got := f.prompts.prompts[0].Model; got != tt.want {
+				t.Errorf("model = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// blockingProvider holds every call until its context ends, announcing it on started
+type blockingProvider struct {
+	LLMProvider
+	started chan struct{}
+}
+
+func (p *blockingProvider) Generate(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	p.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestRunJobStopsAtHeartbeat(t *testing.T) {
+	tests := []struct {
+		name       string
+		takeOver   func(repo *memGeneratedRPSRepository, id uuid.UUID) // what another process does to the row
+		wantErr    func(err error) bool
+		wantStatus string // final status of the AI generation record
+	}{
+		{
+			name: "cancelled elsewhere",
+			takeOver: func(repo *memGeneratedRPSRepository, id uuid.UUID) {
+				repo.Cancel(id, time.Now())
+			},
+			wantErr:    func(err error) bool { return errors.Is(err, ErrJobCancelled) },
+			wantStatus: "cancelled",
+		},
+		{
+			name: "leased by another worker",
+			takeOver: func(repo *memGeneratedRPSRepository, id uuid.UUID) {
+				repo.mu.Lock()
+				defer repo.mu.Unlock()
+				row, other := repo.rows[id], "worker-2"
+				row.LockedBy = &other
+				repo.rows[id] = row
+			},
+			wantErr:    func(err error) bool { return err != nil && !errors.Is(err, ErrJobCancelled) },
+			wantStatus: "failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGenerationFixture(t)
+			provider := &blockingProvider{LLMProvider: newFakeProvider("fake-rps-v1"), started: make(chan struct{}, 1)}
+			f.ai.providers["fake"] = provider
+			f.service.(*generationService).cfg.HeartbeatInterval = 10 * time.Millisecond
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := f.service.GenerateSync(context.Background(), &dto.GenerateRPSRequest{TemplateVersionID: f.version.ID, CourseID: f.course.ID})
+				done <- err
+			}()
+
+			select {
+			case <-provider.started:
+			case <-time.After(5 * time.Second):
+				t.Fatal("provider was never called")
+			}
+			var id uuid.UUID
+			f.rpsRepo.mu.Lock()
+			for rowID := range f.rpsRepo.rows {
+				id = rowID
+			}
+			f.rpsRepo.mu.Unlock()
+			tt.takeOver(f.rpsRepo, id)
+
+			select {
+			case err := <-done:
+				if !tt.wantErr(err) {
+					t.Fatalf("GenerateSync() error = %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("job did not stop at the next heartbeat")
+			}
+			for _, generation := range f.generations.generations {
+				if generation.FinalStatus != tt.wantStatus {
+					t.Errorf("generation status = %q, want %q", generation.FinalStatus, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestRegenerateSectionReusesGenerationParams(t *testing.T) {
+	f := newGenerationFixture(t)
+	f.ai.params.AllowedModels = map[string][]string{"fake": {"fake-rps-v2"}}
+	// The template settles the model and temperature; only ai_metadata records them
+	f.templates.templates = []mongoModels.PromptTemplate{{
+		ID:                 primitive.NewObjectID(),
+		Name:               "rps",
+		Version:            1,
+		Category:           PromptCategoryRPS,
+		UserPromptTemplate: "RPS {{nama_mata_kuliah}}",
+		DefaultModel:       "fake-rps-v2",
+		DefaultTemperature: 0.4,
+	}}
+	ctx := context.Background()
+
+	generated, err := f.service.GenerateSync(ctx, &dto.GenerateRPSRequest{
+		TemplateVersionID: f.version.ID,
+		CourseID:          f.course.ID,
+		Options:           &dto.GenerateRPSOptions{MaxTokens: intPtr(6000)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.RegenerateSection(ctx, generated.ID, "deskripsi_mata_kuliah", &dto.RegenerateSectionRequest{}); err != nil {
+		t.Fatalf("RegenerateSection() error = %v", err)
+	}
+
+	if len(f.prompts.prompts) != 2 {
+		t.Fatalf("got %d prompts, want the generation and the section", len(f.prompts.prompts))
+	}
+	first, section := f.prompts.prompts[0], f.prompts.prompts[1]
+	if section.Section != "deskripsi_mata_kuliah" {
+		t.Fatalf("second prompt is for section %q", section.Section)
+	}
+	if section.Provider != first.Provider || section.Model != "fake-rps-v2" || section.Temperature != 0.4 ||
+		section.TopP != first.TopP || section.TopK != first.TopK || section.MaxTokens != 6000 {
+		t.Errorf("section prompt ran %s/%s at temperature %g, top_p %g, top_k %d, max_tokens %d; want the generation's %s/%s at %g, %g, %d, %d",
+			section.Provider, section.Model, section.Temperature, section.TopP, section.TopK, section.MaxTokens,
+			first.Provider, first.Model, first.Temperature, first.TopP, first.TopK, 6000)
+	}
+}
+
+func TestRunJobReportsInputErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		courseErr  error
+		versionErr error
+		wantErr    error
+		wantMsg    string // error recorded in ai_metadata
+	}{
+		{name: "course deleted", courseErr: helper.ErrNotFound, wantErr: ErrCourseNotFound, wantMsg: "Course not found"},
+		{name: "course lookup fails", courseErr: helper.ErrDatabaseOperation, wantErr: helper.ErrDatabaseOperation, wantMsg: "failed to load course: database operation failed"},
+		{name: "template version deleted", versionErr: helper.ErrNotFound, wantErr: ErrTemplateVersionNotFound, wantMsg: "Template version not found"},
+		{name: "template version lookup fails", versionErr: helper.ErrDatabaseOperation, wantErr: helper.ErrDatabaseOperation, wantMsg: "failed to load template version: database operation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGenerationFixture(t)
+			workerID := "worker-1"
+			job := &models.GeneratedRPS{
+				ID:                uuid.New(),
+				TemplateVersionID: &f.version.ID,
+				CourseID:          &f.course.ID,
+				Status:            "processing",
+				LockedBy:          &workerID,
+			}
+			f.rpsRepo.Create(job)
+			f.courses.err, f.versions.err = tt.courseErr, tt.versionErr
+
+			if err := f.service.RunJob(context.Background(), job, workerID); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RunJob() error = %v, want %v", err, tt.wantErr)
+			}
+			row, _ := f.rpsRepo.FindByID(job.ID)
+			var metadata map[string]interface{}
+			json.Unmarshal(row.AIMetadata, &metadata)
+			if row.Status != "failed" || metadata["error"] != tt.wantMsg {
+				t.Errorf("status = %q with error %v, want failed with %q", row.Status, metadata["error"], tt.wantMsg)
+			}
+		})
+	}
+}
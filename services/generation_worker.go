@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/config"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+	"gorm.io/gorm"
+)
+
+// GenerationWorker is a pool of workers leasing queued jobs from generated_rps
+type GenerationWorker interface {
+	Start()
+	Shutdown(ctx context.Context) error
+}
+
+type generationWorker struct {
+	repo       repositories.GeneratedRPSRepository
+	generation GenerationService
+	cfg        config.WorkerConfig
+	instanceID string
+
+	stop       chan struct{}
+	stopOnce   sync.Once
+	jobCtx     context.Context
+	cancelJobs context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+func NewGenerationWorker(repo repositories.GeneratedRPSRepository, generation GenerationService, cfg config.WorkerConfig) GenerationWorker {
+	hostname, _ := os.Hostname()
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+
+	return &generationWorker{
+		repo:       repo,
+		generation: generation,
+		cfg:        cfg,
+		instanceID: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8]),
+		stop:       make(chan struct{}),
+		jobCtx:     jobCtx,
+		cancelJobs: cancelJobs,
+	}
+}
+
+// Start recovers orphaned jobs, then launches the workers and the lease reaper
+func (w *generationWorker) Start() {
+	w.requeueOrphans()
+
+	log.Printf("⚙️ Starting %d generation workers (%s)", w.cfg.Concurrency, w.instanceID)
+	for i := 1; i <= w.cfg.Concurrency; i++ {
+		w.wg.Add(1)
+		go w.run(fmt.Sprintf("%s#%d", w.instanceID, i))
+	}
+
+	w.wg.Add(1)
+	go w.reap()
+}
+
+// Shutdown stops leasing new jobs and waits for running ones to finish. When ctx
+// expires first, running jobs are cancelled and requeued for the next start.
+func (w *generationWorker) Shutdown(ctx context.Context) error {
+	w.stopOnce.Do(func() { close(w.stop) })
+
+	drained := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		log.Println("✅ Generation workers drained")
+		return nil
+	case <-ctx.Done():
+		log.Println("⚠️ Drain timeout reached, requeueing running generation jobs")
+		w.cancelJobs()
+		<-drained
+		return ctx.Err()
+	}
+}
+
+func (w *generationWorker) run(workerID string) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+
+		job, err := w.repo.ClaimNextQueued(workerID, w.cfg.LeaseDuration)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Warning: %s failed to claim job: %v", workerID, err)
+			}
+			select {
+			case <-w.stop:
+				return
+			case <-time.After(w.cfg.PollInterval):
+			}
+			continue
+		}
+
+		log.Printf("🚀 %s processing job %s (attempt %d)", workerID, job.ID, job.Attempts)
+		if err := w.generation.RunJob(w.jobCtx, job, workerID); err != nil {
+			log.Printf("❌ Job %s: %v", job.ID, err)
+			continue
+		}
+		log.Printf("✅ Job %s done", job.ID)
+	}
+}
+
+// reap periodically requeues jobs whose lease expired without a heartbeat
+func (w *generationWorker) reap() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.cfg.LeaseDuration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.requeueOrphans()
+		}
+	}
+}
+
+func (w *generationWorker) requeueOrphans() {
+	requeued, failed, err := w.repo.RequeueExpired(time.Now(), w.cfg.MaxAttempts)
+	if err != nil {
+		log.Printf("Warning: failed to recover orphaned generation jobs: %v", err)
+		return
+	}
+	if requeued > 0 || failed > 0 {
+		log.Printf("♻️ Recovered orphaned generation jobs: %d requeued, %d failed", requeued, failed)
+	}
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+)
+
+var ErrImportHasErrors = fmt.Errorf("%w: the import has invalid rows", helper.ErrInvalidInput)
+
+// ImportService loads programs and courses from CSV or XLSX files. A file is always
+// validated as a whole first; unless dryRun is set it is then written in a single
+// transaction, and only when no row has errors. With upsert, rows whose code already
+// exists update that record instead of being reported as conflicts.
+//
+// Program columns: code, name. Course columns: program_code, code, title, credits,
+// semester, course_type, theory_credits, practice_credits, description and
+// bahan_kajian (items separated by semicolons).
+type ImportService interface {
+	ImportPrograms(filename string, data []byte, dryRun, upsert bool) (*dto.ImportReport, error)
+	ImportCourses(filename string, data []byte, dryRun, upsert bool) (*dto.ImportReport, error)
+}
+
+type importService struct {
+	programRepo repositories.ProgramRepository
+	courseRepo  repositories.CourseRepository
+}
+
+func NewImportService(programRepo repositories.ProgramRepository, courseRepo repositories.CourseRepository) ImportService {
+	return &importService{programRepo: programRepo, courseRepo: courseRepo}
+}
+
+func (s *importService) ImportPrograms(filename string, data []byte, dryRun, upsert bool) (*dto.ImportReport, error) {
+	rows, err := readImportRows(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.programRepo.FindAll()
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	byCode := map[string]*models.Program{}
+	for i := range existing {
+		byCode[strings.ToUpper(existing[i].Code)] = &existing[i]
+	}
+
+	report := newImportReport("program", dryRun, upsert)
+	seen := map[string]int{}
+	var pending []*models.Program
+	for _, row := range rows {
+		req := dto.CreateProgramRequest{Code: row.get("code"), Name: row.get("name")}
+		errs := validationErrors(&req)
+
+		program, exists := byCode[strings.ToUpper(req.Code)]
+		checkImportCode(errs, seen, req.Code, row.line, exists, upsert, "program")
+
+		if !addImportRow(report, row.line, req.Code, errs, exists) {
+			continue
+		}
+		if exists {
+			program.Name = req.Name
+		} else {
+			program = helper.ToProgramModel(&req)
+		}
+		pending = append(pending, program)
+	}
+
+	return report, s.commit(report, func() error { return s.programRepo.SaveAll(pending) })
+}
+
+func (s *importService) ImportCourses(filename string, data []byte, dryRun, upsert bool) (*dto.ImportReport, error) {
+	rows, err := readImportRows(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	programs, err := s.programRepo.FindAll()
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	programsByCode := map[string]*models.Program{}
+	for i := range programs {
+		programsByCode[strings.ToUpper(programs[i].Code)] = &programs[i]
+	}
+
+	existing, err := s.courseRepo.FindAll()
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	byCode := map[string]*models.Course{}
+	for i := range existing {
+		byCode[strings.ToUpper(existing[i].Code)] = &existing[i]
+	}
+
+	report := newImportReport("course", dryRun, upsert)
+	seen := map[string]int{}
+	var pending []*models.Course
+	for _, row := range rows {
+		req, errs := courseRequestFromRow(row)
+		for field, message := range validationErrors(req) {
+			errs[field] = message
+		}
+
+		if code := row.get("program_code"); code != "" {
+			if program, ok := programsByCode[strings.ToUpper(code)]; ok {
+				req.ProgramID = &program.ID
+			} else {
+				errs["ProgramID"] = fmt.Sprintf("unknown program code %s", code)
+			}
+		}
+
+		course, exists := byCode[strings.ToUpper(req.Code)]
+		checkImportCode(errs, seen, req.Code, row.line, exists, upsert, "course")
+
+		if len(errs) == 0 {
+			if exists {
+				applyCourseUpdate(course, &dto.UpdateCourseRequest{
+					ProgramID:       req.ProgramID,
+					Title:           &req.Title,
+					Credits:         req.Credits,
+					Semester:        req.Semester,
+					CourseType:      req.CourseType,
+					TheoryCredits:   req.TheoryCredits,
+					PracticeCredits: req.PracticeCredits,
+					Description:     req.Description,
+					BahanKajian:     req.BahanKajian,
+				})
+			} else {
+				course = helper.ToCourseModel(req)
+			}
+			if err := checkCreditSplit(course); err != nil {
+				errs["Credits"] = err.Error()
+			}
+		}
+
+		if addImportRow(report, row.line, req.Code, errs, exists) {
+			pending = append(pending, course)
+		}
+	}
+
+	return report, s.commit(report, func() error { return s.courseRepo.SaveAll(pending) })
+}
+
+// commit writes the import unless it is a dry run; an import with invalid rows is never written
+func (s *importService) commit(report *dto.ImportReport, save func() error) error {
+	if report.Failed > 0 {
+		if report.DryRun {
+			return nil
+		}
+		return ErrImportHasErrors
+	}
+	if report.DryRun {
+		return nil
+	}
+
+	if err := save(); err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+	report.Committed = true
+	return nil
+}
+
+// courseRequestFromRow reads the course columns of a row; empty cells stay nil
+func courseRequestFromRow(row importRow) (*dto.CreateCourseRequest, map[string]string) {
+	errs := map[string]string{}
+	req := &dto.CreateCourseRequest{
+		Code:            row.get("code"),
+		Title:           row.get("title"),
+		Credits:         importInt(row, "credits", "Credits", errs),
+		Semester:        importInt(row, "semester", "Semester", errs),
+		TheoryCredits:   importInt(row, "theory_credits", "TheoryCredits", errs),
+		PracticeCredits: importInt(row, "practice_credits", "PracticeCredits", errs),
+	}
+	if courseType := strings.ToLower(row.get("course_type")); courseType != "" {
+		req.CourseType = &courseType
+	}
+	if description := row.get("description"); description != "" {
+		req.Description = &description
+	}
+	if bahanKajian := row.get("bahan_kajian"); bahanKajian != "" {
+		for _, item := range strings.FieldsFunc(bahanKajian, func(r rune) bool { return r == ';' || r == '\n' }) {
+			if item = strings.TrimSpace(item); item != "" {
+				req.BahanKajian = append(req.BahanKajian, item)
+			}
+		}
+	}
+	return req, errs
+}
+
+func importInt(row importRow, column, field string, errs map[string]string) *int {
+	value := row.get(column)
+	if value == "" {
+		return nil
+	}
+	number, err := strconv.Atoi(strings.TrimSuffix(value, ".0"))
+	if err != nil {
+		errs[field] = field + " must be a whole number"
+		return nil
+	}
+	return &number
+}
+
+// validationErrors runs the request validation and returns its messages keyed by field
+func validationErrors(req interface{}) map[string]string {
+	if err := helper.ValidateStruct(req); err != nil {
+		return helper.FormatValidationErrors(err)
+	}
+	return map[string]string{}
+}
+
+// checkImportCode reports a code used twice in the file, or one that already exists without upsert
+func checkImportCode(errs map[string]string, seen map[string]int, code string, line int, exists, upsert bool, entity string) {
+	if code == "" {
+		return
+	}
+	key := strings.ToUpper(code)
+	if first, ok := seen[key]; ok {
+		errs["Code"] = fmt.Sprintf("duplicate code %s, first used in row %d", code, first)
+		return
+	}
+	seen[key] = line
+
+	if exists && !upsert {
+		errs["Code"] = fmt.Sprintf("%s %s already exists", entity, code)
+	}
+}
+
+func newImportReport(entity string, dryRun, upsert bool) *dto.ImportReport {
+	return &dto.ImportReport{
+		Entity: entity,
+		DryRun: dryRun,
+		Upsert: upsert,
+		Rows:   []dto.ImportRowResult{},
+	}
+}
+
+// addImportRow records the outcome of a row and reports whether it can be imported
+func addImportRow(report *dto.ImportReport, line int, code string, errs map[string]string, exists bool) bool {
+	result := dto.ImportRowResult{Row: line, Code: code}
+	report.Total++
+
+	switch {
+	case len(errs) > 0:
+		result.Action = "error"
+		result.Errors = errs
+		report.Failed++
+	case exists:
+		result.Action = "update"
+		report.Updated++
+	default:
+		result.Action = "create"
+		report.Created++
+	}
+
+	report.Rows = append(report.Rows, result)
+	return len(errs) == 0
+}
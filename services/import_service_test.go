@@ -0,0 +1,76 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+)
+
+func strPtr(v string) *string { return &v }
+
+func TestCourseRequestFromRow(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   map[string]string
+		want     dto.CreateCourseRequest
+		wantErrs map[string]string
+	}{
+		{
+			name:   "code and title only",
+			values: map[string]string{"code": " IF201 ", "title": "Basis Data"},
+			want:   dto.CreateCourseRequest{Code: "IF201", Title: "Basis Data"},
+		},
+		{
+			name: "every column",
+			values: map[string]string{
+				"code":             "IF201",
+				"title":            "Basis Data",
+				"credits":          "3",
+				"semester":         "4.0",
+				"theory_credits":   "2",
+				"practice_credits": "1",
+				"course_type":      "Wajib",
+				"description":      "Konsep basis data",
+				"bahan_kajian":     "Model relasional; SQL\nNormalisasi;;",
+			},
+			want: dto.CreateCourseRequest{
+				Code:            "IF201",
+				Title:           "Basis Data",
+				Credits:         intPtr(3),
+				Semester:        intPtr(4),
+				TheoryCredits:   intPtr(2),
+				PracticeCredits: intPtr(1),
+				CourseType:      strPtr("wajib"),
+				Description:     strPtr("Konsep basis data"),
+				BahanKajian:     []string{"Model relasional", "SQL", "Normalisasi"},
+			},
+		},
+		{
+			name:   "blank cells stay nil",
+			values: map[string]string{"code": "IF201", "credits": " ", "course_type": "", "bahan_kajian": " ; "},
+			want:   dto.CreateCourseRequest{Code: "IF201"},
+		},
+		{
+			name:     "numbers that are not whole",
+			values:   map[string]string{"code": "IF201", "credits": "tiga", "semester": "2.5"},
+			want:     dto.CreateCourseRequest{Code: "IF201"},
+			wantErrs: map[string]string{"Credits": "Credits must be a whole number", "Semester": "Semester must be a whole number"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, errs := courseRequestFromRow(importRow{line: 2, values: tt.values})
+			if !reflect.DeepEqual(*req, tt.want) {
+				t.Fatalf("courseRequestFromRow() = %+v, want %+v", *req, tt.want)
+			}
+			if tt.wantErrs == nil {
+				tt.wantErrs = map[string]string{}
+			}
+			if !reflect.DeepEqual(errs, tt.wantErrs) {
+				t.Fatalf("courseRequestFromRow() errors = %v, want %v", errs, tt.wantErrs)
+			}
+		})
+	}
+}
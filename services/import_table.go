@@ -0,0 +1,138 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+)
+
+const (
+	MaxImportBytes = 10 << 20 // upload limit of an import file
+	maxImportRows  = 5000
+)
+
+var (
+	ErrUnsupportedImportFormat = fmt.Errorf("%w: only .csv and .xlsx files can be imported", helper.ErrInvalidInput)
+	ErrEmptyImport             = fmt.Errorf("%w: the file has no header row or no data rows", helper.ErrInvalidInput)
+	ErrImportTooLarge          = fmt.Errorf("%w: the file has more than %d rows", helper.ErrInvalidInput, maxImportRows)
+)
+
+// importRow is a data row of an uploaded sheet keyed by normalized header name;
+// line is the row number shown in spreadsheet programs, the header being line 1
+type importRow struct {
+	line   int
+	values map[string]string
+}
+
+func (r importRow) get(column string) string {
+	return strings.TrimSpace(r.values[column])
+}
+
+// readImportRows parses a CSV or XLSX upload into rows keyed by the header of its first row.
+// Blank rows are skipped.
+func readImportRows(filename string, data []byte) ([]importRow, error) {
+	var records [][]string
+	var lines []int
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, lines, err = readCSVRecords(data)
+	case ".xlsx":
+		records, err = readXLSXRows(data)
+		for i := range records {
+			lines = append(lines, i+1)
+		}
+	default:
+		return nil, ErrUnsupportedImportFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", helper.ErrInvalidInput, err)
+	}
+
+	headerIndex := -1
+	for i, record := range records {
+		if !isBlankRecord(record) {
+			headerIndex = i
+			break
+		}
+	}
+	if headerIndex < 0 {
+		return nil, ErrEmptyImport
+	}
+
+	header := make([]string, len(records[headerIndex]))
+	for i, name := range records[headerIndex] {
+		header[i] = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+	}
+
+	var rows []importRow
+	for i := headerIndex + 1; i < len(records); i++ {
+		if isBlankRecord(records[i]) {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, ErrImportTooLarge
+		}
+
+		values := map[string]string{}
+		for j, value := range records[i] {
+			if j < len(header) && header[j] != "" {
+				values[header[j]] = value
+			}
+		}
+		rows = append(rows, importRow{line: lines[i], values: values})
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmptyImport
+	}
+	return rows, nil
+}
+
+// readCSVRecords reads comma or semicolon separated values, the latter being what
+// spreadsheet programs with an Indonesian locale export
+func readCSVRecords(data []byte) ([][]string, []int, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	// Sniff the separator on the header, which may follow blank lines
+	var firstLine []byte
+	for rest := data; len(rest) > 0 && len(bytes.TrimSpace(firstLine)) == 0; {
+		firstLine, rest, _ = bytes.Cut(rest, []byte("\n"))
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return records, lines, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadImportRows(t *testing.T) {
+	xlsx := buildXLSX(t, map[string]string{
+		"xl/sharedStrings.xml":     xlsxSharedStrings,
+		"xl/worksheets/sheet1.xml": xlsxCoursesSheet,
+	})
+
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		want     []importRow
+		wantErr  error
+	}{
+		{
+			name:     "comma separated with BOM",
+			filename: "courses.csv",
+			data:     []byte("\xef\xbb\xbfCode,Course Title\nIF201,Basis Data\n,\nIF202,\"Jaringan, Komputer\"\n"),
+			want: []importRow{
+				{line: 2, values: map[string]string{"code": "IF201", "course_title": "Basis Data"}},
+				{line: 4, values: map[string]string{"code": "IF202", "course_title": "Jaringan, Komputer"}},
+			},
+		},
+		{
+			name:     "semicolon separated after a blank line",
+			filename: "COURSES.CSV",
+			data:     []byte("\ncode;title;credits\nIF201;Basis Data;3,0\n"),
+			want: []importRow{
+				{line: 3, values: map[string]string{"code": "IF201", "title": "Basis Data", "credits": "3,0"}},
+			},
+		},
+		{
+			name:     "cells beyond the header are dropped",
+			filename: "courses.csv",
+			data:     []byte("code\nIF201,extra\n"),
+			want:     []importRow{{line: 2, values: map[string]string{"code": "IF201"}}},
+		},
+		{
+			name:     "xlsx keeps spreadsheet line numbers",
+			filename: "courses.xlsx",
+			data:     xlsx,
+			want: []importRow{
+				{line: 2, values: map[string]string{"code": "IF201", "title": "Basis Data", "credits": "3"}},
+				{line: 4, values: map[string]string{"code": "IF202", "title": "", "credits": "2"}},
+			},
+		},
+		{name: "header only", filename: "courses.csv", data: []byte("code,title\n"), wantErr: ErrEmptyImport},
+		{name: "empty file", filename: "courses.csv", data: nil, wantErr: ErrEmptyImport},
+		{name: "unsupported format", filename: "courses.xls", data: []byte("x"), wantErr: ErrUnsupportedImportFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := readImportRows(tt.filename, tt.data)
+			if err != tt.wantErr {
+				t.Fatalf("readImportRows() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Fatalf("readImportRows() = %+v, want %+v", rows, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+)
+
+var ErrLearningOutcomeProgramMismatch = fmt.Errorf("%w: learning outcomes must exist and belong to the course program", helper.ErrInvalidInput)
+
+// LearningOutcomeService manages the CPL of a program
+type LearningOutcomeService interface {
+	Create(req *dto.CreateLearningOutcomeRequest) (*dto.LearningOutcomeResponse, error)
+	FindByID(id uuid.UUID) (*dto.LearningOutcomeResponse, error)
+	FindByProgramID(programID uuid.UUID) ([]dto.LearningOutcomeResponse, error)
+	Update(id uuid.UUID, req *dto.UpdateLearningOutcomeRequest) (*dto.LearningOutcomeResponse, error)
+	Delete(id uuid.UUID) error
+}
+
+type learningOutcomeService struct {
+	repo        repositories.LearningOutcomeRepository
+	programRepo repositories.ProgramRepository
+}
+
+func NewLearningOutcomeService(repo repositories.LearningOutcomeRepository, programRepo repositories.ProgramRepository) LearningOutcomeService {
+	return &learningOutcomeService{repo: repo, programRepo: programRepo}
+}
+
+func (s *learningOutcomeService) Create(req *dto.CreateLearningOutcomeRequest) (*dto.LearningOutcomeResponse, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.programRepo.FindByID(req.ProgramID); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	outcome := helper.ToLearningOutcomeModel(req)
+	if err := s.repo.Create(outcome); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return helper.ToLearningOutcomeResponse(outcome), nil
+}
+
+func (s *learningOutcomeService) FindByID(id uuid.UUID) (*dto.LearningOutcomeResponse, error) {
+	outcome, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return helper.ToLearningOutcomeResponse(outcome), nil
+}
+
+func (s *learningOutcomeService) FindByProgramID(programID uuid.UUID) ([]dto.LearningOutcomeResponse, error) {
+	outcomes, err := s.repo.FindByProgramID(programID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return helper.ToLearningOutcomeResponseList(outcomes), nil
+}
+
+func (s *learningOutcomeService) Update(id uuid.UUID, req *dto.UpdateLearningOutcomeRequest) (*dto.LearningOutcomeResponse, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	outcome, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	if req.Code != nil {
+		outcome.Code = *req.Code
+	}
+	if req.Description != nil {
+		outcome.Description = *req.Description
+	}
+	if req.Category != nil {
+		outcome.Category = req.Category
+	}
+
+	if err := s.repo.Update(outcome); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return helper.ToLearningOutcomeResponse(outcome), nil
+}
+
+func (s *learningOutcomeService) Delete(id uuid.UUID) error {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return helper.WrapDatabaseError(err)
+	}
+
+	return s.repo.Delete(id)
+}
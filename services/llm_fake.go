@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+)
+
+// fakeProvider returns a deterministic, schema-valid RPS built from the course
+// data without calling any model, so the pipeline can run offline (CI, demos)
+type fakeProvider struct {
+	model string
+}
+
+func newFakeProvider(model string) LLMProvider {
+	return &fakeProvider{model: model}
+}
+
+func (p *fakeProvider) Name() string  { return "fake" }
+func (p *fakeProvider) Model() string { return p.model }
+
+func (p *fakeProvider) Limits() ModelLimits {
+	return ModelLimits{MaxTemperature: 2, MaxTopK: 100, MaxTokens: 65536}
+}
+
+func (p *fakeProvider) Generate(ctx context.Context, llmReq LLMRequest) (*LLMResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	title := fakeString(llmReq.CourseData["title"], "Mata Kuliah")
+	code := fakeString(llmReq.CourseData["code"], "MK000")
+	sks := fakeInt(llmReq.CourseData["credits"], 3)
+
+	rps := dto.RPSStructuredOutput{
+		Identitas: dto.RPSIdentitas{
+			NamaMataKuliah: title,
+			KodeMataKuliah: code,
+			SKS:            sks,
+			Semester:       "-",
+			Prasyarat:      "-",
+			DosenPengampu:  "-",
+		},
+		CapaianPembelajaran: dto.RPSCapaianPembelajaran{
+			CPLProdi: []string{"CPL-01: Mampu menerapkan pemikiran logis, kritis, dan sistematis"},
+			CPMK:     []string{fmt.Sprintf("CPMK-01: Mahasiswa mampu menjelaskan konsep dasar %s", title)},
+			SubCPMK:  []string{fmt.Sprintf("Sub-CPMK-01: Mahasiswa mampu menerapkan %s pada studi kasus", title)},
+		},
+		DeskripsiMataKuliah: dto.RPSDeskripsi{
+			DeskripsiSingkat: fmt.Sprintf("Mata kuliah %s (%s) membahas konsep dan penerapan dasar.", title, code),
+			BahanKajian:      []string{fmt.Sprintf("Pengantar %s", title), fmt.Sprintf("Penerapan %s", title)},
+		},
+		RencanaPenilaian: dto.RPSPenilaian{
+			Komponen: []dto.RPSKomponenPenilaian{
+				{Nama: "Tugas", Bobot: 20, Teknik: "Penugasan", Instrumen: "Rubrik"},
+				{Nama: "Kuis", Bobot: 10, Teknik: "Tes tertulis", Instrumen: "Soal"},
+				{Nama: "Partisipasi", Bobot: 10, Teknik: "Observasi", Instrumen: "Lembar observasi"},
+				{Nama: "UTS", Bobot: 25, Teknik: "Tes tertulis", Instrumen: "Soal"},
+				{Nama: "UAS", Bobot: 35, Teknik: "Tes tertulis", Instrumen: "Soal"},
+			},
+		},
+		DaftarReferensi: dto.RPSReferensi{
+			Utama:     []string{fmt.Sprintf("Buku ajar %s", title)},
+			Pendukung: []string{"Jurnal dan artikel ilmiah terkait"},
+		},
+	}
+
+	for week := 1; week <= 16; week++ {
+		plan := dto.RPSRencanaMingguan{
+			Minggu:             week,
+			Topik:              fmt.Sprintf("Topik %d %s", week, title),
+			SubTopik:           []string{fmt.Sprintf("Subtopik %d.1", week), fmt.Sprintf("Subtopik %d.2", week)},
+			IndikatorCapaian:   fmt.Sprintf("Mahasiswa memahami topik minggu ke-%d", week),
+			MetodePembelajaran: "Ceramah, diskusi",
+			WaktuMenit:         sks * 50,
+			Referensi:          "Referensi utama",
+			BentukPenilaian:    "Tugas",
+		}
+		switch week {
+		case 8:
+			plan.Topik = "Ujian Tengah Semester (UTS)"
+			plan.BentukPenilaian = "UTS"
+		case 16:
+			plan.Topik = "Ujian Akhir Semester (UAS)"
+			plan.BentukPenilaian = "UAS"
+		}
+		rps.RencanaMingguan = append(rps.RencanaMingguan, plan)
+	}
+
+	content, err := json.Marshal(rps)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rough token estimate (~4 chars per token) keeps usage accounting exercised
+	promptTokens := (len(llmReq.SystemPrompt) + len(llmReq.UserPrompt)) / 4
+	completionTokens := len(content) / 4
+
+	return &LLMResponse{
+		Content:          string(content),
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+		FinishReason:     "STOP",
+		StatusCode:       200,
+	}, nil
+}
+
+func fakeString(value interface{}, fallback string) string {
+	if s, ok := value.(string); ok && s != "" {
+		return s
+	}
+	return fallback
+}
+
+func fakeInt(value interface{}, fallback int) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case *int:
+		if v != nil {
+			return *v
+		}
+	case float64:
+		return int(v)
+	}
+	return fallback
+}
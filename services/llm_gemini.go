@@ -0,0 +1,131 @@
+package services
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+)
+
+// geminiProvider calls the Google Gemini generateContent API with a response schema
+type geminiProvider struct {
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+func newGeminiProvider(apiKey, model string) LLMProvider {
+	log.Printf("✅ Gemini API Key detected (length: %d)", len(apiKey))
+	return &geminiProvider{apiKey: apiKey, model: model, httpClient: &http.Client{}}
+}
+
+func (p *geminiProvider) Name() string  { return "google_gemini" }
+func (p *geminiProvider) Model() string { return p.model }
+
+func (p *geminiProvider) Limits() ModelLimits {
+	return ModelLimits{MaxTemperature: 2, MaxTopK: 64, MaxTokens: 65536}
+}
+
+// getGeminiAPIURL builds the Gemini API URL
+func (p *geminiProvider) getGeminiAPIURL(model string) string {
+	return fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", cmp.Or(model, p.model), p.apiKey)
+}
+
+func (p *geminiProvider) Generate(ctx context.Context, llmReq LLMRequest) (*LLMResponse, error) {
+	// Build Gemini request with structured output
+	reqBody := dto.GeminiRequest{
+		SystemInstruction: &dto.GeminiContent{
+			Parts: []dto.GeminiPart{{Text: llmReq.SystemPrompt}},
+		},
+		Contents: []dto.GeminiContent{
+			{
+				Role:  "user",
+				Parts: []dto.GeminiPart{{Text: llmReq.UserPrompt}},
+			},
+		},
+		GenerationConfig: &dto.GeminiGenConfig{
+			Temperature:      &llmReq.Temperature,
+			TopP:             llmReq.TopP,
+			TopK:             llmReq.TopK,
+			MaxOutputTokens:  llmReq.MaxTokens,
+			ResponseMimeType: "application/json",
+			ResponseSchema:   llmReq.Schema,
+		},
+		SafetySettings: []dto.GeminiSafety{
+			{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_NONE"},
+			{Category: "HARM_CATEGORY_HATE_SPEECH", Threshold: "BLOCK_NONE"},
+			{Category: "HARM_CATEGORY_SEXUALLY_EXPLICIT", Threshold: "BLOCK_NONE"},
+			{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Threshold: "BLOCK_NONE"},
+		},
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.getGeminiAPIURL(llmReq.Model), bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Gemini API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	log.Printf("📥 Gemini Response Status: %d", resp.StatusCode)
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("❌ Gemini API Error Response: %s", string(body))
+		var geminiErr dto.GeminiError
+		json.Unmarshal(body, &geminiErr)
+		return nil, httpProviderError(resp, fmt.Sprintf("Gemini API error (status %d): %s", resp.StatusCode, geminiErr.Error.Message))
+	}
+
+	// Parse Gemini response
+	var geminiResp dto.GeminiResponse
+	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		log.Printf("Raw response: %s", string(body))
+		return nil, &ProviderError{StatusCode: resp.StatusCode, Message: "failed to parse Gemini response", Retryable: true}
+	}
+
+	result := &LLMResponse{
+		PromptTokens:     geminiResp.UsageMetadata.PromptTokenCount,
+		CompletionTokens: geminiResp.UsageMetadata.CandidatesTokenCount,
+		TotalTokens:      geminiResp.UsageMetadata.TotalTokenCount,
+		StatusCode:       resp.StatusCode,
+	}
+	if len(geminiResp.Candidates) == 0 {
+		return result, &ProviderError{StatusCode: resp.StatusCode, Message: "no candidates in Gemini response", Retryable: true}
+	}
+
+	result.FinishReason = geminiResp.Candidates[0].FinishReason
+	if len(geminiResp.Candidates[0].Content.Parts) > 0 {
+		result.Content = geminiResp.Candidates[0].Content.Parts[0].Text
+	}
+	return result, nil
+}
+
+// httpProviderError classifies a non-200 response: 429 and 5xx are transient
+func httpProviderError(resp *http.Response, message string) *ProviderError {
+	return &ProviderError{
+		StatusCode: resp.StatusCode,
+		Message:    message,
+		Retryable:  resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
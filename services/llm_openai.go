@@ -0,0 +1,117 @@
+package services
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+)
+
+// openAIProvider calls an OpenAI-compatible /chat/completions endpoint. Pointing
+// the base URL at Ollama or vLLM runs the pipeline against a local model.
+type openAIProvider struct {
+	apiKey     string
+	model      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func newOpenAIProvider(apiKey, model, baseURL string) LLMProvider {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	return &openAIProvider{
+		apiKey:     apiKey,
+		model:      model,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{},
+	}
+}
+
+func (p *openAIProvider) Name() string  { return "openai_compatible" }
+func (p *openAIProvider) Model() string { return p.model }
+
+func (p *openAIProvider) Limits() ModelLimits {
+	return ModelLimits{MaxTemperature: 2, MaxTokens: 16384}
+}
+
+func (p *openAIProvider) Generate(ctx context.Context, llmReq LLMRequest) (*LLMResponse, error) {
+	reqBody := dto.OpenAIChatRequest{
+		Model: cmp.Or(llmReq.Model, p.model),
+		Messages: []dto.OpenAIChatMessage{
+			{Role: "system", Content: llmReq.SystemPrompt},
+			{Role: "user", Content: llmReq.UserPrompt},
+		},
+		Temperature: llmReq.Temperature,
+		TopP:        llmReq.TopP,
+		MaxTokens:   llmReq.MaxTokens,
+		ResponseFormat: &dto.OpenAIResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &dto.OpenAIJSONSchema{
+				Name:   "rps",
+				Schema: llmReq.Schema,
+			},
+		},
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/chat/completions", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call OpenAI-compatible API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	log.Printf("📥 OpenAI-compatible Response Status: %d", resp.StatusCode)
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("❌ OpenAI-compatible API Error Response: %s", string(body))
+		var openAIErr dto.OpenAIError
+		json.Unmarshal(body, &openAIErr)
+		return nil, httpProviderError(resp, fmt.Sprintf("OpenAI-compatible API error (status %d): %s", resp.StatusCode, openAIErr.Error.Message))
+	}
+
+	var chatResp dto.OpenAIChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		log.Printf("Raw response: %s", string(body))
+		return nil, &ProviderError{StatusCode: resp.StatusCode, Message: "failed to parse OpenAI-compatible response", Retryable: true}
+	}
+
+	result := &LLMResponse{
+		PromptTokens:     chatResp.Usage.PromptTokens,
+		CompletionTokens: chatResp.Usage.CompletionTokens,
+		TotalTokens:      chatResp.Usage.TotalTokens,
+		StatusCode:       resp.StatusCode,
+	}
+	if len(chatResp.Choices) == 0 {
+		return result, &ProviderError{StatusCode: resp.StatusCode, Message: "no choices in OpenAI-compatible response", Retryable: true}
+	}
+
+	result.FinishReason = chatResp.Choices[0].FinishReason
+	result.Content = chatResp.Choices[0].Message.Content
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/config"
+)
+
+// LLMProvider is a backend able to produce structured JSON output from a prompt
+type LLMProvider interface {
+	// Name is the value recorded as ai_metadata.provider
+	Name() string
+	Model() string
+	Limits() ModelLimits
+	Generate(ctx context.Context, req LLMRequest) (*LLMResponse, error)
+}
+
+// LLMRequest is a provider-neutral structured generation request
+type LLMRequest struct {
+	Model        string // empty uses the provider's configured model
+	SystemPrompt string
+	UserPrompt   string
+	Schema       map[string]interface{}
+	Temperature  float64
+	TopP         float64
+	TopK         int
+	MaxTokens    int
+
+	// Input context, used by providers that do not call a model
+	CourseData map[string]interface{}
+}
+
+// ModelLimits are the sampling parameter ranges a provider accepts
+type ModelLimits struct {
+	MaxTemperature float64
+	MaxTopK        int // 0 when the provider has no top_k parameter
+	MaxTokens      int
+}
+
+// LLMResponse is the raw structured output with token usage
+type LLMResponse struct {
+	Content          string
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	FinishReason     string
+	StatusCode       int
+}
+
+// ProviderError is a failed provider call; Retryable marks transient failures
+type ProviderError struct {
+	StatusCode int
+	Message    string
+	Retryable  bool
+	RetryAfter time.Duration
+}
+
+func (e *ProviderError) Error() string {
+	return e.Message
+}
+
+// newLLMProviders builds the configured providers keyed by their selection name
+func newLLMProviders(cfg config.AIProviderConfig) map[string]LLMProvider {
+	providers := map[string]LLMProvider{
+		"fake": newFakeProvider(cfg.FakeModel),
+	}
+	if cfg.GeminiAPIKey != "" {
+		providers["gemini"] = newGeminiProvider(cfg.GeminiAPIKey, cfg.GeminiModel)
+	}
+	// A base URL alone is enough for keyless local servers (Ollama, vLLM)
+	if cfg.OpenAIAPIKey != "" || cfg.OpenAIBaseURL != "" {
+		providers["openai"] = newOpenAIProvider(cfg.OpenAIAPIKey, cfg.OpenAIModel, cfg.OpenAIBaseURL)
+	}
+	return providers
+}
+
+// providerFor returns the requested provider, or the default one when name is empty
+func (s *aiService) providerFor(name string) (LLMProvider, error) {
+	if name == "" {
+		name = s.defaultProvider
+	}
+
+	provider, ok := s.providers[name]
+	if !ok {
+		if name == "gemini" {
+			return nil, fmt.Errorf("GEMINI_API_KEY is not set. Please set it in .env file")
+		}
+		available := make([]string, 0, len(s.providers))
+		for key := range s.providers {
+			available = append(available, key)
+		}
+		sort.Strings(available)
+		return nil, fmt.Errorf("LLM provider %q is not configured (available: %v)", name, available)
+	}
+	return provider, nil
+}
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	models "github.com/syrlramadhan/dokumentasi-rps-api/models/mongo"
+	mongoRepo "github.com/syrlramadhan/dokumentasi-rps-api/repositories/mongo"
+)
+
+var (
+	ErrPromptTemplateNotFound  = fmt.Errorf("prompt template %w", helper.ErrNotFound)
+	ErrPromptTemplateNameTaken = errors.New("prompt template name already exists")
+	ErrPromptVariableMissing   = fmt.Errorf("%w: required prompt variable has no value", helper.ErrInvalidInput)
+)
+
+// PromptCategoryRPS is the category of the templates GenerateRPS renders
+const PromptCategoryRPS = "rps_generation"
+
+var (
+	// promptPlaceholder matches a {{name}} reference to a template variable
+	promptPlaceholder  = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+	promptVariableName = regexp.MustCompile(`^\w+$`)
+)
+
+// promptVariables documents the values promptValues provides to RPS generation templates
+var promptVariables = []dto.PromptVariableInfo{
+	{Name: "nama_mata_kuliah", Description: "Course title"},
+	{Name: "kode_mata_kuliah", Description: "Course code"},
+	{Name: "sks", Description: "Credits"},
+	{Name: "sks_teori", Description: "Theory credits, when set in the catalog"},
+	{Name: "sks_praktikum", Description: "Practice credits, when set in the catalog"},
+	{Name: "sifat_mata_kuliah", Description: "Course type, when set in the catalog"},
+	{Name: "deskripsi", Description: "Official course description, when set in the catalog"},
+	{Name: "bahan_kajian", Description: "Study materials as a markdown list, when set in the catalog"},
+	{Name: "cpl", Description: "Program learning outcomes mapped to the course as a markdown list of \"CODE: description\""},
+	{Name: "semester", Description: "Academic semester, from the options (default Ganjil 2024/2025)"},
+	{Name: "dosen_pengampu", Description: "Lecturer, from the options (default Tim Dosen)"},
+	{Name: "prasyarat", Description: "Prerequisites, from the options (default -)"},
+	{Name: "program_studi", Description: "Study program, from the options"},
+	{Name: "fakultas", Description: "Faculty, from the options"},
+	{Name: "bahasa", Description: "Output language (default Indonesia)"},
+	{Name: "gaya_penulisan", Description: "Writing tone (default formal dan akademis)"},
+	{Name: "template_struktur", Description: "RPS template definition as indented JSON"},
+}
+
+type PromptTemplateService interface {
+	Create(ctx context.Context, req *dto.CreatePromptTemplateRequest, userID string) (*models.PromptTemplate, error)
+	FindAll(ctx context.Context) ([]models.PromptTemplate, error)
+	FindByID(ctx context.Context, id string) (*models.PromptTemplate, error)
+	Update(ctx context.Context, id string, req *dto.UpdatePromptTemplateRequest, userID string) (*models.PromptTemplate, error)
+	Delete(ctx context.Context, id string) error
+	Variables() []dto.PromptVariableInfo
+}
+
+type promptTemplateService struct {
+	repo mongoRepo.PromptTemplateRepository
+}
+
+func NewPromptTemplateService(repo mongoRepo.PromptTemplateRepository) PromptTemplateService {
+	return &promptTemplateService{repo: repo}
+}
+
+func (s *promptTemplateService) Create(ctx context.Context, req *dto.CreatePromptTemplateRequest, userID string) (*models.PromptTemplate, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	template := &models.PromptTemplate{
+		Name:               req.Name,
+		Description:        req.Description,
+		Version:            1,
+		SystemPrompt:       req.SystemPrompt,
+		UserPromptTemplate: req.UserPromptTemplate,
+		Variables:          toPromptVariables(req.Variables),
+		DefaultModel:       req.DefaultModel,
+		DefaultTemperature: req.DefaultTemperature,
+		DefaultMaxTokens:   req.DefaultMaxTokens,
+		Category:           req.Category,
+		Tags:               req.Tags,
+		IsActive:           req.IsActive,
+		CreatedBy:          userID,
+	}
+	if template.Category == "" {
+		template.Category = PromptCategoryRPS
+	}
+	if err := checkPromptTemplate(template); err != nil {
+		return nil, err
+	}
+
+	template, err := s.repo.Create(ctx, template)
+	if err != nil {
+		return nil, promptTemplateError(err)
+	}
+	if err := s.activate(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *promptTemplateService) FindAll(ctx context.Context) ([]models.PromptTemplate, error) {
+	templates, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, promptTemplateError(err)
+	}
+	return templates, nil
+}
+
+func (s *promptTemplateService) FindByID(ctx context.Context, id string) (*models.PromptTemplate, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrPromptTemplateNotFound
+	}
+
+	template, err := s.repo.FindByID(ctx, objectID)
+	if err != nil {
+		return nil, promptTemplateError(err)
+	}
+	return template, nil
+}
+
+func (s *promptTemplateService) Update(ctx context.Context, id string, req *dto.UpdatePromptTemplateRequest, userID string) (*models.PromptTemplate, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	template, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new version whenever what gets sent to the model changes
+	changed := false
+	if req.SystemPrompt != nil && *req.SystemPrompt != template.SystemPrompt {
+		template.SystemPrompt = *req.SystemPrompt
+		changed = true
+	}
+	if req.UserPromptTemplate != nil && *req.UserPromptTemplate != template.UserPromptTemplate {
+		template.UserPromptTemplate = *req.UserPromptTemplate
+		changed = true
+	}
+	if req.Variables != nil {
+		if variables := toPromptVariables(req.Variables); !slices.Equal(variables, template.Variables) {
+			template.Variables = variables
+			changed = true
+		}
+	}
+	if changed {
+		template.Version++
+	}
+
+	if req.Name != nil {
+		template.Name = *req.Name
+	}
+	if req.Description != nil {
+		template.Description = *req.Description
+	}
+	if req.DefaultModel != nil {
+		template.DefaultModel = *req.DefaultModel
+	}
+	if req.DefaultTemperature != nil {
+		template.DefaultTemperature = *req.DefaultTemperature
+	}
+	if req.DefaultMaxTokens != nil {
+		template.DefaultMaxTokens = *req.DefaultMaxTokens
+	}
+	if req.Category != nil {
+		template.Category = *req.Category
+	}
+	if req.Tags != nil {
+		template.Tags = req.Tags
+	}
+	if req.IsActive != nil {
+		template.IsActive = *req.IsActive
+	}
+	template.UpdatedBy = userID
+
+	if err := checkPromptTemplate(template); err != nil {
+		return nil, err
+	}
+
+	template, err = s.repo.Update(ctx, template)
+	if err != nil {
+		return nil, promptTemplateError(err)
+	}
+	if err := s.activate(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *promptTemplateService) Delete(ctx context.Context, id string) error {
+	template, err := s.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, template.ID); err != nil {
+		return promptTemplateError(err)
+	}
+	return nil
+}
+
+func (s *promptTemplateService) Variables() []dto.PromptVariableInfo {
+	return promptVariables
+}
+
+// activate keeps an active template the only active one of its category
+func (s *promptTemplateService) activate(ctx context.Context, template *models.PromptTemplate) error {
+	if !template.IsActive {
+		return nil
+	}
+	if err := s.repo.DeactivateOthers(ctx, template.Category, template.ID); err != nil {
+		return promptTemplateError(err)
+	}
+	return nil
+}
+
+func toPromptVariables(reqs []dto.PromptVariableRequest) []models.PromptVariable {
+	variables := make([]models.PromptVariable, 0, len(reqs))
+	for _, req := range reqs {
+		variable := models.PromptVariable{
+			Name:         req.Name,
+			Description:  req.Description,
+			Required:     req.Required,
+			DefaultValue: req.DefaultValue,
+			Type:         req.Type,
+		}
+		if variable.Type == "" {
+			variable.Type = "string"
+		}
+		variables = append(variables, variable)
+	}
+	return variables
+}
+
+// checkPromptTemplate makes sure every placeholder is a declared variable and,
+// for RPS generation, that variables the generator does not provide have a default
+func checkPromptTemplate(template *models.PromptTemplate) error {
+	declared := make(map[string]bool, len(template.Variables))
+	for _, variable := range template.Variables {
+		if !promptVariableName.MatchString(variable.Name) {
+			return fmt.Errorf("%w: variable name %q may only contain letters, digits and underscores", helper.ErrInvalidInput, variable.Name)
+		}
+		if declared[variable.Name] {
+			return fmt.Errorf("%w: variable %q is declared twice", helper.ErrInvalidInput, variable.Name)
+		}
+		declared[variable.Name] = true
+
+		if template.Category == PromptCategoryRPS && variable.DefaultValue == "" && !slices.ContainsFunc(promptVariables, func(info dto.PromptVariableInfo) bool { return info.Name == variable.Name }) {
+			return fmt.Errorf("%w: variable %q is not provided by the generator and needs a default_value", helper.ErrInvalidInput, variable.Name)
+		}
+	}
+
+	for _, text := range []string{template.SystemPrompt, template.UserPromptTemplate} {
+		for _, match := range promptPlaceholder.FindAllStringSubmatch(text, -1) {
+			if !declared[match[1]] {
+				return fmt.Errorf("%w: placeholder {{%s}} is not a declared variable", helper.ErrInvalidInput, match[1])
+			}
+		}
+	}
+	return nil
+}
+
+// renderPromptTemplate fills the placeholders of both prompts of template. A
+// variable without a value takes its default; a required one without either fails.
+func renderPromptTemplate(template *models.PromptTemplate, values map[string]string) (string, string, error) {
+	resolved := make(map[string]string, len(template.Variables))
+	for _, variable := range template.Variables {
+		value := values[variable.Name]
+		if value == "" {
+			value = variable.DefaultValue
+		}
+		if value == "" && variable.Required {
+			return "", "", fmt.Errorf("%w: %s", ErrPromptVariableMissing, variable.Name)
+		}
+		resolved[variable.Name] = value
+	}
+
+	render := func(text string) string {
+		return promptPlaceholder.ReplaceAllStringFunc(text, func(match string) string {
+			return resolved[promptPlaceholder.FindStringSubmatch(match)[1]]
+		})
+	}
+	return render(template.SystemPrompt), render(template.UserPromptTemplate), nil
+}
+
+func promptTemplateError(err error) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return ErrPromptTemplateNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return ErrPromptTemplateNameTaken
+	default:
+		return fmt.Errorf("%w: %v", helper.ErrDatabaseOperation, err)
+	}
+}
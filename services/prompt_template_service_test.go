@@ -0,0 +1,221 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	models "github.com/syrlramadhan/dokumentasi-rps-api/models/mongo"
+)
+
+func TestRenderPromptTemplate(t *testing.T) {
+	template := &models.PromptTemplate{
+		SystemPrompt:       "Anda menyusun RPS dalam bahasa {{bahasa}}.",
+		UserPromptTemplate: "Buat RPS {{ nama_mata_kuliah }} ({{kode_mata_kuliah}}), {{sks}} SKS. {{catatan}}{{nama_mata_kuliah}}",
+		Variables: []models.PromptVariable{
+			{Name: "nama_mata_kuliah", Required: true},
+			{Name: "kode_mata_kuliah", Required: true},
+			{Name: "sks", DefaultValue: "3"},
+			{Name: "bahasa", Required: true, DefaultValue: "Indonesia"},
+			{Name: "catatan"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		values     map[string]string
+		wantSystem string
+		wantUser   string
+		wantErr    error
+	}{
+		{
+			name:       "values and defaults",
+			values:     map[string]string{"nama_mata_kuliah": "Basis Data", "kode_mata_kuliah": "IF201"},
+			wantSystem: "Anda menyusun RPS dalam bahasa Indonesia.",
+			wantUser:   "Buat RPS Basis Data (IF201), 3 SKS. Basis Data",
+		},
+		{
+			name: "values override defaults",
+			values: map[string]string{
+				"nama_mata_kuliah": "Basis Data",
+				"kode_mata_kuliah": "IF201",
+				"sks":              "4",
+				"bahasa":           "Inggris",
+				"catatan":          "Tekankan SQL. ",
+			},
+			wantSystem: "Anda menyusun RPS dalam bahasa Inggris.",
+			wantUser:   "Buat RPS Basis Data (IF201), 4 SKS. Tekankan SQL. Basis Data",
+		},
+		{
+			name:       "values are not rendered again",
+			values:     map[string]string{"nama_mata_kuliah": "{{kode_mata_kuliah}}", "kode_mata_kuliah": "IF201"},
+			wantSystem: "Anda menyusun RPS dalam bahasa Indonesia.",
+			wantUser:   "Buat RPS {{kode_mata_kuliah}} (IF201), 3 SKS. {{kode_mata_kuliah}}",
+		},
+		{
+			name:    "required variable without value",
+			values:  map[string]string{"nama_mata_kuliah": "Basis Data"},
+			wantErr: ErrPromptVariableMissing,
+		},
+		{
+			name:    "empty value of a required variable",
+			values:  map[string]string{"nama_mata_kuliah": "", "kode_mata_kuliah": "IF201"},
+			wantErr: ErrPromptVariableMissing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			system, user, err := renderPromptTemplate(template, tt.values)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("renderPromptTemplate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if system != tt.wantSystem {
+				t.Errorf("system prompt = %q, want %q", system, tt.wantSystem)
+			}
+			if user != tt.wantUser {
+				t.Errorf("user prompt = %q, want %q", user, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestCheckPromptTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template models.PromptTemplate
+		wantErr  bool
+	}{
+		{
+			name: "generator variables",
+			template: models.PromptTemplate{
+				Category:           PromptCategoryRPS,
+				UserPromptTemplate: "RPS {{nama_mata_kuliah}} {{sks}}",
+				Variables:          []models.PromptVariable{{Name: "nama_mata_kuliah"}, {Name: "sks"}},
+			},
+		},
+		{
+			name: "own variable with default",
+			template: models.PromptTemplate{
+				Category:     PromptCategoryRPS,
+				SystemPrompt: "Gaya {{gaya}}",
+				Variables:    []models.PromptVariable{{Name: "gaya", DefaultValue: "formal"}},
+			},
+		},
+		{
+			name: "own variable without default in an RPS template",
+			template: models.PromptTemplate{
+				Category:  PromptCategoryRPS,
+				Variables: []models.PromptVariable{{Name: "gaya"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "own variable without default elsewhere",
+			template: models.PromptTemplate{
+				Category:           "review",
+				UserPromptTemplate: "{{gaya}}",
+				Variables:          []models.PromptVariable{{Name: "gaya"}},
+			},
+		},
+		{
+			name: "undeclared placeholder",
+			template: models.PromptTemplate{
+				Category:           PromptCategoryRPS,
+				UserPromptTemplate: "RPS {{nama_mata_kuliah}} {{ tahun }}",
+				Variables:          []models.PromptVariable{{Name: "nama_mata_kuliah"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "variable declared twice",
+			template: models.PromptTemplate{
+				Variables: []models.PromptVariable{{Name: "sks"}, {Name: "sks"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid variable name",
+			template: models.PromptTemplate{
+				Variables: []models.PromptVariable{{Name: "nama-mk", DefaultValue: "x"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPromptTemplate(&tt.template)
+			if tt.wantErr && !errors.Is(err, helper.ErrInvalidInput) {
+				t.Fatalf("checkPromptTemplate() = %v, want ErrInvalidInput", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("checkPromptTemplate() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestPromptValuesCourseCatalog(t *testing.T) {
+	tests := []struct {
+		name       string
+		courseData map[string]interface{}
+		want       map[string]string // values that must be set
+		missing    []string          // values that must be left to the template defaults
+	}{
+		{
+			name:       "bare course",
+			courseData: map[string]interface{}{"title": "Basis Data", "code": "IF201", "credits": 3},
+			want:       map[string]string{"nama_mata_kuliah": "Basis Data", "kode_mata_kuliah": "IF201", "sks": "3"},
+			missing:    []string{"bahan_kajian", "deskripsi", "sifat_mata_kuliah", "sks_teori", "sks_praktikum", "cpl"},
+		},
+		{
+			name: "catalog fields",
+			courseData: map[string]interface{}{
+				"title":            "Basis Data",
+				"course_type":      "wajib",
+				"theory_credits":   2,
+				"practice_credits": 1,
+				"description":      "Konsep basis data",
+				"bahan_kajian":     []string{"Model data relasional", "SQL"},
+				"cpl":              []string{"CPL-01: Berpikir kritis"},
+			},
+			want: map[string]string{
+				"sifat_mata_kuliah": "wajib",
+				"sks_teori":         "2",
+				"sks_praktikum":     "1",
+				"deskripsi":         "Konsep basis data",
+				"bahan_kajian":      "- Model data relasional\n- SQL",
+				"cpl":               "- CPL-01: Berpikir kritis",
+			},
+		},
+		{
+			name:       "empty bahan kajian",
+			courseData: map[string]interface{}{"bahan_kajian": []string{}},
+			missing:    []string{"bahan_kajian"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := promptValues(tt.courseData, map[string]interface{}{}, dto.GenerateRPSOptions{})
+			for key, want := range tt.want {
+				if values[key] != want {
+					t.Errorf("%s = %q, want %q", key, values[key], want)
+				}
+			}
+			for _, key := range tt.missing {
+				if value, ok := values[key]; ok {
+					t.Errorf("%s = %q, want it unset", key, value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+	"gorm.io/datatypes"
+)
+
+var (
+	ErrCommentNotFound = fmt.Errorf("comment %w", helper.ErrNotFound)
+	ErrInvalidAnchor   = fmt.Errorf("%w: anchor path does not exist in the RPS result", helper.ErrInvalidInput)
+	ErrUnknownMention  = fmt.Errorf("%w: mentioned user does not exist", helper.ErrInvalidInput)
+	ErrNotThreadRoot   = fmt.Errorf("%w: only root comments can be replied to or resolved", helper.ErrInvalidInput)
+)
+
+// RPSCommentService manages review threads anchored to JSON paths of an RPS result.
+// Threads outlive revisions; a thread is reported as outdated once the value at its
+// anchor differs from the value it was written against.
+type RPSCommentService interface {
+	FindThreads(generatedRPSID uuid.UUID, unresolvedOnly bool) ([]dto.RPSCommentResponse, error)
+	Create(generatedRPSID uuid.UUID, req *dto.CreateRPSCommentRequest, authorID *uuid.UUID) (*dto.RPSCommentResponse, error)
+	Reply(generatedRPSID, commentID uuid.UUID, req *dto.ReplyRPSCommentRequest, authorID *uuid.UUID) (*dto.RPSCommentResponse, error)
+	Resolve(generatedRPSID, commentID uuid.UUID, resolvedBy *uuid.UUID) (*dto.RPSCommentResponse, error)
+	Unresolve(generatedRPSID, commentID uuid.UUID) (*dto.RPSCommentResponse, error)
+}
+
+type rpsCommentService struct {
+	repo     repositories.RPSCommentRepository
+	rpsRepo  repositories.GeneratedRPSRepository
+	userRepo repositories.UserRepository
+}
+
+func NewRPSCommentService(repo repositories.RPSCommentRepository, rpsRepo repositories.GeneratedRPSRepository, userRepo repositories.UserRepository) RPSCommentService {
+	return &rpsCommentService{
+		repo:     repo,
+		rpsRepo:  rpsRepo,
+		userRepo: userRepo,
+	}
+}
+
+func (s *rpsCommentService) FindThreads(generatedRPSID uuid.UUID, unresolvedOnly bool) ([]dto.RPSCommentResponse, error) {
+	rps, err := s.rpsRepo.FindByID(generatedRPSID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	threads, err := s.repo.FindThreads(generatedRPSID, unresolvedOnly)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	responses := helper.ToRPSCommentResponseList(threads)
+	for i := range responses {
+		markOutdated(&responses[i], rps.Result)
+	}
+	return responses, nil
+}
+
+// Create opens a thread on the value currently found at req.AnchorPath
+func (s *rpsCommentService) Create(generatedRPSID uuid.UUID, req *dto.CreateRPSCommentRequest, authorID *uuid.UUID) (*dto.RPSCommentResponse, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	rps, err := s.rpsRepo.FindByID(generatedRPSID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if len(rps.Result) == 0 {
+		return nil, ErrRPSNotReady
+	}
+
+	anchorPath := strings.TrimSpace(req.AnchorPath)
+	value, found, err := helper.LookupJSONPath(rps.Result, anchorPath)
+	if err != nil || !found {
+		return nil, ErrInvalidAnchor
+	}
+	anchorValue, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.findMentions(req.Mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.RPSComment{
+		ID:             uuid.New(),
+		GeneratedRPSID: rps.ID,
+		AnchorPath:     anchorPath,
+		AnchorValue:    datatypes.JSON(anchorValue),
+		Body:           strings.TrimSpace(req.Body),
+		AuthorID:       authorID,
+		Mentions:       mentions,
+	}
+	if err := s.repo.Create(comment); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return s.thread(comment.ID, rps.Result)
+}
+
+// Reply adds a comment to a thread and returns the whole thread
+func (s *rpsCommentService) Reply(generatedRPSID, commentID uuid.UUID, req *dto.ReplyRPSCommentRequest, authorID *uuid.UUID) (*dto.RPSCommentResponse, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	rps, root, err := s.findRoot(generatedRPSID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.findMentions(req.Mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &models.RPSComment{
+		ID:             uuid.New(),
+		GeneratedRPSID: root.GeneratedRPSID,
+		ParentID:       &root.ID,
+		AnchorPath:     root.AnchorPath,
+		Body:           strings.TrimSpace(req.Body),
+		AuthorID:       authorID,
+		Mentions:       mentions,
+	}
+	if err := s.repo.Create(reply); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return s.thread(root.ID, rps.Result)
+}
+
+func (s *rpsCommentService) Resolve(generatedRPSID, commentID uuid.UUID, resolvedBy *uuid.UUID) (*dto.RPSCommentResponse, error) {
+	rps, root, err := s.findRoot(generatedRPSID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	root.Resolved = true
+	root.ResolvedBy = resolvedBy
+	root.ResolvedAt = &now
+	root.UpdatedAt = now
+	if err := s.repo.Update(root); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return s.thread(root.ID, rps.Result)
+}
+
+func (s *rpsCommentService) Unresolve(generatedRPSID, commentID uuid.UUID) (*dto.RPSCommentResponse, error) {
+	rps, root, err := s.findRoot(generatedRPSID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	root.Resolved = false
+	root.ResolvedBy = nil
+	root.ResolvedAt = nil
+	root.UpdatedAt = time.Now()
+	if err := s.repo.Update(root); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return s.thread(root.ID, rps.Result)
+}
+
+// findRoot loads the RPS and a root comment belonging to it
+func (s *rpsCommentService) findRoot(generatedRPSID, commentID uuid.UUID) (*models.GeneratedRPS, *models.RPSComment, error) {
+	rps, err := s.rpsRepo.FindByID(generatedRPSID)
+	if err != nil {
+		return nil, nil, helper.WrapDatabaseError(err)
+	}
+
+	comment, err := s.repo.FindByID(commentID)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, nil, ErrCommentNotFound
+		}
+		return nil, nil, helper.WrapDatabaseError(err)
+	}
+	if comment.GeneratedRPSID != rps.ID {
+		return nil, nil, ErrCommentNotFound
+	}
+	if comment.ParentID != nil {
+		return nil, nil, ErrNotThreadRoot
+	}
+
+	return rps, comment, nil
+}
+
+func (s *rpsCommentService) findMentions(ids []uuid.UUID) ([]models.User, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	unique := make([]uuid.UUID, 0, len(ids))
+	seen := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	users, err := s.userRepo.FindByIDs(unique)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if len(users) != len(unique) {
+		return nil, ErrUnknownMention
+	}
+	return users, nil
+}
+
+func (s *rpsCommentService) thread(rootID uuid.UUID, result datatypes.JSON) (*dto.RPSCommentResponse, error) {
+	root, err := s.repo.FindByID(rootID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	response := helper.ToRPSCommentResponse(root)
+	markOutdated(response, result)
+	return response, nil
+}
+
+// markOutdated flags a thread whose anchored value changed or no longer exists
+func markOutdated(thread *dto.RPSCommentResponse, result datatypes.JSON) {
+	current, found, err := helper.LookupJSONPath(result, thread.AnchorPath)
+
+	var original interface{}
+	if len(thread.AnchorValue) > 0 {
+		json.Unmarshal(thread.AnchorValue, &original)
+	}
+
+	thread.Outdated = err != nil || !found || !reflect.DeepEqual(current, original)
+	for i := range thread.Replies {
+		thread.Replies[i].Outdated = thread.Outdated
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+	"gorm.io/datatypes"
+)
+
+var ErrRevisionNotFound = fmt.Errorf("revision %w", helper.ErrNotFound)
+
+// RPSRevisionService exposes the immutable revisions written on every change of a
+// generated RPS result
+type RPSRevisionService interface {
+	FindAll(generatedRPSID uuid.UUID) ([]dto.RPSRevisionResponse, error)
+	FindByNumber(generatedRPSID uuid.UUID, number int) (*dto.RPSRevisionResponse, error)
+	Restore(generatedRPSID uuid.UUID, number int, req *dto.RestoreRPSRevisionRequest, restoredBy *uuid.UUID) (*dto.GeneratedRPSResponse, error)
+	Diff(generatedRPSID uuid.UUID, from, to int) (*dto.RPSRevisionDiffResponse, error)
+}
+
+type rpsRevisionService struct {
+	repo repositories.GeneratedRPSRepository
+}
+
+func NewRPSRevisionService(repo repositories.GeneratedRPSRepository) RPSRevisionService {
+	return &rpsRevisionService{repo: repo}
+}
+
+func (s *rpsRevisionService) FindAll(generatedRPSID uuid.UUID) ([]dto.RPSRevisionResponse, error) {
+	if _, err := s.repo.FindByID(generatedRPSID); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	revisions, err := s.repo.FindRevisions(generatedRPSID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	return helper.ToRPSRevisionResponseList(revisions), nil
+}
+
+func (s *rpsRevisionService) FindByNumber(generatedRPSID uuid.UUID, number int) (*dto.RPSRevisionResponse, error) {
+	revision, err := s.findRevision(generatedRPSID, number)
+	if err != nil {
+		return nil, err
+	}
+	return helper.ToRPSRevisionResponse(revision), nil
+}
+
+// Restore makes the content of an older revision current again, recorded as a new revision
+func (s *rpsRevisionService) Restore(generatedRPSID uuid.UUID, number int, req *dto.RestoreRPSRevisionRequest, restoredBy *uuid.UUID) (*dto.GeneratedRPSResponse, error) {
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	rps, err := s.repo.FindByID(generatedRPSID)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if isContentLocked(rps) {
+		return nil, ErrRPSLocked
+	}
+
+	revision, err := s.findRevision(generatedRPSID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	note := req.Note
+	if note == nil {
+		restored := fmt.Sprintf("Restored revision %d", number)
+		note = &restored
+	}
+
+	rps.Result = revision.Result
+	rps.UpdatedAt = time.Now()
+	applyValidation(rps)
+
+	restore := newRevision(rps.Result, models.RevisionSourceRestore, restoredBy, note)
+	restore.RestoredFrom = &revision.Number
+	if err := s.repo.UpdateWithRevisions(rps, restore); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	return helper.ToGeneratedRPSResponse(rps), nil
+}
+
+// Diff compares two revisions field by field after normalizing both to RPSStructuredOutput,
+// so paths read like "rencana_mingguan[3].topik" or "rencana_penilaian.komponen[1].bobot"
+func (s *rpsRevisionService) Diff(generatedRPSID uuid.UUID, from, to int) (*dto.RPSRevisionDiffResponse, error) {
+	fromRevision, err := s.findRevision(generatedRPSID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.findRevision(generatedRPSID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := helper.DiffJSON(structuredResult(fromRevision.Result), structuredResult(toRevision.Result))
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff revisions: %w", err)
+	}
+
+	return &dto.RPSRevisionDiffResponse{
+		GeneratedRPSID: generatedRPSID,
+		From:           from,
+		To:             to,
+		Changes:        helper.ToRPSRevisionChanges(changes),
+	}, nil
+}
+
+func (s *rpsRevisionService) findRevision(generatedRPSID uuid.UUID, number int) (*models.RPSRevision, error) {
+	revision, err := s.repo.FindRevision(generatedRPSID, number)
+	if err != nil {
+		if helper.IsNotFoundError(err) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, helper.WrapDatabaseError(err)
+	}
+	return revision, nil
+}
+
+// structuredResult parses a result into RPSStructuredOutput so field order and unknown
+// keys do not show up as changes; results that do not fit are compared as raw JSON
+func structuredResult(result datatypes.JSON) interface{} {
+	var rps dto.RPSStructuredOutput
+	if err := json.Unmarshal(result, &rps); err != nil {
+		return []byte(result)
+	}
+	return rps
+}
+
+func newRevision(result datatypes.JSON, source string, authorID *uuid.UUID, note *string) *models.RPSRevision {
+	return &models.RPSRevision{
+		ID:       uuid.New(),
+		Source:   source,
+		Result:   result,
+		Note:     note,
+		AuthorID: authorID,
+	}
+}
+
+// revisionsFor returns the revisions to store with a new result. An RPS whose result
+// predates revision history first gets its previous content saved as a baseline.
+func revisionsFor(repo repositories.GeneratedRPSRepository, rpsID uuid.UUID, previous datatypes.JSON, revision *models.RPSRevision) ([]*models.RPSRevision, error) {
+	if len(previous) == 0 {
+		return []*models.RPSRevision{revision}, nil
+	}
+
+	count, err := repo.CountRevisions(rpsID)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return []*models.RPSRevision{revision}, nil
+	}
+
+	baselineNote := "Content before revision history"
+	baseline := newRevision(previous, models.RevisionSourceBaseline, nil, &baselineNote)
+	return []*models.RPSRevision{baseline, revision}, nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"gorm.io/datatypes"
+)
+
+const (
+	rpsWeekCount    = 16
+	rpsUTSWeek      = 8
+	rpsUASWeek      = 16
+	minutesPerSKS   = 50
+	totalBobotRPS   = 100
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+var (
+	utsPattern = regexp.MustCompile(`(?i)\bUTS\b|ujian tengah semester|mid[- ]?term`)
+	uasPattern = regexp.MustCompile(`(?i)\bUAS\b|ujian akhir semester|final exam`)
+)
+
+// ValidateRPSResult checks an RPS result against the structural and academic rules the
+// generation prompt demands. course may be nil, which skips the course consistency rules.
+func ValidateRPSResult(result datatypes.JSON, course *models.Course) []dto.ValidationFinding {
+	findings := []dto.ValidationFinding{}
+	add := func(severity, rule, path, format string, args ...interface{}) {
+		findings = append(findings, dto.ValidationFinding{Severity: severity, Rule: rule, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var rps dto.RPSStructuredOutput
+	if len(result) == 0 {
+		add(severityError, "result.present", "", "RPS result is empty")
+		return findings
+	}
+	if err := json.Unmarshal(result, &rps); err != nil {
+		add(severityError, "result.schema", "", "RPS result does not match the RPS structure: %v", err)
+		return findings
+	}
+
+	// Identitas
+	if course != nil {
+		if !strings.EqualFold(strings.TrimSpace(rps.Identitas.KodeMataKuliah), strings.TrimSpace(course.Code)) {
+			add(severityError, "identitas.kode", "identitas.kode_mata_kuliah", "course code %q does not match course %q", rps.Identitas.KodeMataKuliah, course.Code)
+		}
+		if !strings.EqualFold(strings.TrimSpace(rps.Identitas.NamaMataKuliah), strings.TrimSpace(course.Title)) {
+			add(severityWarning, "identitas.nama", "identitas.nama_mata_kuliah", "course name %q differs from course %q", rps.Identitas.NamaMataKuliah, course.Title)
+		}
+		if course.Credits != nil && rps.Identitas.SKS != *course.Credits {
+			add(severityError, "identitas.sks", "identitas.sks", "SKS %d does not match the course credits %d", rps.Identitas.SKS, *course.Credits)
+		}
+	}
+	if rps.Identitas.SKS <= 0 {
+		add(severityError, "identitas.sks", "identitas.sks", "SKS must be greater than 0")
+	}
+
+	// Capaian pembelajaran & deskripsi
+	if len(rps.CapaianPembelajaran.CPMK) == 0 {
+		add(severityWarning, "capaian.cpmk", "capaian_pembelajaran.cpmk", "no CPMK listed")
+	}
+	if len(rps.CapaianPembelajaran.SubCPMK) == 0 {
+		add(severityWarning, "capaian.sub_cpmk", "capaian_pembelajaran.sub_cpmk", "no Sub-CPMK listed")
+	}
+	if strings.TrimSpace(rps.DeskripsiMataKuliah.DeskripsiSingkat) == "" {
+		add(severityWarning, "deskripsi.singkat", "deskripsi_mata_kuliah.deskripsi_singkat", "course description is empty")
+	}
+
+	// Rencana mingguan
+	if len(rps.RencanaMingguan) != rpsWeekCount {
+		add(severityError, "mingguan.jumlah", "rencana_mingguan", "expected %d weeks, found %d", rpsWeekCount, len(rps.RencanaMingguan))
+	}
+	seen := map[int]bool{}
+	expectedMinutes := rps.Identitas.SKS * minutesPerSKS
+	for i, plan := range rps.RencanaMingguan {
+		path := fmt.Sprintf("rencana_mingguan[%d]", i)
+		if plan.Minggu != i+1 {
+			add(severityError, "mingguan.urutan", path+".minggu", "week %d is listed at position %d", plan.Minggu, i+1)
+		}
+		if seen[plan.Minggu] {
+			add(severityError, "mingguan.duplikat", path+".minggu", "week %d appears more than once", plan.Minggu)
+		}
+		seen[plan.Minggu] = true
+
+		if strings.TrimSpace(plan.Topik) == "" {
+			add(severityError, "mingguan.topik", path+".topik", "week %d has no topic", plan.Minggu)
+		}
+
+		text := plan.Topik + " " + plan.BentukPenilaian
+		isUTS, isUAS := utsPattern.MatchString(text), uasPattern.MatchString(text)
+		switch plan.Minggu {
+		case rpsUTSWeek:
+			if !isUTS {
+				add(severityError, "mingguan.uts", path, "week %d must be the UTS", rpsUTSWeek)
+			}
+		case rpsUASWeek:
+			if !isUAS {
+				add(severityError, "mingguan.uas", path, "week %d must be the UAS", rpsUASWeek)
+			}
+		default:
+			if isUTS || isUAS {
+				add(severityWarning, "mingguan.ujian", path, "week %d mentions an exam outside weeks %d and %d", plan.Minggu, rpsUTSWeek, rpsUASWeek)
+			}
+		}
+
+		if expectedMinutes > 0 && plan.WaktuMenit != expectedMinutes {
+			severity := severityError
+			if plan.Minggu == rpsUTSWeek || plan.Minggu == rpsUASWeek {
+				severity = severityWarning // exams may run shorter or longer than a meeting
+			}
+			add(severity, "mingguan.waktu", path+".waktu_menit", "week %d lasts %d minutes, %d SKS requires %d", plan.Minggu, plan.WaktuMenit, rps.Identitas.SKS, expectedMinutes)
+		}
+	}
+
+	// Rencana penilaian
+	if len(rps.RencanaPenilaian.Komponen) == 0 {
+		add(severityError, "penilaian.komponen", "rencana_penilaian.komponen", "no assessment components")
+	} else {
+		total := 0
+		for i, k := range rps.RencanaPenilaian.Komponen {
+			if k.Bobot <= 0 {
+				add(severityError, "penilaian.bobot", fmt.Sprintf("rencana_penilaian.komponen[%d].bobot", i), "component %q must have a positive weight", k.Nama)
+			}
+			total += k.Bobot
+		}
+		if total != totalBobotRPS {
+			add(severityError, "penilaian.total_bobot", "rencana_penilaian.komponen", "assessment weights sum to %d%%, expected %d%%", total, totalBobotRPS)
+		}
+	}
+
+	// Daftar referensi
+	if len(rps.DaftarReferensi.Utama) == 0 {
+		add(severityWarning, "referensi.utama", "daftar_referensi.utama", "no main references listed")
+	}
+
+	return findings
+}
+
+// hasValidationErrors reports whether any finding is an error
+func hasValidationErrors(findings []dto.ValidationFinding) bool {
+	for _, finding := range findings {
+		if finding.Severity == severityError {
+			return true
+		}
+	}
+	return false
+}
+
+// applyValidation validates the RPS result and stores the findings on the row
+func applyValidation(rps *models.GeneratedRPS) []dto.ValidationFinding {
+	findings := ValidateRPSResult(rps.Result, rps.Course)
+	findingsJSON, _ := json.Marshal(findings)
+	now := time.Now()
+
+	rps.ValidationFindings = datatypes.JSON(findingsJSON)
+	rps.ValidatedAt = &now
+	return findings
+}
+
+// toValidationReport summarizes the stored findings of an RPS
+func toValidationReport(rps *models.GeneratedRPS) (*dto.RPSValidationReport, error) {
+	findings := []dto.ValidationFinding{}
+	if len(rps.ValidationFindings) > 0 {
+		if err := json.Unmarshal(rps.ValidationFindings, &findings); err != nil {
+			return nil, fmt.Errorf("failed to parse validation findings: %w", err)
+		}
+	}
+
+	report := &dto.RPSValidationReport{
+		GeneratedRPSID: rps.ID,
+		Findings:       findings,
+		ValidatedAt:    rps.ValidatedAt,
+	}
+	for _, finding := range findings {
+		if finding.Severity == severityError {
+			report.ErrorCount++
+		} else {
+			report.WarningCount++
+		}
+	}
+	report.Valid = report.ErrorCount == 0
+	return report, nil
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"gorm.io/datatypes"
+)
+
+// validRPS returns an RPS of a 3 SKS course that passes every rule
+func validRPS() dto.RPSStructuredOutput {
+	rps := dto.RPSStructuredOutput{
+		Identitas: dto.RPSIdentitas{NamaMataKuliah: "Basis Data", KodeMataKuliah: "IF201", SKS: 3},
+		CapaianPembelajaran: dto.RPSCapaianPembelajaran{
+			CPMK:    []string{"CPMK-01"},
+			SubCPMK: []string{"Sub-CPMK-01"},
+		},
+		DeskripsiMataKuliah: dto.RPSDeskripsi{DeskripsiSingkat: "Konsep basis data relasional."},
+		RencanaPenilaian: dto.RPSPenilaian{
+			Komponen: []dto.RPSKomponenPenilaian{
+				{Nama: "Tugas", Bobot: 30},
+				{Nama: "UTS", Bobot: 30},
+				{Nama: "UAS", Bobot: 40},
+			},
+		},
+		DaftarReferensi: dto.RPSReferensi{Utama: []string{"Database System Concepts"}},
+	}
+	for week := 1; week <= rpsWeekCount; week++ {
+		plan := dto.RPSRencanaMingguan{Minggu: week, Topik: fmt.Sprintf("Topik %d", week), WaktuMenit: 150, BentukPenilaian: "Tugas"}
+		switch week {
+		case rpsUTSWeek:
+			plan.Topik = "Ujian Tengah Semester"
+		case rpsUASWeek:
+			plan.Topik = "Ujian Akhir Semester"
+		}
+		rps.RencanaMingguan = append(rps.RencanaMingguan, plan)
+	}
+	return rps
+}
+
+func TestValidateRPSResult(t *testing.T) {
+	course := &models.Course{Code: "IF201", Title: "Basis Data", Credits: intPtr(3)}
+
+	tests := []struct {
+		name   string
+		mutate func(rps *dto.RPSStructuredOutput)
+		course *models.Course
+		want   []string // severity:rule of every finding, sorted
+	}{
+		{
+			name:   "valid",
+			course: course,
+		},
+		{
+			name:   "valid without course",
+			mutate: func(rps *dto.RPSStructuredOutput) { rps.Identitas.KodeMataKuliah = "XX999" },
+		},
+		{
+			name: "code and name compare case-insensitively",
+			mutate: func(rps *dto.RPSStructuredOutput) {
+				rps.Identitas.KodeMataKuliah = " if201 "
+				rps.Identitas.NamaMataKuliah = "BASIS DATA"
+			},
+			course: course,
+		},
+		{
+			name: "course mismatch",
+			mutate: func(rps *dto.RPSStructuredOutput) {
+				rps.Identitas.KodeMataKuliah = "IF999"
+				rps.Identitas.NamaMataKuliah = "Jaringan Komputer"
+			},
+			course: course,
+			want:   []string{"error:identitas.kode", "warning:identitas.nama"},
+		},
+		{
+			name: "sks differs from the course",
+			mutate: func(rps *dto.RPSStructuredOutput) {
+				rps.Identitas.SKS = 2
+				for i := range rps.RencanaMingguan {
+					rps.RencanaMingguan[i].WaktuMenit = 100
+				}
+			},
+			course: course,
+			want:   []string{"error:identitas.sks"},
+		},
+		{
+			name: "missing sks",
+			mutate: func(rps *dto.RPSStructuredOutput) {
+				rps.Identitas.SKS = 0
+			},
+			want: []string{"error:identitas.sks"},
+		},
+		{
+			name: "empty learning outcomes and description",
+			mutate: func(rps *dto.RPSStructuredOutput) {
+				rps.CapaianPembelajaran = dto.RPSCapaianPembelajaran{}
+				rps.DeskripsiMataKuliah.DeskripsiSingkat = "  "
+				rps.DaftarReferensi.Utama = nil
+			},
+			want: []string{"warning:capaian.cpmk", "warning:capaian.sub_cpmk", "warning:deskripsi.singkat", "warning:referensi.utama"},
+		},
+		{
+			name:   "missing last week",
+			mutate: func(rps *dto.RPSStructuredOutput) { rps.RencanaMingguan = rps.RencanaMingguan[:rpsWeekCount-1] },
+			want:   []string{"error:mingguan.jumlah"},
+		},
+		{
+			name: "weeks out of order and duplicated",
+			mutate: func(rps *dto.RPSStructuredOutput) {
+				rps.RencanaMingguan[2].Minggu = 2
+			},
+			want: []string{"error:mingguan.duplikat", "error:mingguan.urutan"},
+		},
+		{
+			name:   "week without topic",
+			mutate: func(rps *dto.RPSStructuredOutput) { rps.RencanaMingguan[0].Topik = "" },
+			want:   []string{"error:mingguan.topik"},
+		},
+		{
+			name: "exams in the wrong weeks",
+			mutate: func(rps *dto.RPSStructuredOutput) {
+				rps.RencanaMingguan[rpsUTSWeek-1].Topik = "Normalisasi"
+				rps.RencanaMingguan[rpsUASWeek-1].Topik = "Review"
+				rps.RencanaMingguan[3].BentukPenilaian = "UTS"
+			},
+			want: []string{"error:mingguan.uas", "error:mingguan.uts", "warning:mingguan.ujian"},
+		},
+		{
+			name: "exam found in the assessment form",
+			mutate: func(rps *dto.RPSStructuredOutput) {
+				rps.RencanaMingguan[rpsUTSWeek-1].Topik = "Evaluasi"
+				rps.RencanaMingguan[rpsUTSWeek-1].BentukPenilaian = "Midterm"
+			},
+		},
+		{
+			name: "meeting length off the SKS",
+			mutate: func(rps *dto.RPSStructuredOutput) {
+				rps.RencanaMingguan[0].WaktuMenit = 100
+				rps.RencanaMingguan[rpsUTSWeek-1].WaktuMenit = 120
+			},
+			want: []string{"error:mingguan.waktu", "warning:mingguan.waktu"},
+		},
+		{
+			name:   "no assessment components",
+			mutate: func(rps *dto.RPSStructuredOutput) { rps.RencanaPenilaian.Komponen = nil },
+			want:   []string{"error:penilaian.komponen"},
+		},
+		{
+			name: "weights off 100 and not positive",
+			mutate: func(rps *dto.RPSStructuredOutput) {
+				rps.RencanaPenilaian.Komponen[0].Bobot = 0
+			},
+			want: []string{"error:penilaian.bobot", "error:penilaian.total_bobot"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rps := validRPS()
+			if tt.mutate != nil {
+				tt.mutate(&rps)
+			}
+			result, err := json.Marshal(rps)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := findingKeys(ValidateRPSResult(datatypes.JSON(result), tt.course))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("ValidateRPSResult() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRPSResultUnreadable(t *testing.T) {
+	tests := []struct {
+		name   string
+		result datatypes.JSON
+		want   string
+	}{
+		{"empty", nil, "error:result.present"},
+		{"not an object", datatypes.JSON(`[1, 2]`), "error:result.schema"},
+		{"wrong field type", datatypes.JSON(`{"identitas": {"sks": "tiga"}}`), "error:result.schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findingKeys(ValidateRPSResult(tt.result, nil))
+			if len(got) != 1 || got[0] != tt.want {
+				t.Fatalf("ValidateRPSResult() = %v, want [%s]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyValidation(t *testing.T) {
+	rps := validRPS()
+	rps.RencanaPenilaian.Komponen = nil
+	result, _ := json.Marshal(rps)
+	row := &models.GeneratedRPS{Result: datatypes.JSON(result)}
+
+	findings := applyValidation(row)
+	if !hasValidationErrors(findings) {
+		t.Fatalf("applyValidation() = %v, want an error finding", findings)
+	}
+	if row.ValidatedAt == nil {
+		t.Fatal("ValidatedAt was not set")
+	}
+
+	report, err := toValidationReport(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Valid || report.ErrorCount != 1 || report.WarningCount != 0 {
+		t.Fatalf("report = %+v, want one error", report)
+	}
+}
+
+func findingKeys(findings []dto.ValidationFinding) []string {
+	keys := []string{}
+	for _, finding := range findings {
+		keys = append(keys, finding.Severity+":"+finding.Rule)
+	}
+	sort.Strings(keys)
+	return keys
+}
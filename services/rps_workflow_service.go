@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/syrlramadhan/dokumentasi-rps-api/dto"
+	"github.com/syrlramadhan/dokumentasi-rps-api/helper"
+	"github.com/syrlramadhan/dokumentasi-rps-api/models"
+	"github.com/syrlramadhan/dokumentasi-rps-api/repositories"
+)
+
+// Workflow actions, each exposed as its own endpoint
+const (
+	WorkflowActionSubmit         = "submit"
+	WorkflowActionRequestChanges = "request_changes"
+	WorkflowActionApprove        = "approve"
+	WorkflowActionPublish        = "publish"
+	WorkflowActionReopen         = "reopen"
+)
+
+var (
+	ErrUnknownWorkflowAction   = fmt.Errorf("unknown workflow action: %w", helper.ErrInvalidInput)
+	ErrWorkflowTransition      = fmt.Errorf("workflow transition not allowed: %w", helper.ErrInvalidInput)
+	ErrWorkflowCommentRequired = fmt.Errorf("a comment is required for this transition: %w", helper.ErrInvalidInput)
+	ErrWorkflowConflict        = errors.New("workflow status was changed by another request")
+	ErrRPSLocked               = errors.New("RPS content is locked while in review, approved or published")
+)
+
+// workflowRule describes which states an action leaves from, where it leads and who may take it
+type workflowRule struct {
+	from            []string
+	to              string
+	roles           []string
+	commentRequired bool
+}
+
+var workflowRules = map[string]workflowRule{
+	WorkflowActionSubmit: {
+		from:  []string{models.WorkflowDraft, models.WorkflowChangesRequested},
+		to:    models.WorkflowInReview,
+		roles: []string{models.RoleAdmin, models.RoleKaprodi, models.RoleDosen},
+	},
+	WorkflowActionRequestChanges: {
+		from:            []string{models.WorkflowInReview},
+		to:              models.WorkflowChangesRequested,
+		roles:           []string{models.RoleAdmin, models.RoleKaprodi},
+		commentRequired: true,
+	},
+	WorkflowActionApprove: {
+		from:  []string{models.WorkflowInReview},
+		to:    models.WorkflowApproved,
+		roles: []string{models.RoleAdmin, models.RoleKaprodi},
+	},
+	WorkflowActionPublish: {
+		from:  []string{models.WorkflowApproved},
+		to:    models.WorkflowPublished,
+		roles: []string{models.RoleAdmin, models.RoleKaprodi},
+	},
+	WorkflowActionReopen: {
+		from:            []string{models.WorkflowApproved, models.WorkflowPublished},
+		to:              models.WorkflowDraft,
+		roles:           []string{models.RoleAdmin, models.RoleKaprodi},
+		commentRequired: true,
+	},
+}
+
+// RPSWorkflowService moves generated RPS through draft → in_review → approved → published.
+// Resource scope (own program / assigned course) is enforced by the routes; the service
+// checks the role allowed for each transition.
+type RPSWorkflowService interface {
+	Transition(id uuid.UUID, action string, actor *models.User, req *dto.RPSWorkflowTransitionRequest) (*dto.GeneratedRPSResponse, error)
+	FindTransitions(id uuid.UUID) ([]dto.RPSWorkflowTransitionResponse, error)
+}
+
+type rpsWorkflowService struct {
+	repo repositories.GeneratedRPSRepository
+}
+
+func NewRPSWorkflowService(repo repositories.GeneratedRPSRepository) RPSWorkflowService {
+	return &rpsWorkflowService{repo: repo}
+}
+
+func (s *rpsWorkflowService) Transition(id uuid.UUID, action string, actor *models.User, req *dto.RPSWorkflowTransitionRequest) (*dto.GeneratedRPSResponse, error) {
+	rule, ok := workflowRules[action]
+	if !ok {
+		return nil, ErrUnknownWorkflowAction
+	}
+	if err := helper.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+	if !containsString(rule.roles, actor.Role) {
+		return nil, helper.ErrForbidden
+	}
+
+	var comment *string
+	if req.Comment != nil && strings.TrimSpace(*req.Comment) != "" {
+		trimmed := strings.TrimSpace(*req.Comment)
+		comment = &trimmed
+	}
+	if rule.commentRequired && comment == nil {
+		return nil, ErrWorkflowCommentRequired
+	}
+
+	rps, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if action == WorkflowActionSubmit && (rps.Status != "done" || len(rps.Result) == 0) {
+		return nil, ErrRPSNotReady
+	}
+
+	from := rps.WorkflowStatus
+	if from == "" {
+		from = models.WorkflowDraft
+	}
+	if !containsString(rule.from, from) {
+		return nil, fmt.Errorf("cannot %s an RPS in %s: %w", strings.ReplaceAll(action, "_", " "), from, ErrWorkflowTransition)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"updated_at": now}
+	switch action {
+	case WorkflowActionSubmit:
+		updates["submitted_at"] = now
+	case WorkflowActionRequestChanges:
+		updates["reviewed_at"] = now
+		updates["reviewed_by"] = actor.ID
+	case WorkflowActionApprove:
+		updates["reviewed_at"] = now
+		updates["reviewed_by"] = actor.ID
+		updates["approved_at"] = now
+	case WorkflowActionPublish:
+		updates["published_at"] = now
+	case WorkflowActionReopen:
+		updates["approved_at"] = nil
+		updates["published_at"] = nil
+	}
+
+	transition := &models.RPSWorkflowTransition{
+		ID:             uuid.New(),
+		GeneratedRPSID: rps.ID,
+		Action:         action,
+		FromStatus:     from,
+		ToStatus:       rule.to,
+		ActorID:        &actor.ID,
+		Comment:        comment,
+		CreatedAt:      now,
+	}
+
+	applied, err := s.repo.ApplyTransition(transition, updates)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	if !applied {
+		return nil, ErrWorkflowConflict
+	}
+
+	rps, err = s.repo.FindByID(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	return helper.ToGeneratedRPSResponse(rps), nil
+}
+
+func (s *rpsWorkflowService) FindTransitions(id uuid.UUID) ([]dto.RPSWorkflowTransitionResponse, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+
+	transitions, err := s.repo.FindTransitions(id)
+	if err != nil {
+		return nil, helper.WrapDatabaseError(err)
+	}
+	return helper.ToRPSWorkflowTransitionResponseList(transitions), nil
+}
+
+// isContentLocked reports whether the RPS content may no longer be edited or regenerated;
+// reviewers judge a fixed version, and changes start again from a reopen
+func isContentLocked(rps *models.GeneratedRPS) bool {
+	return containsString(models.ContentLockedStatuses, rps.WorkflowStatus)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
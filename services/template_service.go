@@ -84,6 +84,7 @@ func (s *templateService) Update(id uuid.UUID, req *dto.UpdateTemplateRequest) (
 		return nil, helper.WrapDatabaseError(err)
 	}
 
+	moved := req.ProgramID != nil && !sameProgram(template.ProgramID, req.ProgramID)
 	if req.ProgramID != nil {
 		template.ProgramID = req.ProgramID
 	}
@@ -100,6 +101,12 @@ func (s *templateService) Update(id uuid.UUID, req *dto.UpdateTemplateRequest) (
 	if err := s.repo.Update(template); err != nil {
 		return nil, helper.WrapDatabaseError(err)
 	}
+	if moved {
+		// Reload the new program for the response
+		if template, err = s.repo.FindByID(id); err != nil {
+			return nil, helper.WrapDatabaseError(err)
+		}
+	}
 
 	return helper.ToTemplateResponse(template), nil
 }
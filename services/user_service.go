@@ -30,6 +30,12 @@ func (s *userService) Create(req *dto.CreateUserRequest) (*dto.UserResponse, err
 	}
 
 	user := helper.ToUserModel(req)
+	passwordHash, err := helper.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+	user.PasswordHash = passwordHash
+
 	if err := s.repo.Create(user); err != nil {
 		return nil, helper.WrapDatabaseError(err)
 	}
@@ -86,6 +92,16 @@ func (s *userService) Update(id uuid.UUID, req *dto.UpdateUserRequest) (*dto.Use
 	if req.Role != nil {
 		user.Role = *req.Role
 	}
+	if req.ProgramID != nil {
+		user.ProgramID = req.ProgramID
+	}
+	if req.Password != nil {
+		passwordHash, err := helper.HashPassword(*req.Password)
+		if err != nil {
+			return nil, err
+		}
+		user.PasswordHash = passwordHash
+	}
 
 	if err := s.repo.Update(user); err != nil {
 		return nil, helper.WrapDatabaseError(err)
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// xlsxSharedString is a shared or inline string; rich text keeps its runs in R
+type xlsxSharedString struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s xlsxSharedString) text() string {
+	var text strings.Builder
+	text.WriteString(s.T)
+	for _, run := range s.R {
+		text.WriteString(run.T)
+	}
+	return text.String()
+}
+
+type xlsxCell struct {
+	Ref    string           `xml:"r,attr"`
+	Type   string           `xml:"t,attr"`
+	Value  string           `xml:"v"`
+	Inline xlsxSharedString `xml:"is"`
+}
+
+type xlsxRow struct {
+	Number *int       `xml:"r,attr"`
+	Cells  []xlsxCell `xml:"c"`
+}
+
+// maxXLSXColumns is the column limit of SpreadsheetML (XFD)
+const maxXLSXColumns = 16384
+
+// readXLSXRows is a minimal SpreadsheetML (.xlsx) reader covering what catalog imports
+// need: the cell values of the first worksheet from shared strings, inline strings,
+// numbers and the cached value of formulas. rows[i] holds spreadsheet row i+1.
+func readXLSXRows(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx file: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var sharedStrings []string
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxSharedString `xml:"si"`
+		}
+		if err := decodeXLSXPart(file, &sst); err != nil {
+			return nil, err
+		}
+		for _, item := range sst.Items {
+			sharedStrings = append(sharedStrings, item.text())
+		}
+	}
+
+	sheet, ok := files[firstXLSXSheet(files)]
+	if !ok {
+		return nil, fmt.Errorf("invalid xlsx file: worksheet not found")
+	}
+	var worksheet struct {
+		Rows []xlsxRow `xml:"sheetData>row"`
+	}
+	if err := decodeXLSXPart(sheet, &worksheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for i, row := range worksheet.Rows {
+		number := i + 1
+		if row.Number != nil {
+			number = *row.Number
+		}
+		// Checked before padding so a far-off row number cannot allocate without bound
+		if number < 1 {
+			return nil, fmt.Errorf("invalid xlsx file: bad row number %d", number)
+		}
+		if number > maxImportRows+1 {
+			return nil, fmt.Errorf("invalid xlsx file: row %d is beyond the %d row limit", number, maxImportRows+1)
+		}
+		for len(rows) < number {
+			rows = append(rows, nil)
+		}
+
+		var values []string
+		for j, cell := range row.Cells {
+			column := j
+			if cell.Ref != "" {
+				column = xlsxColumn(cell.Ref)
+			}
+			if column < 0 || column >= maxXLSXColumns {
+				return nil, fmt.Errorf("invalid xlsx file: bad cell reference %q in row %d", cell.Ref, number)
+			}
+			for len(values) <= column {
+				values = append(values, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(sharedStrings) {
+					return nil, fmt.Errorf("invalid xlsx file: bad shared string in %s", cell.Ref)
+				}
+				values[column] = sharedStrings[index]
+			case "inlineStr":
+				values[column] = cell.Inline.text()
+			default:
+				values[column] = cell.Value
+			}
+		}
+		rows[number-1] = values
+	}
+	return rows, nil
+}
+
+// firstXLSXSheet resolves the part name of the first sheet listed in the workbook
+func firstXLSXSheet(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	workbookFile, ok := files["xl/workbook.xml"]
+	relsFile, relsOK := files["xl/_rels/workbook.xml.rels"]
+	if !ok || !relsOK {
+		return fallback
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if decodeXLSXPart(workbookFile, &workbook) != nil || decodeXLSXPart(relsFile, &rels) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+func decodeXLSXPart(file *zip.File, v interface{}) error {
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("invalid xlsx file: %w", err)
+	}
+	defer reader.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(reader, MaxImportBytes*10)).Decode(v); err != nil {
+		return fmt.Errorf("invalid xlsx file: %w", err)
+	}
+	return nil
+}
+
+// xlsxColumn converts the letters of a cell reference such as "AB12" to a zero-based
+// column, or -1 when the reference has no column within maxXLSXColumns
+func xlsxColumn(ref string) int {
+	column := 0
+	for _, r := range strings.ToUpper(ref) {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+		if column > maxXLSXColumns {
+			return -1
+		}
+	}
+	return column - 1
+}
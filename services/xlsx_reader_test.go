@@ -0,0 +1,141 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// buildXLSX zips the given parts into an in-memory workbook
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		file, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const (
+	xlsxSharedStrings = `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>code</t></si>
+<si><t>title</t></si>
+<si><t>credits</t></si>
+<si><r><t>Basis </t></r><r><t>Data</t></r></si>
+</sst>`
+
+	xlsxCoursesSheet = `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><t>IF201</t></is></c><c r="B2" t="s"><v>3</v></c><c r="C2"><v>3</v></c></row>
+<row r="4"><c r="A4" t="str"><v>IF202</v></c><c r="C4"><f>1+1</f><v>2</v></c></row>
+</sheetData>
+</worksheet>`
+)
+
+func TestReadXLSXRows(t *testing.T) {
+	data := buildXLSX(t, map[string]string{
+		"xl/sharedStrings.xml":     xlsxSharedStrings,
+		"xl/worksheets/sheet1.xml": xlsxCoursesSheet,
+	})
+
+	rows, err := readXLSXRows(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"code", "title", "credits"},
+		{"IF201", "Basis Data", "3"},
+		nil,
+		{"IF202", "", "2"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("readXLSXRows() = %q, want %q", rows, want)
+	}
+}
+
+func TestReadXLSXRowsFirstSheetFromWorkbook(t *testing.T) {
+	data := buildXLSX(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Courses" sheetId="2" r:id="rId7"/><sheet name="Other" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId7" Target="/xl/worksheets/courses.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml":  `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>wrong</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/courses.xml": `<worksheet><sheetData><row r="1"><c r="B1" t="inlineStr"><is><t>right</t></is></c></row></sheetData></worksheet>`,
+	})
+
+	rows, err := readXLSXRows(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"", "right"}}; !reflect.DeepEqual(rows, want) {
+		t.Fatalf("readXLSXRows() = %q, want %q", rows, want)
+	}
+}
+
+// xlsxSheet builds a workbook whose only worksheet holds rows
+func xlsxSheet(t *testing.T, rows string) []byte {
+	return buildXLSX(t, map[string]string{"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` + rows + `</sheetData></worksheet>`})
+}
+
+func TestReadXLSXRowsLowercaseReference(t *testing.T) {
+	rows, err := readXLSXRows(xlsxSheet(t, `<row r="1"><c r="b1"><v>7</v></c></row>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"", "7"}}; !reflect.DeepEqual(rows, want) {
+		t.Fatalf("readXLSXRows() = %q, want %q", rows, want)
+	}
+}
+
+func TestReadXLSXRowsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not a zip", []byte("code,title\n")},
+		{"no worksheet", buildXLSX(t, map[string]string{"xl/sharedStrings.xml": xlsxSharedStrings})},
+		{"shared string out of range", buildXLSX(t, map[string]string{
+			"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>9</v></c></row></sheetData></worksheet>`,
+		})},
+		{"malformed sheet", buildXLSX(t, map[string]string{"xl/worksheets/sheet1.xml": `<worksheet><sheetData>`})},
+		{"cell reference without column", xlsxSheet(t, `<row r="1"><c r="12"><v>1</v></c></row>`)},
+		{"column beyond XFD", xlsxSheet(t, `<row r="1"><c r="ABCDEFG1"><v>1</v></c></row>`)},
+		{"negative row", xlsxSheet(t, `<row r="-3"><c r="A1"><v>1</v></c></row>`)},
+		{"zero row", xlsxSheet(t, `<row r="0"><c r="A1"><v>1</v></c></row>`)},
+		{"row beyond the import limit", xlsxSheet(t, `<row r="999999999"><c r="A1"><v>1</v></c></row>`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := readXLSXRows(tt.data); err == nil {
+				t.Fatal("readXLSXRows() = nil error")
+			}
+		})
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	tests := map[string]int{
+		"A1": 0, "C12": 2, "Z3": 25, "AA1": 26, "AB12": 27, "BA7": 52, "XFD1": 16383,
+		"ab12": 27, "12": -1, "": -1, "XFE1": -1, "ABCDEFG1": -1,
+	}
+	for ref, want := range tests {
+		if got := xlsxColumn(ref); got != want {
+			t.Errorf("xlsxColumn(%q) = %d, want %d", ref, got, want)
+		}
+	}
+}